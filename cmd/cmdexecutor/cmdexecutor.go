@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"strings"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/libopenstorage/stork/pkg/version"
 	"github.com/portworx/sched-ops/k8s/core"
 	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -19,6 +21,7 @@ import (
 const (
 	defaultStatusCheckTimeout = 900
 	statusFile                = "/tmp/cmdexecutor-status"
+	serviceAccountNamespace   = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
 )
 
 type arrayFlags []string
@@ -104,6 +107,10 @@ func main() {
 		logrus.Fatalf(err.Error())
 	}
 
+	// Report the status of the commands in a config map for the task so that
+	// the caller can watch it instead of polling this pod
+	createTaskStatus(hostname)
+
 	var podNames []types.NamespacedName
 	// Get list of specified by the -pod args.
 	if podListSpecified {
@@ -132,6 +139,8 @@ func main() {
 		err = executor.Start(errChan)
 		if err != nil {
 			msg := fmt.Sprintf("failed to run command in pod: [%s] %s due to: %v", pod.Namespace, pod.Name, err)
+			persistPodStatus(pod.Namespace, pod.Name, status.TaskFailed, err.Error())
+			persistTaskStatus(status.TaskFailed, msg)
 			persistStatusErr := status.Persist(hostname, msg)
 			if persistStatusErr != nil {
 				logrus.Warnf("failed to persist cmd executor status due to: %v", persistStatusErr)
@@ -159,12 +168,15 @@ func main() {
 		ns, name := executor.GetPod()
 		podKey := createPodStringFromNameAndNamespace(ns, name)
 		go func(errChan chan error, doneChan chan bool, execInst cmdexecutor.Executor) {
+			ns, name := execInst.GetPod()
 			err := execInst.Wait(time.Duration(statusCheckTimeout) * time.Second)
 			if err != nil {
+				persistPodStatus(ns, name, status.TaskFailed, err.Error())
 				errChan <- err
 				return
 			}
 
+			persistPodStatus(ns, name, status.TaskSucceeded, "")
 			doneChan <- true
 		}(errChans[podKey], done, executor)
 	}
@@ -178,6 +190,7 @@ Loop:
 		select {
 		case err := <-aggErrorChan:
 			// If we hit any error, persist the error using hostname as key and then exit
+			persistTaskStatus(status.TaskFailed, err.Error())
 			persistStatusErr := status.Persist(hostname, err.Error())
 			if persistStatusErr != nil {
 				logrus.Warnf("failed to persist cmd executor status due to: %v", persistStatusErr)
@@ -194,6 +207,7 @@ Loop:
 					if err != nil {
 						logrus.Fatalf("failed to create statusfile: %s due to: %v", statusFile, err)
 					}
+					persistTaskStatus(status.TaskSucceeded, "")
 					// All executors are done, we can exit successfully now
 					break Loop
 				}
@@ -202,6 +216,38 @@ Loop:
 	}
 }
 
+// createTaskStatus creates the config map for the status of the task, owned
+// by the pod of the command executor. Failures are only logged since the
+// caller falls back to watching the pod.
+func createTaskStatus(hostname string) {
+	var owner *v1.Pod
+	podNamespace := metav1.NamespaceSystem
+	if ns, err := ioutil.ReadFile(serviceAccountNamespace); err == nil && len(ns) > 0 {
+		podNamespace = strings.TrimSpace(string(ns))
+	}
+	pod, err := core.Instance().GetPodByName(hostname, podNamespace)
+	if err != nil {
+		logrus.Warnf("failed to get command executor pod: [%s] %s due to: %v", podNamespace, hostname, err)
+	} else {
+		owner = pod
+	}
+	if err := status.CreateTaskStatus(taskID, owner); err != nil {
+		logrus.Warnf("failed to create cmd executor task status due to: %v", err)
+	}
+}
+
+func persistPodStatus(podNamespace, podName string, state status.TaskState, message string) {
+	if err := status.PersistPodStatus(taskID, podNamespace, podName, state, message); err != nil {
+		logrus.Warnf("failed to persist cmd executor status for pod: [%s] %s due to: %v", podNamespace, podName, err)
+	}
+}
+
+func persistTaskStatus(state status.TaskState, message string) {
+	if err := status.PersistTaskStatus(taskID, state, message); err != nil {
+		logrus.Warnf("failed to persist cmd executor task status due to: %v", err)
+	}
+}
+
 func getPodNamesFromArgs(podList []string) ([]types.NamespacedName, error) {
 	var podNames []types.NamespacedName
 	for _, pod := range podList {
@@ -0,0 +1,52 @@
+//go:build storkscheduler
+// +build storkscheduler
+
+// The stork scheduler is kube-scheduler with the stork plugin registered, so
+// that pods don't need the round-trips to the stork scheduler extender.
+// k8s.io/kubernetes/cmd/kube-scheduler/app isn't vendored with the rest of
+// stork, so it is only built with the storkscheduler build tag and -mod=mod,
+// see the stork-scheduler make target.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	_ "github.com/libopenstorage/stork/drivers/volume/aws"
+	_ "github.com/libopenstorage/stork/drivers/volume/azure"
+	_ "github.com/libopenstorage/stork/drivers/volume/csi"
+	_ "github.com/libopenstorage/stork/drivers/volume/gcp"
+	_ "github.com/libopenstorage/stork/drivers/volume/linstor"
+	_ "github.com/libopenstorage/stork/drivers/volume/portworx"
+	"github.com/libopenstorage/stork/pkg/extender"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubernetes/cmd/kube-scheduler/app"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func main() {
+	var driverName string
+	// The plugin is created after the flags have been parsed
+	factory := func(obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+		if driverName == "" {
+			return nil, fmt.Errorf("storage driver name should be specified with --driver")
+		}
+		d, err := volume.Get(driverName)
+		if err != nil {
+			return nil, fmt.Errorf("error getting Stork Driver %v: %v", driverName, err)
+		}
+		if err := d.Init(nil); err != nil {
+			return nil, fmt.Errorf("error initializing Stork Driver %v: %v", driverName, err)
+		}
+		log.Infof("Using driver %v", driverName)
+		return extender.NewPluginFactory(d)(obj, handle)
+	}
+
+	command := app.NewSchedulerCommand(app.WithPlugin(extender.PluginName, factory))
+	command.Flags().StringVar(&driverName, "driver", "", "Storage driver name")
+	if err := command.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -206,6 +206,16 @@ func main() {
 			Name:  controllers.ResourceTransformationControllerName,
 			Usage: "Start the resource transformation controller (default: true)",
 		},
+		cli.IntFlag{
+			Name:  "max-concurrent-scheduled-operations",
+			Value: 0,
+			Usage: "Max number of backups and migrations triggered by schedules that can run at the same time, 0 for no limit (default: 0)",
+		},
+		cli.IntFlag{
+			Name:  "scheduled-operation-jitter-seconds",
+			Value: 0,
+			Usage: "Max random delay in seconds added before starting a backup or migration triggered by a schedule (default: 0)",
+		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -435,6 +445,8 @@ func runStork(mgr manager.Manager, d volume.Driver, recorder record.EventRecorde
 	if err := schedule.Init(); err != nil {
 		log.Fatalf("Error initializing schedule: %v", err)
 	}
+	schedule.SetTriggerLimits(c.Int("max-concurrent-scheduled-operations"),
+		time.Duration(c.Int("scheduled-operation-jitter-seconds"))*time.Second)
 	if d != nil {
 		if c.Bool("health-monitor") {
 			if err := monitor.Start(); err != nil {
@@ -451,7 +463,7 @@ func runStork(mgr manager.Manager, d volume.Driver, recorder record.EventRecorde
 				Driver:   d,
 				Recorder: recorder,
 			}
-			if err := groupsnapshotInst.Init(mgr); err != nil {
+			if err := groupsnapshotInst.Init(mgr, adminNamespace); err != nil {
 				log.Fatalf("Error initializing groupsnapshot controller: %v", err)
 			}
 		}
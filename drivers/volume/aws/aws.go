@@ -70,6 +70,7 @@ type aws struct {
 	storkvolume.ClusterDomainsNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.FileRestoreNotSupported
 }
 
 func (a *aws) Init(_ interface{}) error {
@@ -185,6 +186,7 @@ func isCsiProvisioner(provisioner string) bool {
 
 func (a *aws) StartBackup(backup *storkapi.ApplicationBackup,
 	pvcs []v1.PersistentVolumeClaim,
+	parents map[string]*storkapi.ApplicationBackupVolumeInfo,
 ) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
 	client, err := a.getAWSClient(backup.Spec.BackupLocation, backup.Namespace)
 	if err != nil {
@@ -59,6 +59,7 @@ type azure struct {
 	storkvolume.ClusterDomainsNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.FileRestoreNotSupported
 }
 
 type azureSession struct {
@@ -258,6 +259,7 @@ func (a *azure) findExistingSnapshot(tags map[string]string, snapshotClient comp
 func (a *azure) StartBackup(
 	backup *storkapi.ApplicationBackup,
 	pvcs []v1.PersistentVolumeClaim,
+	parents map[string]*storkapi.ApplicationBackupVolumeInfo,
 ) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
 	azureSession, err := a.getAzureSession(backup.Spec.BackupLocation, backup.Namespace)
 	if err != nil {
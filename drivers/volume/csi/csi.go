@@ -15,7 +15,6 @@ import (
 	storkvolume "github.com/libopenstorage/stork/drivers/volume"
 	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/applicationmanager/controllers"
-	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/errors"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
 	"github.com/libopenstorage/stork/pkg/log"
@@ -34,6 +33,8 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -161,12 +162,13 @@ func (cbo *csiBackupObject) GetVolumeSnapshotClass(snapshotID string) (interface
 
 type csi struct {
 	snapshotClient     *kSnapshotClient.Clientset
+	dynamicClient      dynamic.Interface
+	discoveryClient    discovery.DiscoveryInterface
 	snapshotter        snapshotter.Driver
 	v1SnapshotRequired bool
 
 	storkvolume.ClusterPairNotSupported
 	storkvolume.MigrationNotSupported
-	storkvolume.GroupSnapshotNotSupported
 	storkvolume.ClusterDomainsNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
@@ -184,6 +186,16 @@ func (c *csi) Init(_ interface{}) error {
 	}
 	c.snapshotClient = cs
 
+	c.dynamicClient, err = dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c.discoveryClient, err = discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return err
+	}
+
 	c.v1SnapshotRequired, err = version.RequiresV1VolumeSnapshot()
 	if err != nil {
 		return err
@@ -469,6 +481,7 @@ func (c *csi) cancelBackupDuringStartFailure(backup *storkapi.ApplicationBackup,
 func (c *csi) StartBackup(
 	backup *storkapi.ApplicationBackup,
 	pvcs []v1.PersistentVolumeClaim,
+	parents map[string]*storkapi.ApplicationBackupVolumeInfo,
 ) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
 	volumeInfos := make([]*storkapi.ApplicationBackupVolumeInfo, 0)
 	var storageClasses []*storagev1.StorageClass
@@ -551,32 +564,16 @@ func (c *csi) uploadObject(
 	if err != nil {
 		return err
 	}
-	if backupLocation.Location.EncryptionKey != "" {
-		return fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
-	}
-	if backupLocation.Location.EncryptionV2Key != "" {
-		if data, err = crypto.Encrypt(data, backupLocation.Location.EncryptionV2Key); err != nil {
-			return err
-		}
-	}
-
-	objectPath := controllers.GetObjectPath(backup)
-	writer, err := bucket.NewWriter(context.TODO(), filepath.Join(objectPath, objectName), nil)
+	dataKey, err := objectstore.GetDataKey(backupLocation, backup)
 	if err != nil {
 		return err
 	}
-
-	_, err = writer.Write(data)
-	if err != nil {
-		closeErr := writer.Close()
-		if closeErr != nil {
-			log.ApplicationBackupLog(backup).Errorf("error closing writer for objectstore: %v", closeErr)
-		}
+	if data, err = objectstore.Encrypt(backupLocation, dataKey, data); err != nil {
 		return err
 	}
-	err = writer.Close()
-	if err != nil {
-		log.ApplicationBackupLog(backup).Errorf("error closing writer for objectstore: %v", err)
+
+	if err := objectstore.UploadObject(bucket, backupLocation, controllers.GetObjectPath(backup), objectName, data); err != nil {
+		log.ApplicationBackupLog(backup).Errorf("error uploading %v to objectstore: %v", objectName, err)
 		return err
 	}
 	return nil
@@ -1239,16 +1236,12 @@ func (c *csi) downloadObject(
 	if restoreLocation.Location.EncryptionKey != "" {
 		return nil, fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
 	}
-	if restoreLocation.Location.EncryptionV2Key != "" {
-		var decryptData []byte
-		if decryptData, err = crypto.Decrypt(data, restoreLocation.Location.EncryptionV2Key); err != nil {
-			logrus.Debugf("decrypt failed with: %v and returning the data as it is", err)
-			return data, nil
-		}
-		return decryptData, nil
+	decryptData, err := objectstore.Decrypt(restoreLocation, data)
+	if err != nil {
+		logrus.Debugf("decrypt failed with: %v and returning the data as it is", err)
+		return data, nil
 	}
-
-	return data, nil
+	return decryptData, nil
 }
 
 // getRestoreSnapshotsAndContent retrieves the volumeSnapshots and
@@ -1295,6 +1288,17 @@ func (c *csi) getBackupResources(restore *storkapi.ApplicationRestore) ([]runtim
 	if err != nil {
 		return nil, fmt.Errorf("error getting backup resources for CSI restore: %v", err)
 	}
+	if backup.Status.ResourcesFormat == storkapi.ApplicationBackupResourcesFormatStream ||
+		backup.Status.ResourcesFormat == storkapi.ApplicationBackupResourcesFormatIncremental {
+		backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if backup.Status.ResourcesFormat == storkapi.ApplicationBackupResourcesFormatIncremental {
+			return controllers.DownloadIncrementalResources(backup, backupLocation)
+		}
+		return controllers.DownloadResourceStream(backup, backupLocation)
+	}
 
 	backupObjectBytes, err := c.downloadObject(backup, backup.Spec.BackupLocation, backup.Namespace, resourcesObjectName)
 	if err != nil {
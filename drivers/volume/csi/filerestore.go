@@ -0,0 +1,176 @@
+package csi
+
+import (
+	"context"
+	"fmt"
+
+	kSnapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	kSnapshotv1beta1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1beta1"
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/snapshotter"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	fileRestoreUIDLabel = "fileRestoreUID"
+)
+
+func (c *csi) getFileRestoreUIDLabelSelector(fileRestore *storkapi.VolumeFileRestore) string {
+	return fmt.Sprintf("%s=%s", fileRestoreUIDLabel, string(fileRestore.GetUID()))
+}
+
+func (c *csi) getFileRestoreUIDLabels(fileRestore *storkapi.VolumeFileRestore, labels map[string]string) map[string]string {
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[fileRestoreUIDLabel] = string(fileRestore.GetUID())
+	return labels
+}
+
+// StartBackupVolumeMount recreates the snapshot of the volume from the backup
+// in the namespace of the file restore and provisions the PVC from it
+func (c *csi) StartBackupVolumeMount(
+	fileRestore *storkapi.VolumeFileRestore,
+	backup *storkapi.ApplicationBackup,
+	volumeInfo *storkapi.ApplicationBackupVolumeInfo,
+	pvc *v1.PersistentVolumeClaim,
+) error {
+	if c.snapshotClient == nil {
+		if err := c.Init(nil); err != nil {
+			return err
+		}
+	}
+	csiBackupObject, err := c.getCSIBackupObject(backup.Name, backup.Namespace)
+	if err != nil {
+		return err
+	}
+
+	snapshotID := volumeInfo.BackupID
+	vsc, err := csiBackupObject.GetVolumeSnapshotContent(snapshotID)
+	if err != nil {
+		return err
+	}
+	vs, err := csiBackupObject.GetVolumeSnapshot(snapshotID)
+	if err != nil {
+		return err
+	}
+	vsClass, err := csiBackupObject.GetVolumeSnapshotClass(snapshotID)
+	if err != nil {
+		return err
+	}
+	if _, err := c.restoreVolumeSnapshotClass(vsClass); err != nil {
+		return err
+	}
+
+	var snapshotName string
+	switch v := vs.(type) {
+	case *kSnapshotv1beta1.VolumeSnapshot:
+		vscObj := vsc.(*kSnapshotv1beta1.VolumeSnapshotContent)
+		vscObj.Name = c.getRestoreSnapshotContentName(vscObj.UID, fileRestore.UID)
+		vscObj.Labels = c.getFileRestoreUIDLabels(fileRestore, vscObj.Labels)
+		v.Name = c.getRestoreSnapshotName(v.UID, fileRestore.UID)
+		v.Labels = c.getFileRestoreUIDLabels(fileRestore, v.Labels)
+		if vs, err = c.restoreVolumeSnapshot(fileRestore.Namespace, v, vscObj); err != nil {
+			return err
+		}
+		if _, err = c.restoreVolumeSnapshotContent(fileRestore.Namespace, vs, vscObj); err != nil {
+			return err
+		}
+		snapshotName = vs.(*kSnapshotv1beta1.VolumeSnapshot).Name
+	case *kSnapshotv1.VolumeSnapshot:
+		vscObj := vsc.(*kSnapshotv1.VolumeSnapshotContent)
+		vscObj.Name = c.getRestoreSnapshotContentName(vscObj.UID, fileRestore.UID)
+		vscObj.Labels = c.getFileRestoreUIDLabels(fileRestore, vscObj.Labels)
+		v.Name = c.getRestoreSnapshotName(v.UID, fileRestore.UID)
+		v.Labels = c.getFileRestoreUIDLabels(fileRestore, v.Labels)
+		if vs, err = c.restoreVolumeSnapshot(fileRestore.Namespace, v, vscObj); err != nil {
+			return err
+		}
+		if _, err = c.restoreVolumeSnapshotContent(fileRestore.Namespace, vs, vscObj); err != nil {
+			return err
+		}
+		snapshotName = vs.(*kSnapshotv1.VolumeSnapshot).Name
+	default:
+		return fmt.Errorf("unknown type %T recieved for volumeSnapshot %v", v, v)
+	}
+	log.VolumeFileRestoreLog(fileRestore).Debugf("created vs: %s", snapshotName)
+
+	pvc, err = c.snapshotter.RestoreVolumeClaim(
+		snapshotter.RestoreSnapshotName(snapshotName),
+		snapshotter.RestoreNamespace(fileRestore.Namespace),
+		snapshotter.PVC(*pvc),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore pvc %s: %v", volumeInfo.PersistentVolumeClaim, err)
+	}
+	log.VolumeFileRestoreLog(fileRestore).Debugf("created pvc: %s", pvc.Name)
+	return nil
+}
+
+// GetBackupVolumeMountStatus returns true once the PVC provisioned from the
+// snapshot is bound
+func (c *csi) GetBackupVolumeMountStatus(fileRestore *storkapi.VolumeFileRestore) (bool, error) {
+	restoreInfo, err := c.snapshotter.RestoreStatus(fileRestore.Status.MountPVC, fileRestore.Namespace)
+	if err != nil {
+		return false, err
+	}
+	switch restoreInfo.Status {
+	case snapshotter.StatusReady:
+		return true, nil
+	case snapshotter.StatusFailed:
+		return false, fmt.Errorf("failed to provision pvc %v from snapshot: %v", fileRestore.Status.MountPVC, restoreInfo.Reason)
+	}
+	return false, nil
+}
+
+// CleanupBackupVolumeMount deletes the snapshots that were recreated from the
+// backup. The contents are retained since they point to the snapshots of the
+// backup
+func (c *csi) CleanupBackupVolumeMount(fileRestore *storkapi.VolumeFileRestore) error {
+	if c.snapshotClient == nil {
+		if err := c.Init(nil); err != nil {
+			return err
+		}
+	}
+	listOptions := metav1.ListOptions{
+		LabelSelector: c.getFileRestoreUIDLabelSelector(fileRestore),
+	}
+	if c.v1SnapshotRequired {
+		vsMap := make(map[string]*kSnapshotv1.VolumeSnapshot)
+		vsContentMap := make(map[string]*kSnapshotv1.VolumeSnapshotContent)
+		vsList, err := c.snapshotClient.SnapshotV1().VolumeSnapshots(fileRestore.Namespace).List(context.TODO(), listOptions)
+		if err != nil {
+			return err
+		}
+		for i := range vsList.Items {
+			vsMap[vsList.Items[i].Name] = &vsList.Items[i]
+		}
+		vsContentList, err := c.snapshotClient.SnapshotV1().VolumeSnapshotContents().List(context.TODO(), listOptions)
+		if err != nil {
+			return err
+		}
+		for i := range vsContentList.Items {
+			vsContentMap[vsContentList.Items[i].Name] = &vsContentList.Items[i]
+		}
+		return c.cleanupSnapshots(vsMap, vsContentMap, true)
+	}
+	vsMap := make(map[string]*kSnapshotv1beta1.VolumeSnapshot)
+	vsContentMap := make(map[string]*kSnapshotv1beta1.VolumeSnapshotContent)
+	vsList, err := c.snapshotClient.SnapshotV1beta1().VolumeSnapshots(fileRestore.Namespace).List(context.TODO(), listOptions)
+	if err != nil {
+		return err
+	}
+	for i := range vsList.Items {
+		vsMap[vsList.Items[i].Name] = &vsList.Items[i]
+	}
+	vsContentList, err := c.snapshotClient.SnapshotV1beta1().VolumeSnapshotContents().List(context.TODO(), listOptions)
+	if err != nil {
+		return err
+	}
+	for i := range vsContentList.Items {
+		vsContentMap[vsContentList.Items[i].Name] = &vsContentList.Items[i]
+	}
+	return c.cleanupSnapshots(vsMap, vsContentMap, true)
+}
@@ -0,0 +1,636 @@
+package csi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	crdv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	storkvolume "github.com/libopenstorage/stork/drivers/volume"
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/k8sutils"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/snapshotter"
+	"github.com/portworx/sched-ops/k8s/core"
+	v1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// optCSIVolumeGroupSnapshotClassName is an option for providing the
+	// volume group snapshot class name for group snapshots
+	optCSIVolumeGroupSnapshotClassName = "stork.libopenstorage.org/csi-volume-group-snapshot-class-name"
+	// optCSIGroupSnapshotFreeze is an option to freeze the filesystems of the
+	// PVCs while the snapshots are taken for group snapshots when the
+	// VolumeGroupSnapshot API isn't available. fsfreeze needs to be installed
+	// and permitted in the containers that mount the PVCs
+	optCSIGroupSnapshotFreeze = "stork.libopenstorage.org/csi-group-snapshot-freeze"
+
+	// groupSnapshotFreezeTimeout is the time to wait for the snapshots to be
+	// taken while the filesystems are frozen
+	groupSnapshotFreezeTimeout = 1 * time.Minute
+	// groupSnapshotCheckInterval is the interval at which the snapshots are
+	// checked while the filesystems are frozen
+	groupSnapshotCheckInterval = 2 * time.Second
+
+	readyGroupSnapshotMsg   = "Snapshot created successfully and it is ready"
+	pendingGroupSnapshotMsg = "Snapshot created, waiting for it to be ready"
+
+	volumeGroupSnapshotGroup    = "groupsnapshot.storage.k8s.io"
+	volumeGroupSnapshotResource = "volumegroupsnapshots"
+	volumeGroupSnapshotKind     = "VolumeGroupSnapshot"
+)
+
+var volumeSnapshotResource = schema.GroupVersionResource{
+	Group:    "snapshot.storage.k8s.io",
+	Version:  "v1",
+	Resource: "volumesnapshots",
+}
+
+// frozenVolume is a filesystem of a PVC that was frozen in a pod for a
+// group snapshot
+type frozenVolume struct {
+	pod       string
+	namespace string
+	container string
+	path      string
+}
+
+// CreateGroupSnapshot creates a VolumeGroupSnapshot in each namespace of the
+// group snapshot if the API is available. Otherwise a VolumeSnapshot is
+// created for each PVC, with the filesystems of the PVCs frozen if the
+// freeze option is set.
+func (c *csi) CreateGroupSnapshot(snap *storkapi.GroupVolumeSnapshot) (
+	*storkvolume.GroupSnapshotCreateResponse, error) {
+	pvcs, err := k8sutils.GetPVCsForGroupSnapshotInNamespaces(snap.GetPVCNamespaces(), snap.Spec.PVCSelector.MatchLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	resource, err := c.getVolumeGroupSnapshotResource()
+	if err != nil {
+		return nil, err
+	}
+	if resource != nil {
+		return c.createVolumeGroupSnapshots(snap, pvcs, *resource)
+	}
+
+	log.GroupSnapshotLog(snap).Infof("VolumeGroupSnapshot API is not available, creating snapshots for each PVC")
+	return c.createVolumeSnapshots(snap, pvcs)
+}
+
+// GetGroupSnapshotStatus returns the status of the VolumeGroupSnapshots or
+// VolumeSnapshots created for the group snapshot
+func (c *csi) GetGroupSnapshotStatus(snap *storkapi.GroupVolumeSnapshot) (
+	*storkvolume.GroupSnapshotCreateResponse, error) {
+	if len(snap.Status.VolumeSnapshots) == 0 {
+		return nil, fmt.Errorf("group snapshot has 0 snapshots in status")
+	}
+
+	// The task ID is the name of the VolumeGroupSnapshot that the snapshot is
+	// part of
+	if snap.Status.VolumeSnapshots[0].TaskID != "" {
+		resource, err := c.getServedVolumeGroupSnapshotResource()
+		if err != nil {
+			return nil, err
+		}
+		return c.getVolumeGroupSnapshotsStatus(snap.Status.VolumeSnapshots, resource)
+	}
+	return c.getVolumeSnapshotsStatus(snap.Status.VolumeSnapshots)
+}
+
+// DeleteGroupSnapshot deletes the VolumeGroupSnapshots or VolumeSnapshots
+// created for the group snapshot
+func (c *csi) DeleteGroupSnapshot(snap *storkapi.GroupVolumeSnapshot) error {
+	var lastError error
+	deletedGroups := make(map[string]bool)
+	for _, vs := range snap.Status.VolumeSnapshots {
+		var err error
+		if vs.TaskID != "" {
+			key := vs.VolumeSnapshotNamespace + "/" + vs.TaskID
+			if deletedGroups[key] {
+				continue
+			}
+			deletedGroups[key] = true
+			var resource schema.GroupVersionResource
+			if resource, err = c.getServedVolumeGroupSnapshotResource(); err == nil {
+				err = c.dynamicClient.Resource(resource).Namespace(vs.VolumeSnapshotNamespace).Delete(
+					context.TODO(), vs.TaskID, metav1.DeleteOptions{})
+			}
+		} else if vs.VolumeSnapshotName != "" {
+			err = c.deleteVolumeSnapshot(vs.VolumeSnapshotName, vs.VolumeSnapshotNamespace)
+		}
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			log.GroupSnapshotLog(snap).Errorf("failed to delete snapshot due to: %v", err)
+			lastError = err
+		}
+	}
+
+	return lastError
+}
+
+// getVolumeGroupSnapshotResource returns the VolumeGroupSnapshot resource in
+// the version preferred by the cluster, falling back to the other versions
+// served for the group. nil is returned if the API isn't served.
+func (c *csi) getVolumeGroupSnapshotResource() (*schema.GroupVersionResource, error) {
+	groups, err := c.discoveryClient.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups.Groups {
+		if group.Name != volumeGroupSnapshotGroup {
+			continue
+		}
+		versions := []string{group.PreferredVersion.Version}
+		for _, version := range group.Versions {
+			if version.Version != group.PreferredVersion.Version {
+				versions = append(versions, version.Version)
+			}
+		}
+		for _, version := range versions {
+			gv := schema.GroupVersion{Group: group.Name, Version: version}
+			resources, err := c.discoveryClient.ServerResourcesForGroupVersion(gv.String())
+			if err != nil {
+				if k8s_errors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			for _, resource := range resources.APIResources {
+				if resource.Name == volumeGroupSnapshotResource {
+					gvr := gv.WithResource(volumeGroupSnapshotResource)
+					return &gvr, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// getServedVolumeGroupSnapshotResource returns the VolumeGroupSnapshot
+// resource for VolumeGroupSnapshots that were already created. An error is
+// returned if the API isn't served anymore.
+func (c *csi) getServedVolumeGroupSnapshotResource() (schema.GroupVersionResource, error) {
+	resource, err := c.getVolumeGroupSnapshotResource()
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	if resource == nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("%v API is not available", volumeGroupSnapshotKind)
+	}
+	return *resource, nil
+}
+
+func (c *csi) getGroupSnapshotName(snap *storkapi.GroupVolumeSnapshot) string {
+	return fmt.Sprintf("%s-%s", snap.Name, getUIDLastSection(snap.UID))
+}
+
+// createVolumeGroupSnapshots creates a VolumeGroupSnapshot in each namespace
+// that has PVCs for the group snapshot. The snapshots don't have any
+// conditions until the VolumeGroupSnapshots have been taken.
+func (c *csi) createVolumeGroupSnapshots(
+	snap *storkapi.GroupVolumeSnapshot,
+	pvcs []v1.PersistentVolumeClaim,
+	resource schema.GroupVersionResource,
+) (*storkvolume.GroupSnapshotCreateResponse, error) {
+	matchLabels := make(map[string]interface{})
+	for k, v := range snap.Spec.PVCSelector.MatchLabels {
+		matchLabels[k] = v
+	}
+	name := c.getGroupSnapshotName(snap)
+	response := &storkvolume.GroupSnapshotCreateResponse{
+		Snapshots: make([]*storkapi.VolumeSnapshotStatus, 0),
+	}
+	created := make(map[string]bool)
+	for _, pvc := range pvcs {
+		if created[pvc.Namespace] {
+			continue
+		}
+		vgs := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": resource.GroupVersion().String(),
+				"kind":       volumeGroupSnapshotKind,
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": pvc.Namespace,
+				},
+				"spec": map[string]interface{}{
+					"source": map[string]interface{}{
+						"selector": map[string]interface{}{
+							"matchLabels": matchLabels,
+						},
+					},
+				},
+			},
+		}
+		if className := snap.Spec.Options[optCSIVolumeGroupSnapshotClassName]; className != "" {
+			if err := unstructured.SetNestedField(vgs.Object, className, "spec", "volumeGroupSnapshotClassName"); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err := c.dynamicClient.Resource(resource).Namespace(pvc.Namespace).Create(
+			context.TODO(), vgs, metav1.CreateOptions{})
+		if err != nil && !k8s_errors.IsAlreadyExists(err) {
+			if deleteErr := c.DeleteGroupSnapshot(&storkapi.GroupVolumeSnapshot{
+				Status: storkapi.GroupVolumeSnapshotStatus{VolumeSnapshots: response.Snapshots},
+			}); deleteErr != nil {
+				log.GroupSnapshotLog(snap).Warnf("Failed to delete volume group snapshots: %v", deleteErr)
+			}
+			return nil, fmt.Errorf("error creating volume group snapshot [%v] %v: %v", pvc.Namespace, name, err)
+		}
+		created[pvc.Namespace] = true
+		response.Snapshots = append(response.Snapshots, &storkapi.VolumeSnapshotStatus{
+			TaskID:                  name,
+			VolumeSnapshotNamespace: pvc.Namespace,
+		})
+	}
+
+	return response, nil
+}
+
+// getVolumeGroupSnapshotsStatus returns the status of the snapshots in each
+// VolumeGroupSnapshot
+func (c *csi) getVolumeGroupSnapshotsStatus(
+	snapshots []*storkapi.VolumeSnapshotStatus,
+	resource schema.GroupVersionResource,
+) (*storkvolume.GroupSnapshotCreateResponse, error) {
+	response := &storkvolume.GroupSnapshotCreateResponse{
+		Snapshots: make([]*storkapi.VolumeSnapshotStatus, 0),
+	}
+	checked := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		key := snapshot.VolumeSnapshotNamespace + "/" + snapshot.TaskID
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+
+		vgs, err := c.dynamicClient.Resource(resource).Namespace(snapshot.VolumeSnapshotNamespace).Get(
+			context.TODO(), snapshot.TaskID, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		var conditions []crdv1.VolumeSnapshotCondition
+		errorMessage, _, _ := unstructured.NestedString(vgs.Object, "status", "error", "message")
+		readyToUse, _, _ := unstructured.NestedBool(vgs.Object, "status", "readyToUse")
+		creationTime, _, _ := unstructured.NestedString(vgs.Object, "status", "creationTime")
+		if errorMessage != "" {
+			conditions = getErrorGroupSnapshotConditions(fmt.Errorf("%v", errorMessage))
+		} else if readyToUse {
+			conditions = getReadyGroupSnapshotConditions()
+		} else if creationTime != "" {
+			conditions = getPendingGroupSnapshotConditions(pendingGroupSnapshotMsg)
+		}
+
+		names, err := c.getVolumeGroupSnapshotMembers(vgs)
+		if err != nil {
+			return nil, err
+		}
+		if len(names) == 0 {
+			response.Snapshots = append(response.Snapshots, &storkapi.VolumeSnapshotStatus{
+				TaskID:                  snapshot.TaskID,
+				VolumeSnapshotNamespace: snapshot.VolumeSnapshotNamespace,
+				Conditions:              conditions,
+			})
+			continue
+		}
+		for _, name := range names {
+			response.Snapshots = append(response.Snapshots, &storkapi.VolumeSnapshotStatus{
+				VolumeSnapshotName:      name,
+				VolumeSnapshotNamespace: snapshot.VolumeSnapshotNamespace,
+				TaskID:                  snapshot.TaskID,
+				Conditions:              conditions,
+			})
+		}
+	}
+
+	return response, nil
+}
+
+// getVolumeGroupSnapshotMembers returns the names of the VolumeSnapshots
+// created for a VolumeGroupSnapshot. Both the object references and the PVC
+// to snapshot pairs reported by v1alpha1 are handled. Later versions don't
+// report the snapshots in the status, they are found from their owner
+// references instead.
+func (c *csi) getVolumeGroupSnapshotMembers(vgs *unstructured.Unstructured) ([]string, error) {
+	names := make([]string, 0)
+	refs, _, _ := unstructured.NestedSlice(vgs.Object, "status", "volumeSnapshotRefList")
+	for _, ref := range refs {
+		if refMap, ok := ref.(map[string]interface{}); ok {
+			if name, _, _ := unstructured.NestedString(refMap, "name"); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	pairs, _, _ := unstructured.NestedSlice(vgs.Object, "status", "pvcVolumeSnapshotRefList")
+	for _, pair := range pairs {
+		if pairMap, ok := pair.(map[string]interface{}); ok {
+			if name, _, _ := unstructured.NestedString(pairMap, "volumeSnapshotRef", "name"); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) != 0 {
+		return names, nil
+	}
+
+	snapshots, err := c.dynamicClient.Resource(volumeSnapshotResource).Namespace(vgs.GetNamespace()).List(
+		context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, vs := range snapshots.Items {
+		for _, owner := range vs.GetOwnerReferences() {
+			if owner.Kind == volumeGroupSnapshotKind && owner.Name == vgs.GetName() && owner.UID == vgs.GetUID() {
+				names = append(names, vs.GetName())
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+func (c *csi) getGroupVolumeSnapshotName(snap *storkapi.GroupVolumeSnapshot, pvc *v1.PersistentVolumeClaim) string {
+	return fmt.Sprintf("%s-%s-%s", snap.Name, getUIDLastSection(snap.UID), getUIDLastSection(pvc.UID))
+}
+
+// createVolumeSnapshots creates a VolumeSnapshot for each PVC. If the freeze
+// option is set the filesystems of the PVCs are frozen first and thawed once
+// all the snapshots have been taken.
+func (c *csi) createVolumeSnapshots(
+	snap *storkapi.GroupVolumeSnapshot,
+	pvcs []v1.PersistentVolumeClaim,
+) (*storkvolume.GroupSnapshotCreateResponse, error) {
+	if c.snapshotter == nil {
+		return nil, fmt.Errorf("found uninitialized snapshotter object")
+	}
+
+	snapshotClassNames, err := c.getGroupSnapshotClassNames(snap, pvcs)
+	if err != nil {
+		return nil, err
+	}
+
+	freeze := snap.Spec.Options[optCSIGroupSnapshotFreeze] == "true"
+	if freeze {
+		frozen, err := freezePVCs(snap, pvcs)
+		defer thawPVCs(snap, frozen)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	snapshots := make([]*storkapi.VolumeSnapshotStatus, 0)
+	revert := func() {
+		for _, snapshot := range snapshots {
+			if err := c.deleteVolumeSnapshot(snapshot.VolumeSnapshotName, snapshot.VolumeSnapshotNamespace); err != nil {
+				log.GroupSnapshotLog(snap).Warnf("Failed to delete volume snapshot [%v] %v: %v",
+					snapshot.VolumeSnapshotNamespace, snapshot.VolumeSnapshotName, err)
+			}
+		}
+	}
+	for _, pvc := range pvcs {
+		vsName := c.getGroupVolumeSnapshotName(snap, &pvc)
+		_, _, _, err := c.snapshotter.CreateSnapshot(
+			snapshotter.Name(vsName),
+			snapshotter.PVCName(pvc.Name),
+			snapshotter.PVCNamespace(pvc.Namespace),
+			snapshotter.SnapshotClassName(snapshotClassNames[pvc.Namespace+"/"+pvc.Name]),
+		)
+		if err != nil {
+			revert()
+			return nil, fmt.Errorf("error creating volume snapshot for PVC [%v] %v: %v", pvc.Namespace, pvc.Name, err)
+		}
+		snapshots = append(snapshots, &storkapi.VolumeSnapshotStatus{
+			VolumeSnapshotName:      vsName,
+			VolumeSnapshotNamespace: pvc.Namespace,
+			ParentVolumeID:          pvc.Spec.VolumeName,
+		})
+	}
+
+	if freeze {
+		// The filesystems can only be thawed once the snapshots have been
+		// taken, they don't need to be ready
+		err := wait.PollImmediate(groupSnapshotCheckInterval, groupSnapshotFreezeTimeout, func() (bool, error) {
+			for _, snapshot := range snapshots {
+				taken, err := c.volumeSnapshotTaken(snapshot.VolumeSnapshotName, snapshot.VolumeSnapshotNamespace)
+				if err != nil || !taken {
+					return false, err
+				}
+			}
+			return true, nil
+		})
+		if err != nil {
+			revert()
+			return nil, fmt.Errorf("error waiting for volume snapshots to be taken: %v", err)
+		}
+	}
+
+	return c.getVolumeSnapshotsStatus(snapshots)
+}
+
+// getGroupSnapshotClassNames returns the snapshot class for each PVC. The
+// default snapshot class of the CSI driver of the PVC is used unless a class
+// is set in the options.
+func (c *csi) getGroupSnapshotClassNames(
+	snap *storkapi.GroupVolumeSnapshot,
+	pvcs []v1.PersistentVolumeClaim,
+) (map[string]string, error) {
+	classNames := make(map[string]string)
+	for _, pvc := range pvcs {
+		key := pvc.Namespace + "/" + pvc.Name
+		if className := snap.Spec.Options[optCSISnapshotClassName]; className != "" {
+			classNames[key] = className
+			continue
+		}
+		pv, err := core.Instance().GetPersistentVolume(pvc.Spec.VolumeName)
+		if err != nil {
+			return nil, fmt.Errorf("error getting PV for PVC [%v] %v: %v", pvc.Namespace, pvc.Name, err)
+		}
+		if pv.Spec.CSI == nil {
+			return nil, fmt.Errorf("PV %v for PVC [%v] %v is not a CSI volume", pv.Name, pvc.Namespace, pvc.Name)
+		}
+		classNames[key] = c.getDefaultSnapshotClassName(pv.Spec.CSI.Driver)
+	}
+	return classNames, nil
+}
+
+// getVolumeSnapshotsStatus returns the status of the VolumeSnapshots created
+// for each PVC
+func (c *csi) getVolumeSnapshotsStatus(
+	snapshots []*storkapi.VolumeSnapshotStatus,
+) (*storkvolume.GroupSnapshotCreateResponse, error) {
+	response := &storkvolume.GroupSnapshotCreateResponse{
+		Snapshots: make([]*storkapi.VolumeSnapshotStatus, 0),
+	}
+	for _, snapshot := range snapshots {
+		snapshotStatus := &storkapi.VolumeSnapshotStatus{
+			VolumeSnapshotName:      snapshot.VolumeSnapshotName,
+			VolumeSnapshotNamespace: snapshot.VolumeSnapshotNamespace,
+			ParentVolumeID:          snapshot.ParentVolumeID,
+		}
+		info, err := c.snapshotter.SnapshotStatus(snapshot.VolumeSnapshotName, snapshot.VolumeSnapshotNamespace)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return nil, err
+		}
+		switch info.Status {
+		case snapshotter.StatusReady:
+			snapshotStatus.Conditions = getReadyGroupSnapshotConditions()
+		case snapshotter.StatusFailed:
+			snapshotStatus.Conditions = getErrorGroupSnapshotConditions(fmt.Errorf("%v", info.Reason))
+		default:
+			snapshotStatus.Conditions = getPendingGroupSnapshotConditions(info.Reason)
+		}
+		response.Snapshots = append(response.Snapshots, snapshotStatus)
+	}
+
+	return response, nil
+}
+
+// volumeSnapshotTaken returns true if the snapshot has been taken by the CSI
+// driver. An error is returned if the snapshot failed.
+func (c *csi) volumeSnapshotTaken(name, namespace string) (bool, error) {
+	if c.v1SnapshotRequired {
+		vs, err := c.snapshotClient.SnapshotV1().VolumeSnapshots(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if vs.Status == nil {
+			return false, nil
+		}
+		if vs.Status.Error != nil && vs.Status.Error.Message != nil {
+			return false, fmt.Errorf("volume snapshot [%v] %v failed: %v", namespace, name, *vs.Status.Error.Message)
+		}
+		return vs.Status.CreationTime != nil, nil
+	}
+	vs, err := c.snapshotClient.SnapshotV1beta1().VolumeSnapshots(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	if vs.Status == nil {
+		return false, nil
+	}
+	if vs.Status.Error != nil && vs.Status.Error.Message != nil {
+		return false, fmt.Errorf("volume snapshot [%v] %v failed: %v", namespace, name, *vs.Status.Error.Message)
+	}
+	return vs.Status.CreationTime != nil, nil
+}
+
+// deleteVolumeSnapshot deletes the VolumeSnapshot. The VolumeSnapshotContent
+// is deleted based on the deletion policy of the snapshot class.
+func (c *csi) deleteVolumeSnapshot(name, namespace string) error {
+	var err error
+	if c.v1SnapshotRequired {
+		err = c.snapshotClient.SnapshotV1().VolumeSnapshots(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	} else {
+		err = c.snapshotClient.SnapshotV1beta1().VolumeSnapshots(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	}
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// freezePVCs freezes the filesystems of the PVCs in a running pod that mounts
+// them. PVCs that aren't mounted in any running pod aren't being written to
+// and don't need to be frozen. The filesystems that were frozen are returned
+// even on failure so that they can be thawed.
+func freezePVCs(snap *storkapi.GroupVolumeSnapshot, pvcs []v1.PersistentVolumeClaim) ([]*frozenVolume, error) {
+	frozen := make([]*frozenVolume, 0)
+	for _, pvc := range pvcs {
+		pods, err := core.Instance().GetPodsUsingPVC(pvc.Name, pvc.Namespace)
+		if err != nil {
+			return frozen, err
+		}
+		volume := getPVCMount(pods, pvc.Name)
+		if volume == nil {
+			log.GroupSnapshotLog(snap).Infof("PVC [%v] %v is not mounted in any running pod, not freezing it",
+				pvc.Namespace, pvc.Name)
+			continue
+		}
+		if _, err := core.Instance().RunCommandInPod(
+			[]string{"fsfreeze", "-f", volume.path}, volume.pod, volume.container, volume.namespace); err != nil {
+			return frozen, fmt.Errorf("error freezing %v in pod [%v] %v for PVC %v: %v",
+				volume.path, volume.namespace, volume.pod, pvc.Name, err)
+		}
+		frozen = append(frozen, volume)
+	}
+	return frozen, nil
+}
+
+// thawPVCs thaws the filesystems that were frozen for the group snapshot
+func thawPVCs(snap *storkapi.GroupVolumeSnapshot, frozen []*frozenVolume) {
+	for _, volume := range frozen {
+		if _, err := core.Instance().RunCommandInPod(
+			[]string{"fsfreeze", "-u", volume.path}, volume.pod, volume.container, volume.namespace); err != nil {
+			log.GroupSnapshotLog(snap).Errorf("Failed to thaw %v in pod [%v] %v: %v",
+				volume.path, volume.namespace, volume.pod, err)
+		}
+	}
+}
+
+// getPVCMount returns the path at which the PVC is mounted in a container of
+// the first running pod that mounts the PVC
+func getPVCMount(pods []v1.Pod, pvcName string) *frozenVolume {
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != pvcName {
+				continue
+			}
+			for _, container := range pod.Spec.Containers {
+				for _, mount := range container.VolumeMounts {
+					if mount.Name == volume.Name {
+						return &frozenVolume{
+							pod:       pod.Name,
+							namespace: pod.Namespace,
+							container: container.Name,
+							path:      mount.MountPath,
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func getReadyGroupSnapshotConditions() []crdv1.VolumeSnapshotCondition {
+	return []crdv1.VolumeSnapshotCondition{
+		{
+			Type:               crdv1.VolumeSnapshotConditionReady,
+			Status:             v1.ConditionTrue,
+			Message:            readyGroupSnapshotMsg,
+			LastTransitionTime: metav1.Now(),
+		},
+	}
+}
+
+func getErrorGroupSnapshotConditions(err error) []crdv1.VolumeSnapshotCondition {
+	return []crdv1.VolumeSnapshotCondition{
+		{
+			Type:               crdv1.VolumeSnapshotConditionError,
+			Status:             v1.ConditionTrue,
+			Message:            fmt.Sprintf("snapshot failed due to err: %v", err),
+			LastTransitionTime: metav1.Now(),
+		},
+	}
+}
+
+func getPendingGroupSnapshotConditions(msg string) []crdv1.VolumeSnapshotCondition {
+	return []crdv1.VolumeSnapshotCondition{
+		{
+			Type:               crdv1.VolumeSnapshotConditionPending,
+			Status:             v1.ConditionTrue,
+			Message:            msg,
+			LastTransitionTime: metav1.Now(),
+		},
+	}
+}
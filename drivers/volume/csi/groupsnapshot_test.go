@@ -0,0 +1,378 @@
+//go:build unittest
+// +build unittest
+
+package csi
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	crdv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/snapshotter"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	volumeGroupSnapshotV1alpha1 = schema.GroupVersionResource{
+		Group:    volumeGroupSnapshotGroup,
+		Version:  "v1alpha1",
+		Resource: volumeGroupSnapshotResource,
+	}
+	volumeGroupSnapshotV1beta1 = schema.GroupVersionResource{
+		Group:    volumeGroupSnapshotGroup,
+		Version:  "v1beta1",
+		Resource: volumeGroupSnapshotResource,
+	}
+)
+
+// fakeSnapshotter records the snapshots that were created and reports them
+// as ready
+type fakeSnapshotter struct {
+	snapshotter.Driver
+	created map[string]snapshotter.Options
+}
+
+func (f *fakeSnapshotter) CreateSnapshot(opts ...snapshotter.Option) (string, string, string, error) {
+	o := snapshotter.Options{}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return "", "", "", err
+		}
+	}
+	f.created[o.PVCNamespace+"/"+o.Name] = o
+	return o.Name, o.PVCNamespace, "", nil
+}
+
+func (f *fakeSnapshotter) SnapshotStatus(name, namespace string) (snapshotter.SnapshotInfo, error) {
+	if _, ok := f.created[namespace+"/"+name]; !ok {
+		return snapshotter.SnapshotInfo{}, k8s_errors.NewNotFound(schema.GroupResource{}, name)
+	}
+	return snapshotter.SnapshotInfo{Status: snapshotter.StatusReady}, nil
+}
+
+// fakeCoreOps records the commands run in pods
+type fakeCoreOps struct {
+	core.Ops
+	commands []string
+}
+
+func (f *fakeCoreOps) RunCommandInPod(cmds []string, podName, containerName, namespace string) (string, error) {
+	f.commands = append(f.commands, strings.Join(append([]string{namespace + "/" + podName + "/" + containerName}, cmds...), " "))
+	return "", nil
+}
+
+// groupVersionResources returns the discovery resources for the given
+// versions of the VolumeGroupSnapshot API. The first version is the one
+// preferred by the fake discovery client.
+func groupVersionResources(versions ...string) []*metav1.APIResourceList {
+	resources := make([]*metav1.APIResourceList, 0)
+	for _, version := range versions {
+		resources = append(resources, &metav1.APIResourceList{
+			GroupVersion: volumeGroupSnapshotGroup + "/" + version,
+			APIResources: []metav1.APIResource{
+				{Name: volumeGroupSnapshotResource, Kind: volumeGroupSnapshotKind, Namespaced: true},
+			},
+		})
+	}
+	return resources
+}
+
+func newTestCSI(resources []*metav1.APIResourceList, objects ...runtime.Object) (*csi, *fakeSnapshotter) {
+	core.SetInstance(&fakeCoreOps{Ops: core.New(kubernetes.NewSimpleClientset(objects...))})
+	fakeSnap := &fakeSnapshotter{created: make(map[string]snapshotter.Options)}
+	return &csi{
+		dynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				volumeGroupSnapshotV1alpha1: "VolumeGroupSnapshotList",
+				volumeGroupSnapshotV1beta1:  "VolumeGroupSnapshotList",
+				volumeSnapshotResource:      "VolumeSnapshotList",
+			}),
+		discoveryClient: &fakediscovery.FakeDiscovery{
+			Fake: &k8stesting.Fake{
+				Resources: resources,
+			},
+		},
+		snapshotter: fakeSnap,
+	}, fakeSnap
+}
+
+func newTestPVC(name, namespace, volume string) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID("uid-" + name),
+			Labels:    map[string]string{"app": "db"},
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			VolumeName: volume,
+		},
+		Status: v1.PersistentVolumeClaimStatus{
+			Phase: v1.ClaimBound,
+		},
+	}
+}
+
+func newTestPV(name, driver string) *v1.PersistentVolume {
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+	if driver != "" {
+		pv.Spec.CSI = &v1.CSIPersistentVolumeSource{Driver: driver, VolumeHandle: name}
+	}
+	return pv
+}
+
+func newTestGroupSnapshot(options map[string]string) *storkapi.GroupVolumeSnapshot {
+	return &storkapi.GroupVolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "group",
+			Namespace: "ns1",
+			UID:       types.UID("group-uid"),
+		},
+		Spec: storkapi.GroupVolumeSnapshotSpec{
+			PVCSelector: storkapi.PVCSelectorSpec{
+				LabelSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			},
+			Options: options,
+		},
+		Status: storkapi.GroupVolumeSnapshotStatus{
+			Namespaces: []string{"ns1", "ns2"},
+		},
+	}
+}
+
+func TestGetVolumeGroupSnapshotResource(t *testing.T) {
+	c, _ := newTestCSI(nil)
+	resource, err := c.getVolumeGroupSnapshotResource()
+	require.NoError(t, err, "Error discovering VolumeGroupSnapshot API")
+	require.Nil(t, resource, "VolumeGroupSnapshot API shouldn't be found")
+	_, err = c.getServedVolumeGroupSnapshotResource()
+	require.Error(t, err, "Expected error when VolumeGroupSnapshot API isn't served")
+
+	c, _ = newTestCSI(groupVersionResources("v1alpha1"))
+	resource, err = c.getVolumeGroupSnapshotResource()
+	require.NoError(t, err, "Error discovering VolumeGroupSnapshot API")
+	require.Equal(t, volumeGroupSnapshotV1alpha1, *resource, "Unexpected VolumeGroupSnapshot resource")
+
+	c, _ = newTestCSI(groupVersionResources("v1beta1", "v1alpha1"))
+	resource, err = c.getVolumeGroupSnapshotResource()
+	require.NoError(t, err, "Error discovering VolumeGroupSnapshot API")
+	require.Equal(t, volumeGroupSnapshotV1beta1, *resource, "Preferred version should be used")
+
+	// Fall back to another version if the preferred one doesn't serve
+	// VolumeGroupSnapshots
+	resources := groupVersionResources("v1beta1", "v1alpha1")
+	resources[0].APIResources = []metav1.APIResource{{Name: "volumegroupsnapshotclasses"}}
+	c, _ = newTestCSI(resources)
+	resource, err = c.getVolumeGroupSnapshotResource()
+	require.NoError(t, err, "Error discovering VolumeGroupSnapshot API")
+	require.Equal(t, volumeGroupSnapshotV1alpha1, *resource, "Other served version should be used")
+}
+
+func TestVolumeGroupSnapshotV1beta1(t *testing.T) {
+	c, _ := newTestCSI(groupVersionResources("v1beta1", "v1alpha1"),
+		newTestPVC("pvc1", "ns1", "pv1"),
+		newTestPVC("pvc2", "ns2", "pv2"),
+		newTestPVC("pvc3", "ns2", "pv3"))
+	snap := newTestGroupSnapshot(map[string]string{optCSIVolumeGroupSnapshotClassName: "group-class"})
+
+	response, err := c.CreateGroupSnapshot(snap)
+	require.NoError(t, err, "Error creating group snapshot")
+	require.Len(t, response.Snapshots, 2, "Expected a VolumeGroupSnapshot per namespace")
+	name := c.getGroupSnapshotName(snap)
+	for _, snapshot := range response.Snapshots {
+		require.Equal(t, name, snapshot.TaskID, "Unexpected VolumeGroupSnapshot name")
+	}
+
+	vgs, err := c.dynamicClient.Resource(volumeGroupSnapshotV1beta1).Namespace("ns2").Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err, "VolumeGroupSnapshot should be created with the served version")
+	require.Equal(t, "groupsnapshot.storage.k8s.io/v1beta1", vgs.GetAPIVersion(), "Unexpected API version")
+	className, _, _ := unstructured.NestedString(vgs.Object, "spec", "volumeGroupSnapshotClassName")
+	require.Equal(t, "group-class", className, "Unexpected VolumeGroupSnapshot class")
+	selector, _, _ := unstructured.NestedStringMap(vgs.Object, "spec", "source", "selector", "matchLabels")
+	require.Equal(t, map[string]string{"app": "db"}, selector, "Unexpected PVC selector")
+
+	snap.Status.VolumeSnapshots = response.Snapshots
+	status, err := c.GetGroupSnapshotStatus(snap)
+	require.NoError(t, err, "Error getting group snapshot status")
+	require.Len(t, status.Snapshots, 2, "Snapshots shouldn't be listed before they are created")
+	for _, snapshot := range status.Snapshots {
+		require.Empty(t, snapshot.Conditions, "Snapshots shouldn't have conditions before they are taken")
+	}
+
+	// v1beta1 doesn't list the snapshots in the status, they are owned by
+	// the VolumeGroupSnapshot instead
+	vgs.SetUID(types.UID("vgs-uid"))
+	require.NoError(t, unstructured.SetNestedField(vgs.Object, true, "status", "readyToUse"), "Error setting status")
+	_, err = c.dynamicClient.Resource(volumeGroupSnapshotV1beta1).Namespace("ns2").Update(context.TODO(), vgs, metav1.UpdateOptions{})
+	require.NoError(t, err, "Error updating VolumeGroupSnapshot")
+	for _, vsName := range []string{"snapshot-pvc2", "snapshot-pvc3", "other"} {
+		vs := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "snapshot.storage.k8s.io/v1",
+			"kind":       "VolumeSnapshot",
+			"metadata": map[string]interface{}{
+				"name":      vsName,
+				"namespace": "ns2",
+			},
+		}}
+		if vsName != "other" {
+			vs.SetOwnerReferences([]metav1.OwnerReference{
+				{Kind: volumeGroupSnapshotKind, Name: name, UID: types.UID("vgs-uid")},
+			})
+		}
+		_, err = c.dynamicClient.Resource(volumeSnapshotResource).Namespace("ns2").Create(context.TODO(), vs, metav1.CreateOptions{})
+		require.NoError(t, err, "Error creating VolumeSnapshot")
+	}
+
+	status, err = c.GetGroupSnapshotStatus(snap)
+	require.NoError(t, err, "Error getting group snapshot status")
+	members := make([]string, 0)
+	for _, snapshot := range status.Snapshots {
+		if snapshot.VolumeSnapshotNamespace != "ns2" {
+			continue
+		}
+		members = append(members, snapshot.VolumeSnapshotName)
+		require.Len(t, snapshot.Conditions, 1, "Expected a condition for the snapshot")
+		require.Equal(t, crdv1.VolumeSnapshotConditionReady, snapshot.Conditions[0].Type, "Snapshot should be ready")
+	}
+	require.ElementsMatch(t, []string{"snapshot-pvc2", "snapshot-pvc3"}, members,
+		"Only the snapshots owned by the VolumeGroupSnapshot should be returned")
+
+	require.NoError(t, c.DeleteGroupSnapshot(snap), "Error deleting group snapshot")
+	for _, ns := range []string{"ns1", "ns2"} {
+		_, err = c.dynamicClient.Resource(volumeGroupSnapshotV1beta1).Namespace(ns).Get(context.TODO(), name, metav1.GetOptions{})
+		require.True(t, k8s_errors.IsNotFound(err), "VolumeGroupSnapshot should be deleted in %v", ns)
+	}
+}
+
+func TestVolumeGroupSnapshotV1alpha1Members(t *testing.T) {
+	c, _ := newTestCSI(groupVersionResources("v1alpha1"), newTestPVC("pvc1", "ns1", "pv1"))
+	snap := newTestGroupSnapshot(nil)
+	snap.Status.Namespaces = nil
+
+	response, err := c.CreateGroupSnapshot(snap)
+	require.NoError(t, err, "Error creating group snapshot")
+	require.Len(t, response.Snapshots, 1, "Expected a VolumeGroupSnapshot")
+	name := response.Snapshots[0].TaskID
+	vgs, err := c.dynamicClient.Resource(volumeGroupSnapshotV1alpha1).Namespace("ns1").Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err, "VolumeGroupSnapshot should be created with v1alpha1")
+	_, found, _ := unstructured.NestedString(vgs.Object, "spec", "volumeGroupSnapshotClassName")
+	require.False(t, found, "Class shouldn't be set unless it is in the options")
+
+	require.NoError(t, unstructured.SetNestedField(vgs.Object, "2023-01-01T00:00:00Z", "status", "creationTime"), "Error setting status")
+	require.NoError(t, unstructured.SetNestedSlice(vgs.Object, []interface{}{
+		map[string]interface{}{"name": "snapshot-pvc1"},
+	}, "status", "volumeSnapshotRefList"), "Error setting status")
+	_, err = c.dynamicClient.Resource(volumeGroupSnapshotV1alpha1).Namespace("ns1").Update(context.TODO(), vgs, metav1.UpdateOptions{})
+	require.NoError(t, err, "Error updating VolumeGroupSnapshot")
+
+	snap.Status.VolumeSnapshots = response.Snapshots
+	status, err := c.GetGroupSnapshotStatus(snap)
+	require.NoError(t, err, "Error getting group snapshot status")
+	require.Len(t, status.Snapshots, 1, "Expected the snapshot from the status")
+	require.Equal(t, "snapshot-pvc1", status.Snapshots[0].VolumeSnapshotName, "Unexpected snapshot name")
+	require.Equal(t, crdv1.VolumeSnapshotConditionPending, status.Snapshots[0].Conditions[0].Type, "Snapshot should be pending")
+}
+
+func TestGroupVolumeSnapshotsSnapshotClass(t *testing.T) {
+	c, fakeSnap := newTestCSI(nil,
+		newTestPVC("pvc1", "ns1", "pv1"),
+		newTestPVC("pvc2", "ns2", "pv2"),
+		newTestPV("pv1", "driver.example.com"),
+		newTestPV("pv2", "other.example.com"))
+	snap := newTestGroupSnapshot(nil)
+
+	response, err := c.CreateGroupSnapshot(snap)
+	require.NoError(t, err, "Error creating group snapshot")
+	require.Len(t, response.Snapshots, 2, "Expected a VolumeSnapshot per PVC")
+	for _, snapshot := range response.Snapshots {
+		require.Empty(t, snapshot.TaskID, "VolumeSnapshots shouldn't be part of a VolumeGroupSnapshot")
+		require.Equal(t, crdv1.VolumeSnapshotConditionReady, snapshot.Conditions[0].Type, "Snapshot should be ready")
+	}
+	classes := make(map[string]string)
+	for _, opts := range fakeSnap.created {
+		classes[opts.PVCName] = opts.SnapshotClassName
+	}
+	require.Equal(t, map[string]string{
+		"pvc1": "stork-csi-snapshot-class-driver.example.com",
+		"pvc2": "stork-csi-snapshot-class-other.example.com",
+	}, classes, "Default snapshot class of the CSI driver should be used")
+
+	c, fakeSnap = newTestCSI(nil,
+		newTestPVC("pvc1", "ns1", "pv1"),
+		newTestPV("pv1", "driver.example.com"))
+	snap = newTestGroupSnapshot(map[string]string{optCSISnapshotClassName: "custom"})
+	snap.Status.Namespaces = nil
+	_, err = c.CreateGroupSnapshot(snap)
+	require.NoError(t, err, "Error creating group snapshot")
+	require.Len(t, fakeSnap.created, 1, "Expected a VolumeSnapshot")
+	for _, opts := range fakeSnap.created {
+		require.Equal(t, "custom", opts.SnapshotClassName, "Snapshot class from the options should be used")
+	}
+
+	c, fakeSnap = newTestCSI(nil,
+		newTestPVC("pvc1", "ns1", "pv1"),
+		newTestPV("pv1", ""))
+	_, err = c.CreateGroupSnapshot(snap)
+	require.NoError(t, err, "Class from the options shouldn't need a CSI volume")
+	snap.Spec.Options = nil
+	_, err = c.CreateGroupSnapshot(snap)
+	require.Error(t, err, "Expected error for a PV that isn't a CSI volume")
+}
+
+func TestGroupVolumeSnapshotsFreeze(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "ns1"},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{
+					Name: "data",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc1"},
+					},
+				},
+			},
+			Containers: []v1.Container{
+				{
+					Name:         "db",
+					VolumeMounts: []v1.VolumeMount{{Name: "data", MountPath: "/data"}},
+				},
+			},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+	c, _ := newTestCSI(nil,
+		newTestPVC("pvc1", "ns1", "pv1"),
+		newTestPV("pv1", "driver.example.com"),
+		pod)
+	snap := newTestGroupSnapshot(nil)
+	snap.Status.Namespaces = nil
+
+	_, err := c.CreateGroupSnapshot(snap)
+	require.NoError(t, err, "Error creating group snapshot")
+	require.Empty(t, core.Instance().(*fakeCoreOps).commands, "Filesystems shouldn't be frozen by default")
+
+	frozen, err := freezePVCs(snap, []v1.PersistentVolumeClaim{*newTestPVC("pvc1", "ns1", "pv1")})
+	require.NoError(t, err, "Error freezing PVCs")
+	require.Len(t, frozen, 1, "Expected the mounted PVC to be frozen")
+	thawPVCs(snap, frozen)
+	require.Equal(t, []string{
+		"ns1/db/db fsfreeze -f /data",
+		"ns1/db/db fsfreeze -u /data",
+	}, core.Instance().(*fakeCoreOps).commands, "Unexpected freeze commands")
+}
@@ -54,6 +54,7 @@ type gcp struct {
 	storkvolume.ClusterDomainsNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.FileRestoreNotSupported
 }
 
 type gcpSession struct {
@@ -167,6 +168,7 @@ func isCsiProvisioner(provisioner string) bool {
 
 func (g *gcp) StartBackup(backup *storkapi.ApplicationBackup,
 	pvcs []v1.PersistentVolumeClaim,
+	parents map[string]*storkapi.ApplicationBackupVolumeInfo,
 ) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
 	gcpSession, err := g.getGCPSession(backup.Spec.BackupLocation, backup.Namespace)
 	if err != nil {
@@ -41,6 +41,7 @@ const (
 	prefixRestore          = "restore"
 	prefixBackup           = "backup"
 	prefixDelete           = "delete"
+	prefixFileRestore      = "filerestore"
 	skipResourceAnnotation = "stork.libopenstorage.org/skip-resource"
 	volumeinitialDelay     = 2 * time.Second
 	volumeFactor           = 1.5
@@ -84,6 +85,10 @@ const (
 	restoreObjectNameKey        = kdmpAnnotationPrefix + "restoreobject-name"
 	restoreObjectUIDKey         = kdmpAnnotationPrefix + "restoreobject-uid"
 
+	// file restore related Labels
+	volumeFileRestoreCRNameKey = kdmpAnnotationPrefix + "volumefilerestore-cr-name"
+	volumeFileRestoreCRUIDKey  = kdmpAnnotationPrefix + "volumefilerestore-cr-uid"
+
 	pvcNameKey = kdmpAnnotationPrefix + "pvc-name"
 	pvcUIDKey  = kdmpAnnotationPrefix + "pvc-uid"
 	// pvcProvisionerAnnotation is the annotation on PVC which has the
@@ -255,6 +260,7 @@ func getCloudProvider() (bool, bool, error) {
 
 func (k *kdmp) StartBackup(backup *storkapi.ApplicationBackup,
 	pvcs []v1.PersistentVolumeClaim,
+	parents map[string]*storkapi.ApplicationBackupVolumeInfo,
 ) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
 	log.ApplicationBackupLog(backup).Debugf("started generic backup: %v", backup.Name)
 	volumeInfos := make([]*storkapi.ApplicationBackupVolumeInfo, 0)
@@ -1060,3 +1066,118 @@ func getVolumeSnapshotClassFromBackupVolumeInfo(bkvpInfo *storkapi.ApplicationBa
 
 	return vsClass
 }
+
+// getFileRestoreCRName returns the name of the kdmp CRs used to provision the
+// PVC for a file restore
+func getFileRestoreCRName(fileRestore *storkapi.VolumeFileRestore) string {
+	name := fmt.Sprintf("%s-%s-%s", prefixFileRestore, getShortUID(string(fileRestore.UID)), fileRestore.Namespace)
+	return getValidLabel(name)
+}
+
+// StartBackupVolumeMount starts a kopia restore of the backup of the volume
+// into the PVC in the namespace of the file restore
+func (k *kdmp) StartBackupVolumeMount(
+	fileRestore *storkapi.VolumeFileRestore,
+	backup *storkapi.ApplicationBackup,
+	volumeInfo *storkapi.ApplicationBackupVolumeInfo,
+	pvc *v1.PersistentVolumeClaim,
+) error {
+	backupUID, ok := backup.Annotations[backupUIDKey]
+	if !ok {
+		return fmt.Errorf("unable to find backup uid from applicationbackup %s/%s", backup.Namespace, backup.Name)
+	}
+	labels := make(map[string]string)
+	labels[volumeFileRestoreCRNameKey] = getValidLabel(fileRestore.Name)
+	labels[volumeFileRestoreCRUIDKey] = getValidLabel(string(fileRestore.UID))
+	labels[pvcNameKey] = getValidLabel(volumeInfo.PersistentVolumeClaim)
+	labels[pvcUIDKey] = getValidLabel(volumeInfo.PersistentVolumeClaimUID)
+	crName := getFileRestoreCRName(fileRestore)
+
+	volBackup := &kdmpapi.VolumeBackup{}
+	volBackup.Labels = labels
+	volBackup.Annotations = map[string]string{skipResourceAnnotation: "true"}
+	volBackup.Name = crName
+	volBackup.Namespace = fileRestore.Namespace
+	volBackup.Spec.BackupLocation = kdmpapi.DataExportObjectReference{
+		Kind:       reflect.TypeOf(storkapi.BackupLocation{}).Name(),
+		Name:       backup.Spec.BackupLocation,
+		Namespace:  backup.Namespace,
+		APIVersion: StorkAPIVersion,
+	}
+	volBackup.Spec.Repository = fmt.Sprintf("%s/%s-%s/", prefixRepo, volumeInfo.Namespace, volumeInfo.PersistentVolumeClaim)
+	volBackup.Status.SnapshotID = volumeInfo.BackupID
+	if _, err := kdmpShedOps.Instance().CreateVolumeBackup(volBackup); err != nil && !k8serror.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to create volumebackup CR: %v", err)
+	}
+
+	if pvc.Annotations == nil {
+		pvc.Annotations = make(map[string]string)
+	}
+	pvc.Annotations[KdmpAnnotation] = StorkAnnotation
+	storkPodNs, err := k8sutils.GetStorkPodNamespace()
+	if err != nil {
+		return fmt.Errorf("error in getting stork pod namespace: %v", err)
+	}
+	dataExport := &kdmpapi.DataExport{}
+	dataExport.Labels = labels
+	dataExport.Annotations = map[string]string{
+		skipResourceAnnotation: "true",
+		backupObjectUIDKey:     backupUID,
+		pvcUIDKey:              volumeInfo.PersistentVolumeClaimUID,
+	}
+	dataExport.Name = crName
+	dataExport.Namespace = fileRestore.Namespace
+	dataExport.Spec.Type = kdmpapi.DataExportKopia
+	dataExport.Spec.TriggeredFrom = kdmputils.TriggeredFromStork
+	dataExport.Spec.TriggeredFromNs = storkPodNs
+	dataExport.Status.TransferID = volBackup.Namespace + "/" + volBackup.Name
+	dataExport.Status.RestorePVC = pvc
+	// Use the local snapshot if it is still around, the snapshot class was
+	// recorded with the snapshot during the backup
+	if volumeInfo.VolumeSnapshot != "" {
+		dataExport.Status.LocalSnapshotRestore = true
+		dataExport.Spec.SnapshotStorageClass = getVolumeSnapshotClassFromBackupVolumeInfo(volumeInfo)
+	}
+	dataExport.Spec.Source = kdmpapi.DataExportObjectReference{
+		Kind:       reflect.TypeOf(kdmpapi.VolumeBackup{}).Name(),
+		Name:       volBackup.Name,
+		Namespace:  volBackup.Namespace,
+		APIVersion: KdmpAPIVersion,
+	}
+	dataExport.Spec.Destination = kdmpapi.DataExportObjectReference{
+		Kind:       PVCKind,
+		Name:       pvc.Name,
+		Namespace:  fileRestore.Namespace,
+		APIVersion: "v1",
+	}
+	if _, err := kdmpShedOps.Instance().CreateDataExport(dataExport); err != nil && !k8serror.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create DataExport CR: %v", err)
+	}
+	return nil
+}
+
+// GetBackupVolumeMountStatus returns true once the kopia restore into the PVC
+// is complete
+func (k *kdmp) GetBackupVolumeMountStatus(fileRestore *storkapi.VolumeFileRestore) (bool, error) {
+	dataExport, err := kdmpShedOps.Instance().GetDataExport(getFileRestoreCRName(fileRestore), fileRestore.Namespace)
+	if err != nil {
+		return false, err
+	}
+	if dataExport.Status.Status == kdmpapi.DataExportStatusFailed &&
+		dataExport.Status.Stage == kdmpapi.DataExportStageFinal {
+		return false, fmt.Errorf("restore of volume %v failed: %v", fileRestore.Spec.SourcePVC, dataExport.Status.Reason)
+	}
+	return isDataExportCompleted(dataExport.Status), nil
+}
+
+// CleanupBackupVolumeMount deletes the kdmp CRs created for the file restore
+func (k *kdmp) CleanupBackupVolumeMount(fileRestore *storkapi.VolumeFileRestore) error {
+	crName := getFileRestoreCRName(fileRestore)
+	if err := kdmpShedOps.Instance().DeleteDataExport(crName, fileRestore.Namespace); err != nil && !k8serror.IsNotFound(err) {
+		return fmt.Errorf("failed to delete data export CR %s/%s: %v", fileRestore.Namespace, crName, err)
+	}
+	if err := kdmpShedOps.Instance().DeleteVolumeBackup(crName, fileRestore.Namespace); err != nil && !k8serror.IsNotFound(err) {
+		return fmt.Errorf("failed to delete volume backup CR %s/%s: %v", fileRestore.Namespace, crName, err)
+	}
+	return nil
+}
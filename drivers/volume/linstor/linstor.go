@@ -51,6 +51,7 @@ type linstor struct {
 	storkvolume.BackupRestoreNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.FileRestoreNotSupported
 }
 
 func (l *linstor) linstorClient() (*lclient.Client, error) {
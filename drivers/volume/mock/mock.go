@@ -43,6 +43,7 @@ type Driver struct {
 	storkvolume.BackupRestoreNotSupported
 	storkvolume.CloneNotSupported
 	storkvolume.SnapshotRestoreNotSupported
+	storkvolume.FileRestoreNotSupported
 	nodes          []*storkvolume.NodeInfo
 	volumes        map[string]*storkvolume.Info
 	pvcs           map[string]*v1.PersistentVolumeClaim
@@ -179,6 +180,20 @@ func (m *Driver) UpdateNodeStatus(
 	return nil
 }
 
+// UpdateNodeCapacity Update the storage capacity reported for a node
+func (m *Driver) UpdateNodeCapacity(
+	nodeIndex int,
+	totalCapacity uint64,
+	usedCapacity uint64,
+) error {
+	if len(m.nodes) <= nodeIndex {
+		return fmt.Errorf("node %v not found", nodeIndex)
+	}
+	m.nodes[nodeIndex].TotalCapacity = totalCapacity
+	m.nodes[nodeIndex].UsedCapacity = usedCapacity
+	return nil
+}
+
 // UpdateNodeIP Update IP for a node
 func (m *Driver) UpdateNodeIP(
 	nodeIndex int,
@@ -243,6 +243,8 @@ type portworx struct {
 	jwtSharedSecret string
 	jwtIssuer       string
 	initDone        bool
+
+	storkvolume.FileRestoreNotSupported
 }
 
 type portworxGrpcConnection struct {
@@ -632,6 +634,10 @@ func (p *portworx) GetNodes() ([]*storkvolume.NodeInfo, error) {
 		}
 		nodeInfo.IPs = append(nodeInfo.IPs, n.MgmtIp)
 		nodeInfo.IPs = append(nodeInfo.IPs, n.DataIp)
+		for i := range n.Pools {
+			nodeInfo.TotalCapacity += n.Pools[i].TotalSize
+			nodeInfo.UsedCapacity += n.Pools[i].Used
+		}
 
 		labels, err := p.getNodeLabels(nodeInfo)
 		if err == nil {
@@ -2398,6 +2404,28 @@ func (p *portworx) DeletePair(pair *storkapi.ClusterPair) error {
 	return nil
 }
 
+func (p *portworx) ValidatePair(pair *storkapi.ClusterPair) error {
+	if !p.initDone {
+		if err := p.initPortworxClients(); err != nil {
+			return err
+		}
+	}
+
+	clusterManager, err := p.getClusterManagerClient()
+	if err != nil {
+		return fmt.Errorf("cannot get cluster manager, err: %s", err.Error())
+	}
+
+	resp, err := clusterManager.GetPair(pair.Status.RemoteStorageID)
+	if err != nil {
+		return fmt.Errorf("error getting pair %v: %v", pair.Status.RemoteStorageID, err)
+	}
+	if resp.GetPairInfo() == nil {
+		return fmt.Errorf("pair %v not found", pair.Status.RemoteStorageID)
+	}
+	return nil
+}
+
 func (p *portworx) StartMigration(migration *storkapi.Migration) ([]*storkapi.MigrationVolumeInfo, error) {
 	if !p.initDone {
 		if err := p.initPortworxClients(); err != nil {
@@ -2620,7 +2648,7 @@ func (p *portworx) CreateGroupSnapshot(snap *storkapi.GroupVolumeSnapshot) (
 		return nil, err
 	}
 
-	volNames, err := k8sutils.GetVolumeNamesFromLabelSelector(snap.Namespace, snap.Spec.PVCSelector.MatchLabels)
+	volNames, err := k8sutils.GetVolumeNamesFromLabelSelectorInNamespaces(snap.GetPVCNamespaces(), snap.Spec.PVCSelector.MatchLabels)
 	if err != nil {
 		return nil, err
 	}
@@ -2977,6 +3005,7 @@ func (p *portworx) addApplicationBackupCloudsnapInfo(
 
 func (p *portworx) StartBackup(backup *storkapi.ApplicationBackup,
 	pvcs []v1.PersistentVolumeClaim,
+	parents map[string]*storkapi.ApplicationBackupVolumeInfo,
 ) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
 	if !p.initDone {
 		if err := p.initPortworxClients(); err != nil {
@@ -3080,6 +3109,16 @@ func (p *portworx) StartBackup(backup *storkapi.ApplicationBackup,
 				request.Full = false
 			}
 		}
+		if backup.Spec.Incremental {
+			// Cloudsnaps are incremental to the previous cloudsnap of the
+			// volume, so start with a full backup if the volume doesn't
+			// have a parent backup
+			if parent, ok := parents[volumeInfo.PersistentVolumeClaimUID]; !ok {
+				request.Full = true
+			} else if !request.Full {
+				volumeInfo.ParentBackupID = parent.BackupID
+			}
+		}
 
 		p.addApplicationBackupCloudsnapInfo(request, backup)
 		var cloudBackupCreateErr error
@@ -155,6 +155,8 @@ type Driver interface {
 	ClonePluginInterface
 	// SnapshotRestorePluginInterface Interface to do in-place restore of volumes
 	SnapshotRestorePluginInterface
+	// FileRestorePluginInterface Interface to restore files from the backup of a volume
+	FileRestorePluginInterface
 }
 
 // GroupSnapshotCreateResponse is the response for the group snapshot operation
@@ -178,6 +180,8 @@ type ClusterPairPluginInterface interface {
 	CreatePair(*storkapi.ClusterPair) (string, error)
 	// Deletes a paring with a remote cluster
 	DeletePair(*storkapi.ClusterPair) error
+	// ValidatePair checks that the pairing with a remote cluster still exists
+	ValidatePair(*storkapi.ClusterPair) error
 }
 
 // MigratePluginInterface Interface to migrate data between clusters
@@ -208,8 +212,12 @@ type ClusterDomainsPluginInterface interface {
 // BackupRestorePluginInterface Interface to backup and restore volumes
 type BackupRestorePluginInterface interface {
 	// Start backup of volumes specified by the spec. Should only backup
-	// volumes, not the specs associated with them
-	StartBackup(*storkapi.ApplicationBackup, []v1.PersistentVolumeClaim) ([]*storkapi.ApplicationBackupVolumeInfo, error)
+	// volumes, not the specs associated with them. For incremental backups
+	// the backups of the volumes in the parent backups are passed in keyed
+	// by the UID of the PVC. Drivers that take the backup of a volume
+	// relative to its parent should set ParentBackupID in the returned info,
+	// others can take a full backup
+	StartBackup(*storkapi.ApplicationBackup, []v1.PersistentVolumeClaim, map[string]*storkapi.ApplicationBackupVolumeInfo) ([]*storkapi.ApplicationBackupVolumeInfo, error)
 	// Get the status of backup of the volumes specified in the status
 	// for the backup spec
 	GetBackupStatus(*storkapi.ApplicationBackup) ([]*storkapi.ApplicationBackupVolumeInfo, error)
@@ -217,7 +225,9 @@ type BackupRestorePluginInterface interface {
 	CancelBackup(*storkapi.ApplicationBackup) error
 	// CleanupBackupResources the backup of resource specified backup
 	CleanupBackupResources(*storkapi.ApplicationBackup) error
-	// Delete the backups specified in the status
+	// Delete the backups specified in the status. Drivers that take backups
+	// of volumes relative to their parents need to keep the data that the
+	// backups of other volumes are incremental to until those are deleted
 	DeleteBackup(*storkapi.ApplicationBackup) (bool, error)
 	// Get any resources that should be created before the restore is started
 	GetPreRestoreResources(*storkapi.ApplicationBackup, *storkapi.ApplicationRestore, []runtime.Unstructured) ([]runtime.Unstructured, error)
@@ -254,6 +264,21 @@ type ClonePluginInterface interface {
 	CreateVolumeClones(*storkapi.ApplicationClone) error
 }
 
+// FileRestorePluginInterface Interface to restore individual files from the
+// backup of a volume
+type FileRestorePluginInterface interface {
+	// StartBackupVolumeMount starts provisioning a PVC with the contents of
+	// the backup of a volume. The PVC is created from the given spec in the
+	// namespace of the file restore
+	StartBackupVolumeMount(*storkapi.VolumeFileRestore, *storkapi.ApplicationBackup, *storkapi.ApplicationBackupVolumeInfo, *v1.PersistentVolumeClaim) error
+	// GetBackupVolumeMountStatus returns true once the PVC has been
+	// provisioned with the contents of the backup
+	GetBackupVolumeMountStatus(*storkapi.VolumeFileRestore) (bool, error)
+	// CleanupBackupVolumeMount deletes any resources created by the driver
+	// to provision the PVC. The PVC itself is deleted by the caller
+	CleanupBackupVolumeMount(*storkapi.VolumeFileRestore) error
+}
+
 // Info Information about a volume
 type Info struct {
 	// VolumeID is a unique identifier for the volume
@@ -305,6 +330,11 @@ type NodeInfo struct {
 	Status NodeStatus
 	// RawStatus as returned by the driver
 	RawStatus string
+	// TotalCapacity of the storage on the node in bytes. 0 if the driver
+	// doesn't report it
+	TotalCapacity uint64
+	// UsedCapacity of the storage on the node in bytes
+	UsedCapacity uint64
 }
 
 var (
@@ -409,6 +439,11 @@ func (c *ClusterPairNotSupported) DeletePair(*storkapi.ClusterPair) error {
 	return &errors.ErrNotSupported{}
 }
 
+// ValidatePair Returns ErrNotSupported
+func (c *ClusterPairNotSupported) ValidatePair(*storkapi.ClusterPair) error {
+	return &errors.ErrNotSupported{}
+}
+
 // MigrationNotSupported to be used by drivers that don't support migration
 type MigrationNotSupported struct{}
 
@@ -477,6 +512,7 @@ type BackupRestoreNotSupported struct{}
 func (b *BackupRestoreNotSupported) StartBackup(
 	*storkapi.ApplicationBackup,
 	[]v1.PersistentVolumeClaim,
+	map[string]*storkapi.ApplicationBackupVolumeInfo,
 ) ([]*storkapi.ApplicationBackupVolumeInfo, error) {
 	return nil, &errors.ErrNotSupported{}
 }
@@ -566,6 +602,30 @@ func (s *SnapshotRestoreNotSupported) CleanupSnapshotRestoreObjects(*storkapi.Vo
 	return &errors.ErrNotImplemented{}
 }
 
+// FileRestoreNotSupported to be used by drivers that don't support restoring
+// files from the backup of a volume
+type FileRestoreNotSupported struct{}
+
+// StartBackupVolumeMount returns ErrNotSupported
+func (f *FileRestoreNotSupported) StartBackupVolumeMount(
+	*storkapi.VolumeFileRestore,
+	*storkapi.ApplicationBackup,
+	*storkapi.ApplicationBackupVolumeInfo,
+	*v1.PersistentVolumeClaim,
+) error {
+	return &errors.ErrNotSupported{}
+}
+
+// GetBackupVolumeMountStatus returns ErrNotSupported
+func (f *FileRestoreNotSupported) GetBackupVolumeMountStatus(*storkapi.VolumeFileRestore) (bool, error) {
+	return false, &errors.ErrNotSupported{}
+}
+
+// CleanupBackupVolumeMount returns ErrNotSupported
+func (f *FileRestoreNotSupported) CleanupBackupVolumeMount(*storkapi.VolumeFileRestore) error {
+	return &errors.ErrNotSupported{}
+}
+
 // IsNodeMatch There are a couple of things that need to be checked to see if the driver
 // node matched the k8s node since different k8s installs set the node name,
 // hostname and IPs differently
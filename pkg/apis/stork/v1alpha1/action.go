@@ -0,0 +1,144 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ActionResourceName is name for "action" resource
+	ActionResourceName = "action"
+	// ActionResourcePlural is plural for "action" resource
+	ActionResourcePlural = "actions"
+)
+
+// ActionType is the type of the action
+type ActionType string
+
+const (
+	// ActionTypeFailover activates the applications migrated to this cluster
+	// after deactivating them on the source cluster
+	ActionTypeFailover ActionType = "failover"
+	// ActionTypeFailback activates the applications on this cluster again
+	// after they were failed over to the remote cluster
+	ActionTypeFailback ActionType = "failback"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Action fails over or fails back the applications migrated by a
+// MigrationSchedule. It is created in the cluster where the applications
+// need to be activated
+type Action struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ActionSpec   `json:"spec"`
+	Status            ActionStatus `json:"status"`
+}
+
+// ActionSpec is the spec used to fail over or fail back applications
+type ActionSpec struct {
+	ActionType ActionType `json:"actionType"`
+	// MigrationSchedule is the schedule, in the namespace of the Action on
+	// the remote cluster, that migrates the applications to this cluster
+	MigrationSchedule string `json:"migrationSchedule"`
+	// ClusterPair is the ClusterPair, in the namespace of the Action, that
+	// pairs this cluster with the remote cluster. It is used to reach the
+	// remote cluster and by the reverse schedule. It isn't created by the
+	// action and needs to exist, and be ready if the remote cluster is
+	// reachable, when the action is created. The reverse schedule is left
+	// suspended if the pair isn't ready anymore when it is resumed
+	ClusterPair string `json:"clusterPair"`
+	// ReverseMigrationSchedule is the schedule in this cluster that
+	// migrates the applications back to the remote cluster. It is resumed if
+	// it exists and created from MigrationSchedule otherwise. Defaults to the
+	// schedule MigrationSchedule was reversed from, or
+	// <MigrationSchedule>-reverse
+	ReverseMigrationSchedule string `json:"reverseMigrationSchedule,omitempty"`
+	// Namespaces are the namespaces to activate. Defaults to the namespaces
+	// of MigrationSchedule
+	Namespaces []string `json:"namespaces,omitempty"`
+	// SkipFinalMigration skips the migration that is run from the remote
+	// cluster before deactivating the applications there
+	SkipFinalMigration bool `json:"skipFinalMigration,omitempty"`
+}
+
+// ActionStageType is the stage of an action
+type ActionStageType string
+
+const (
+	// ActionStageInitial for when the action is created
+	ActionStageInitial ActionStageType = ""
+	// ActionStageSuspendSchedule for when the schedule on the remote
+	// cluster is being suspended
+	ActionStageSuspendSchedule ActionStageType = "SuspendSchedule"
+	// ActionStageFinalMigration for when the last migration from the remote
+	// cluster is in progress
+	ActionStageFinalMigration ActionStageType = "FinalMigration"
+	// ActionStageDeactivate for when the applications on the remote cluster
+	// are being deactivated. Namespaces that couldn't be deactivated aren't
+	// activated on this cluster
+	ActionStageDeactivate ActionStageType = "Deactivate"
+	// ActionStageActivate for when the applications on this cluster are
+	// being activated
+	ActionStageActivate ActionStageType = "Activate"
+	// ActionStageReverseSchedule for when the reverse schedule is being
+	// created or resumed
+	ActionStageReverseSchedule ActionStageType = "ReverseSchedule"
+	// ActionStageFinal for when the action is done
+	ActionStageFinal ActionStageType = "Final"
+)
+
+// ActionStatusType is the status of an action
+type ActionStatusType string
+
+const (
+	// ActionStatusInitial for when the action is created
+	ActionStatusInitial ActionStatusType = ""
+	// ActionStatusInProgress for when the action is in progress
+	ActionStatusInProgress ActionStatusType = "InProgress"
+	// ActionStatusSuccessful for when the action was successful
+	ActionStatusSuccessful ActionStatusType = "Successful"
+	// ActionStatusPartialSuccess for when some of the namespaces could not
+	// be deactivated on the remote cluster or activated
+	ActionStatusPartialSuccess ActionStatusType = "PartialSuccess"
+	// ActionStatusFailed for when the action failed
+	ActionStatusFailed ActionStatusType = "Failed"
+)
+
+// ActionStatus is the status of an action
+type ActionStatus struct {
+	Stage  ActionStageType  `json:"stage"`
+	Status ActionStatusType `json:"status"`
+	Reason string           `json:"reason"`
+	// RemoteReachable is set if the remote cluster could be reached when
+	// the action started. The remote cluster is left untouched otherwise
+	RemoteReachable bool `json:"remoteReachable"`
+	// Migration is the final migration started on the remote cluster
+	Migration string `json:"migration,omitempty"`
+	// ReverseMigrationSchedule is the schedule in this cluster that migrates
+	// the applications back to the remote cluster
+	ReverseMigrationSchedule string                   `json:"reverseMigrationSchedule,omitempty"`
+	Namespaces               []*ActionNamespaceStatus `json:"namespaces"`
+	FinishTimestamp          metav1.Time              `json:"finishTimestamp"`
+	LastUpdateTimestamp      metav1.Time              `json:"lastUpdateTimestamp"`
+}
+
+// ActionNamespaceStatus is the status of an action for a namespace
+type ActionNamespaceStatus struct {
+	Namespace string `json:"namespace"`
+	// Stage is the last stage that was run for the namespace
+	Stage  ActionStageType  `json:"stage"`
+	Status ActionStatusType `json:"status"`
+	Reason string           `json:"reason"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ActionList is a list of Actions
+type ActionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []Action `json:"items"`
+}
@@ -40,8 +40,47 @@ type ApplicationBackupSpec struct {
 	IncludeResources []ObjectInfo      `json:"includeResources"`
 	ResourceTypes    []string          `json:"resourceTypes"`
 	BackupType       string            `json:"backupType"`
+	// ResourceCompression is the compression used when uploading the
+	// resources to the backup location. Defaults to gzip
+	ResourceCompression ApplicationBackupCompressionType `json:"resourceCompression"`
+	// Incremental backs up the volumes and resources relative to the last
+	// successful incremental backup in the namespace for the same backup
+	// location. Resources that haven't changed are referenced from the
+	// parent backup instead of being uploaded again
+	Incremental bool `json:"incremental,omitempty"`
 }
 
+// ApplicationBackupCompressionType is the compression used for the resources
+// uploaded to the backup location
+type ApplicationBackupCompressionType string
+
+const (
+	// ApplicationBackupCompressionNone uploads the resources uncompressed
+	ApplicationBackupCompressionNone ApplicationBackupCompressionType = "none"
+	// ApplicationBackupCompressionGzip compresses the resources with gzip
+	ApplicationBackupCompressionGzip ApplicationBackupCompressionType = "gzip"
+)
+
+// ApplicationBackupResourcesFormatType is the layout used to store the
+// resources of a backup in the backup location
+type ApplicationBackupResourcesFormatType string
+
+const (
+	// ApplicationBackupResourcesFormatLegacy is used by backups where the
+	// resources were uploaded as a single JSON document which was encrypted
+	// as a whole
+	ApplicationBackupResourcesFormatLegacy ApplicationBackupResourcesFormatType = ""
+	// ApplicationBackupResourcesFormatStream is used by backups where the
+	// resources were streamed to the backup location, optionally compressed
+	// and encrypted in chunks
+	ApplicationBackupResourcesFormatStream ApplicationBackupResourcesFormatType = "stream-v1"
+	// ApplicationBackupResourcesFormatIncremental is used by incremental
+	// backups. The resources that changed since the parent backup are
+	// streamed like ApplicationBackupResourcesFormatStream and an index
+	// references the unchanged ones in the backups where they were uploaded
+	ApplicationBackupResourcesFormatIncremental ApplicationBackupResourcesFormatType = "incremental-v1"
+)
+
 // ApplicationBackupReclaimPolicyType is the reclaim policy for the application backup
 type ApplicationBackupReclaimPolicyType string
 
@@ -66,6 +105,25 @@ type ApplicationBackupStatus struct {
 	LastUpdateTimestamp metav1.Time                      `json:"lastUpdateTimestamp"`
 	FinishTimestamp     metav1.Time                      `json:"finishTimestamp"`
 	TotalSize           uint64                           `json:"totalSize"`
+	// ResourcesFormat is the layout used to upload the resources for the
+	// backup, restores use it to decide how to read them back
+	ResourcesFormat ApplicationBackupResourcesFormatType `json:"resourcesFormat"`
+	// EncryptionKeyID is the ID of the key encryption key that was used to
+	// wrap the data key for the backup if the backup location uses envelope
+	// encryption
+	EncryptionKeyID string `json:"encryptionKeyID,omitempty"`
+	// WrappedDataKey is the data key for the backup wrapped with the key
+	// identified by EncryptionKeyID
+	WrappedDataKey []byte `json:"wrappedDataKey,omitempty"`
+	// RuleActionFailures are the failures of actions in the pre and post
+	// exec rules that were ignored since their OnFailure policy is Continue
+	RuleActionFailures []*RuleActionFailure `json:"ruleActionFailures,omitempty"`
+	// RuleRuns are the records of the actions run by the pre and post exec
+	// rules
+	RuleRuns []*RuleRun `json:"ruleRuns,omitempty"`
+	// ParentBackup is the name of the backup that the resources of an
+	// incremental backup were deduplicated against
+	ParentBackup string `json:"parentBackup,omitempty"`
 }
 
 // ObjectInfo contains info about an object being backed up or restored
@@ -97,6 +155,13 @@ type ApplicationBackupVolumeInfo struct {
 	StorageClass             string                      `json:"storageClass"`
 	Provisioner              string                      `json:"provisioner"`
 	VolumeSnapshot           string                      `json:"volumeSnapshot"`
+	// ParentBackup is the name of the backup that the backup of the volume
+	// is incremental to. It is set to the parent of that backup when that
+	// backup is deleted
+	ParentBackup string `json:"parentBackup,omitempty"`
+	// ParentBackupID is the ID of the backup of the volume that it was taken
+	// relative to
+	ParentBackupID string `json:"parentBackupID,omitempty"`
 }
 
 // ApplicationBackupStatusType is the status of the application backup
@@ -29,6 +29,9 @@ type ApplicationBackupTemplateSpec struct {
 // ApplicationBackupScheduleStatus is the status of a applicationbackup schedule
 type ApplicationBackupScheduleStatus struct {
 	Items map[SchedulePolicyType][]*ScheduledApplicationBackupStatus `json:"items"`
+	// Pending is set if a backup is due but is deferred by a blackout
+	// window or is queued
+	Pending *SchedulePendingStatus `json:"pending,omitempty"`
 }
 
 // ScheduledApplicationBackupStatus keeps track of the applicationbackup that was triggered by a
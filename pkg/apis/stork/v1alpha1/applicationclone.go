@@ -38,6 +38,10 @@ type ApplicationCloneSpec struct {
 	// ReplacePolicy to decide how to react when a object conflict occurs in the cloning process
 	ReplacePolicy                ApplicationCloneReplacePolicyType `json:"replacePolicy"`
 	IncludeOptionalResourceTypes []string                          `json:"includeOptionalResourceTypes"`
+	// TransformSpecs are the names of the ResourceTransformations, in the
+	// namespace of the clone, applied to the resources before they are
+	// cloned
+	TransformSpecs []string `json:"transformSpecs,omitempty"`
 }
 
 // ApplicationCloneStatus defines the status of the clone
@@ -49,6 +53,14 @@ type ApplicationCloneStatus struct {
 	Resources       []*ApplicationCloneResourceInfo `json:"resources"`
 	Volumes         []*ApplicationCloneVolumeInfo   `json:"volumes"`
 	FinishTimestamp meta.Time                       `json:"finishTimestamp"`
+	// Reason the clone failed before any resources were cloned
+	Reason string `json:"reason,omitempty"`
+	// RuleActionFailures are the failures of actions in the pre and post
+	// exec rules that were ignored since their OnFailure policy is Continue
+	RuleActionFailures []*RuleActionFailure `json:"ruleActionFailures,omitempty"`
+	// RuleRuns are the records of the actions run by the pre and post exec
+	// rules
+	RuleRuns []*RuleRun `json:"ruleRuns,omitempty"`
 }
 
 // ApplicationCloneResourceInfo is the info for the cloning of a resource
@@ -31,6 +31,22 @@ type ApplicationRestoreSpec struct {
 	IncludeOptionalResourceTypes []string                            `json:"includeOptionalResourceTypes"`
 	IncludeResources             []ObjectInfo                        `json:"includeResources"`
 	StorageClassMapping          map[string]string                   `json:"storageClassMapping"`
+	// DryRun reports what the restore would do in the status without
+	// restoring any volumes or resources
+	DryRun bool `json:"dryRun,omitempty"`
+	// TransformSpecs are the names of the ResourceTransformations, in the
+	// namespace of the restore, applied to the resources before they are
+	// restored
+	TransformSpecs []string `json:"transformSpecs,omitempty"`
+	// BackupSchedule is the ApplicationBackupSchedule, in the namespace of
+	// the restore, to pick the backup to restore from if BackupName isn't
+	// set
+	BackupSchedule string `json:"backupSchedule,omitempty"`
+	// PointInTime is the time to restore the state of the applications to
+	// when restoring from BackupSchedule. The latest successful backup that
+	// finished at or before it is used. Defaults to the latest successful
+	// backup
+	PointInTime *metav1.Time `json:"pointInTime,omitempty"`
 }
 
 // ApplicationRestoreReplacePolicyType is the replace policy for the application restore
@@ -58,6 +74,51 @@ type ApplicationRestoreStatus struct {
 	FinishTimestamp     metav1.Time                       `json:"finishTimestamp"`
 	LastUpdateTimestamp metav1.Time                       `json:"lastUpdateTimestamp"`
 	TotalSize           uint64                            `json:"totalSize"`
+	// DryRunResources is the action that would be taken for each resource
+	// in the backup if DryRun is set
+	DryRunResources []*ApplicationRestoreDryRunResourceInfo `json:"dryRunResources,omitempty"`
+	// DryRunVolumes are the PVCs that would be provisioned if DryRun is set
+	DryRunVolumes []*ApplicationRestoreDryRunVolumeInfo `json:"dryRunVolumes,omitempty"`
+	// BackupName is the backup that was picked from the BackupSchedule to
+	// restore from
+	BackupName string `json:"backupName,omitempty"`
+	// BackupParents are the parent backups of BackupName if it is an
+	// incremental backup, closest first
+	BackupParents []string `json:"backupParents,omitempty"`
+}
+
+// ApplicationRestoreDryRunActionType is the action a restore would take for
+// a resource
+type ApplicationRestoreDryRunActionType string
+
+const (
+	// ApplicationRestoreDryRunActionCreate means the resource doesn't exist
+	// and would be created
+	ApplicationRestoreDryRunActionCreate ApplicationRestoreDryRunActionType = "Create"
+	// ApplicationRestoreDryRunActionReplace means the resource exists and
+	// would be deleted and created again since ReplacePolicy is Delete
+	ApplicationRestoreDryRunActionReplace ApplicationRestoreDryRunActionType = "Replace"
+	// ApplicationRestoreDryRunActionSkip means the resource wouldn't be
+	// restored
+	ApplicationRestoreDryRunActionSkip ApplicationRestoreDryRunActionType = "Skip"
+)
+
+// ApplicationRestoreDryRunResourceInfo is the dry run result for a resource
+type ApplicationRestoreDryRunResourceInfo struct {
+	ObjectInfo `json:",inline"`
+	Action     ApplicationRestoreDryRunActionType `json:"action"`
+	Reason     string                             `json:"reason"`
+}
+
+// ApplicationRestoreDryRunVolumeInfo is a PVC that would be provisioned by
+// the restore
+type ApplicationRestoreDryRunVolumeInfo struct {
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+	Namespace             string `json:"namespace"`
+	SourceNamespace       string `json:"sourceNamespace"`
+	StorageClass          string `json:"storageClass"`
+	Size                  string `json:"size"`
+	DriverName            string `json:"driverName"`
 }
 
 // ApplicationRestoreResourceInfo is the info for the restore of a resource
@@ -29,7 +29,7 @@ type BackupLocation struct {
 }
 
 // BackupLocationItem is the spec used to store a backup location
-// Only one of S3Config, AzureConfig or GoogleConfig should be specified and
+// Only one of S3Config, AzureConfig, GoogleConfig or NFSConfig should be specified and
 // should match the Type field. Members of the config can be specified inline or
 // through the SecretConfig
 type BackupLocationItem struct {
@@ -41,11 +41,33 @@ type BackupLocationItem struct {
 	S3Config           *S3Config     `json:"s3Config,omitempty"`
 	AzureConfig        *AzureConfig  `json:"azureConfig,omitempty"`
 	GoogleConfig       *GoogleConfig `json:"googleConfig,omitempty"`
+	NFSConfig          *NFSConfig    `json:"nfsConfig,omitempty"`
 	SecretConfig       string        `json:"secretConfig"`
 	Sync               bool          `json:"sync"`
 	RepositoryPassword string        `json:"repositoryPassword"`
 	// EncryptionV2Key will be used to pass encryption key.
 	EncryptionV2Key string `json:"encryptionV2Key"`
+	// EncryptionKeys enables envelope encryption for the backup location.
+	// It takes precedence over EncryptionV2Key for new backups, which is
+	// still used to read backups that were encrypted with it
+	EncryptionKeys *EncryptionKeyConfig `json:"encryptionKeys,omitempty"`
+}
+
+// EncryptionKeyConfig configures envelope encryption for a backup location.
+// Every backup is encrypted with its own data key, which is wrapped with the
+// key encryption key identified by CurrentKeyID. Backups whose data key was
+// wrapped with one of the PreviousKeyIDs can still be restored, so keys can be
+// rotated without re-encrypting existing backups
+type EncryptionKeyConfig struct {
+	// Provider is the name of the key encryption key provider, secret or
+	// transit
+	Provider string `json:"provider"`
+	// Options are passed to the provider
+	Options map[string]string `json:"options,omitempty"`
+	// CurrentKeyID is used to wrap the data keys for new backups
+	CurrentKeyID string `json:"currentKeyID"`
+	// PreviousKeyIDs are only used to unwrap data keys of existing backups
+	PreviousKeyIDs []string `json:"previousKeyIDs,omitempty"`
 }
 
 // ClusterItem is the spec used to store a the credentials associated with the cluster
@@ -73,6 +95,15 @@ const (
 	BackupLocationAzure BackupLocationType = "azure"
 	// BackupLocationGoogle stores the backup in Google Cloud Storage
 	BackupLocationGoogle BackupLocationType = "google"
+	// BackupLocationNFS stores the backup on an NFS export (or any other
+	// filesystem) that is mounted in the stork pod
+	BackupLocationNFS BackupLocationType = "nfs"
+)
+
+const (
+	// DefaultNFSMountPath is the path in the stork pod where the NFS export
+	// is expected to be mounted if one isn't provided in the NFSConfig
+	DefaultNFSMountPath = "/var/lib/stork/nfs"
 )
 
 // ClusterType is the type of the cluster
@@ -123,6 +154,18 @@ type GoogleConfig struct {
 	AccountKey string `json:"accountKey"`
 }
 
+// NFSConfig specifies the config required to store backups on an NFS export.
+// Stork does not mount the export itself, it needs to be mounted at MountPath
+// in the stork pods (for example with an nfs volume in the stork deployment).
+// Backups are then stored under MountPath/SubPath/Path
+type NFSConfig struct {
+	// SubPath is the directory on the export to use for the backup location
+	SubPath string `json:"subPath"`
+	// MountPath will be defaulted to /var/lib/stork/nfs by the controller if
+	// not provided
+	MountPath string `json:"mountPath"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // BackupLocationList is a list of ApplicationBackups
@@ -154,6 +197,8 @@ func (bl *BackupLocation) UpdateFromSecret(client kubernetes.Interface) error {
 		return bl.getMergedAzureConfig(client)
 	case BackupLocationGoogle:
 		return bl.getMergedGoogleConfig(client)
+	case BackupLocationNFS:
+		return bl.getMergedNFSConfig(client)
 	default:
 		return fmt.Errorf("Invalid BackupLocation type %v", bl.Location.Type)
 	}
@@ -252,6 +297,28 @@ func (bl *BackupLocation) getMergedGoogleConfig(client kubernetes.Interface) err
 	return nil
 }
 
+func (bl *BackupLocation) getMergedNFSConfig(client kubernetes.Interface) error {
+	if bl.Location.NFSConfig == nil {
+		bl.Location.NFSConfig = &NFSConfig{}
+	}
+	if bl.Location.SecretConfig != "" {
+		secretConfig, err := client.CoreV1().Secrets(bl.Namespace).Get(context.TODO(), bl.Location.SecretConfig, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("error getting secretConfig for backupLocation: %v", err)
+		}
+		if val, ok := secretConfig.Data["subPath"]; ok && val != nil {
+			bl.Location.NFSConfig.SubPath = strings.TrimSuffix(string(val), "\n")
+		}
+		if val, ok := secretConfig.Data["mountPath"]; ok && val != nil {
+			bl.Location.NFSConfig.MountPath = strings.TrimSuffix(string(val), "\n")
+		}
+	}
+	if bl.Location.NFSConfig.MountPath == "" {
+		bl.Location.NFSConfig.MountPath = DefaultNFSMountPath
+	}
+	return nil
+}
+
 func (bl *BackupLocation) getMergedAWSClusterCred(client kubernetes.Interface) error {
 	if bl.Cluster.SecretConfig != "" {
 		secretConfig, err := client.CoreV1().Secrets(bl.Namespace).Get(context.TODO(), bl.Cluster.SecretConfig, metav1.GetOptions{})
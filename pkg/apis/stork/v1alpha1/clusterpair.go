@@ -30,6 +30,20 @@ type ClusterPairSpec struct {
 	// PlatformOptions are kubernetes platform provider related
 	// options.
 	PlatformOptions PlatformSpec `json:"platformOptions",yaml:"platformOptions"`
+	// CredentialsSecret references a secret with the kubeconfig of the
+	// remote cluster. The config of the pair is refreshed from the secret
+	// when it changes, so that expired credentials can be rotated without
+	// recreating the pair
+	CredentialsSecret *ClusterPairCredentialsSecret `json:"credentialsSecret,omitempty"`
+}
+
+// ClusterPairCredentialsSecret is the reference to the secret with the
+// kubeconfig of the remote cluster
+type ClusterPairCredentialsSecret struct {
+	// Name of the secret in the namespace of the cluster pair
+	Name string `json:"name"`
+	// Key of the kubeconfig in the secret, defaults to "kubeconfig"
+	Key string `json:"key,omitempty"`
 }
 
 // ClusterPairStatusType is the status of the pair
@@ -64,6 +78,44 @@ type ClusterPairStatus struct {
 	// ID of the remote storage which is paired
 	// +optional
 	RemoteStorageID string `json:"remoteStorageId"`
+	// Conditions from the last probe of the pair
+	// +optional
+	Conditions []ClusterPairCondition `json:"conditions,omitempty"`
+	// LastProbeTimestamp is the time the pair was last probed
+	// +optional
+	LastProbeTimestamp meta.Time `json:"lastProbeTimestamp,omitempty"`
+}
+
+// ClusterPairConditionType is the type of a condition of the pair
+type ClusterPairConditionType string
+
+const (
+	// ClusterPairConditionRemoteReachable is set if the API server of the
+	// remote cluster can be reached with the credentials of the pair
+	ClusterPairConditionRemoteReachable ClusterPairConditionType = "RemoteReachable"
+	// ClusterPairConditionRemotePermissions is set if the credentials of the
+	// pair have the permissions required to migrate to the remote cluster
+	ClusterPairConditionRemotePermissions ClusterPairConditionType = "RemotePermissions"
+	// ClusterPairConditionStoragePaired is set if the storage of the clusters
+	// is still paired
+	ClusterPairConditionStoragePaired ClusterPairConditionType = "StoragePaired"
+)
+
+// ClusterPairCondition is the result of a check done by the probe of the pair
+type ClusterPairCondition struct {
+	// Type of the condition
+	Type ClusterPairConditionType `json:"type"`
+	// Status of the condition, one of True, False or Unknown
+	Status meta.ConditionStatus `json:"status"`
+	// Reason for the last transition of the condition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// Message with details about the last probe
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the time the status of the condition last changed
+	// +optional
+	LastTransitionTime meta.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // RancherSecret holds the reference to the api keys used to interact
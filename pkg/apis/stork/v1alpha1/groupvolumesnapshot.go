@@ -34,6 +34,13 @@ type GroupVolumeSnapshotSpec struct {
 	PostExecRule string `json:"postExecRule"`
 	// PVCSelector selects the PVCs that are part of the group snapshot
 	PVCSelector PVCSelectorSpec `json:"pvcSelector"`
+	// Namespaces is a list of namespaces in which the PVCs are selected. PVCs
+	// can only be selected from other namespaces if the group snapshot is in
+	// the admin namespace. default: namespace of the group snapshot
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects the namespaces, in addition to Namespaces, in
+	// which the PVCs are selected using the labels of the namespaces
+	NamespaceSelector map[string]string `json:"namespaceSelector,omitempty"`
 	// RestoreNamespaces is a list of namespaces to which the snapshots can be restored to
 	RestoreNamespaces []string `json:"restoreNamespaces"`
 	// MaxRetries is the number of times to retry the groupvolumesnapshot on failure. default: 0
@@ -63,15 +70,48 @@ type GroupVolumeSnapshotStatus struct {
 	Status          GroupVolumeSnapshotStatusType `json:"status"`
 	NumRetries      int                           `json:"numRetries"`
 	VolumeSnapshots []*VolumeSnapshotStatus       `json:"volumeSnapshots"`
+	// Namespaces are the namespaces in which the PVCs were selected when the
+	// group snapshot was started
+	Namespaces []string `json:"namespaces,omitempty"`
+	// RuleActionFailures are the failures of actions in the pre and post
+	// exec rules that were ignored since their OnFailure policy is Continue
+	RuleActionFailures []*RuleActionFailure `json:"ruleActionFailures,omitempty"`
+	// RuleRuns are the records of the actions run by the pre and post exec
+	// rules
+	RuleRuns []*RuleRun `json:"ruleRuns,omitempty"`
 }
 
 // VolumeSnapshotStatus captures the status of a volume snapshot operation
 type VolumeSnapshotStatus struct {
 	VolumeSnapshotName string
-	TaskID             string
-	ParentVolumeID     string
-	DataSource         *crdv1.VolumeSnapshotDataSource
-	Conditions         []crdv1.VolumeSnapshotCondition
+	// VolumeSnapshotNamespace is the namespace of the volume snapshot if it
+	// was created by the driver. The volume snapshots created by the group
+	// snapshot controller are in the namespace of the group snapshot
+	VolumeSnapshotNamespace string
+	TaskID                  string
+	ParentVolumeID          string
+	DataSource              *crdv1.VolumeSnapshotDataSource
+	Conditions              []crdv1.VolumeSnapshotCondition
+}
+
+// GetPVCNamespaces returns the namespaces in which the PVCs of the group
+// snapshot were selected
+func (g *GroupVolumeSnapshot) GetPVCNamespaces() []string {
+	if len(g.Status.Namespaces) > 0 {
+		return g.Status.Namespaces
+	}
+	return []string{g.Namespace}
+}
+
+// IsCrossNamespace returns true if the PVCs of the group snapshot were
+// selected in namespaces other than the namespace of the group snapshot
+func (g *GroupVolumeSnapshot) IsCrossNamespace() bool {
+	for _, ns := range g.GetPVCNamespaces() {
+		if ns != g.Namespace {
+			return true
+		}
+	}
+	return false
 }
 
 // GroupVolumeSnapshotStatusType is types of statuses of a group snapshot operation
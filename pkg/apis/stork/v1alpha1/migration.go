@@ -41,6 +41,12 @@ type MigrationStatus struct {
 	ResourceMigrationFinishTimestamp meta.Time                `json:"resourceMigrationFinishTimestamp"`
 	// Summary provides a short summary on the migration
 	Summary *MigrationSummary `json:"summary"`
+	// RuleActionFailures are the failures of actions in the pre and post
+	// exec rules that were ignored since their OnFailure policy is Continue
+	RuleActionFailures []*RuleActionFailure `json:"ruleActionFailures,omitempty"`
+	// RuleRuns are the records of the actions run by the pre and post exec
+	// rules
+	RuleRuns []*RuleRun `json:"ruleRuns,omitempty"`
 }
 
 // MigrationResourceInfo is the info for the migration of a resource
@@ -50,8 +56,32 @@ type MigrationResourceInfo struct {
 	meta.GroupVersionKind `json:",inline"`
 	Status                MigrationStatusType `json:"status"`
 	Reason                string              `json:"reason"`
+	// Action is the change made to the resource on the destination cluster
+	Action MigrationResourceActionType `json:"action,omitempty"`
+	// Diff is a summary of the fields that were changed if the resource
+	// was updated
+	Diff string `json:"diff,omitempty"`
 }
 
+// MigrationResourceActionType is the change made to a resource on the
+// destination cluster
+type MigrationResourceActionType string
+
+const (
+	// MigrationResourceActionCreated for when the resource didn't exist on
+	// the destination cluster
+	MigrationResourceActionCreated MigrationResourceActionType = "Created"
+	// MigrationResourceActionUpdated for when the resource was changed since
+	// it was last migrated
+	MigrationResourceActionUpdated MigrationResourceActionType = "Updated"
+	// MigrationResourceActionUnchanged for when the resource was the same on
+	// the destination cluster
+	MigrationResourceActionUnchanged MigrationResourceActionType = "Unchanged"
+	// MigrationResourceActionPurged for when the resource was deleted from
+	// the destination cluster since it was deleted on the source cluster
+	MigrationResourceActionPurged MigrationResourceActionType = "Purged"
+)
+
 // MigrationSummary provides a short summary on the migration
 type MigrationSummary struct {
 	// TotalNumberOfVolumes gives the total count of volumes
@@ -72,6 +102,18 @@ type MigrationSummary struct {
 	// resource migration stage has been running or the total time
 	// taken for the resource migration to complete if the volume migration has finished
 	ElapsedTimeForResourceMigration string `json:"elapsedTimeForResourceMigration"`
+	// NumberOfCreatedResources gives the count of resources created on the
+	// destination cluster
+	NumberOfCreatedResources uint64 `json:"numOfCreatedResources"`
+	// NumberOfUpdatedResources gives the count of resources that were
+	// changed on the destination cluster
+	NumberOfUpdatedResources uint64 `json:"numOfUpdatedResources"`
+	// NumberOfUnchangedResources gives the count of resources that were the
+	// same on the destination cluster
+	NumberOfUnchangedResources uint64 `json:"numOfUnchangedResources"`
+	// NumberOfPurgedResources gives the count of resources deleted from the
+	// destination cluster
+	NumberOfPurgedResources uint64 `json:"numOfPurgedResources"`
 }
 
 // MigrationVolumeInfo is the info for the migration of a volume
@@ -29,6 +29,9 @@ type MigrationTemplateSpec struct {
 type MigrationScheduleStatus struct {
 	Items                map[SchedulePolicyType][]*ScheduledMigrationStatus `json:"items"`
 	ApplicationActivated bool                                               `json:"applicationActivated"`
+	// Pending is set if a migration is due but is deferred by a blackout
+	// window or is queued
+	Pending *SchedulePendingStatus `json:"pending,omitempty"`
 }
 
 // ScheduledMigrationStatus keeps track of the migration that was triggered by a
@@ -68,6 +68,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&DataExportList{},
 		&ResourceTransformation{},
 		&ResourceTransformationList{},
+		&VolumeFileRestore{},
+		&VolumeFileRestoreList{},
+		&Action{},
+		&ActionList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
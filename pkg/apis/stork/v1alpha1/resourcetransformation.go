@@ -29,6 +29,11 @@ const (
 	DeleteResourcePath ResourceTransformationOperationType = "delete"
 	// JsonResourcePatch will patch json in given resource spec
 	JsonResourcePatch ResourceTransformationOperationType = "jsonpatch"
+	// RegexReplaceResourcePath replaces the matches of the regex pattern in
+	// the string values at the specified path with the value. Lists found
+	// along the path are traversed, so that the values in every entry are
+	// replaced
+	RegexReplaceResourcePath ResourceTransformationOperationType = "regexReplace"
 )
 
 // ResourceTransformationValueType is types of value supported on
@@ -110,6 +115,10 @@ type TransformSpecs struct {
 	// Selectors label selector to filter out resource for
 	// patching
 	Selectors map[string]string `json:"selectors"`
+	// When is a CEL expression to filter out resources for patching based
+	// on their content. The resource is available as object in the
+	// expression, for example object.spec.replicas > 1
+	When string `json:"when,omitempty"`
 	// Paths collection of resource path to update
 	Paths []ResourcePaths `json:"paths"`
 }
@@ -132,8 +141,17 @@ type ResourcePaths struct {
 	// Type of value specified int/bool/string/slice/keypair
 	Type ResourceTransformationValueType `json:"type"`
 	// Operation to be performed on path
-	// add/modify/delete/replace/jsonPatch
+	// add/modify/delete/replace/jsonPatch/regexReplace
 	Operation ResourceTransformationOperationType `json:"operation"`
+	// Pattern is the regex matched against the values at the path for the
+	// regexReplace operation. The value can refer to the submatches with
+	// $1, $2 etc
+	Pattern string `json:"pattern,omitempty"`
+	// Template renders the value as a Go template before it is applied.
+	// The template can refer to the object being transformed with .Object
+	// and to the metadata of the clusterpair used for the migration with
+	// .ClusterPair
+	Template bool `json:"template,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
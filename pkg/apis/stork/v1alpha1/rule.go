@@ -7,11 +7,34 @@ import (
 const (
 	// RuleActionCommand is a command action
 	RuleActionCommand RuleActionType = "command"
+	// RuleActionHTTP is an action that sends an HTTP request for each of the
+	// pods
+	RuleActionHTTP RuleActionType = "http"
+	// RuleActionScale is an action that scales the Deployments and
+	// StatefulSets that own the pods to 0. The replicas are restored by a
+	// scale action in the post exec rule, which is required
+	RuleActionScale RuleActionType = "scale"
+	// RuleActionFsfreeze is an action that freezes the filesystem mounted at
+	// the path in the value of the action in the pods. The filesystem is
+	// thawed by an fsfreeze action in the post exec rule, which is required
+	RuleActionFsfreeze RuleActionType = "fsfreeze"
 )
 
 // RuleActionType is a type for actions that are supported in a stork rule
 type RuleActionType string
 
+// RuleActionFailurePolicy is the policy for when an action in a rule fails
+type RuleActionFailurePolicy string
+
+const (
+	// RuleActionFailurePolicyFail fails the rule, and the operation that runs
+	// it, when the action fails
+	RuleActionFailurePolicyFail RuleActionFailurePolicy = "Fail"
+	// RuleActionFailurePolicyContinue continues with the next action when the
+	// action fails. The failure is recorded in the status of the operation
+	RuleActionFailurePolicyContinue RuleActionFailurePolicy = "Continue"
+)
+
 // +genclient
 // +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -30,6 +53,10 @@ type RuleItem struct {
 	// Container Name of the container in which to run the rule if there are
 	// multiple containers in the pod
 	Container string `json:"container"`
+	// Order in which the item is run. Items with a lower order are run first
+	// and items with the same order are run in the order they are specified
+	// +optional
+	Order int `json:"order,omitempty"`
 	// Actions are actions to be performed on the pods selected using the selector
 	Actions []RuleAction `json:"actions"`
 }
@@ -47,6 +74,98 @@ type RuleAction struct {
 	RunInSinglePod bool `json:"runInSinglePod,omitempty"`
 	// Value is the actual action value for e.g the command to run
 	Value string `json:"value"`
+	// HTTP is the request to send for http actions
+	// +optional
+	HTTP *RuleHTTPAction `json:"http,omitempty"`
+	// TimeoutSeconds is the time allowed for the action, including retries.
+	// The action fails if it doesn't complete in time
+	// +optional
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
+	// Retries is the number of times the action is retried on each pod if it
+	// fails. Applies to command, http and fsfreeze actions
+	// +optional
+	Retries *int `json:"retries,omitempty"`
+	// OnFailure is the policy for when the action fails, Fail by default
+	// +optional
+	OnFailure RuleActionFailurePolicy `json:"onFailure,omitempty"`
+}
+
+// RuleActionFailure is an action in a rule that failed
+type RuleActionFailure struct {
+	// Rule is the name of the rule
+	Rule string `json:"rule"`
+	// Namespace of the pods the rule was run for
+	Namespace string `json:"namespace"`
+	// Item is the index of the rule item with the action
+	Item int `json:"item"`
+	// Action is the index of the action in the rule item
+	Action    int                     `json:"action"`
+	Type      RuleActionType          `json:"type"`
+	OnFailure RuleActionFailurePolicy `json:"onFailure"`
+	Reason    string                  `json:"reason"`
+	Timestamp meta.Time               `json:"timestamp"`
+}
+
+// RuleRunStatusType is the status of an action run by a rule
+type RuleRunStatusType string
+
+const (
+	// RuleRunStatusSuccessful for when the action completed successfully
+	RuleRunStatusSuccessful RuleRunStatusType = "Successful"
+	// RuleRunStatusFailed for when the action failed
+	RuleRunStatusFailed RuleRunStatusType = "Failed"
+)
+
+// RuleRun is the record of an action in a rule being run on a pod
+type RuleRun struct {
+	// Rule is the name of the rule
+	Rule string `json:"rule"`
+	// RuleType is the type of the rule, preExecRule or postExecRule
+	RuleType string `json:"ruleType"`
+	// Namespace of the pod the action was run for
+	Namespace string `json:"namespace"`
+	// Pod the action was run for. Empty for scale actions, which are run for
+	// the workloads of the pods
+	Pod       string         `json:"pod,omitempty"`
+	Container string         `json:"container,omitempty"`
+	Action    RuleActionType `json:"action"`
+	// Command that was run, or a description of the action for actions that
+	// don't run a command
+	Command         string            `json:"command"`
+	Background      bool              `json:"background,omitempty"`
+	Status          RuleRunStatusType `json:"status"`
+	StartTimestamp  meta.Time         `json:"startTimestamp"`
+	FinishTimestamp meta.Time         `json:"finishTimestamp"`
+	// ExitCode of the command, if it was run in the pod
+	ExitCode *int `json:"exitCode,omitempty"`
+	// Stdout and Stderr are truncated to the last few hundred bytes
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// RuleHTTPAction is the request sent for an http rule action
+type RuleHTTPAction struct {
+	// URL to send the request to. It is a template that can refer to
+	// {{.PodName}}, {{.PodIP}} and {{.Namespace}} of the pod the request is
+	// sent for
+	URL string `json:"url"`
+	// Method of the request, GET by default
+	// +optional
+	Method string `json:"method,omitempty"`
+	// Headers to set in the request
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body of the request
+	// +optional
+	Body string `json:"body,omitempty"`
+	// ExpectedStatus is the status code expected in the response. Any 2xx
+	// status code is accepted by default
+	// +optional
+	ExpectedStatus int `json:"expectedStatus,omitempty"`
+	// TimeoutSeconds is the timeout for the request, 30 seconds by default
+	// +optional
+	TimeoutSeconds int64 `json:"timeoutSeconds,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
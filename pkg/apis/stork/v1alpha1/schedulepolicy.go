@@ -41,12 +41,15 @@ const (
 	SchedulePolicyTypeWeekly SchedulePolicyType = "Weekly"
 	// SchedulePolicyTypeMonthly is the type for a monthly schedule policy
 	SchedulePolicyTypeMonthly SchedulePolicyType = "Monthly"
+	// SchedulePolicyTypeCron is the type for a schedule policy using a cron
+	// expression
+	SchedulePolicyTypeCron SchedulePolicyType = "Cron"
 )
 
 // GetValidSchedulePolicyTypes returns the valid types of schedule policies that
 // can be configured
 func GetValidSchedulePolicyTypes() []SchedulePolicyType {
-	return []SchedulePolicyType{SchedulePolicyTypeInterval, SchedulePolicyTypeDaily, SchedulePolicyTypeWeekly, SchedulePolicyTypeMonthly}
+	return []SchedulePolicyType{SchedulePolicyTypeInterval, SchedulePolicyTypeDaily, SchedulePolicyTypeWeekly, SchedulePolicyTypeMonthly, SchedulePolicyTypeCron}
 }
 
 // Days is a map of valid Day strings
@@ -81,6 +84,32 @@ type SchedulePolicyItem struct {
 	// Monthly policy that will be triggered on the specified date of the month
 	// at the specified time
 	Monthly *MonthlyPolicy `json:"monthly"`
+	// Cron policy that will be triggered at the times matching a standard
+	// 5 field cron expression
+	Cron *CronPolicy `json:"cron,omitempty"`
+	// TimeZone is the IANA name of the time zone, eg America/New_York, in
+	// which the times of the policies are evaluated. Defaults to the local
+	// time zone of stork
+	TimeZone string `json:"timeZone,omitempty"`
+	// Retention is a grandfather-father-son retention for the objects
+	// triggered by all the policy types. If set, it is used instead of the
+	// Retain count of each policy type
+	Retention *GFSRetention `json:"retention,omitempty"`
+	// BlackoutWindows are periods during which no objects are triggered.
+	// Triggers that are due during a window are deferred until it ends
+	BlackoutWindows []BlackoutWindow `json:"blackoutWindows,omitempty"`
+}
+
+// GetLocation returns the location for the time zone of the policy
+func (s *SchedulePolicyItem) GetLocation() (*time.Location, error) {
+	if s.TimeZone == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid timeZone (%v): %v", s.TimeZone, err)
+	}
+	return location, nil
 }
 
 // Retain Type to specify how many objects should be retained for a policy
@@ -228,6 +257,117 @@ func (m *MonthlyPolicy) Validate() error {
 	return nil
 }
 
+// DefaultCronPolicyRetain Default for objects to be retained for the cron
+// policy
+const DefaultCronPolicyRetain = Retain(10)
+
+// CronPolicy contains the cron expression for when an action should be
+// executed
+type CronPolicy struct {
+	// Expression in the standard 5 field cron format, ie
+	// "minute hour day-of-month month day-of-week". The @hourly, @daily,
+	// @weekly, @monthly and @yearly shorthands are also accepted
+	Expression string `json:"expression"`
+	// Retain Number of objects to retain for cron policy. Defaults to
+	// @DefaultCronPolicyRetain
+	Retain Retain `json:"retain"`
+	// Options to be passed in to the driver. These will be passed in
+	// to the object being triggered
+	Options map[string]string `json:"options"`
+}
+
+// GFSRetention specifies how many of the most recent hourly, daily, weekly,
+// monthly and yearly objects should be retained. The latest successful
+// object in each period is retained for that period, and one object can be
+// retained for more than one period.
+type GFSRetention struct {
+	Hourly  int `json:"hourly,omitempty"`
+	Daily   int `json:"daily,omitempty"`
+	Weekly  int `json:"weekly,omitempty"`
+	Monthly int `json:"monthly,omitempty"`
+	Yearly  int `json:"yearly,omitempty"`
+}
+
+// Validate validates a GFSRetention
+func (g *GFSRetention) Validate() error {
+	if g.Hourly < 0 || g.Daily < 0 || g.Weekly < 0 || g.Monthly < 0 || g.Yearly < 0 {
+		return fmt.Errorf("Invalid retention, counts can't be negative")
+	}
+	if g.Hourly+g.Daily+g.Weekly+g.Monthly+g.Yearly == 0 {
+		return fmt.Errorf("Invalid retention, at least one count should be set")
+	}
+	return nil
+}
+
+// BlackoutWindow is a period of the day during which no objects should be
+// triggered
+type BlackoutWindow struct {
+	// Days of the week on which the window starts, as specified in `Days`
+	// above. Defaults to every day
+	Days []string `json:"days,omitempty"`
+	// StartTime of the window. Expected format is time.Kitchen eg 12:04PM or
+	// 12:04pm
+	StartTime string `json:"startTime"`
+	// EndTime of the window. Expected format is time.Kitchen eg 12:04PM or
+	// 12:04pm. If it is before StartTime the window ends on the next day
+	EndTime string `json:"endTime"`
+}
+
+// GetStartHourMinute parses and returns the hour and minute of the start of
+// the window
+func (b *BlackoutWindow) GetStartHourMinute() (int, int, error) {
+	return getHourMinute(b.StartTime)
+}
+
+// GetEndHourMinute parses and returns the hour and minute of the end of the
+// window
+func (b *BlackoutWindow) GetEndHourMinute() (int, int, error) {
+	return getHourMinute(b.EndTime)
+}
+
+// Validate validates a BlackoutWindow
+func (b *BlackoutWindow) Validate() error {
+	startHour, startMinute, err := b.GetStartHourMinute()
+	if err != nil {
+		return fmt.Errorf("Invalid startTime (%v) in blackout window: %v", b.StartTime, err)
+	}
+	endHour, endMinute, err := b.GetEndHourMinute()
+	if err != nil {
+		return fmt.Errorf("Invalid endTime (%v) in blackout window: %v", b.EndTime, err)
+	}
+	if startHour == endHour && startMinute == endMinute {
+		return fmt.Errorf("Invalid blackout window, startTime and endTime can't be the same")
+	}
+	for _, day := range b.Days {
+		if _, present := Days[day]; !present {
+			return fmt.Errorf("Invalid day of the week (%v) in blackout window", day)
+		}
+	}
+	return nil
+}
+
+// SchedulePendingReason is the reason a trigger for a schedule is pending
+type SchedulePendingReason string
+
+const (
+	// SchedulePendingReasonBlackoutWindow means the trigger is deferred until
+	// a blackout window of the schedule policy ends
+	SchedulePendingReasonBlackoutWindow SchedulePendingReason = "BlackoutWindow"
+	// SchedulePendingReasonQueued means the trigger is waiting in the queue
+	// for scheduled operations, either for a running operation to complete or
+	// for its jitter delay
+	SchedulePendingReasonQueued SchedulePendingReason = "Queued"
+)
+
+// SchedulePendingStatus is the status of a trigger that is due for a
+// schedule but hasn't been started yet
+type SchedulePendingStatus struct {
+	PolicyType   SchedulePolicyType    `json:"policyType"`
+	Reason       SchedulePendingReason `json:"reason"`
+	Message      string                `json:"message"`
+	PendingSince meta.Time             `json:"pendingSince"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // SchedulePolicyList is a list of schedule policies
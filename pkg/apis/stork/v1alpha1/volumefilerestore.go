@@ -0,0 +1,105 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// VolumeFileRestoreResourceName is name for "volumefilerestore" resource
+	VolumeFileRestoreResourceName = "volumefilerestore"
+	// VolumeFileRestoreResourcePlural is plural for "volumefilerestore" resource
+	VolumeFileRestoreResourcePlural = "volumefilerestores"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VolumeFileRestore restores individual files from the backup of a volume
+type VolumeFileRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              VolumeFileRestoreSpec   `json:"spec"`
+	Status            VolumeFileRestoreStatus `json:"status"`
+}
+
+// VolumeFileRestoreSpec is the spec used to restore files from the backup of
+// a volume
+type VolumeFileRestoreSpec struct {
+	// BackupName is the ApplicationBackup, in the namespace of the
+	// VolumeFileRestore, to restore the files from
+	BackupName string `json:"backupName"`
+	// SourceNamespace is the namespace of the backed up PVC
+	SourceNamespace string `json:"sourceNamespace"`
+	// SourcePVC is the name of the backed up PVC
+	SourcePVC string `json:"sourcePVC"`
+	// Paths are the files and directories to restore, relative to the root
+	// of the volume
+	Paths []string `json:"paths"`
+	// TargetPVC is the PVC, in the namespace of the VolumeFileRestore, to
+	// copy the files to
+	TargetPVC string `json:"targetPVC,omitempty"`
+	// TargetPath is the directory in TargetPVC to copy the files to,
+	// relative to the root of the volume. Defaults to the root
+	TargetPath string `json:"targetPath,omitempty"`
+	// Export uploads the files as a tarball to the backup location of the
+	// backup instead of copying them to TargetPVC
+	Export bool `json:"export,omitempty"`
+}
+
+// VolumeFileRestoreStageType is the stage of a file restore
+type VolumeFileRestoreStageType string
+
+const (
+	// VolumeFileRestoreStageInitial for when the file restore is created
+	VolumeFileRestoreStageInitial VolumeFileRestoreStageType = ""
+	// VolumeFileRestoreStageMount for when a PVC with the contents of the
+	// backup is being provisioned
+	VolumeFileRestoreStageMount VolumeFileRestoreStageType = "Mount"
+	// VolumeFileRestoreStageCopy for when the files are being copied or
+	// exported
+	VolumeFileRestoreStageCopy VolumeFileRestoreStageType = "Copy"
+	// VolumeFileRestoreStageFinal for when the file restore is done
+	VolumeFileRestoreStageFinal VolumeFileRestoreStageType = "Final"
+)
+
+// VolumeFileRestoreStatusType is the status of a file restore
+type VolumeFileRestoreStatusType string
+
+const (
+	// VolumeFileRestoreStatusInitial for when the file restore is created
+	VolumeFileRestoreStatusInitial VolumeFileRestoreStatusType = ""
+	// VolumeFileRestoreStatusInProgress for when the file restore is in
+	// progress
+	VolumeFileRestoreStatusInProgress VolumeFileRestoreStatusType = "InProgress"
+	// VolumeFileRestoreStatusSuccessful for when the files were restored
+	VolumeFileRestoreStatusSuccessful VolumeFileRestoreStatusType = "Successful"
+	// VolumeFileRestoreStatusFailed for when the file restore failed
+	VolumeFileRestoreStatusFailed VolumeFileRestoreStatusType = "Failed"
+)
+
+// VolumeFileRestoreStatus is the status of a file restore
+type VolumeFileRestoreStatus struct {
+	Stage  VolumeFileRestoreStageType  `json:"stage"`
+	Status VolumeFileRestoreStatusType `json:"status"`
+	Reason string                      `json:"reason"`
+	// DriverName is the driver that backed up the volume
+	DriverName string `json:"driverName,omitempty"`
+	// MountPVC is the temporary PVC, in the namespace of the
+	// VolumeFileRestore, provisioned with the contents of the backup
+	MountPVC string `json:"mountPVC,omitempty"`
+	// ExportPath is the path of the tarball in the backup location if
+	// the files were exported
+	ExportPath          string      `json:"exportPath,omitempty"`
+	FinishTimestamp     metav1.Time `json:"finishTimestamp"`
+	LastUpdateTimestamp metav1.Time `json:"lastUpdateTimestamp"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VolumeFileRestoreList is a list of VolumeFileRestores
+type VolumeFileRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []VolumeFileRestore `json:"items"`
+}
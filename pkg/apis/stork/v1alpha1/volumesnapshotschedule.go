@@ -43,6 +43,9 @@ const (
 // VolumeSnapshotScheduleStatus is the status of a volumesnapshot schedule
 type VolumeSnapshotScheduleStatus struct {
 	Items map[SchedulePolicyType][]*ScheduledVolumeSnapshotStatus `json:"items"`
+	// Pending is set if a snapshot is due but is deferred by a blackout
+	// window
+	Pending *SchedulePendingStatus `json:"pending,omitempty"`
 }
 
 // ScheduledVolumeSnapshotStatus keeps track of the volumesnapshot that was triggered by a
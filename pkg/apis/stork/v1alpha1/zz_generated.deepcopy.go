@@ -27,6 +27,133 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Action) DeepCopyInto(out *Action) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Action.
+func (in *Action) DeepCopy() *Action {
+	if in == nil {
+		return nil
+	}
+	out := new(Action)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Action) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionList) DeepCopyInto(out *ActionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Action, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionList.
+func (in *ActionList) DeepCopy() *ActionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ActionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionNamespaceStatus) DeepCopyInto(out *ActionNamespaceStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionNamespaceStatus.
+func (in *ActionNamespaceStatus) DeepCopy() *ActionNamespaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionNamespaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionSpec) DeepCopyInto(out *ActionSpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionSpec.
+func (in *ActionSpec) DeepCopy() *ActionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActionStatus) DeepCopyInto(out *ActionStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]*ActionNamespaceStatus, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ActionNamespaceStatus)
+				**out = **in
+			}
+		}
+	}
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	in.LastUpdateTimestamp.DeepCopyInto(&out.LastUpdateTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActionStatus.
+func (in *ActionStatus) DeepCopy() *ActionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ActionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationBackup) DeepCopyInto(out *ApplicationBackup) {
 	*out = *in
@@ -212,6 +339,11 @@ func (in *ApplicationBackupScheduleStatus) DeepCopyInto(out *ApplicationBackupSc
 			(*out)[key] = outVal
 		}
 	}
+	if in.Pending != nil {
+		in, out := &in.Pending, &out.Pending
+		*out = new(SchedulePendingStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -298,6 +430,33 @@ func (in *ApplicationBackupStatus) DeepCopyInto(out *ApplicationBackupStatus) {
 	in.TriggerTimestamp.DeepCopyInto(&out.TriggerTimestamp)
 	in.LastUpdateTimestamp.DeepCopyInto(&out.LastUpdateTimestamp)
 	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	if in.WrappedDataKey != nil {
+		in, out := &in.WrappedDataKey, &out.WrappedDataKey
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.RuleActionFailures != nil {
+		in, out := &in.RuleActionFailures, &out.RuleActionFailures
+		*out = make([]*RuleActionFailure, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RuleActionFailure)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.RuleRuns != nil {
+		in, out := &in.RuleRuns, &out.RuleRuns
+		*out = make([]*RuleRun, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RuleRun)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 	return
 }
 
@@ -449,6 +608,11 @@ func (in *ApplicationCloneSpec) DeepCopyInto(out *ApplicationCloneSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.TransformSpecs != nil {
+		in, out := &in.TransformSpecs, &out.TransformSpecs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -488,6 +652,28 @@ func (in *ApplicationCloneStatus) DeepCopyInto(out *ApplicationCloneStatus) {
 		}
 	}
 	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	if in.RuleActionFailures != nil {
+		in, out := &in.RuleActionFailures, &out.RuleActionFailures
+		*out = make([]*RuleActionFailure, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RuleActionFailure)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.RuleRuns != nil {
+		in, out := &in.RuleRuns, &out.RuleRuns
+		*out = make([]*RuleRun, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RuleRun)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 	return
 }
 
@@ -634,6 +820,39 @@ func (in *ApplicationRestore) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRestoreDryRunResourceInfo) DeepCopyInto(out *ApplicationRestoreDryRunResourceInfo) {
+	*out = *in
+	out.ObjectInfo = in.ObjectInfo
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRestoreDryRunResourceInfo.
+func (in *ApplicationRestoreDryRunResourceInfo) DeepCopy() *ApplicationRestoreDryRunResourceInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRestoreDryRunResourceInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplicationRestoreDryRunVolumeInfo) DeepCopyInto(out *ApplicationRestoreDryRunVolumeInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplicationRestoreDryRunVolumeInfo.
+func (in *ApplicationRestoreDryRunVolumeInfo) DeepCopy() *ApplicationRestoreDryRunVolumeInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplicationRestoreDryRunVolumeInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApplicationRestoreList) DeepCopyInto(out *ApplicationRestoreList) {
 	*out = *in
@@ -711,6 +930,15 @@ func (in *ApplicationRestoreSpec) DeepCopyInto(out *ApplicationRestoreSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.TransformSpecs != nil {
+		in, out := &in.TransformSpecs, &out.TransformSpecs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PointInTime != nil {
+		in, out := &in.PointInTime, &out.PointInTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -751,6 +979,33 @@ func (in *ApplicationRestoreStatus) DeepCopyInto(out *ApplicationRestoreStatus)
 	}
 	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
 	in.LastUpdateTimestamp.DeepCopyInto(&out.LastUpdateTimestamp)
+	if in.DryRunResources != nil {
+		in, out := &in.DryRunResources, &out.DryRunResources
+		*out = make([]*ApplicationRestoreDryRunResourceInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ApplicationRestoreDryRunResourceInfo)
+				**out = **in
+			}
+		}
+	}
+	if in.DryRunVolumes != nil {
+		in, out := &in.DryRunVolumes, &out.DryRunVolumes
+		*out = make([]*ApplicationRestoreDryRunVolumeInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ApplicationRestoreDryRunVolumeInfo)
+				**out = **in
+			}
+		}
+	}
+	if in.BackupParents != nil {
+		in, out := &in.BackupParents, &out.BackupParents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -854,6 +1109,16 @@ func (in *BackupLocationItem) DeepCopyInto(out *BackupLocationItem) {
 		*out = new(GoogleConfig)
 		**out = **in
 	}
+	if in.NFSConfig != nil {
+		in, out := &in.NFSConfig, &out.NFSConfig
+		*out = new(NFSConfig)
+		**out = **in
+	}
+	if in.EncryptionKeys != nil {
+		in, out := &in.EncryptionKeys, &out.EncryptionKeys
+		*out = new(EncryptionKeyConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -900,6 +1165,27 @@ func (in *BackupLocationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlackoutWindow) DeepCopyInto(out *BlackoutWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlackoutWindow.
+func (in *BlackoutWindow) DeepCopy() *BlackoutWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(BlackoutWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterDomainInfo) DeepCopyInto(out *ClusterDomainInfo) {
 	*out = *in
@@ -1127,7 +1413,7 @@ func (in *ClusterPair) DeepCopyInto(out *ClusterPair) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -1149,6 +1435,39 @@ func (in *ClusterPair) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPairCondition) DeepCopyInto(out *ClusterPairCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPairCondition.
+func (in *ClusterPairCondition) DeepCopy() *ClusterPairCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPairCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterPairCredentialsSecret) DeepCopyInto(out *ClusterPairCredentialsSecret) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPairCredentialsSecret.
+func (in *ClusterPairCredentialsSecret) DeepCopy() *ClusterPairCredentialsSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterPairCredentialsSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterPairList) DeepCopyInto(out *ClusterPairList) {
 	*out = *in
@@ -1194,6 +1513,11 @@ func (in *ClusterPairSpec) DeepCopyInto(out *ClusterPairSpec) {
 		}
 	}
 	in.PlatformOptions.DeepCopyInto(&out.PlatformOptions)
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(ClusterPairCredentialsSecret)
+		**out = **in
+	}
 	return
 }
 
@@ -1210,6 +1534,14 @@ func (in *ClusterPairSpec) DeepCopy() *ClusterPairSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterPairStatus) DeepCopyInto(out *ClusterPairStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ClusterPairCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastProbeTimestamp.DeepCopyInto(&out.LastProbeTimestamp)
 	return
 }
 
@@ -1223,6 +1555,29 @@ func (in *ClusterPairStatus) DeepCopy() *ClusterPairStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronPolicy) DeepCopyInto(out *CronPolicy) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronPolicy.
+func (in *CronPolicy) DeepCopy() *CronPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CronPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DailyPolicy) DeepCopyInto(out *DailyPolicy) {
 	*out = *in
@@ -1368,7 +1723,35 @@ func (in *DataExportSpec) DeepCopy() *DataExportSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ExportStatus) DeepCopyInto(out *ExportStatus) {
+func (in *EncryptionKeyConfig) DeepCopyInto(out *EncryptionKeyConfig) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PreviousKeyIDs != nil {
+		in, out := &in.PreviousKeyIDs, &out.PreviousKeyIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionKeyConfig.
+func (in *EncryptionKeyConfig) DeepCopy() *EncryptionKeyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionKeyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportStatus) DeepCopyInto(out *ExportStatus) {
 	*out = *in
 	return
 }
@@ -1383,6 +1766,22 @@ func (in *ExportStatus) DeepCopy() *ExportStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GFSRetention) DeepCopyInto(out *GFSRetention) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GFSRetention.
+func (in *GFSRetention) DeepCopy() *GFSRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(GFSRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GoogleConfig) DeepCopyInto(out *GoogleConfig) {
 	*out = *in
@@ -1464,6 +1863,18 @@ func (in *GroupVolumeSnapshotList) DeepCopyObject() runtime.Object {
 func (in *GroupVolumeSnapshotSpec) DeepCopyInto(out *GroupVolumeSnapshotSpec) {
 	*out = *in
 	in.PVCSelector.DeepCopyInto(&out.PVCSelector)
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.RestoreNamespaces != nil {
 		in, out := &in.RestoreNamespaces, &out.RestoreNamespaces
 		*out = make([]string, len(*in))
@@ -1503,6 +1914,33 @@ func (in *GroupVolumeSnapshotStatus) DeepCopyInto(out *GroupVolumeSnapshotStatus
 			}
 		}
 	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RuleActionFailures != nil {
+		in, out := &in.RuleActionFailures, &out.RuleActionFailures
+		*out = make([]*RuleActionFailure, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RuleActionFailure)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.RuleRuns != nil {
+		in, out := &in.RuleRuns, &out.RuleRuns
+		*out = make([]*RuleRun, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RuleRun)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 	return
 }
 
@@ -1539,6 +1977,38 @@ func (in *IntervalPolicy) DeepCopy() *IntervalPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in KindResourceTransform) DeepCopyInto(out *KindResourceTransform) {
+	{
+		in := &in
+		*out = make(KindResourceTransform, len(*in))
+		for key, val := range *in {
+			var outVal []TransformResourceInfo
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]TransformResourceInfo, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KindResourceTransform.
+func (in KindResourceTransform) DeepCopy() KindResourceTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(KindResourceTransform)
+	in.DeepCopyInto(out)
+	return *out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Migration) DeepCopyInto(out *Migration) {
 	*out = *in
@@ -1724,6 +2194,11 @@ func (in *MigrationScheduleStatus) DeepCopyInto(out *MigrationScheduleStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.Pending != nil {
+		in, out := &in.Pending, &out.Pending
+		*out = new(SchedulePendingStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1792,6 +2267,11 @@ func (in *MigrationSpec) DeepCopyInto(out *MigrationSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.TransformSpecs != nil {
+		in, out := &in.TransformSpecs, &out.TransformSpecs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1838,6 +2318,28 @@ func (in *MigrationStatus) DeepCopyInto(out *MigrationStatus) {
 		*out = new(MigrationSummary)
 		**out = **in
 	}
+	if in.RuleActionFailures != nil {
+		in, out := &in.RuleActionFailures, &out.RuleActionFailures
+		*out = make([]*RuleActionFailure, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RuleActionFailure)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.RuleRuns != nil {
+		in, out := &in.RuleRuns, &out.RuleRuns
+		*out = make([]*RuleRun, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RuleRun)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 	return
 }
 
@@ -1923,6 +2425,22 @@ func (in *MonthlyPolicy) DeepCopy() *MonthlyPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NFSConfig) DeepCopyInto(out *NFSConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NFSConfig.
+func (in *NFSConfig) DeepCopy() *NFSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespacedSchedulePolicy) DeepCopyInto(out *NamespacedSchedulePolicy) {
 	*out = *in
@@ -2019,6 +2537,29 @@ func (in *PVCSelectorSpec) DeepCopy() *PVCSelectorSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatchStruct) DeepCopyInto(out *PatchStruct) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(map[string]TransformResourceInfo, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatchStruct.
+func (in *PatchStruct) DeepCopy() *PatchStruct {
+	if in == nil {
+		return nil
+	}
+	out := new(PatchStruct)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PlatformSpec) DeepCopyInto(out *PlatformSpec) {
 	*out = *in
@@ -2101,6 +2642,7 @@ func (in *ResourceTransformation) DeepCopyInto(out *ResourceTransformation) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -2178,6 +2720,33 @@ func (in *ResourceTransformationSpec) DeepCopy() *ResourceTransformationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceTransformationStatus) DeepCopyInto(out *ResourceTransformationStatus) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]*TransformResourceInfo, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(TransformResourceInfo)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceTransformationStatus.
+func (in *ResourceTransformationStatus) DeepCopy() *ResourceTransformationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceTransformationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreVolumeInfo) DeepCopyInto(out *RestoreVolumeInfo) {
 	*out = *in
@@ -2230,6 +2799,16 @@ func (in *Rule) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RuleAction) DeepCopyInto(out *RuleAction) {
 	*out = *in
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(RuleHTTPAction)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int)
+		**out = **in
+	}
 	return
 }
 
@@ -2243,6 +2822,46 @@ func (in *RuleAction) DeepCopy() *RuleAction {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleActionFailure) DeepCopyInto(out *RuleActionFailure) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleActionFailure.
+func (in *RuleActionFailure) DeepCopy() *RuleActionFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleActionFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleHTTPAction) DeepCopyInto(out *RuleHTTPAction) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleHTTPAction.
+func (in *RuleHTTPAction) DeepCopy() *RuleHTTPAction {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleHTTPAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RuleItem) DeepCopyInto(out *RuleItem) {
 	*out = *in
@@ -2256,7 +2875,9 @@ func (in *RuleItem) DeepCopyInto(out *RuleItem) {
 	if in.Actions != nil {
 		in, out := &in.Actions, &out.Actions
 		*out = make([]RuleAction, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -2304,6 +2925,29 @@ func (in *RuleList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuleRun) DeepCopyInto(out *RuleRun) {
+	*out = *in
+	in.StartTimestamp.DeepCopyInto(&out.StartTimestamp)
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuleRun.
+func (in *RuleRun) DeepCopy() *RuleRun {
+	if in == nil {
+		return nil
+	}
+	out := new(RuleRun)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3Config) DeepCopyInto(out *S3Config) {
 	*out = *in
@@ -2320,6 +2964,23 @@ func (in *S3Config) DeepCopy() *S3Config {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulePendingStatus) DeepCopyInto(out *SchedulePendingStatus) {
+	*out = *in
+	in.PendingSince.DeepCopyInto(&out.PendingSince)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulePendingStatus.
+func (in *SchedulePendingStatus) DeepCopy() *SchedulePendingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulePendingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SchedulePolicy) DeepCopyInto(out *SchedulePolicy) {
 	*out = *in
@@ -2370,6 +3031,23 @@ func (in *SchedulePolicyItem) DeepCopyInto(out *SchedulePolicyItem) {
 		*out = new(MonthlyPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Cron != nil {
+		in, out := &in.Cron, &out.Cron
+		*out = new(CronPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(GFSRetention)
+		**out = **in
+	}
+	if in.BlackoutWindows != nil {
+		in, out := &in.BlackoutWindows, &out.BlackoutWindows
+		*out = make([]BlackoutWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -2486,6 +3164,47 @@ func (in *SuspendOptions) DeepCopy() *SuspendOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformResourceInfo) DeepCopyInto(out *TransformResourceInfo) {
+	*out = *in
+	out.GroupVersionKind = in.GroupVersionKind
+	in.Specs.DeepCopyInto(&out.Specs)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformResourceInfo.
+func (in *TransformResourceInfo) DeepCopy() *TransformResourceInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformResourceInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformSpecPatch) DeepCopyInto(out *TransformSpecPatch) {
+	*out = *in
+	if in.GVK != nil {
+		in, out := &in.GVK, &out.GVK
+		*out = make(map[string]PatchStruct, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformSpecPatch.
+func (in *TransformSpecPatch) DeepCopy() *TransformSpecPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformSpecPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TransformSpecs) DeepCopyInto(out *TransformSpecs) {
 	*out = *in
@@ -2514,6 +3233,106 @@ func (in *TransformSpecs) DeepCopy() *TransformSpecs {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeFileRestore) DeepCopyInto(out *VolumeFileRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeFileRestore.
+func (in *VolumeFileRestore) DeepCopy() *VolumeFileRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeFileRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeFileRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeFileRestoreList) DeepCopyInto(out *VolumeFileRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VolumeFileRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeFileRestoreList.
+func (in *VolumeFileRestoreList) DeepCopy() *VolumeFileRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeFileRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VolumeFileRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeFileRestoreSpec) DeepCopyInto(out *VolumeFileRestoreSpec) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeFileRestoreSpec.
+func (in *VolumeFileRestoreSpec) DeepCopy() *VolumeFileRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeFileRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeFileRestoreStatus) DeepCopyInto(out *VolumeFileRestoreStatus) {
+	*out = *in
+	in.FinishTimestamp.DeepCopyInto(&out.FinishTimestamp)
+	in.LastUpdateTimestamp.DeepCopyInto(&out.LastUpdateTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeFileRestoreStatus.
+func (in *VolumeFileRestoreStatus) DeepCopy() *VolumeFileRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeFileRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeSnapshotRestore) DeepCopyInto(out *VolumeSnapshotRestore) {
 	*out = *in
@@ -2732,6 +3551,11 @@ func (in *VolumeSnapshotScheduleStatus) DeepCopyInto(out *VolumeSnapshotSchedule
 			(*out)[key] = outVal
 		}
 	}
+	if in.Pending != nil {
+		in, out := &in.Pending, &out.Pending
+		*out = new(SchedulePendingStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -52,6 +52,11 @@ func (a *ApplicationManager) Init(mgr manager.Manager, adminNamespace string, st
 		return err
 	}
 
+	fileRestoreController := controllers.NewVolumeFileRestore(mgr, a.Recorder)
+	if err := fileRestoreController.Init(mgr); err != nil {
+		return err
+	}
+
 	scheduleController := controllers.NewApplicationBackupSchedule(mgr, a.Recorder)
 	if err := scheduleController.Init(mgr); err != nil {
 		return err
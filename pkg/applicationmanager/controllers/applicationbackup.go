@@ -18,7 +18,6 @@ import (
 	"github.com/libopenstorage/stork/pkg/apis/stork"
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/controllers"
-	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/errors"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
 	"github.com/libopenstorage/stork/pkg/log"
@@ -177,6 +176,10 @@ func (a *ApplicationBackupController) setDefaults(backup *stork_api.ApplicationB
 		backup.Spec.ReclaimPolicy = stork_api.ApplicationBackupReclaimPolicyDelete
 		updated = true
 	}
+	if backup.Spec.ResourceCompression == "" {
+		backup.Spec.ResourceCompression = stork_api.ApplicationBackupCompressionGzip
+		updated = true
+	}
 	if backup.Status.TriggerTimestamp.IsZero() {
 		backup.Status.TriggerTimestamp = backup.CreationTimestamp
 		updated = true
@@ -311,9 +314,30 @@ func (a *ApplicationBackupController) handle(ctx context.Context, backup *stork_
 				err.Error())
 		}
 
+		// Generate the data key if the backup location uses envelope
+		// encryption. It is recorded in the status before any objects are
+		// uploaded so that all of them are encrypted with the same key
+		if backup.Status.EncryptionKeyID == "" {
+			if err := a.setDataKey(backup); err != nil {
+				message := fmt.Sprintf("Error generating data key for backup: %v", err)
+				log.ApplicationBackupLog(backup).Errorf(message)
+				a.recorder.Event(backup,
+					v1.EventTypeWarning,
+					string(stork_api.ApplicationBackupStatusFailed),
+					message)
+				backup.Status.Status = stork_api.ApplicationBackupStatusFailed
+				backup.Status.Reason = message
+				backup.Status.Stage = stork_api.ApplicationBackupStageFinal
+				backup.Status.FinishTimestamp = metav1.Now()
+				backup.Status.LastUpdateTimestamp = metav1.Now()
+				return a.client.Update(context.TODO(), backup)
+			}
+		}
+
 		// Make sure the rules exist if configured
+		var preExecRule, postExecRule *stork_api.Rule
 		if backup.Spec.PreExecRule != "" {
-			_, err := storkops.Instance().GetRule(backup.Spec.PreExecRule, backup.Namespace)
+			preExecRule, err = storkops.Instance().GetRule(backup.Spec.PreExecRule, backup.Namespace)
 			if err != nil {
 				message := fmt.Sprintf("Error getting PreExecRule %v: %v", backup.Spec.PreExecRule, err)
 				log.ApplicationBackupLog(backup).Errorf(message)
@@ -325,7 +349,7 @@ func (a *ApplicationBackupController) handle(ctx context.Context, backup *stork_
 			}
 		}
 		if backup.Spec.PostExecRule != "" {
-			_, err := storkops.Instance().GetRule(backup.Spec.PostExecRule, backup.Namespace)
+			postExecRule, err = storkops.Instance().GetRule(backup.Spec.PostExecRule, backup.Namespace)
 			if err != nil {
 				message := fmt.Sprintf("Error getting PostExecRule %v: %v", backup.Spec.PreExecRule, err)
 				log.ApplicationBackupLog(backup).Errorf(message)
@@ -336,6 +360,15 @@ func (a *ApplicationBackupController) handle(ctx context.Context, backup *stork_
 				return nil
 			}
 		}
+		if err := rule.ValidateRules(preExecRule, postExecRule); err != nil {
+			message := fmt.Sprintf("Error validating rules: %v", err)
+			log.ApplicationBackupLog(backup).Errorf(message)
+			a.recorder.Event(backup,
+				v1.EventTypeWarning,
+				string(stork_api.ApplicationBackupStatusFailed),
+				message)
+			return nil
+		}
 		fallthrough
 	case stork_api.ApplicationBackupStagePreExecRule:
 		var inProgress bool
@@ -347,11 +380,15 @@ func (a *ApplicationBackupController) handle(ctx context.Context, backup *stork_
 				v1.EventTypeWarning,
 				string(stork_api.ApplicationBackupStatusFailed),
 				message)
+			// Keep the records of the rule that failed when refreshing the
+			// backup
+			ruleRuns := backup.Status.RuleRuns
 			key := runtimeclient.ObjectKeyFromObject(backup)
 			err = a.client.Get(context.TODO(), key, backup)
 			if err != nil {
 				return err
 			}
+			backup.Status.RuleRuns = ruleRuns
 			backup.Status.Stage = stork_api.ApplicationBackupStageFinal
 			backup.Status.Status = stork_api.ApplicationBackupStatusFailed
 			backup.Status.Reason = message
@@ -587,6 +624,11 @@ func (a *ApplicationBackupController) backupVolumes(backup *stork_api.Applicatio
 		namespacedName.Namespace = backup.Namespace
 		namespacedName.Name = backup.Name
 		if len(backup.Status.Volumes) != pvcCount {
+			var volumeParents map[string]*volumeParent
+			volumeParents, err = getVolumeParents(backup)
+			if err != nil {
+				return fmt.Errorf("error getting parent backups for volumes: %v", err)
+			}
 
 			for driverName, pvcs := range pvcMappings {
 				var driver volume.Driver
@@ -603,7 +645,8 @@ func (a *ApplicationBackupController) backupVolumes(backup *stork_api.Applicatio
 				}
 				for i := 0; i < len(pvcs); i += batchCount {
 					batch := pvcs[i:min(i+batchCount, len(pvcs))]
-					volumeInfos, err := driver.StartBackup(backup, batch)
+					volumeInfos, err := driver.StartBackup(backup, batch, getDriverVolumeParents(volumeParents, driverName))
+					setVolumeParents(volumeInfos, volumeParents)
 					if err != nil {
 						// TODO: If starting backup for a drive fails mark the entire backup
 						// as Cancelling, cancel any other started backups and then mark
@@ -866,12 +909,17 @@ func (a *ApplicationBackupController) runPreExecRule(backup *stork_api.Applicati
 		}
 		return nil, false, err
 	}
+	results := make([]*rule.Result, 0)
 	for _, ns := range backup.Spec.Namespaces {
-		ch, err := rule.ExecuteRule(r, rule.PreExecRule, backup, ns)
+		ch, result, err := rule.ExecuteRule(r, rule.PreExecRule, backup, ns)
+		results = append(results, result)
 		if err != nil {
 			for _, channel := range terminationChannels {
 				channel <- true
 			}
+			for _, result := range results {
+				backup.Status.RuleRuns = rule.AppendRuleRuns(backup.Status.RuleRuns, result)
+			}
 			return nil, false, fmt.Errorf("error executing PreExecRule for namespace %v: %v", ns, err)
 		}
 		if ch != nil {
@@ -889,6 +937,10 @@ func (a *ApplicationBackupController) runPreExecRule(backup *stork_api.Applicati
 		}
 		return nil, false, err
 	}
+	for _, result := range results {
+		backup.Status.RuleActionFailures = append(backup.Status.RuleActionFailures, result.Failures...)
+		backup.Status.RuleRuns = rule.AppendRuleRuns(backup.Status.RuleRuns, result)
+	}
 	return terminationChannels, false, nil
 }
 
@@ -898,7 +950,9 @@ func (a *ApplicationBackupController) runPostExecRule(backup *stork_api.Applicat
 		return err
 	}
 	for _, ns := range backup.Spec.Namespaces {
-		_, err = rule.ExecuteRule(r, rule.PostExecRule, backup, ns)
+		_, result, err := rule.ExecuteRule(r, rule.PostExecRule, backup, ns)
+		backup.Status.RuleActionFailures = append(backup.Status.RuleActionFailures, result.Failures...)
+		backup.Status.RuleRuns = rule.AppendRuleRuns(backup.Status.RuleRuns, result)
 		if err != nil {
 			return fmt.Errorf("error executing PreExecRule for namespace %v: %v", ns, err)
 		}
@@ -921,6 +975,22 @@ func GetObjectPath(
 	return filepath.Join(backup.Namespace, backup.Name, string(backup.UID))
 }
 
+// setDataKey generates the data key for the backup and updates the backup
+// with it, if the backup location uses envelope encryption
+func (a *ApplicationBackupController) setDataKey(backup *stork_api.ApplicationBackup) error {
+	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+	if err != nil {
+		return err
+	}
+	if _, err := objectstore.GetDataKey(backupLocation, backup); err != nil {
+		return err
+	}
+	if backup.Status.EncryptionKeyID == "" {
+		return nil
+	}
+	return a.client.Update(context.TODO(), backup)
+}
+
 // Uploads the given data to the backup location specified in the backup object
 func (a *ApplicationBackupController) uploadObject(
 	backup *stork_api.ApplicationBackup,
@@ -935,32 +1005,16 @@ func (a *ApplicationBackupController) uploadObject(
 	if err != nil {
 		return err
 	}
-	if backupLocation.Location.EncryptionKey != "" {
-		return fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
-	}
-	if backupLocation.Location.EncryptionV2Key != "" {
-		if data, err = crypto.Encrypt(data, backupLocation.Location.EncryptionV2Key); err != nil {
-			return err
-		}
-	}
-
-	objectPath := GetObjectPath(backup)
-	writer, err := bucket.NewWriter(context.TODO(), filepath.Join(objectPath, objectName), nil)
+	dataKey, err := objectstore.GetDataKey(backupLocation, backup)
 	if err != nil {
 		return err
 	}
-
-	_, err = writer.Write(data)
-	if err != nil {
-		closeErr := writer.Close()
-		if closeErr != nil {
-			log.ApplicationBackupLog(backup).Errorf("Error closing writer for objectstore: %v", closeErr)
-		}
+	if data, err = objectstore.Encrypt(backupLocation, dataKey, data); err != nil {
 		return err
 	}
-	err = writer.Close()
-	if err != nil {
-		log.ApplicationBackupLog(backup).Errorf("Error closing writer for objectstore: %v", err)
+
+	if err := objectstore.UploadObject(bucket, backupLocation, GetObjectPath(backup), objectName, data); err != nil {
+		log.ApplicationBackupLog(backup).Errorf("Error uploading %v to objectstore: %v", objectName, err)
 		return err
 	}
 	return nil
@@ -983,12 +1037,66 @@ func (a *ApplicationBackupController) uploadResources(
 	if err := a.uploadCRDResources(backup, resKinds); err != nil {
 		return err
 	}
-	jsonBytes, err := json.MarshalIndent(objects, "", " ")
+	if backup.Spec.Incremental {
+		if err := a.uploadIncrementalResources(backup, objects); err != nil {
+			return err
+		}
+		backup.Status.ResourcesFormat = stork_api.ApplicationBackupResourcesFormatIncremental
+		return nil
+	}
+	if err := a.uploadResourceStream(backup, objects); err != nil {
+		return err
+	}
+	backup.Status.ResourcesFormat = stork_api.ApplicationBackupResourcesFormatStream
+	return nil
+}
+
+// Streams the objects to the backup location, encoding, compressing and
+// encrypting them on the fly so that the serialized resources don't have to
+// be held in memory
+func (a *ApplicationBackupController) uploadResourceStream(
+	backup *stork_api.ApplicationBackup,
+	objects []runtime.Unstructured,
+) error {
+	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+	if err != nil {
+		return err
+	}
+	if backupLocation.Location.EncryptionKey != "" {
+		return fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return err
+	}
+	dataKey, err := objectstore.GetDataKey(backupLocation, backup)
+	if err != nil {
+		return err
+	}
+
+	writer, err := newResourceStreamWriter(
+		context.TODO(),
+		bucket,
+		filepath.Join(GetObjectPath(backup), resourceObjectName),
+		backup.Spec.ResourceCompression,
+		dataKey,
+		backupLocation.Location.EncryptionV2Key,
+	)
 	if err != nil {
 		return err
 	}
-	// TODO: Encrypt if requested
-	return a.uploadObject(backup, resourceObjectName, jsonBytes)
+	for _, obj := range objects {
+		if err := writer.Write(obj); err != nil {
+			writer.abort()
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		log.ApplicationBackupLog(backup).Errorf("Error closing writer for objectstore: %v", err)
+		return err
+	}
+	_, err = objectstore.UpdateManifest(bucket, backupLocation, GetObjectPath(backup), writer.manifestObject(GetObjectPath(backup)))
+	return err
 }
 func (a *ApplicationBackupController) uploadNamespaces(backup *stork_api.ApplicationBackup) error {
 	var namespaces []*v1.Namespace
@@ -1304,6 +1412,18 @@ func (a *ApplicationBackupController) deleteBackup(backup *stork_api.Application
 		return true, nil
 	}
 
+	// Incremental backups that depend on the backup are rebased onto its
+	// parents before it is deleted
+	if backup.Spec.Incremental {
+		dependents, err := getIncrementalDependents(backup)
+		if err != nil {
+			return false, fmt.Errorf("backup %v/%v can't be deleted yet: %v", backup.Namespace, backup.Name, err)
+		}
+		if err := a.rebaseIncrementalDependents(backup, dependents); err != nil {
+			return false, fmt.Errorf("error rebasing backups depending on backup %v/%v: %v", backup.Namespace, backup.Name, err)
+		}
+	}
+
 	drivers := a.getDriversForBackup(backup)
 	for driverName := range drivers {
 
@@ -1346,6 +1466,14 @@ func (a *ApplicationBackupController) deleteBackup(backup *stork_api.Application
 
 	objectPath := backup.Status.BackupPath
 	if objectPath != "" {
+		if backup.Status.ResourcesFormat == stork_api.ApplicationBackupResourcesFormatIncremental {
+			if err = bucket.Delete(context.TODO(), filepath.Join(objectPath, resourceIndexObjectName)); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+				return true, fmt.Errorf("error deleting resource index for backup %v/%v: %v", backup.Namespace, backup.Name, err)
+			}
+			if err = deleteRebasedResources(bucket, objectPath); err != nil {
+				return true, fmt.Errorf("error deleting resources copied to backup %v/%v: %v", backup.Namespace, backup.Name, err)
+			}
+		}
 		if err = bucket.Delete(context.TODO(), filepath.Join(objectPath, resourceObjectName)); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
 			return true, fmt.Errorf("error deleting resources for backup %v/%v: %v", backup.Namespace, backup.Name, err)
 		}
@@ -1361,6 +1489,10 @@ func (a *ApplicationBackupController) deleteBackup(backup *stork_api.Application
 		if err = bucket.Delete(context.TODO(), filepath.Join(objectPath, nsObjectName)); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
 			return true, fmt.Errorf("error deleting namespaces for backup %v/%v: %v", backup.Namespace, backup.Name, err)
 		}
+
+		if err = bucket.Delete(context.TODO(), filepath.Join(objectPath, objectstore.ManifestObjectName)); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+			return true, fmt.Errorf("error deleting manifest for backup %v/%v: %v", backup.Namespace, backup.Name, err)
+		}
 	}
 
 	return true, nil
@@ -86,6 +86,7 @@ func (s *ApplicationBackupScheduleController) Reconcile(ctx context.Context, req
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			schedule.DeleteFromQueue(schedule.GetQueueKey(stork_api.ApplicationBackupScheduleResourceName, request.Namespace, request.Name))
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -119,9 +120,14 @@ func (s *ApplicationBackupScheduleController) handle(ctx context.Context, backup
 		return err
 	}
 
+	queueKey := schedule.GetQueueKey(stork_api.ApplicationBackupScheduleResourceName, backupSchedule.Namespace, backupSchedule.Name)
+	schedule.UpdateRunning(queueKey, s.getRunningApplicationBackups(backupSchedule))
+
+	start := false
+	policyType := stork_api.SchedulePolicyTypeInvalid
 	if backupSchedule.Spec.Suspend == nil || !*backupSchedule.Spec.Suspend {
 		// Then check if any of the policies require a trigger
-		policyType, start, err := s.shouldStartApplicationBackup(backupSchedule)
+		policyType, start, err = s.shouldStartApplicationBackup(backupSchedule)
 		if err != nil {
 			msg := fmt.Sprintf("Error checking if backup should be triggered: %v", err)
 			s.recorder.Event(backupSchedule,
@@ -131,8 +137,41 @@ func (s *ApplicationBackupScheduleController) handle(ctx context.Context, backup
 			log.ApplicationBackupScheduleLog(backupSchedule).Error(msg)
 			return nil
 		}
-		// Start a backup for a policy if required
-		if start {
+	}
+	if !start {
+		schedule.RemoveFromQueue(queueKey)
+		if backupSchedule.Status.Pending != nil {
+			backupSchedule.Status.Pending = nil
+			if err := s.client.Update(context.TODO(), backupSchedule); err != nil {
+				return err
+			}
+		}
+	} else {
+		// Blackout windows and the limit on concurrent scheduled operations
+		// can defer the trigger
+		pending, err := schedule.AdmitTrigger(
+			backupSchedule.Spec.SchedulePolicyName,
+			backupSchedule.Namespace,
+			policyType,
+			queueKey,
+			backupSchedule.Status.Pending,
+		)
+		if err != nil {
+			msg := fmt.Sprintf("Error checking if backup can be triggered: %v", err)
+			s.recorder.Event(backupSchedule,
+				v1.EventTypeWarning,
+				string(stork_api.ApplicationBackupStatusFailed),
+				msg)
+			log.ApplicationBackupScheduleLog(backupSchedule).Error(msg)
+			return nil
+		}
+		if pending != nil {
+			if err := s.updatePendingStatus(backupSchedule, pending); err != nil {
+				return err
+			}
+		} else {
+			// Start a backup for the policy
+			backupSchedule.Status.Pending = nil
 			err := s.startApplicationBackup(backupSchedule, policyType)
 			if err != nil {
 				msg := fmt.Sprintf("Error triggering backup for schedule(%v): %v", policyType, err)
@@ -233,6 +272,40 @@ func (s *ApplicationBackupScheduleController) isApplicationBackupComplete(status
 		status == stork_api.ApplicationBackupStatusSuccessful
 }
 
+func (s *ApplicationBackupScheduleController) getRunningApplicationBackups(backupSchedule *stork_api.ApplicationBackupSchedule) int {
+	running := 0
+	for _, policyApplicationBackup := range backupSchedule.Status.Items {
+		for _, backup := range policyApplicationBackup {
+			if !s.isApplicationBackupComplete(backup.Status) {
+				running++
+			}
+		}
+	}
+	return running
+}
+
+// updatePendingStatus records why a backup that is due hasn't been started.
+// The schedule is only updated if the reason or message changed
+func (s *ApplicationBackupScheduleController) updatePendingStatus(
+	backupSchedule *stork_api.ApplicationBackupSchedule,
+	pending *stork_api.SchedulePendingStatus,
+) error {
+	current := backupSchedule.Status.Pending
+	if current != nil && current.Reason == pending.Reason && current.Message == pending.Message {
+		return nil
+	}
+	if current == nil || current.Reason != pending.Reason {
+		msg := fmt.Sprintf("Backup for schedule(%v) is pending: %v", pending.PolicyType, pending.Message)
+		s.recorder.Event(backupSchedule,
+			v1.EventTypeNormal,
+			string(pending.Reason),
+			msg)
+		log.ApplicationBackupScheduleLog(backupSchedule).Info(msg)
+	}
+	backupSchedule.Status.Pending = pending
+	return s.client.Update(context.TODO(), backupSchedule)
+}
+
 func (s *ApplicationBackupScheduleController) shouldStartApplicationBackup(backupSchedule *stork_api.ApplicationBackupSchedule) (stork_api.SchedulePolicyType, bool, error) {
 	// Don't trigger a new backup if one is already in progress
 	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
@@ -246,6 +319,11 @@ func (s *ApplicationBackupScheduleController) shouldStartApplicationBackup(backu
 		}
 	}
 
+	// A trigger that was deferred is still due
+	if backupSchedule.Status.Pending != nil {
+		return backupSchedule.Status.Pending.PolicyType, true, nil
+	}
+
 	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
 		var latestApplicationBackupTimestamp meta.Time
 		policyApplicationBackup, present := backupSchedule.Status.Items[policyType]
@@ -464,6 +542,13 @@ func (s *ApplicationBackupScheduleController) startApplicationBackup(backupSched
 }
 
 func (s *ApplicationBackupScheduleController) pruneApplicationBackups(backupSchedule *stork_api.ApplicationBackupSchedule) error {
+	retention, location, err := schedule.GetGFSRetention(backupSchedule.Spec.SchedulePolicyName, backupSchedule.Namespace)
+	if err != nil {
+		return err
+	}
+	if retention != nil {
+		return s.pruneApplicationBackupsGFS(backupSchedule, retention, location)
+	}
 	for policyType, policyApplicationBackup := range backupSchedule.Status.Items {
 		numApplicationBackups := len(policyApplicationBackup)
 		deleteBefore := 0
@@ -507,6 +592,39 @@ func (s *ApplicationBackupScheduleController) pruneApplicationBackups(backupSche
 	return s.client.Update(context.TODO(), backupSchedule)
 }
 
+// pruneApplicationBackupsGFS deletes the backups, across all policy types,
+// that aren't retained by the GFS retention of the policy
+func (s *ApplicationBackupScheduleController) pruneApplicationBackupsGFS(
+	backupSchedule *stork_api.ApplicationBackupSchedule,
+	retention *stork_api.GFSRetention,
+	location *time.Location,
+) error {
+	deleted, err := schedule.PruneGFSRetention(
+		retention,
+		location,
+		schedule.GetApplicationBackupRetentionItems(backupSchedule.Status.Items),
+		func(name string) error {
+			return storkops.Instance().DeleteApplicationBackup(name, backupSchedule.Namespace)
+		},
+	)
+	if err != nil {
+		log.ApplicationBackupScheduleLog(backupSchedule).Warnf("Error pruning backups: %v", err)
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+	for policyType, policyApplicationBackup := range backupSchedule.Status.Items {
+		retained := make([]*stork_api.ScheduledApplicationBackupStatus, 0, len(policyApplicationBackup))
+		for _, backup := range policyApplicationBackup {
+			if !deleted[backup.Name] {
+				retained = append(retained, backup)
+			}
+		}
+		backupSchedule.Status.Items[policyType] = retained
+	}
+	return s.client.Update(context.TODO(), backupSchedule)
+}
+
 func (s *ApplicationBackupScheduleController) createCRD() error {
 	resource := apiextensions.CustomResource{
 		Name:    stork_api.ApplicationBackupScheduleResourceName,
@@ -231,8 +231,9 @@ func (a *ApplicationCloneController) handle(ctx context.Context, clone *stork_ap
 			return nil
 		}
 		// Make sure the rules exist if configured
+		var preExecRule, postExecRule *stork_api.Rule
 		if clone.Spec.PreExecRule != "" {
-			_, err := storkops.Instance().GetRule(clone.Spec.PreExecRule, clone.Namespace)
+			preExecRule, err = storkops.Instance().GetRule(clone.Spec.PreExecRule, clone.Namespace)
 			if err != nil {
 				message := fmt.Sprintf("Error getting PreExecRule %v: %v", clone.Spec.PreExecRule, err)
 				log.ApplicationCloneLog(clone).Errorf(message)
@@ -244,7 +245,7 @@ func (a *ApplicationCloneController) handle(ctx context.Context, clone *stork_ap
 			}
 		}
 		if clone.Spec.PostExecRule != "" {
-			_, err := storkops.Instance().GetRule(clone.Spec.PostExecRule, clone.Namespace)
+			postExecRule, err = storkops.Instance().GetRule(clone.Spec.PostExecRule, clone.Namespace)
 			if err != nil {
 				message := fmt.Sprintf("Error getting PostExecRule %v: %v", clone.Spec.PostExecRule, err)
 				log.ApplicationCloneLog(clone).Errorf(message)
@@ -255,6 +256,31 @@ func (a *ApplicationCloneController) handle(ctx context.Context, clone *stork_ap
 				return nil
 			}
 		}
+		if err := rule.ValidateRules(preExecRule, postExecRule); err != nil {
+			message := fmt.Sprintf("Error validating rules: %v", err)
+			log.ApplicationCloneLog(clone).Errorf(message)
+			a.recorder.Event(clone,
+				v1.EventTypeWarning,
+				string(stork_api.ApplicationCloneStatusFailed),
+				message)
+			return nil
+		}
+		// Make sure the transformations are valid if configured
+		if len(clone.Spec.TransformSpecs) != 0 {
+			if _, err := resourcecollector.ValidateResourceTransformations(clone.Spec.TransformSpecs, clone.Namespace); err != nil {
+				message := fmt.Sprintf("Error validating resource transformations: %v", err)
+				log.ApplicationCloneLog(clone).Errorf(message)
+				a.recorder.Event(clone,
+					v1.EventTypeWarning,
+					string(stork_api.ApplicationCloneStatusFailed),
+					message)
+				clone.Status.Stage = stork_api.ApplicationCloneStageFinal
+				clone.Status.FinishTimestamp = metav1.Now()
+				clone.Status.Status = stork_api.ApplicationCloneStatusFailed
+				clone.Status.Reason = message
+				return a.client.Update(context.TODO(), clone)
+			}
+		}
 		fallthrough
 	case stork_api.ApplicationCloneStagePreExecRule:
 		terminationChannel, err = a.runPreExecRule(clone)
@@ -481,7 +507,9 @@ func (a *ApplicationCloneController) runPreExecRule(clone *stork_api.Application
 		return nil, err
 	}
 
-	ch, err := rule.ExecuteRule(r, rule.PreExecRule, clone, clone.Spec.SourceNamespace)
+	ch, result, err := rule.ExecuteRule(r, rule.PreExecRule, clone, clone.Spec.SourceNamespace)
+	clone.Status.RuleActionFailures = append(clone.Status.RuleActionFailures, result.Failures...)
+	clone.Status.RuleRuns = rule.AppendRuleRuns(clone.Status.RuleRuns, result)
 	if err != nil {
 		return nil, fmt.Errorf("error executing PreExecRule for namespace %v: %v", clone.Spec.SourceNamespace, err)
 	}
@@ -494,7 +522,9 @@ func (a *ApplicationCloneController) runPostExecRule(clone *stork_api.Applicatio
 		return err
 	}
 
-	_, err = rule.ExecuteRule(r, rule.PostExecRule, clone, clone.Spec.SourceNamespace)
+	_, result, err := rule.ExecuteRule(r, rule.PostExecRule, clone, clone.Spec.SourceNamespace)
+	clone.Status.RuleActionFailures = append(clone.Status.RuleActionFailures, result.Failures...)
+	clone.Status.RuleRuns = rule.AppendRuleRuns(clone.Status.RuleRuns, result)
 	if err != nil {
 		return fmt.Errorf("error executing PreExecRule for namespace %v: %v", clone.Namespace, err)
 	}
@@ -510,6 +540,10 @@ func (a *ApplicationCloneController) prepareResources(
 	if err != nil {
 		return nil, err
 	}
+	transforms, err := resourcecollector.GetResourceTransformations(clone.Spec.TransformSpecs, clone.Namespace)
+	if err != nil {
+		return nil, err
+	}
 
 	namespaceMapping := make(map[string]string)
 	namespaceMapping[clone.Spec.SourceNamespace] = clone.Spec.DestinationNamespace
@@ -549,6 +583,9 @@ func (a *ApplicationCloneController) prepareResources(
 		if err != nil {
 			return nil, err
 		}
+		if err := resourcecollector.TransformObject(o, transforms); err != nil {
+			return nil, err
+		}
 		tempObjects = append(tempObjects, o)
 	}
 	return tempObjects, nil
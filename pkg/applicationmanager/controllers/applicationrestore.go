@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/libopenstorage/stork/drivers/volume"
@@ -13,7 +14,6 @@ import (
 	"github.com/libopenstorage/stork/pkg/apis/stork"
 	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/controllers"
-	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
 	"github.com/libopenstorage/stork/pkg/log"
 	"github.com/libopenstorage/stork/pkg/objectstore"
@@ -103,6 +103,127 @@ func (a *ApplicationRestoreController) setDefaults(restore *storkapi.Application
 	return nil
 }
 
+// resolveScheduledBackup picks the backup to restore from the history of the
+// backup schedule and records it in the restore
+func (a *ApplicationRestoreController) resolveScheduledBackup(ctx context.Context, restore *storkapi.ApplicationRestore) error {
+	backup, err := getScheduledBackup(restore.Spec.BackupSchedule, restore.Namespace, restore.Spec.PointInTime)
+	var parents []string
+	if err == nil {
+		parents, err = getBackupParents(backup)
+	}
+	if err != nil {
+		message := fmt.Sprintf("Error picking backup from schedule %v: %v", restore.Spec.BackupSchedule, err)
+		log.ApplicationRestoreLog(restore).Errorf(message)
+		a.recorder.Event(restore,
+			v1.EventTypeWarning,
+			string(storkapi.ApplicationRestoreStatusFailed),
+			message)
+		restore.Status.Stage = storkapi.ApplicationRestoreStageFinal
+		restore.Status.Status = storkapi.ApplicationRestoreStatusFailed
+		restore.Status.Reason = message
+		restore.Status.FinishTimestamp = metav1.Now()
+		restore.Status.LastUpdateTimestamp = metav1.Now()
+		return a.client.Update(ctx, restore)
+	}
+
+	restore.Spec.BackupName = backup.Name
+	if restore.Spec.BackupLocation == "" {
+		restore.Spec.BackupLocation = backup.Spec.BackupLocation
+	}
+	restore.Status.BackupName = backup.Name
+	restore.Status.BackupParents = parents
+	restore.Status.LastUpdateTimestamp = metav1.Now()
+	message := fmt.Sprintf("Restoring from backup %v of schedule %v", backup.Name, restore.Spec.BackupSchedule)
+	log.ApplicationRestoreLog(restore).Infof(message)
+	a.recorder.Event(restore,
+		v1.EventTypeNormal,
+		string(storkapi.ApplicationRestoreStatusInProgress),
+		message)
+	return a.client.Update(ctx, restore)
+}
+
+// getScheduledBackup returns the latest successful backup triggered by the
+// schedule that finished at or before the point in time, or the latest
+// successful one if the point in time isn't set
+func getScheduledBackup(
+	scheduleName string,
+	namespace string,
+	pointInTime *metav1.Time,
+) (*storkapi.ApplicationBackup, error) {
+	schedule, err := storkops.Instance().GetApplicationBackupSchedule(scheduleName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]*storkapi.ScheduledApplicationBackupStatus, 0)
+	for _, policyItems := range schedule.Status.Items {
+		for _, item := range policyItems {
+			if item.Status != storkapi.ApplicationBackupStatusSuccessful {
+				continue
+			}
+			if pointInTime != nil && item.FinishTimestamp.After(pointInTime.Time) {
+				continue
+			}
+			candidates = append(candidates, item)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[j].FinishTimestamp.Before(&candidates[i].FinishTimestamp)
+	})
+	for _, candidate := range candidates {
+		backup, err := storkops.Instance().GetApplicationBackup(candidate.Name, namespace)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if backup.DeletionTimestamp != nil || backup.Status.Status != storkapi.ApplicationBackupStatusSuccessful {
+			continue
+		}
+		return backup, nil
+	}
+	if pointInTime != nil {
+		return nil, fmt.Errorf("no successful backup finished at or before %v", pointInTime.UTC().Format(time.RFC3339))
+	}
+	return nil, fmt.Errorf("no successful backup found")
+}
+
+// getBackupParents returns the parent backups of an incremental backup that
+// still exist, after checking that they were successful. Parents that were
+// deleted are skipped since their resources were copied to the children.
+func getBackupParents(backup *storkapi.ApplicationBackup) ([]string, error) {
+	parents := make([]string, 0)
+	visited := map[string]bool{backup.Name: true}
+	queue := []*storkapi.ApplicationBackup{backup}
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+		names := []string{current.Status.ParentBackup}
+		for _, vInfo := range current.Status.Volumes {
+			names = append(names, vInfo.ParentBackup)
+		}
+		for _, name := range names {
+			if name == "" || visited[name] {
+				continue
+			}
+			visited[name] = true
+			parent, err := storkops.Instance().GetApplicationBackup(name, backup.Namespace)
+			if err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			if parent.Status.Status != storkapi.ApplicationBackupStatusSuccessful {
+				return nil, fmt.Errorf("parent backup %v of backup %v is not successful", parent.Name, current.Name)
+			}
+			parents = append(parents, parent.Name)
+			queue = append(queue, parent)
+		}
+	}
+	return parents, nil
+}
+
 func (a *ApplicationRestoreController) verifyNamespaces(restore *storkapi.ApplicationRestore) error {
 	// Check whether namespace is allowed to be restored to before each stage
 	// Restrict restores to only the namespace that the object belongs
@@ -123,7 +244,7 @@ func (a *ApplicationRestoreController) createNamespaces(backup *storkapi.Applica
 	restore *storkapi.ApplicationRestore) error {
 	var namespaces []*v1.Namespace
 
-	nsData, err := a.downloadObject(backup, backupLocation, restore.Namespace, nsObjectName, true)
+	nsData, err := downloadObject(backup, backupLocation, restore.Namespace, nsObjectName, true)
 	if err != nil {
 		return err
 	}
@@ -266,6 +387,14 @@ func (a *ApplicationRestoreController) handle(ctx context.Context, restore *stor
 		return nil
 	}
 
+	if restore.Spec.BackupName == "" && restore.Spec.BackupSchedule != "" {
+		// Nothing to do if picking the backup failed
+		if restore.Status.Stage == storkapi.ApplicationRestoreStageFinal {
+			return nil
+		}
+		return a.resolveScheduledBackup(ctx, restore)
+	}
+
 	err := a.setDefaults(restore)
 	if err != nil {
 		log.ApplicationRestoreLog(restore).Errorf(err.Error())
@@ -276,6 +405,10 @@ func (a *ApplicationRestoreController) handle(ctx context.Context, restore *stor
 		return nil
 	}
 
+	if restore.Spec.DryRun {
+		return a.dryRunRestore(ctx, restore)
+	}
+
 	err = a.verifyNamespaces(restore)
 	if err != nil {
 		log.ApplicationRestoreLog(restore).Errorf(err.Error())
@@ -288,7 +421,23 @@ func (a *ApplicationRestoreController) handle(ctx context.Context, restore *stor
 
 	switch restore.Status.Stage {
 	case storkapi.ApplicationRestoreStageInitial:
-		// Make sure the namespaces exist
+		// Make sure the transformations are valid before restoring anything
+		if len(restore.Spec.TransformSpecs) != 0 {
+			if _, err := resourcecollector.ValidateResourceTransformations(restore.Spec.TransformSpecs, restore.Namespace); err != nil {
+				message := fmt.Sprintf("Error validating resource transformations: %v", err)
+				log.ApplicationRestoreLog(restore).Errorf(message)
+				a.recorder.Event(restore,
+					v1.EventTypeWarning,
+					string(storkapi.ApplicationRestoreStatusFailed),
+					message)
+				restore.Status.Stage = storkapi.ApplicationRestoreStageFinal
+				restore.Status.Status = storkapi.ApplicationRestoreStatusFailed
+				restore.Status.Reason = message
+				restore.Status.FinishTimestamp = metav1.Now()
+				restore.Status.LastUpdateTimestamp = metav1.Now()
+				return a.client.Update(ctx, restore)
+			}
+		}
 		fallthrough
 	case storkapi.ApplicationRestoreStageVolumes:
 		err := a.restoreVolumes(restore)
@@ -693,7 +842,7 @@ func (a *ApplicationRestoreController) restoreVolumes(restore *storkapi.Applicat
 	return nil
 }
 
-func (a *ApplicationRestoreController) downloadObject(
+func downloadObject(
 	backup *storkapi.ApplicationBackup,
 	backupLocation string,
 	namespace string,
@@ -724,16 +873,12 @@ func (a *ApplicationRestoreController) downloadObject(
 	if restoreLocation.Location.EncryptionKey != "" {
 		return nil, fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
 	}
-	if restoreLocation.Location.EncryptionV2Key != "" {
-		var decryptData []byte
-		if decryptData, err = crypto.Decrypt(data, restoreLocation.Location.EncryptionV2Key); err != nil {
-			logrus.Errorf("ApplicationRestoreController/downloadObject: decrypt failed :%v, returing data direclty", err)
-			return data, nil
-		}
-		return decryptData, nil
+	decryptData, err := objectstore.Decrypt(restoreLocation, data)
+	if err != nil {
+		logrus.Errorf("ApplicationRestoreController/downloadObject: decrypt failed :%v, returing data direclty", err)
+		return data, nil
 	}
-
-	return data, nil
+	return decryptData, nil
 }
 
 func (a *ApplicationRestoreController) downloadResources(
@@ -745,7 +890,28 @@ func (a *ApplicationRestoreController) downloadResources(
 	if err := a.downloadCRD(backup, backupLocation, namespace); err != nil {
 		return nil, fmt.Errorf("error downloading CRDs: %v", err)
 	}
-	data, err := a.downloadObject(backup, backupLocation, namespace, resourceObjectName, false)
+	return downloadResourceObjects(backup, backupLocation, namespace)
+}
+
+// downloadResourceObjects downloads the resources in the backup without
+// registering the CRDs for them
+func downloadResourceObjects(
+	backup *storkapi.ApplicationBackup,
+	backupLocation string,
+	namespace string,
+) ([]runtime.Unstructured, error) {
+	if backup.Status.ResourcesFormat == storkapi.ApplicationBackupResourcesFormatStream ||
+		backup.Status.ResourcesFormat == storkapi.ApplicationBackupResourcesFormatIncremental {
+		restoreLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if backup.Status.ResourcesFormat == storkapi.ApplicationBackupResourcesFormatIncremental {
+			return DownloadIncrementalResources(backup, restoreLocation)
+		}
+		return DownloadResourceStream(backup, restoreLocation)
+	}
+	data, err := downloadObject(backup, backupLocation, namespace, resourceObjectName, false)
 	if err != nil {
 		return nil, err
 	}
@@ -768,7 +934,7 @@ func (a *ApplicationRestoreController) downloadCRD(
 ) error {
 	var crds []*apiextensionsv1beta1.CustomResourceDefinition
 	var crdsV1 []*apiextensionsv1.CustomResourceDefinition
-	crdData, err := a.downloadObject(backup, backupLocation, namespace, crdObjectName, true)
+	crdData, err := downloadObject(backup, backupLocation, namespace, crdObjectName, true)
 	if err != nil {
 		return err
 	}
@@ -1134,6 +1300,10 @@ func (a *ApplicationRestoreController) applyResources(
 	if err != nil {
 		return err
 	}
+	transforms, err := resourcecollector.GetResourceTransformations(restore.Spec.TransformSpecs, restore.Namespace)
+	if err != nil {
+		return err
+	}
 	objectMap := storkapi.CreateObjectsMap(restore.Spec.IncludeResources)
 	tempObjects := make([]runtime.Unstructured, 0)
 	for _, o := range objects {
@@ -1150,9 +1320,13 @@ func (a *ApplicationRestoreController) applyResources(
 		if err != nil {
 			return err
 		}
-		if !skip {
-			tempObjects = append(tempObjects, o)
+		if skip {
+			continue
+		}
+		if err := resourcecollector.TransformObject(o, transforms); err != nil {
+			return err
 		}
+		tempObjects = append(tempObjects, o)
 	}
 	objects = tempObjects
 
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8shelper "k8s.io/component-helpers/storage/volume"
+)
+
+// dryRunRestore plans the restore and records the plan in the status. Only
+// reads are done against the cluster, no namespaces, CRDs, volumes or
+// resources are created
+func (a *ApplicationRestoreController) dryRunRestore(
+	ctx context.Context,
+	restore *storkapi.ApplicationRestore,
+) error {
+	if restore.Status.Stage == storkapi.ApplicationRestoreStageFinal {
+		return nil
+	}
+	if !a.namespaceRestoreAllowed(restore) {
+		err := fmt.Errorf("Spec.Namespaces should only contain the current namespace")
+		log.ApplicationRestoreLog(restore).Errorf(err.Error())
+		a.recorder.Event(restore,
+			v1.EventTypeWarning,
+			string(storkapi.ApplicationRestoreStatusFailed),
+			err.Error())
+		return nil
+	}
+
+	resources, volumes, err := a.planRestore(restore)
+	if err != nil {
+		message := fmt.Sprintf("Error planning restore: %v", err)
+		log.ApplicationRestoreLog(restore).Errorf(message)
+		a.recorder.Event(restore,
+			v1.EventTypeWarning,
+			string(storkapi.ApplicationRestoreStatusFailed),
+			message)
+		restore.Status.Status = storkapi.ApplicationRestoreStatusFailed
+		restore.Status.Reason = message
+	} else {
+		restore.Status.DryRunResources = resources
+		restore.Status.DryRunVolumes = volumes
+		restore.Status.Status = storkapi.ApplicationRestoreStatusSuccessful
+		restore.Status.Reason = "Dry run completed, no volumes or resources were restored"
+	}
+	restore.Status.Stage = storkapi.ApplicationRestoreStageFinal
+	restore.Status.FinishTimestamp = metav1.Now()
+	restore.Status.LastUpdateTimestamp = metav1.Now()
+	return a.client.Update(ctx, restore)
+}
+
+// planRestore goes through the same preparation as applyResources and
+// returns the action that would be taken for every resource in the backup,
+// along with the PVCs that would be provisioned
+func (a *ApplicationRestoreController) planRestore(
+	restore *storkapi.ApplicationRestore,
+) ([]*storkapi.ApplicationRestoreDryRunResourceInfo, []*storkapi.ApplicationRestoreDryRunVolumeInfo, error) {
+	backup, err := storkops.Instance().GetApplicationBackup(restore.Spec.BackupName, restore.Namespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting backup: %v", err)
+	}
+	objects, err := downloadResourceObjects(backup, restore.Spec.BackupLocation, restore.Namespace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error downloading resources: %v", err)
+	}
+
+	// The dry-run shouldn't update the transformations, so use them as they
+	// were last validated
+	transforms, err := resourcecollector.GetResourceTransformations(restore.Spec.TransformSpecs, restore.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, transform := range transforms {
+		if transform.Status.Status != storkapi.ResourceTransformationStatusReady {
+			return nil, nil, fmt.Errorf("transformation %v is not in ready state: %v", transform.Name, transform.Status.Status)
+		}
+	}
+
+	resources := make([]*storkapi.ApplicationRestoreDryRunResourceInfo, 0)
+	volumes := make([]*storkapi.ApplicationRestoreDryRunVolumeInfo, 0)
+	objectMap := storkapi.CreateObjectsMap(restore.Spec.IncludeResources)
+	for _, o := range objects {
+		metadata, err := meta.Accessor(o)
+		if err != nil {
+			return nil, nil, err
+		}
+		sourceNamespace := metadata.GetNamespace()
+		// No volumes have been restored, so PVs are always skipped and PVCs
+		// are left unbound
+		skip, err := a.resourceCollector.PrepareResourceForApply(
+			o,
+			objects,
+			objectMap,
+			restore.Spec.NamespaceMapping,
+			restore.Spec.StorageClassMapping,
+			nil,
+			restore.Spec.IncludeOptionalResourceTypes,
+			nil,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		gvk := o.GetObjectKind().GroupVersionKind()
+		resource := &storkapi.ApplicationRestoreDryRunResourceInfo{
+			ObjectInfo: storkapi.ObjectInfo{
+				Name:             metadata.GetName(),
+				Namespace:        metadata.GetNamespace(),
+				GroupVersionKind: metav1.GroupVersionKind(gvk),
+			},
+		}
+		resources = append(resources, resource)
+		if skip {
+			resource.Action = storkapi.ApplicationRestoreDryRunActionSkip
+			if gvk.Kind == "PersistentVolume" {
+				resource.Reason = "PersistentVolumes are created when the volumes are restored"
+			} else {
+				resource.Reason = "Resource is not selected for restore"
+			}
+			continue
+		}
+		if err := resourcecollector.TransformObject(o, transforms); err != nil {
+			return nil, nil, err
+		}
+
+		exists, err := a.resourceCollector.ResourceExists(a.dynamicInterface, o)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error checking if %v %v/%v exists: %v",
+				gvk.Kind, metadata.GetNamespace(), metadata.GetName(), err)
+		}
+		switch {
+		case !exists:
+			resource.Action = storkapi.ApplicationRestoreDryRunActionCreate
+			resource.Reason = "Resource does not exist"
+		case restore.Spec.ReplacePolicy == storkapi.ApplicationRestoreReplacePolicyDelete:
+			resource.Action = storkapi.ApplicationRestoreDryRunActionReplace
+			resource.Reason = "Resource already exists and ReplacePolicy is set to Delete"
+		default:
+			resource.Action = storkapi.ApplicationRestoreDryRunActionSkip
+			resource.Reason = "Resource already exists and ReplacePolicy is set to Retain"
+			continue
+		}
+
+		if gvk.Kind == "PersistentVolumeClaim" {
+			volume, err := getDryRunVolumeInfo(backup, o, sourceNamespace)
+			if err != nil {
+				return nil, nil, err
+			}
+			volumes = append(volumes, volume)
+		}
+	}
+	return resources, volumes, nil
+}
+
+func getDryRunVolumeInfo(
+	backup *storkapi.ApplicationBackup,
+	object runtime.Unstructured,
+	sourceNamespace string,
+) (*storkapi.ApplicationRestoreDryRunVolumeInfo, error) {
+	var pvc v1.PersistentVolumeClaim
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.UnstructuredContent(), &pvc); err != nil {
+		return nil, fmt.Errorf("error converting PVC object: %v: %v", object, err)
+	}
+	volume := &storkapi.ApplicationRestoreDryRunVolumeInfo{
+		PersistentVolumeClaim: pvc.Name,
+		Namespace:             pvc.Namespace,
+		SourceNamespace:       sourceNamespace,
+		StorageClass:          k8shelper.GetPersistentVolumeClaimClass(&pvc),
+	}
+	if size, ok := pvc.Spec.Resources.Requests[v1.ResourceStorage]; ok {
+		volume.Size = size.String()
+	}
+	for _, vInfo := range backup.Status.Volumes {
+		if vInfo.PersistentVolumeClaim == pvc.Name && vInfo.Namespace == sourceNamespace {
+			volume.DriverName = vInfo.DriverName
+			break
+		}
+	}
+	return volume, nil
+}
@@ -10,7 +10,6 @@ import (
 	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
-	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/log"
 	"github.com/libopenstorage/stork/pkg/objectstore"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
@@ -105,12 +104,10 @@ func (b *BackupSyncController) syncBackupsFromLocation(location *storkv1.BackupL
 				if location.Location.EncryptionKey != "" {
 					return fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
 				}
-				if location.Location.EncryptionV2Key != "" {
-					if decryptData, err := crypto.Decrypt(data, location.Location.EncryptionV2Key); err != nil {
-						log.BackupLocationLog(location).Errorf("Error decrypting backup %v during sync: %v", backupName, err)
-					} else {
-						data = decryptData
-					}
+				if decryptData, err := objectstore.Decrypt(location, data); err != nil {
+					log.BackupLocationLog(location).Errorf("Error decrypting backup %v during sync: %v", backupName, err)
+				} else {
+					data = decryptData
 				}
 				backupInfo := storkv1.ApplicationBackup{}
 				if err = json.Unmarshal(data, &backupInfo); err != nil {
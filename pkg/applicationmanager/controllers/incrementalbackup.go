@@ -0,0 +1,596 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// resourceIndexObjectName is the index of the resources for backups
+	// with the ApplicationBackupResourcesFormatIncremental format
+	resourceIndexObjectName = "resourceindex.json"
+	// rebasedResourcePrefix is the prefix of the objects that hold the
+	// resources copied from a deleted parent backup
+	rebasedResourcePrefix = "resources-"
+)
+
+// resourceIndexEntry references a resource of an incremental backup in the
+// object where it was uploaded, which could belong to a parent backup
+type resourceIndexEntry struct {
+	// Hash is the SHA256 of the JSON encoding of the resource
+	Hash string `json:"hash"`
+	// Object is the key of the object in the backup location that holds
+	// the resource
+	Object string `json:"object"`
+	// Index is the position of the resource in the object
+	Index int `json:"index"`
+}
+
+// volumeParent is the backup of a volume in a parent backup
+type volumeParent struct {
+	backup string
+	volume *stork_api.ApplicationBackupVolumeInfo
+}
+
+func isBackupFinished(backup *stork_api.ApplicationBackup) bool {
+	return backup.Status.Status == stork_api.ApplicationBackupStatusSuccessful ||
+		backup.Status.Status == stork_api.ApplicationBackupStatusPartialSuccess ||
+		backup.Status.Status == stork_api.ApplicationBackupStatusFailed
+}
+
+// getParentBackups returns the backups that can be used as the parent of an
+// incremental backup, newest first. Only successful incremental backups in
+// the same namespace for the same backup location are considered.
+func getParentBackups(backup *stork_api.ApplicationBackup) ([]*stork_api.ApplicationBackup, error) {
+	backupList, err := storkops.Instance().ListApplicationBackups(backup.Namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	parents := make([]*stork_api.ApplicationBackup, 0)
+	for i := range backupList.Items {
+		parent := &backupList.Items[i]
+		if parent.Name == backup.Name ||
+			parent.DeletionTimestamp != nil ||
+			parent.Spec.BackupLocation != backup.Spec.BackupLocation ||
+			parent.Status.Status != stork_api.ApplicationBackupStatusSuccessful ||
+			parent.Status.ResourcesFormat != stork_api.ApplicationBackupResourcesFormatIncremental {
+			continue
+		}
+		parents = append(parents, parent)
+	}
+	sort.Slice(parents, func(i, j int) bool {
+		return parents[j].Status.FinishTimestamp.Before(&parents[i].Status.FinishTimestamp)
+	})
+	return parents, nil
+}
+
+// getVolumeParents returns the latest successful backup of each PVC in the
+// parent backups, keyed by the UID of the PVC
+func getVolumeParents(backup *stork_api.ApplicationBackup) (map[string]*volumeParent, error) {
+	volumeParents := make(map[string]*volumeParent)
+	if !backup.Spec.Incremental {
+		return volumeParents, nil
+	}
+	parents, err := getParentBackups(backup)
+	if err != nil {
+		return nil, err
+	}
+	for _, parent := range parents {
+		for _, vInfo := range parent.Status.Volumes {
+			if vInfo.Status != stork_api.ApplicationBackupStatusSuccessful || vInfo.BackupID == "" {
+				continue
+			}
+			if _, ok := volumeParents[vInfo.PersistentVolumeClaimUID]; !ok {
+				volumeParents[vInfo.PersistentVolumeClaimUID] = &volumeParent{
+					backup: parent.Name,
+					volume: vInfo,
+				}
+			}
+		}
+	}
+	return volumeParents, nil
+}
+
+// getDriverVolumeParents returns the parent volume backups that were taken
+// by the driver
+func getDriverVolumeParents(
+	volumeParents map[string]*volumeParent,
+	driverName string,
+) map[string]*stork_api.ApplicationBackupVolumeInfo {
+	parents := make(map[string]*stork_api.ApplicationBackupVolumeInfo)
+	for uid, parent := range volumeParents {
+		if parent.volume.DriverName == driverName {
+			parents[uid] = parent.volume
+		}
+	}
+	return parents
+}
+
+// setVolumeParents records the parent backup for the volumes that the driver
+// backed up incrementally
+func setVolumeParents(
+	volumeInfos []*stork_api.ApplicationBackupVolumeInfo,
+	volumeParents map[string]*volumeParent,
+) {
+	for _, vInfo := range volumeInfos {
+		if vInfo.ParentBackupID == "" {
+			continue
+		}
+		if parent, ok := volumeParents[vInfo.PersistentVolumeClaimUID]; ok {
+			vInfo.ParentBackup = parent.backup
+		}
+	}
+}
+
+func hashResource(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// uploadIncrementalResources streams the objects that changed since the
+// parent backup to the backup location and uploads the index for all the
+// objects. Unchanged objects are referenced from the object they were
+// uploaded to by the parent backups.
+func (a *ApplicationBackupController) uploadIncrementalResources(
+	backup *stork_api.ApplicationBackup,
+	objects []runtime.Unstructured,
+) error {
+	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+	if err != nil {
+		return err
+	}
+	if backupLocation.Location.EncryptionKey != "" {
+		return fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return err
+	}
+	dataKey, err := objectstore.GetDataKey(backupLocation, backup)
+	if err != nil {
+		return err
+	}
+
+	parentEntries := make(map[string]*resourceIndexEntry)
+	parents, err := getParentBackups(backup)
+	if err != nil {
+		return err
+	}
+	backup.Status.ParentBackup = ""
+	if len(parents) != 0 {
+		entries, err := downloadResourceIndex(bucket, backupLocation, parents[0].Status.BackupPath)
+		if err != nil {
+			return fmt.Errorf("error downloading resource index for parent backup %v: %v", parents[0].Name, err)
+		}
+		for _, entry := range entries {
+			parentEntries[entry.Hash] = entry
+		}
+		backup.Status.ParentBackup = parents[0].Name
+	}
+
+	objectKey := filepath.Join(GetObjectPath(backup), resourceObjectName)
+	writer, err := newResourceStreamWriter(
+		context.TODO(),
+		bucket,
+		objectKey,
+		backup.Spec.ResourceCompression,
+		dataKey,
+		backupLocation.Location.EncryptionV2Key,
+	)
+	if err != nil {
+		return err
+	}
+	index := make([]*resourceIndexEntry, 0, len(objects))
+	var uploaded int
+	for _, obj := range objects {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			writer.abort()
+			return err
+		}
+		hash := hashResource(data)
+		if entry, ok := parentEntries[hash]; ok {
+			index = append(index, entry)
+			continue
+		}
+		if err := writer.Write(json.RawMessage(data)); err != nil {
+			writer.abort()
+			return err
+		}
+		index = append(index, &resourceIndexEntry{
+			Hash:   hash,
+			Object: objectKey,
+			Index:  uploaded,
+		})
+		uploaded++
+	}
+	if err := writer.Close(); err != nil {
+		log.ApplicationBackupLog(backup).Errorf("Error closing writer for objectstore: %v", err)
+		return err
+	}
+	objectPath := GetObjectPath(backup)
+	if _, err := objectstore.UpdateManifest(bucket, backupLocation, objectPath, writer.manifestObject(objectPath)); err != nil {
+		return err
+	}
+	log.ApplicationBackupLog(backup).Infof("Uploaded %v of %v resources, parent backup: %v",
+		uploaded, len(objects), backup.Status.ParentBackup)
+
+	return uploadResourceIndex(bucket, backupLocation, backup, index)
+}
+
+// uploadResourceIndex uploads the index of the resources for the backup
+func uploadResourceIndex(
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	backup *stork_api.ApplicationBackup,
+	index []*resourceIndexEntry,
+) error {
+	dataKey, err := objectstore.GetDataKey(backupLocation, backup)
+	if err != nil {
+		return err
+	}
+	writer, err := newResourceStreamWriter(
+		context.TODO(),
+		bucket,
+		filepath.Join(GetObjectPath(backup), resourceIndexObjectName),
+		backup.Spec.ResourceCompression,
+		dataKey,
+		backupLocation.Location.EncryptionV2Key,
+	)
+	if err != nil {
+		return err
+	}
+	for _, entry := range index {
+		if err := writer.Write(entry); err != nil {
+			writer.abort()
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	objectPath := GetObjectPath(backup)
+	_, err = objectstore.UpdateManifest(bucket, backupLocation, objectPath, writer.manifestObject(objectPath))
+	return err
+}
+
+// downloadResourceIndex downloads the index of the resources for the backup
+// at the given path
+func downloadResourceIndex(
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	backupPath string,
+) ([]*resourceIndexEntry, error) {
+	reader, err := bucket.NewReader(context.TODO(), filepath.Join(backupPath, resourceIndexObjectName), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close() // nolint: errcheck
+	stream, err := objectstore.NewStreamReader(reader, backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*resourceIndexEntry, 0)
+	if err := json.NewDecoder(stream).Decode(&entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// downloadResourceObject downloads the resources streamed to the object with
+// the given key
+func downloadResourceObject(
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	key string,
+) ([]runtime.Unstructured, error) {
+	reader, err := bucket.NewReader(context.TODO(), key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close() // nolint: errcheck
+	stream, err := objectstore.NewStreamReader(reader, backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	return readResourceStream(stream)
+}
+
+// resourceObjectCache caches the resources downloaded from the objects
+// referenced by resource indexes
+type resourceObjectCache struct {
+	bucket         *blob.Bucket
+	backupLocation *stork_api.BackupLocation
+	objects        map[string][]runtime.Unstructured
+}
+
+func newResourceObjectCache(bucket *blob.Bucket, backupLocation *stork_api.BackupLocation) *resourceObjectCache {
+	return &resourceObjectCache{
+		bucket:         bucket,
+		backupLocation: backupLocation,
+		objects:        make(map[string][]runtime.Unstructured),
+	}
+}
+
+// get returns a copy of the resource referenced by the index entry
+func (c *resourceObjectCache) get(entry *resourceIndexEntry) (runtime.Unstructured, error) {
+	objects, ok := c.objects[entry.Object]
+	if !ok {
+		var err error
+		objects, err = downloadResourceObject(c.bucket, c.backupLocation, entry.Object)
+		if err != nil {
+			if gcerrors.Code(err) == gcerrors.NotFound {
+				return nil, fmt.Errorf("resources referenced from %v are missing from the backup location", entry.Object)
+			}
+			return nil, err
+		}
+		c.objects[entry.Object] = objects
+	}
+	if entry.Index < 0 || entry.Index >= len(objects) {
+		return nil, fmt.Errorf("resource %v not found in %v", entry.Index, entry.Object)
+	}
+	return objects[entry.Index].DeepCopyObject().(runtime.Unstructured), nil
+}
+
+// DownloadIncrementalResources downloads the resources for a backup that was
+// uploaded with the ApplicationBackupResourcesFormatIncremental format,
+// including the ones referenced from its parent backups
+func DownloadIncrementalResources(
+	backup *stork_api.ApplicationBackup,
+	backupLocation *stork_api.BackupLocation,
+) ([]runtime.Unstructured, error) {
+	if backupLocation.Location.EncryptionKey != "" {
+		return nil, fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := downloadResourceIndex(bucket, backupLocation, backup.Status.BackupPath)
+	if err != nil {
+		return nil, err
+	}
+	cache := newResourceObjectCache(bucket, backupLocation)
+	objects := make([]runtime.Unstructured, 0, len(entries))
+	for _, entry := range entries {
+		obj, err := cache.get(entry)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// getIncrementalDependents returns the backups that descend from the backup,
+// either through their resources or through the backups of their volumes,
+// parents before children. An error is returned if incremental backups are
+// in progress since they could pick the backup as their parent.
+func getIncrementalDependents(backup *stork_api.ApplicationBackup) ([]*stork_api.ApplicationBackup, error) {
+	backupList, err := storkops.Instance().ListApplicationBackups(backup.Namespace, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[string][]*stork_api.ApplicationBackup)
+	for i := range backupList.Items {
+		other := &backupList.Items[i]
+		if other.Name == backup.Name {
+			continue
+		}
+		if other.Spec.Incremental &&
+			other.Spec.BackupLocation == backup.Spec.BackupLocation &&
+			!isBackupFinished(other) {
+			return nil, fmt.Errorf("incremental backup %v is in progress", other.Name)
+		}
+		parents := make(map[string]bool)
+		if other.Status.ParentBackup != "" &&
+			other.Status.ResourcesFormat == stork_api.ApplicationBackupResourcesFormatIncremental {
+			parents[other.Status.ParentBackup] = true
+		}
+		for _, vInfo := range other.Status.Volumes {
+			if vInfo.ParentBackup != "" {
+				parents[vInfo.ParentBackup] = true
+			}
+		}
+		for parent := range parents {
+			children[parent] = append(children[parent], other)
+		}
+	}
+
+	dependents := make([]*stork_api.ApplicationBackup, 0)
+	visited := map[string]bool{backup.Name: true}
+	queue := []string{backup.Name}
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, child := range children[name] {
+			if visited[child.Name] {
+				continue
+			}
+			visited[child.Name] = true
+			dependents = append(dependents, child)
+			queue = append(queue, child.Name)
+		}
+	}
+	return dependents, nil
+}
+
+// rebaseIncrementalDependents rebases the incremental backups that descend
+// from the backup onto its parents so that the backup can be deleted. The
+// resources of the backup that they reference are copied to them first.
+// The children of the backup are then re-parented to the parents of the
+// backup, for their resources and for the backups of their volumes. The
+// drivers keep the data of volume backups that others are incremental to, so
+// only the name of the parent backup is updated for volumes.
+func (a *ApplicationBackupController) rebaseIncrementalDependents(
+	backup *stork_api.ApplicationBackup,
+	dependents []*stork_api.ApplicationBackup,
+) error {
+	if len(dependents) == 0 {
+		return nil
+	}
+	if backup.Status.ResourcesFormat == stork_api.ApplicationBackupResourcesFormatIncremental &&
+		backup.Status.BackupPath != "" {
+		// The resources can't be copied if the backup location was deleted,
+		// they can't be reached from the dependents anymore either
+		backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return err
+		}
+		if err == nil {
+			bucket, err := objectstore.GetBucket(backupLocation)
+			if err != nil {
+				return err
+			}
+			if err := copyIncrementalResources(backup, dependents, bucket, backupLocation); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Re-parent the children only once the resources have been copied to
+	// all the dependents, so that the dependents can still be found from the
+	// backup if the copy is retried
+	volumeParents := make(map[string]*stork_api.ApplicationBackupVolumeInfo)
+	for _, vInfo := range backup.Status.Volumes {
+		volumeParents[vInfo.PersistentVolumeClaimUID] = vInfo
+	}
+	for _, dependent := range dependents {
+		updated := false
+		if dependent.Status.ParentBackup == backup.Name {
+			dependent.Status.ParentBackup = backup.Status.ParentBackup
+			updated = true
+		}
+		for _, vInfo := range dependent.Status.Volumes {
+			if vInfo.ParentBackup != backup.Name {
+				continue
+			}
+			vInfo.ParentBackup = ""
+			if parent, ok := volumeParents[vInfo.PersistentVolumeClaimUID]; ok {
+				vInfo.ParentBackup = parent.ParentBackup
+			}
+			updated = true
+		}
+		if !updated {
+			continue
+		}
+		if err := a.client.Update(context.TODO(), dependent); err != nil {
+			return err
+		}
+		if err := a.uploadMetadata(dependent); err != nil {
+			return err
+		}
+		log.ApplicationBackupLog(dependent).Infof("Rebased from backup %v being deleted", backup.Name)
+	}
+	return nil
+}
+
+// copyIncrementalResources copies the resources of the backup that are
+// referenced by its incremental dependents to those dependents
+func copyIncrementalResources(
+	backup *stork_api.ApplicationBackup,
+	dependents []*stork_api.ApplicationBackup,
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+) error {
+	cache := newResourceObjectCache(bucket, backupLocation)
+	prefix := backup.Status.BackupPath + "/"
+	for _, dependent := range dependents {
+		if dependent.Status.ResourcesFormat != stork_api.ApplicationBackupResourcesFormatIncremental {
+			continue
+		}
+		entries, err := downloadResourceIndex(bucket, backupLocation, dependent.Status.BackupPath)
+		if err != nil {
+			return fmt.Errorf("error downloading resource index for backup %v: %v", dependent.Name, err)
+		}
+		rebasedKey := filepath.Join(dependent.Status.BackupPath, rebasedResourcePrefix+string(backup.UID)+".json")
+		rebased := make([]runtime.Unstructured, 0)
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Object, prefix) {
+				continue
+			}
+			obj, err := cache.get(entry)
+			if err != nil {
+				return err
+			}
+			entry.Object = rebasedKey
+			entry.Index = len(rebased)
+			rebased = append(rebased, obj)
+		}
+		if len(rebased) == 0 {
+			continue
+		}
+
+		dataKey, err := objectstore.GetDataKey(backupLocation, dependent)
+		if err != nil {
+			return err
+		}
+		writer, err := newResourceStreamWriter(
+			context.TODO(),
+			bucket,
+			rebasedKey,
+			dependent.Spec.ResourceCompression,
+			dataKey,
+			backupLocation.Location.EncryptionV2Key,
+		)
+		if err != nil {
+			return err
+		}
+		for _, obj := range rebased {
+			if err := writer.Write(obj); err != nil {
+				writer.abort()
+				return err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		objectPath := dependent.Status.BackupPath
+		if _, err := objectstore.UpdateManifest(bucket, backupLocation, objectPath, writer.manifestObject(objectPath)); err != nil {
+			return err
+		}
+		if err := uploadResourceIndex(bucket, backupLocation, dependent, entries); err != nil {
+			return err
+		}
+		log.ApplicationBackupLog(dependent).Infof("Copied %v resources from backup %v being deleted",
+			len(rebased), backup.Name)
+	}
+	return nil
+}
+
+// deleteRebasedResources deletes the resources that were copied to the
+// backup from its deleted parents
+func deleteRebasedResources(bucket *blob.Bucket, objectPath string) error {
+	iterator := bucket.List(&blob.ListOptions{
+		Prefix: filepath.Join(objectPath, rebasedResourcePrefix),
+	})
+	for {
+		object, err := iterator.Next(context.TODO())
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := bucket.Delete(context.TODO(), object.Key); err != nil && gcerrors.Code(err) != gcerrors.NotFound {
+			return err
+		}
+	}
+}
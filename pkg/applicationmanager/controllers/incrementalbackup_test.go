@@ -0,0 +1,276 @@
+//go:build unittest
+// +build unittest
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const testBackupDriverName = "incremental-test"
+
+// testBackupDriver deletes volume backups right away
+type testBackupDriver struct {
+	volume.Driver
+}
+
+func (d *testBackupDriver) CancelBackup(*stork_api.ApplicationBackup) error {
+	return nil
+}
+
+func (d *testBackupDriver) DeleteBackup(*stork_api.ApplicationBackup) (bool, error) {
+	return true, nil
+}
+
+// storkOpsClient updates backups through storkops so that the controller and
+// the backups listed from storkops see the same objects
+type storkOpsClient struct {
+	runtimeclient.Client
+}
+
+func (c *storkOpsClient) Update(ctx context.Context, obj runtimeclient.Object, opts ...runtimeclient.UpdateOption) error {
+	backup, ok := obj.(*stork_api.ApplicationBackup)
+	if !ok {
+		return fmt.Errorf("unexpected object %T", obj)
+	}
+	_, err := storkops.Instance().UpdateApplicationBackup(backup)
+	return err
+}
+
+func newIncrementalTestController(t *testing.T) (*ApplicationBackupController, *stork_api.BackupLocation, *blob.Bucket) {
+	kubeClient := kubernetes.NewSimpleClientset()
+	core.SetInstance(core.New(kubeClient))
+	storkops.SetInstance(storkops.New(kubeClient, fakeclient.NewSimpleClientset(), nil))
+	require.NoError(t, volume.Register(testBackupDriverName, &testBackupDriver{}), "Error registering driver")
+
+	backupLocation := &stork_api.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "location", Namespace: "ns"},
+		Location: stork_api.BackupLocationItem{
+			Type: stork_api.BackupLocationNFS,
+			Path: "bucket",
+			NFSConfig: &stork_api.NFSConfig{
+				MountPath: t.TempDir(),
+			},
+		},
+	}
+	require.NoError(t, objectstore.CreateBucket(backupLocation), "Error creating bucket")
+	_, err := storkops.Instance().CreateBackupLocation(backupLocation)
+	require.NoError(t, err, "Error creating backuplocation")
+	bucket, err := objectstore.GetBucket(backupLocation)
+	require.NoError(t, err, "Error getting bucket")
+	t.Cleanup(func() { _ = bucket.Close() })
+
+	return &ApplicationBackupController{
+		client:   &storkOpsClient{},
+		recorder: record.NewFakeRecorder(100),
+	}, backupLocation, bucket
+}
+
+func newConfigMap(name, value string) runtime.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "app",
+		},
+		"data": map[string]interface{}{
+			"value": value,
+		},
+	}}
+}
+
+func getConfigMapValues(t *testing.T, objects []runtime.Unstructured) []string {
+	values := make([]string, 0)
+	for _, obj := range objects {
+		u := obj.(*unstructured.Unstructured)
+		value, _, err := unstructured.NestedString(u.Object, "data", "value")
+		require.NoError(t, err, "Error getting value of configmap")
+		values = append(values, u.GetName()+"="+value)
+	}
+	return values
+}
+
+// runIncrementalBackup uploads the resources for an incremental backup and
+// marks it as successful
+func runIncrementalBackup(
+	t *testing.T,
+	controller *ApplicationBackupController,
+	name string,
+	finished time.Time,
+	volumes []*stork_api.ApplicationBackupVolumeInfo,
+	objects ...runtime.Unstructured,
+) *stork_api.ApplicationBackup {
+	backup := &stork_api.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ns",
+			UID:       types.UID(name + "-uid"),
+		},
+		Spec: stork_api.ApplicationBackupSpec{
+			BackupLocation: "location",
+			Incremental:    true,
+			ReclaimPolicy:  stork_api.ApplicationBackupReclaimPolicyDelete,
+		},
+	}
+	require.NoError(t, controller.uploadIncrementalResources(backup, objects), "Error uploading resources for %v", name)
+	backup.Status.Status = stork_api.ApplicationBackupStatusSuccessful
+	backup.Status.ResourcesFormat = stork_api.ApplicationBackupResourcesFormatIncremental
+	backup.Status.BackupPath = GetObjectPath(backup)
+	backup.Status.FinishTimestamp = metav1.NewTime(finished)
+	backup.Status.Volumes = volumes
+	backup, err := storkops.Instance().CreateApplicationBackup(backup)
+	require.NoError(t, err, "Error creating backup %v", name)
+	return backup
+}
+
+func newVolumeInfo(backupID, parentBackup, parentBackupID string) []*stork_api.ApplicationBackupVolumeInfo {
+	return []*stork_api.ApplicationBackupVolumeInfo{
+		{
+			PersistentVolumeClaim:    "pvc",
+			PersistentVolumeClaimUID: "pvc-uid",
+			Namespace:                "app",
+			DriverName:               testBackupDriverName,
+			Status:                   stork_api.ApplicationBackupStatusSuccessful,
+			BackupID:                 backupID,
+			ParentBackup:             parentBackup,
+			ParentBackupID:           parentBackupID,
+		},
+	}
+}
+
+func deleteIncrementalBackup(t *testing.T, controller *ApplicationBackupController, name string) {
+	backup, err := storkops.Instance().GetApplicationBackup(name, "ns")
+	require.NoError(t, err, "Error getting backup %v", name)
+	deleted, err := controller.deleteBackup(backup)
+	require.NoError(t, err, "Error deleting backup %v", name)
+	require.True(t, deleted, "Backup %v should be deleted", name)
+	require.NoError(t, storkops.Instance().DeleteApplicationBackup(name, "ns"), "Error deleting backup %v", name)
+}
+
+func downloadBackupResources(t *testing.T, name string, backupLocation *stork_api.BackupLocation) []string {
+	backup, err := storkops.Instance().GetApplicationBackup(name, "ns")
+	require.NoError(t, err, "Error getting backup %v", name)
+	objects, err := DownloadIncrementalResources(backup, backupLocation)
+	require.NoError(t, err, "Error downloading resources for %v", name)
+	return getConfigMapValues(t, objects)
+}
+
+func TestIncrementalResourcesDedup(t *testing.T) {
+	controller, backupLocation, bucket := newIncrementalTestController(t)
+	now := time.Now()
+
+	first := runIncrementalBackup(t, controller, "first", now, nil,
+		newConfigMap("a", "1"), newConfigMap("b", "1"))
+	require.Empty(t, first.Status.ParentBackup, "First backup shouldn't have a parent")
+
+	second := runIncrementalBackup(t, controller, "second", now.Add(time.Minute), nil,
+		newConfigMap("a", "1"), newConfigMap("b", "2"), newConfigMap("c", "1"))
+	require.Equal(t, "first", second.Status.ParentBackup, "Unexpected parent for second backup")
+	uploaded, err := downloadResourceObject(bucket, backupLocation, filepath.Join(second.Status.BackupPath, resourceObjectName))
+	require.NoError(t, err, "Error downloading resources uploaded for second backup")
+	require.Equal(t, []string{"b=2", "c=1"}, getConfigMapValues(t, uploaded), "Only changed resources should be uploaded")
+
+	third := runIncrementalBackup(t, controller, "third", now.Add(2*time.Minute), nil,
+		newConfigMap("a", "1"), newConfigMap("b", "2"), newConfigMap("c", "1"))
+	require.Equal(t, "second", third.Status.ParentBackup, "Latest backup should be the parent")
+	uploaded, err = downloadResourceObject(bucket, backupLocation, filepath.Join(third.Status.BackupPath, resourceObjectName))
+	require.NoError(t, err, "Error downloading resources uploaded for third backup")
+	require.Empty(t, uploaded, "Unchanged resources shouldn't be uploaded")
+
+	require.Equal(t, []string{"a=1", "b=1"}, downloadBackupResources(t, "first", backupLocation))
+	require.Equal(t, []string{"a=1", "b=2", "c=1"}, downloadBackupResources(t, "second", backupLocation))
+	require.Equal(t, []string{"a=1", "b=2", "c=1"}, downloadBackupResources(t, "third", backupLocation))
+}
+
+func TestIncrementalRebaseMidChain(t *testing.T) {
+	controller, backupLocation, bucket := newIncrementalTestController(t)
+	now := time.Now()
+
+	runIncrementalBackup(t, controller, "first", now, newVolumeInfo("v1", "", ""),
+		newConfigMap("a", "1"), newConfigMap("b", "1"))
+	second := runIncrementalBackup(t, controller, "second", now.Add(time.Minute), newVolumeInfo("v2", "first", "v1"),
+		newConfigMap("a", "1"), newConfigMap("b", "2"), newConfigMap("c", "1"))
+	runIncrementalBackup(t, controller, "third", now.Add(2*time.Minute), newVolumeInfo("v3", "second", "v2"),
+		newConfigMap("a", "1"), newConfigMap("b", "2"), newConfigMap("c", "2"))
+
+	dependents, err := getIncrementalDependents(second)
+	require.NoError(t, err, "Error getting dependents")
+	require.Len(t, dependents, 1, "Expected the third backup to depend on the second one")
+
+	// Deleting the backup in the middle of the chain rebases the third
+	// backup onto the first one
+	deleteIncrementalBackup(t, controller, "second")
+	_, err = bucket.Attributes(context.TODO(), filepath.Join(second.Status.BackupPath, resourceIndexObjectName))
+	require.Error(t, err, "Resource index of deleted backup should be deleted")
+	_, err = bucket.Attributes(context.TODO(), filepath.Join(second.Status.BackupPath, resourceObjectName))
+	require.Error(t, err, "Resources of deleted backup should be deleted")
+
+	third, err := storkops.Instance().GetApplicationBackup("third", "ns")
+	require.NoError(t, err, "Error getting third backup")
+	require.Equal(t, "first", third.Status.ParentBackup, "Resources should be re-parented to the first backup")
+	require.Equal(t, "first", third.Status.Volumes[0].ParentBackup, "Volume should be re-parented to the first backup")
+	require.Equal(t, "v2", third.Status.Volumes[0].ParentBackupID, "Volume should still reference the data it was taken relative to")
+	require.Equal(t, []string{"a=1", "b=2", "c=2"}, downloadBackupResources(t, "third", backupLocation),
+		"Resources of the third backup should be intact")
+	rebased, err := downloadResourceObject(bucket, backupLocation,
+		filepath.Join(third.Status.BackupPath, rebasedResourcePrefix+string(second.UID)+".json"))
+	require.NoError(t, err, "Error downloading rebased resources")
+	require.Equal(t, []string{"b=2"}, getConfigMapValues(t, rebased), "Only referenced resources should be copied")
+
+	// Deleting the root of the chain copies the rest of the resources
+	deleteIncrementalBackup(t, controller, "first")
+	third, err = storkops.Instance().GetApplicationBackup("third", "ns")
+	require.NoError(t, err, "Error getting third backup")
+	require.Empty(t, third.Status.ParentBackup, "Third backup shouldn't have a parent anymore")
+	require.Empty(t, third.Status.Volumes[0].ParentBackup, "Volume shouldn't have a parent backup anymore")
+	require.Equal(t, []string{"a=1", "b=2", "c=2"}, downloadBackupResources(t, "third", backupLocation),
+		"Resources of the third backup should be intact")
+
+	deleteIncrementalBackup(t, controller, "third")
+	iterator := bucket.List(&blob.ListOptions{Prefix: filepath.Join(third.Status.BackupPath, rebasedResourcePrefix)})
+	_, err = iterator.Next(context.TODO())
+	require.Error(t, err, "Rebased resources should be deleted with the backup")
+}
+
+func TestIncrementalDeleteWithBackupInProgress(t *testing.T) {
+	controller, _, _ := newIncrementalTestController(t)
+	runIncrementalBackup(t, controller, "first", time.Now(), nil, newConfigMap("a", "1"))
+	_, err := storkops.Instance().CreateApplicationBackup(&stork_api.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: "ns"},
+		Spec: stork_api.ApplicationBackupSpec{
+			BackupLocation: "location",
+			Incremental:    true,
+		},
+		Status: stork_api.ApplicationBackupStatus{
+			Status: stork_api.ApplicationBackupStatusInProgress,
+		},
+	})
+	require.NoError(t, err, "Error creating backup in progress")
+
+	backup, err := storkops.Instance().GetApplicationBackup("first", "ns")
+	require.NoError(t, err, "Error getting backup")
+	deleted, err := controller.deleteBackup(backup)
+	require.Error(t, err, "Backup shouldn't be deleted while an incremental backup is in progress")
+	require.False(t, deleted, "Backup shouldn't be deleted while an incremental backup is in progress")
+}
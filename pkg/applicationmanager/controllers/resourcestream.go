@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"gocloud.dev/blob"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// resourceStreamWriter encodes objects as a JSON array and streams them
+// through the compressor and encryptor into the bucket writer, so that the
+// whole serialized backup is never held in memory
+type resourceStreamWriter struct {
+	stream *objectstore.StreamWriter
+	out    *bufio.Writer
+	count  int
+}
+
+func newResourceStreamWriter(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	key string,
+	compression stork_api.ApplicationBackupCompressionType,
+	dataKey *crypto.DataKey,
+	encryptionKey string,
+) (*resourceStreamWriter, error) {
+	stream, err := objectstore.NewStreamWriter(ctx, bucket, key, compression, dataKey, encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	s := &resourceStreamWriter{
+		stream: stream,
+		out:    bufio.NewWriter(stream),
+	}
+	if _, err := s.out.WriteString("["); err != nil {
+		s.abort()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write encodes one object and appends it to the stream
+func (s *resourceStreamWriter) Write(obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if s.count > 0 {
+		if _, err := s.out.WriteString(",\n"); err != nil {
+			return err
+		}
+	}
+	if _, err := s.out.Write(data); err != nil {
+		return err
+	}
+	s.count++
+	return nil
+}
+
+// Close terminates the JSON array and flushes the stream. The object is only
+// committed to the bucket if all of it was written
+func (s *resourceStreamWriter) Close() error {
+	if _, err := s.out.WriteString("]"); err != nil {
+		s.abort()
+		return err
+	}
+	if err := s.out.Flush(); err != nil {
+		s.abort()
+		return err
+	}
+	return s.stream.Close()
+}
+
+// manifestObject returns the manifest entry for the object relative to the
+// backup path once the writer has been closed
+func (s *resourceStreamWriter) manifestObject(objectPath string) objectstore.ManifestObject {
+	return s.stream.ManifestObject(objectPath)
+}
+
+// abort discards the object being written
+func (s *resourceStreamWriter) abort() {
+	s.stream.Abort()
+}
+
+// readResourceStream decodes the objects written by resourceStreamWriter
+// from the decrypted and decompressed stream
+func readResourceStream(r io.Reader) ([]runtime.Unstructured, error) {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	if token, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("invalid start of resource stream: %v", token)
+	}
+	objects := make([]runtime.Unstructured, 0)
+	for decoder.More() {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(obj); err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// DownloadResourceStream downloads the resources for a backup that was
+// uploaded with the ApplicationBackupResourcesFormatStream format
+func DownloadResourceStream(
+	backup *stork_api.ApplicationBackup,
+	backupLocation *stork_api.BackupLocation,
+) ([]runtime.Unstructured, error) {
+	if backupLocation.Location.EncryptionKey != "" {
+		return nil, fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := bucket.NewReader(context.TODO(), filepath.Join(backup.Status.BackupPath, resourceObjectName), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close() // nolint: errcheck
+	stream, err := objectstore.NewStreamReader(reader, backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	return readResourceStream(stream)
+}
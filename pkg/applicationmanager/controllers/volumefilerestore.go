@@ -0,0 +1,736 @@
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/pkg/apis/stork"
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/controllers"
+	"github.com/libopenstorage/stork/pkg/k8sutils"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/objectstore"
+	"github.com/libopenstorage/stork/pkg/rule"
+	"github.com/libopenstorage/stork/pkg/version"
+	"github.com/portworx/sched-ops/k8s/apiextensions"
+	"github.com/portworx/sched-ops/k8s/core"
+	schederrors "github.com/portworx/sched-ops/k8s/errors"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/sirupsen/logrus"
+	"gocloud.dev/blob"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	fileRestorePrefix          = "filerestore"
+	fileRestoreUIDLabel        = "stork.libopenstorage.org/volumeFileRestoreUID"
+	fileRestoreBackupMountPath = "/backup"
+	fileRestoreTargetMountPath = "/target"
+	// fileRestoreExportObjectName is the name of the tarball of the files
+	// exported to the backup location. It is suffixed with
+	// fileRestoreEncryptedSuffix if the backup location is encrypted
+	fileRestoreExportObjectName = "files.tar.gz"
+	fileRestoreEncryptedSuffix  = ".enc"
+
+	fileRestoreImageRegistryEnvVar       = "FILE-RESTORE-IMAGE-REGISTRY"
+	fileRestoreImageRegistrySecretEnvVar = "FILE-RESTORE-IMAGE-REGISTRY-SECRET"
+	defaultFileRestoreImage              = "busybox:1.36"
+	// FileRestoreImageOverrideAnnotation can be set on a VolumeFileRestore to
+	// override the image used to copy the files
+	FileRestoreImageOverrideAnnotation = "stork.libopenstorage.org/file-restore-image"
+	// FileRestoreImageSecretOverrideAnnotation can be set on a
+	// VolumeFileRestore to override the secret used to pull the image
+	FileRestoreImageSecretOverrideAnnotation = "stork.libopenstorage.org/file-restore-image-secret"
+)
+
+// Annotations that bind a PVC to its volume which need to be removed before
+// a PVC from a backup can be provisioned again
+var fileRestorePVCBindAnnotations = []string{
+	"pv.kubernetes.io/bind-completed",
+	"pv.kubernetes.io/bound-by-controller",
+	"volume.beta.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/selected-node",
+}
+
+// NewVolumeFileRestore creates a new instance of VolumeFileRestoreController.
+func NewVolumeFileRestore(mgr manager.Manager, r record.EventRecorder) *VolumeFileRestoreController {
+	return &VolumeFileRestoreController{
+		client:    mgr.GetClient(),
+		recorder:  r,
+		execInPod: k8sutils.ExecInPod,
+	}
+}
+
+// VolumeFileRestoreController reconciles volumefilerestore objects
+type VolumeFileRestoreController struct {
+	client runtimeclient.Client
+
+	recorder record.EventRecorder
+	// execInPod runs the commands that copy or export the files in the
+	// helper pod
+	execInPod func(cmd []string, podName, container, namespace string, stdout, stderr io.Writer) error
+}
+
+// Init Initialize the volume file restore controller
+func (f *VolumeFileRestoreController) Init(mgr manager.Manager) error {
+	if err := f.createCRD(); err != nil {
+		return err
+	}
+	return controllers.RegisterTo(mgr, "volume-file-restore-controller", f, &storkapi.VolumeFileRestore{})
+}
+
+// Reconcile updates for VolumeFileRestore objects.
+func (f *VolumeFileRestoreController) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	logrus.Tracef("Reconciling VolumeFileRestore %s/%s", request.Namespace, request.Name)
+
+	fileRestore := &storkapi.VolumeFileRestore{}
+	err := f.client.Get(context.TODO(), request.NamespacedName, fileRestore)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{RequeueAfter: controllers.DefaultRequeueError}, err
+	}
+
+	if !controllers.ContainsFinalizer(fileRestore, controllers.FinalizerCleanup) {
+		controllers.SetFinalizer(fileRestore, controllers.FinalizerCleanup)
+		return reconcile.Result{Requeue: true}, f.client.Update(context.TODO(), fileRestore)
+	}
+
+	if err = f.handle(context.TODO(), fileRestore); err != nil {
+		logrus.Errorf("%s: %s/%s: %s", reflect.TypeOf(f), fileRestore.Namespace, fileRestore.Name, err)
+		return reconcile.Result{RequeueAfter: controllers.DefaultRequeueError}, err
+	}
+
+	return reconcile.Result{RequeueAfter: controllers.DefaultRequeue}, nil
+}
+
+// Handle updates for VolumeFileRestore objects
+func (f *VolumeFileRestoreController) handle(ctx context.Context, fileRestore *storkapi.VolumeFileRestore) error {
+	if fileRestore.DeletionTimestamp != nil {
+		if controllers.ContainsFinalizer(fileRestore, controllers.FinalizerCleanup) {
+			f.cleanup(fileRestore)
+		}
+		if fileRestore.GetFinalizers() != nil {
+			controllers.RemoveFinalizer(fileRestore, controllers.FinalizerCleanup)
+			return f.client.Update(ctx, fileRestore)
+		}
+		return nil
+	}
+
+	switch fileRestore.Status.Stage {
+	case storkapi.VolumeFileRestoreStageInitial:
+		return f.startBackupVolumeMount(ctx, fileRestore)
+	case storkapi.VolumeFileRestoreStageMount:
+		return f.checkBackupVolumeMount(ctx, fileRestore)
+	case storkapi.VolumeFileRestoreStageCopy:
+		return f.restoreFiles(ctx, fileRestore)
+	}
+	return nil
+}
+
+// validateFileRestorePath checks that the path is relative to the root of a
+// volume and doesn't point outside of it
+func validateFileRestorePath(path string) error {
+	if path == "" || filepath.IsAbs(path) {
+		return fmt.Errorf("path %q should be relative to the root of the volume", path)
+	}
+	cleanPath := filepath.Clean(path)
+	if cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+		return fmt.Errorf("path %q can't be outside the volume", path)
+	}
+	return nil
+}
+
+func (f *VolumeFileRestoreController) validateSpec(fileRestore *storkapi.VolumeFileRestore) error {
+	if fileRestore.Spec.BackupName == "" {
+		return fmt.Errorf("backupName should be specified")
+	}
+	if fileRestore.Spec.SourcePVC == "" {
+		return fmt.Errorf("sourcePVC should be specified")
+	}
+	if len(fileRestore.Spec.Paths) == 0 {
+		return fmt.Errorf("at least one path should be specified")
+	}
+	for _, path := range fileRestore.Spec.Paths {
+		if err := validateFileRestorePath(path); err != nil {
+			return err
+		}
+	}
+	if fileRestore.Spec.Export {
+		if fileRestore.Spec.TargetPVC != "" {
+			return fmt.Errorf("targetPVC can't be specified when exporting the files")
+		}
+		return nil
+	}
+	if fileRestore.Spec.TargetPVC == "" {
+		return fmt.Errorf("targetPVC should be specified if the files aren't exported")
+	}
+	if fileRestore.Spec.TargetPath != "" {
+		return validateFileRestorePath(fileRestore.Spec.TargetPath)
+	}
+	return nil
+}
+
+// getBackupVolumeInfo returns the backup of the PVC from the spec
+func getBackupVolumeInfo(
+	fileRestore *storkapi.VolumeFileRestore,
+	backup *storkapi.ApplicationBackup,
+) (*storkapi.ApplicationBackupVolumeInfo, error) {
+	sourceNamespace := fileRestore.Spec.SourceNamespace
+	if sourceNamespace == "" {
+		sourceNamespace = fileRestore.Namespace
+	}
+	for _, volumeInfo := range backup.Status.Volumes {
+		if volumeInfo.Namespace != sourceNamespace || volumeInfo.PersistentVolumeClaim != fileRestore.Spec.SourcePVC {
+			continue
+		}
+		if volumeInfo.Status != storkapi.ApplicationBackupStatusSuccessful {
+			return nil, fmt.Errorf("backup of pvc %v/%v wasn't successful", sourceNamespace, fileRestore.Spec.SourcePVC)
+		}
+		return volumeInfo, nil
+	}
+	return nil, fmt.Errorf("pvc %v/%v not found in backup %v", sourceNamespace, fileRestore.Spec.SourcePVC, backup.Name)
+}
+
+// getBackupVolumeMountPVC returns the spec of the PVC to provision with the
+// contents of the backup. It is based on the spec of the PVC that was backed
+// up
+func getBackupVolumeMountPVC(
+	fileRestore *storkapi.VolumeFileRestore,
+	backup *storkapi.ApplicationBackup,
+	volumeInfo *storkapi.ApplicationBackupVolumeInfo,
+) (*v1.PersistentVolumeClaim, error) {
+	objects, err := downloadResourceObjects(backup, backup.Spec.BackupLocation, backup.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading resources for backup %v: %v", backup.Name, err)
+	}
+	var pvc *v1.PersistentVolumeClaim
+	for _, o := range objects {
+		objectType, err := meta.TypeAccessor(o)
+		if err != nil {
+			return nil, err
+		}
+		metadata, err := meta.Accessor(o)
+		if err != nil {
+			return nil, err
+		}
+		if objectType.GetKind() != "PersistentVolumeClaim" ||
+			metadata.GetName() != volumeInfo.PersistentVolumeClaim ||
+			metadata.GetNamespace() != volumeInfo.Namespace {
+			continue
+		}
+		pvc = &v1.PersistentVolumeClaim{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.UnstructuredContent(), pvc); err != nil {
+			return nil, err
+		}
+		break
+	}
+	if pvc == nil {
+		return nil, fmt.Errorf("pvc %v/%v not found in resources of backup %v", volumeInfo.Namespace, volumeInfo.PersistentVolumeClaim, backup.Name)
+	}
+
+	pvc.ObjectMeta = metav1.ObjectMeta{
+		Name:        getFileRestoreResourceName(fileRestore),
+		Namespace:   fileRestore.Namespace,
+		Labels:      getFileRestoreLabels(fileRestore),
+		Annotations: pvc.Annotations,
+	}
+	for _, annotation := range fileRestorePVCBindAnnotations {
+		delete(pvc.Annotations, annotation)
+	}
+	pvc.Spec.VolumeName = ""
+	pvc.Spec.DataSource = nil
+	pvc.Status = v1.PersistentVolumeClaimStatus{}
+	return pvc, nil
+}
+
+func getFileRestoreResourceName(fileRestore *storkapi.VolumeFileRestore) string {
+	return fmt.Sprintf("%s-%s", fileRestorePrefix, fileRestore.UID)
+}
+
+func getFileRestoreLabels(fileRestore *storkapi.VolumeFileRestore) map[string]string {
+	return map[string]string{
+		fileRestoreUIDLabel: string(fileRestore.UID),
+	}
+}
+
+// startBackupVolumeMount starts provisioning a PVC with the contents of the
+// backup of the volume
+func (f *VolumeFileRestoreController) startBackupVolumeMount(ctx context.Context, fileRestore *storkapi.VolumeFileRestore) error {
+	if err := f.validateSpec(fileRestore); err != nil {
+		return f.fail(ctx, fileRestore, fmt.Sprintf("Invalid spec: %v", err))
+	}
+	backup, err := storkops.Instance().GetApplicationBackup(fileRestore.Spec.BackupName, fileRestore.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return f.fail(ctx, fileRestore, fmt.Sprintf("Backup %v not found", fileRestore.Spec.BackupName))
+		}
+		return err
+	}
+	if backup.Status.Status != storkapi.ApplicationBackupStatusSuccessful &&
+		backup.Status.Status != storkapi.ApplicationBackupStatusPartialSuccess {
+		return f.fail(ctx, fileRestore, fmt.Sprintf("Backup %v is not successful", backup.Name))
+	}
+	volumeInfo, err := getBackupVolumeInfo(fileRestore, backup)
+	if err != nil {
+		return f.fail(ctx, fileRestore, err.Error())
+	}
+	driver, err := volume.Get(volumeInfo.DriverName)
+	if err != nil {
+		return f.fail(ctx, fileRestore, fmt.Sprintf("Error getting driver %v: %v", volumeInfo.DriverName, err))
+	}
+	pvc, err := getBackupVolumeMountPVC(fileRestore, backup, volumeInfo)
+	if err != nil {
+		return f.fail(ctx, fileRestore, err.Error())
+	}
+
+	// Record the driver and PVC before starting so that they are cleaned up
+	// even if starting fails
+	fileRestore.Status.DriverName = volumeInfo.DriverName
+	fileRestore.Status.MountPVC = pvc.Name
+	if err := driver.StartBackupVolumeMount(fileRestore, backup, volumeInfo, pvc); err != nil {
+		return f.fail(ctx, fileRestore, fmt.Sprintf("Error provisioning volume from backup: %v", err))
+	}
+	fileRestore.Status.Stage = storkapi.VolumeFileRestoreStageMount
+	fileRestore.Status.Status = storkapi.VolumeFileRestoreStatusInProgress
+	fileRestore.Status.Reason = fmt.Sprintf("Provisioning volume from the backup of pvc %v", volumeInfo.PersistentVolumeClaim)
+	fileRestore.Status.LastUpdateTimestamp = metav1.Now()
+	f.recorder.Event(fileRestore,
+		v1.EventTypeNormal,
+		string(storkapi.VolumeFileRestoreStatusInProgress),
+		fileRestore.Status.Reason)
+	return f.client.Update(ctx, fileRestore)
+}
+
+// checkBackupVolumeMount waits for the PVC to be provisioned and then starts
+// the pod that mounts it
+func (f *VolumeFileRestoreController) checkBackupVolumeMount(ctx context.Context, fileRestore *storkapi.VolumeFileRestore) error {
+	driver, err := volume.Get(fileRestore.Status.DriverName)
+	if err != nil {
+		return err
+	}
+	ready, err := driver.GetBackupVolumeMountStatus(fileRestore)
+	if err != nil {
+		return f.fail(ctx, fileRestore, fmt.Sprintf("Error provisioning volume from backup: %v", err))
+	}
+	if !ready {
+		return nil
+	}
+	pod, err := f.getHelperPod(fileRestore)
+	if err != nil {
+		return err
+	}
+	if _, err := core.Instance().CreatePod(pod); err != nil && !errors.IsAlreadyExists(err) {
+		return f.fail(ctx, fileRestore, fmt.Sprintf("Error creating pod to restore files: %v", err))
+	}
+	fileRestore.Status.Stage = storkapi.VolumeFileRestoreStageCopy
+	if fileRestore.Spec.Export {
+		fileRestore.Status.Reason = "Exporting files to the backup location"
+	} else {
+		fileRestore.Status.Reason = fmt.Sprintf("Copying files to pvc %v", fileRestore.Spec.TargetPVC)
+	}
+	fileRestore.Status.LastUpdateTimestamp = metav1.Now()
+	return f.client.Update(ctx, fileRestore)
+}
+
+// getHelperImage returns the image and pull secret for the pod used to copy
+// the files. The annotations on the file restore take priority over the
+// environment variables, which take priority over the registry of the stork
+// deployment
+func getHelperImage(fileRestore *storkapi.VolumeFileRestore) (string, string, error) {
+	var image, imageSecret string
+	if registry := os.Getenv(fileRestoreImageRegistryEnvVar); registry != "" {
+		image = registry + "/" + defaultFileRestoreImage
+		imageSecret = os.Getenv(fileRestoreImageRegistrySecretEnvVar)
+	} else {
+		storkPodNs, err := k8sutils.GetStorkPodNamespace()
+		if err != nil {
+			return "", "", err
+		}
+		registry, registrySecret, err := k8sutils.GetImageRegistryFromDeployment(k8sutils.StorkDeploymentName, storkPodNs)
+		if err != nil {
+			return "", "", err
+		}
+		image = defaultFileRestoreImage
+		if registry != "" {
+			image = registry + "/" + defaultFileRestoreImage
+		}
+		imageSecret = registrySecret
+	}
+	if override := fileRestore.Annotations[FileRestoreImageOverrideAnnotation]; override != "" {
+		image = override
+	}
+	if override := fileRestore.Annotations[FileRestoreImageSecretOverrideAnnotation]; override != "" {
+		imageSecret = override
+	}
+	return image, imageSecret, nil
+}
+
+// getHelperPod returns the spec of the pod that mounts the PVC provisioned
+// from the backup and the target PVC. The files are copied by running
+// commands in the pod
+func (f *VolumeFileRestoreController) getHelperPod(fileRestore *storkapi.VolumeFileRestore) (*v1.Pod, error) {
+	image, imageSecret, err := getHelperImage(fileRestore)
+	if err != nil {
+		return nil, err
+	}
+	var gracePeriod int64
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getFileRestoreResourceName(fileRestore),
+			Namespace: fileRestore.Namespace,
+			Labels:    getFileRestoreLabels(fileRestore),
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy:                 v1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: &gracePeriod,
+			ImagePullSecrets:              rule.ToImagePullSecret(imageSecret),
+			Containers: []v1.Container{
+				{
+					Name:    fileRestorePrefix,
+					Image:   image,
+					Command: []string{"sh", "-c", "while true; do sleep 60; done"},
+					VolumeMounts: []v1.VolumeMount{
+						{
+							Name:      "backup",
+							MountPath: fileRestoreBackupMountPath,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name: "backup",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+							ClaimName: fileRestore.Status.MountPVC,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+		},
+	}
+	if fileRestore.Spec.Export {
+		return pod, nil
+	}
+
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, v1.VolumeMount{
+		Name:      "target",
+		MountPath: fileRestoreTargetMountPath,
+	})
+	pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+		Name: "target",
+		VolumeSource: v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: fileRestore.Spec.TargetPVC,
+			},
+		},
+	})
+	// The target PVC could be ReadWriteOnce, so run on the same node as any
+	// pod that is already using it
+	pods, err := core.Instance().GetPodsUsingPVC(fileRestore.Spec.TargetPVC, fileRestore.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pods {
+		if p.Spec.NodeName == "" {
+			continue
+		}
+		pod.Spec.Affinity = &v1.Affinity{
+			NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{
+						{
+							MatchFields: []v1.NodeSelectorRequirement{
+								{
+									Key:      "metadata.name",
+									Operator: v1.NodeSelectorOpIn,
+									Values:   []string{p.Spec.NodeName},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		break
+	}
+	return pod, nil
+}
+
+// restoreFiles copies or exports the files once the helper pod is running
+func (f *VolumeFileRestoreController) restoreFiles(ctx context.Context, fileRestore *storkapi.VolumeFileRestore) error {
+	pod, err := core.Instance().GetPodByName(getFileRestoreResourceName(fileRestore), fileRestore.Namespace)
+	if err != nil {
+		if err == schederrors.ErrPodsNotFound {
+			return f.fail(ctx, fileRestore, "Pod to restore files was deleted")
+		}
+		return err
+	}
+	switch pod.Status.Phase {
+	case v1.PodRunning:
+	case v1.PodPending:
+		return nil
+	default:
+		return f.fail(ctx, fileRestore, fmt.Sprintf("Pod to restore files is in %v phase", pod.Status.Phase))
+	}
+
+	paths := make([]string, 0, len(fileRestore.Spec.Paths))
+	for _, path := range fileRestore.Spec.Paths {
+		paths = append(paths, filepath.Clean(path))
+	}
+	if fileRestore.Spec.Export {
+		exportPath, err := f.exportFiles(fileRestore, pod, paths)
+		if err != nil {
+			return f.fail(ctx, fileRestore, fmt.Sprintf("Error exporting files: %v", err))
+		}
+		fileRestore.Status.ExportPath = exportPath
+		fileRestore.Status.Reason = fmt.Sprintf("Files exported to %v", exportPath)
+	} else {
+		if err := f.copyFiles(fileRestore, pod, paths); err != nil {
+			return f.fail(ctx, fileRestore, fmt.Sprintf("Error copying files: %v", err))
+		}
+		fileRestore.Status.Reason = fmt.Sprintf("Files copied to pvc %v", fileRestore.Spec.TargetPVC)
+	}
+
+	f.cleanup(fileRestore)
+	fileRestore.Status.Stage = storkapi.VolumeFileRestoreStageFinal
+	fileRestore.Status.Status = storkapi.VolumeFileRestoreStatusSuccessful
+	fileRestore.Status.FinishTimestamp = metav1.Now()
+	fileRestore.Status.LastUpdateTimestamp = metav1.Now()
+	f.recorder.Event(fileRestore,
+		v1.EventTypeNormal,
+		string(storkapi.VolumeFileRestoreStatusSuccessful),
+		fileRestore.Status.Reason)
+	log.VolumeFileRestoreLog(fileRestore).Info(fileRestore.Status.Reason)
+	return f.client.Update(ctx, fileRestore)
+}
+
+// getCopyFilesCommand returns the command that copies the paths from the PVC
+// provisioned from the backup to the target PVC with a tar pipeline, which
+// preserves the directory structure, permissions and links. The target
+// directory and the paths are passed as arguments to the script so that they
+// are never interpreted by the shell
+func getCopyFilesCommand(fileRestore *storkapi.VolumeFileRestore, paths []string) []string {
+	targetDir := filepath.Join(fileRestoreTargetMountPath, fileRestore.Spec.TargetPath)
+	script := fmt.Sprintf(`set -eo pipefail; mkdir -p "$0"; tar -C %s -cf - -- "$@" | tar -C "$0" -xf -`, fileRestoreBackupMountPath)
+	return append([]string{"sh", "-c", script, targetDir}, paths...)
+}
+
+// getExportFilesCommand returns the command that writes a tarball of the
+// paths in the PVC provisioned from the backup to stdout
+func getExportFilesCommand(paths []string) []string {
+	return append([]string{"tar", "-C", fileRestoreBackupMountPath, "-cf", "-", "--"}, paths...)
+}
+
+// copyFiles copies the paths to the target PVC in the helper pod
+func (f *VolumeFileRestoreController) copyFiles(fileRestore *storkapi.VolumeFileRestore, pod *v1.Pod, paths []string) error {
+	var stderr bytes.Buffer
+	if err := f.execInPod(getCopyFilesCommand(fileRestore, paths), pod.Name, "", pod.Namespace, nil, &stderr); err != nil {
+		return fmt.Errorf("%v: %v", err, stderr.String())
+	}
+	return nil
+}
+
+// fileExportWriter writes the tarball of the files to the backup location
+type fileExportWriter interface {
+	io.Writer
+	Close() error
+	Abort()
+}
+
+// tarballWriter compresses the tarball and streams it to an object in the
+// bucket
+type tarballWriter struct {
+	cancel       context.CancelFunc
+	bucketWriter *blob.Writer
+	compressor   *gzip.Writer
+}
+
+func newTarballWriter(bucket *blob.Bucket, key string) (*tarballWriter, error) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	bucketWriter, err := bucket.NewWriter(ctx, key, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &tarballWriter{
+		cancel:       cancel,
+		bucketWriter: bucketWriter,
+		compressor:   gzip.NewWriter(bucketWriter),
+	}, nil
+}
+
+func (t *tarballWriter) Write(p []byte) (int, error) {
+	return t.compressor.Write(p)
+}
+
+// Close flushes the compressor. The object is only committed to the bucket
+// if that succeeds
+func (t *tarballWriter) Close() error {
+	if err := t.compressor.Close(); err != nil {
+		t.Abort()
+		return err
+	}
+	defer t.cancel()
+	return t.bucketWriter.Close()
+}
+
+// Abort discards the object being written
+func (t *tarballWriter) Abort() {
+	t.cancel()
+	_ = t.bucketWriter.Close()
+}
+
+// exportFiles streams a tarball of the paths in the PVC provisioned from the
+// backup to the backup location of the backup. The tarball is written as a
+// plain gzipped tarball so that it can be downloaded directly, unless the
+// backup location is encrypted in which case it is written as an encrypted
+// stream that can be read with objectstore.NewStreamReader
+func (f *VolumeFileRestoreController) exportFiles(
+	fileRestore *storkapi.VolumeFileRestore,
+	pod *v1.Pod,
+	paths []string,
+) (string, error) {
+	backup, err := storkops.Instance().GetApplicationBackup(fileRestore.Spec.BackupName, fileRestore.Namespace)
+	if err != nil {
+		return "", err
+	}
+	backupLocation, err := storkops.Instance().GetBackupLocation(backup.Spec.BackupLocation, backup.Namespace)
+	if err != nil {
+		return "", err
+	}
+	if backupLocation.Location.EncryptionKey != "" {
+		return "", fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
+	}
+	bucket, err := objectstore.GetBucket(backupLocation)
+	if err != nil {
+		return "", err
+	}
+	// The wrapped data key is stored in the stream so a new one can be used
+	// for every export
+	dataKey, err := objectstore.GetDataKey(backupLocation, nil)
+	if err != nil {
+		return "", err
+	}
+
+	exportPath := filepath.Join(fileRestore.Namespace, fileRestorePrefix, fileRestore.Name, string(fileRestore.UID), fileRestoreExportObjectName)
+	var writer fileExportWriter
+	if dataKey == nil && backupLocation.Location.EncryptionV2Key == "" {
+		writer, err = newTarballWriter(bucket, exportPath)
+	} else {
+		exportPath += fileRestoreEncryptedSuffix
+		writer, err = objectstore.NewStreamWriter(
+			context.TODO(),
+			bucket,
+			exportPath,
+			storkapi.ApplicationBackupCompressionGzip,
+			dataKey,
+			backupLocation.Location.EncryptionV2Key,
+		)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var stderr bytes.Buffer
+	if err := f.execInPod(getExportFilesCommand(paths), pod.Name, "", pod.Namespace, writer, &stderr); err != nil {
+		writer.Abort()
+		return "", fmt.Errorf("%v: %v", err, stderr.String())
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return exportPath, nil
+}
+
+// fail marks the file restore as failed and cleans up the resources created
+// for it
+func (f *VolumeFileRestoreController) fail(ctx context.Context, fileRestore *storkapi.VolumeFileRestore, reason string) error {
+	f.cleanup(fileRestore)
+	fileRestore.Status.Stage = storkapi.VolumeFileRestoreStageFinal
+	fileRestore.Status.Status = storkapi.VolumeFileRestoreStatusFailed
+	fileRestore.Status.Reason = reason
+	fileRestore.Status.FinishTimestamp = metav1.Now()
+	fileRestore.Status.LastUpdateTimestamp = metav1.Now()
+	f.recorder.Event(fileRestore,
+		v1.EventTypeWarning,
+		string(storkapi.VolumeFileRestoreStatusFailed),
+		reason)
+	log.VolumeFileRestoreLog(fileRestore).Error(reason)
+	return f.client.Update(ctx, fileRestore)
+}
+
+// cleanup deletes the helper pod, the PVC provisioned from the backup and any
+// resources created by the driver to provision it
+func (f *VolumeFileRestoreController) cleanup(fileRestore *storkapi.VolumeFileRestore) {
+	if fileRestore.Status.MountPVC == "" {
+		return
+	}
+	name := getFileRestoreResourceName(fileRestore)
+	if err := core.Instance().DeletePod(name, fileRestore.Namespace, true); err != nil && !errors.IsNotFound(err) {
+		log.VolumeFileRestoreLog(fileRestore).Warnf("Error deleting pod %v: %v", name, err)
+	}
+	if err := core.Instance().DeletePersistentVolumeClaim(fileRestore.Status.MountPVC, fileRestore.Namespace); err != nil && !errors.IsNotFound(err) {
+		log.VolumeFileRestoreLog(fileRestore).Warnf("Error deleting pvc %v: %v", fileRestore.Status.MountPVC, err)
+	}
+	driver, err := volume.Get(fileRestore.Status.DriverName)
+	if err != nil {
+		log.VolumeFileRestoreLog(fileRestore).Warnf("Error getting driver %v: %v", fileRestore.Status.DriverName, err)
+		return
+	}
+	if err := driver.CleanupBackupVolumeMount(fileRestore); err != nil {
+		log.VolumeFileRestoreLog(fileRestore).Warnf("Error cleaning up driver resources: %v", err)
+	}
+}
+
+func (f *VolumeFileRestoreController) createCRD() error {
+	resource := apiextensions.CustomResource{
+		Name:    storkapi.VolumeFileRestoreResourceName,
+		Plural:  storkapi.VolumeFileRestoreResourcePlural,
+		Group:   stork.GroupName,
+		Version: storkapi.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Kind:    reflect.TypeOf(storkapi.VolumeFileRestore{}).Name(),
+	}
+	ok, err := version.RequiresV1Registration()
+	if err != nil {
+		return err
+	}
+	if ok {
+		err := k8sutils.CreateCRD(resource)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		return apiextensions.Instance().ValidateCRD(resource.Plural+"."+resource.Group, validateCRDTimeout, validateCRDInterval)
+	}
+	err = apiextensions.Instance().CreateCRDV1beta1(resource)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return apiextensions.Instance().ValidateCRDV1beta1(resource, validateCRDTimeout, validateCRDInterval)
+}
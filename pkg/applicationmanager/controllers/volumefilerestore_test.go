@@ -0,0 +1,358 @@
+//go:build unittest
+// +build unittest
+
+package controllers
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/core"
+	schederrors "github.com/portworx/sched-ops/k8s/errors"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const fileRestoreTestDriverName = "filerestore-test"
+
+// fileRestoreTestDriver provisions the PVC from the backup once ready is set
+type fileRestoreTestDriver struct {
+	volume.Driver
+	ready     bool
+	pvc       *v1.PersistentVolumeClaim
+	cleanedUp bool
+}
+
+func (d *fileRestoreTestDriver) StartBackupVolumeMount(
+	fileRestore *storkapi.VolumeFileRestore,
+	backup *storkapi.ApplicationBackup,
+	volumeInfo *storkapi.ApplicationBackupVolumeInfo,
+	pvc *v1.PersistentVolumeClaim,
+) error {
+	d.pvc = pvc
+	_, err := core.Instance().CreatePersistentVolumeClaim(pvc)
+	return err
+}
+
+func (d *fileRestoreTestDriver) GetBackupVolumeMountStatus(*storkapi.VolumeFileRestore) (bool, error) {
+	return d.ready, nil
+}
+
+func (d *fileRestoreTestDriver) CleanupBackupVolumeMount(*storkapi.VolumeFileRestore) error {
+	d.cleanedUp = true
+	return nil
+}
+
+// fakeExec records the commands run in the helper pod and writes output to
+// stdout, or fails with stderr if set
+type fakeExec struct {
+	commands [][]string
+	output   string
+	stderr   string
+}
+
+func (e *fakeExec) exec(cmd []string, podName, container, namespace string, stdout, stderr io.Writer) error {
+	e.commands = append(e.commands, cmd)
+	if e.stderr != "" {
+		_, _ = stderr.Write([]byte(e.stderr))
+		return fmt.Errorf("command terminated with exit code 1")
+	}
+	if stdout != nil {
+		_, _ = stdout.Write([]byte(e.output))
+	}
+	return nil
+}
+
+func newPVCObject() runtime.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "PersistentVolumeClaim",
+		"metadata": map[string]interface{}{
+			"name":      "data",
+			"namespace": "app",
+			"annotations": map[string]interface{}{
+				"pv.kubernetes.io/bind-completed": "yes",
+				"custom":                          "value",
+			},
+		},
+		"spec": map[string]interface{}{
+			"storageClassName": "fast",
+			"volumeName":       "pv-data",
+			"accessModes":      []interface{}{"ReadWriteOnce"},
+		},
+	}}
+}
+
+func newFileRestoreTestController(t *testing.T, fileRestore *storkapi.VolumeFileRestore) (
+	*VolumeFileRestoreController, *fileRestoreTestDriver, *fakeExec, *blob.Bucket) {
+	backupController, _, bucket := newIncrementalTestController(t)
+	volumes := []*storkapi.ApplicationBackupVolumeInfo{
+		{
+			PersistentVolumeClaim: "data",
+			Namespace:             "app",
+			DriverName:            fileRestoreTestDriverName,
+			Status:                storkapi.ApplicationBackupStatusSuccessful,
+		},
+	}
+	runIncrementalBackup(t, backupController, "backup", time.Now(), volumes, newPVCObject())
+	driver := &fileRestoreTestDriver{}
+	require.NoError(t, volume.Register(fileRestoreTestDriverName, driver), "Error registering driver")
+	t.Setenv(fileRestoreImageRegistryEnvVar, "registry.example.com")
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, storkapi.AddToScheme(scheme), "Error adding stork types to scheme")
+	exec := &fakeExec{}
+	return &VolumeFileRestoreController{
+		client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(fileRestore).Build(),
+		recorder:  record.NewFakeRecorder(100),
+		execInPod: exec.exec,
+	}, driver, exec, bucket
+}
+
+func newTestFileRestore(spec storkapi.VolumeFileRestoreSpec) *storkapi.VolumeFileRestore {
+	return &storkapi.VolumeFileRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "restore",
+			Namespace: "ns",
+			UID:       types.UID("restore-uid"),
+		},
+		Spec: spec,
+	}
+}
+
+func setHelperPodPhase(t *testing.T, fileRestore *storkapi.VolumeFileRestore, phase v1.PodPhase) {
+	pod, err := core.Instance().GetPodByName(getFileRestoreResourceName(fileRestore), fileRestore.Namespace)
+	require.NoError(t, err, "Error getting helper pod")
+	pod.Status.Phase = phase
+	_, err = core.Instance().UpdatePod(pod)
+	require.NoError(t, err, "Error updating helper pod")
+}
+
+func TestValidateFileRestorePath(t *testing.T) {
+	for _, path := range []string{"file", "dir/file", "dir/../file", "./dir", "dir/", "a..b", "..file"} {
+		require.NoError(t, validateFileRestorePath(path), "Path %q should be valid", path)
+	}
+	for _, path := range []string{"", "/", "/etc/passwd", "..", "../file", "dir/../../file", "./../file"} {
+		require.Error(t, validateFileRestorePath(path), "Path %q should be invalid", path)
+	}
+}
+
+func TestValidateFileRestoreSpec(t *testing.T) {
+	f := &VolumeFileRestoreController{}
+	valid := storkapi.VolumeFileRestoreSpec{
+		BackupName: "backup",
+		SourcePVC:  "data",
+		Paths:      []string{"dir/file"},
+		TargetPVC:  "target",
+		TargetPath: "restored",
+	}
+	require.NoError(t, f.validateSpec(newTestFileRestore(valid)), "Spec should be valid")
+
+	export := valid
+	export.TargetPVC = ""
+	export.TargetPath = ""
+	export.Export = true
+	require.NoError(t, f.validateSpec(newTestFileRestore(export)), "Export spec should be valid")
+
+	invalid := map[string]func(spec *storkapi.VolumeFileRestoreSpec){
+		"no backup":             func(spec *storkapi.VolumeFileRestoreSpec) { spec.BackupName = "" },
+		"no source pvc":         func(spec *storkapi.VolumeFileRestoreSpec) { spec.SourcePVC = "" },
+		"no paths":              func(spec *storkapi.VolumeFileRestoreSpec) { spec.Paths = nil },
+		"absolute path":         func(spec *storkapi.VolumeFileRestoreSpec) { spec.Paths = []string{"file", "/etc"} },
+		"path outside volume":   func(spec *storkapi.VolumeFileRestoreSpec) { spec.Paths = []string{"dir/../../etc"} },
+		"no target pvc":         func(spec *storkapi.VolumeFileRestoreSpec) { spec.TargetPVC = "" },
+		"absolute target path":  func(spec *storkapi.VolumeFileRestoreSpec) { spec.TargetPath = "/restored" },
+		"target outside volume": func(spec *storkapi.VolumeFileRestoreSpec) { spec.TargetPath = "../restored" },
+		"export with target":    func(spec *storkapi.VolumeFileRestoreSpec) { spec.Export = true },
+	}
+	for name, update := range invalid {
+		spec := valid
+		update(&spec)
+		require.Error(t, f.validateSpec(newTestFileRestore(spec)), "Spec with %v should be invalid", name)
+	}
+}
+
+func TestFileRestoreCommands(t *testing.T) {
+	fileRestore := newTestFileRestore(storkapi.VolumeFileRestoreSpec{TargetPath: "restored/dir"})
+	paths := []string{"dir/file", "file with spaces", "$(reboot)"}
+	cmd := getCopyFilesCommand(fileRestore, paths)
+	require.Equal(t, []string{
+		"sh", "-c",
+		`set -eo pipefail; mkdir -p "$0"; tar -C /backup -cf - -- "$@" | tar -C "$0" -xf -`,
+		"/target/restored/dir",
+		"dir/file", "file with spaces", "$(reboot)",
+	}, cmd, "Paths should be passed as arguments to the script")
+
+	fileRestore.Spec.TargetPath = ""
+	cmd = getCopyFilesCommand(fileRestore, paths[:1])
+	require.Equal(t, "/target", cmd[3], "Files should be copied to the root of the target by default")
+
+	require.Equal(t, []string{"tar", "-C", "/backup", "-cf", "-", "--", "dir/file", "-file"},
+		getExportFilesCommand([]string{"dir/file", "-file"}), "Unexpected export command")
+}
+
+func TestVolumeFileRestoreCopy(t *testing.T) {
+	fileRestore := newTestFileRestore(storkapi.VolumeFileRestoreSpec{
+		BackupName:      "backup",
+		SourceNamespace: "app",
+		SourcePVC:       "data",
+		Paths:           []string{"dir/", "file"},
+		TargetPVC:       "target",
+		TargetPath:      "restored",
+	})
+	controller, driver, exec, _ := newFileRestoreTestController(t, fileRestore)
+
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error starting file restore")
+	require.Equal(t, storkapi.VolumeFileRestoreStageMount, fileRestore.Status.Stage, "Unexpected stage after starting")
+	require.Equal(t, storkapi.VolumeFileRestoreStatusInProgress, fileRestore.Status.Status, "Unexpected status after starting")
+	require.Equal(t, fileRestoreTestDriverName, fileRestore.Status.DriverName, "Driver should be recorded")
+	require.Equal(t, "filerestore-restore-uid", fileRestore.Status.MountPVC, "Mount PVC should be recorded")
+	require.NotNil(t, driver.pvc, "Driver should provision the PVC")
+	require.Equal(t, "ns", driver.pvc.Namespace, "PVC should be provisioned in the namespace of the file restore")
+	require.Empty(t, driver.pvc.Spec.VolumeName, "PVC shouldn't be bound to the backed up volume")
+	require.Equal(t, map[string]string{"custom": "value"}, driver.pvc.Annotations, "Bind annotations should be removed")
+	require.Equal(t, "fast", *driver.pvc.Spec.StorageClassName, "Spec of the backed up PVC should be used")
+
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error checking mount")
+	require.Equal(t, storkapi.VolumeFileRestoreStageMount, fileRestore.Status.Stage, "File restore should wait for the PVC")
+
+	driver.ready = true
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error checking mount")
+	require.Equal(t, storkapi.VolumeFileRestoreStageCopy, fileRestore.Status.Stage, "Unexpected stage after mounting")
+	pod, err := core.Instance().GetPodByName("filerestore-restore-uid", "ns")
+	require.NoError(t, err, "Helper pod should be created")
+	require.Equal(t, "registry.example.com/"+defaultFileRestoreImage, pod.Spec.Containers[0].Image, "Unexpected helper image")
+	require.Len(t, pod.Spec.Volumes, 2, "Helper pod should mount the backup and the target")
+	require.Equal(t, "target", pod.Spec.Volumes[1].PersistentVolumeClaim.ClaimName, "Unexpected target PVC")
+
+	setHelperPodPhase(t, fileRestore, v1.PodPending)
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error waiting for helper pod")
+	require.Equal(t, storkapi.VolumeFileRestoreStageCopy, fileRestore.Status.Stage, "File restore should wait for the pod")
+	require.Empty(t, exec.commands, "Files shouldn't be copied before the pod is running")
+
+	setHelperPodPhase(t, fileRestore, v1.PodRunning)
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error copying files")
+	require.Equal(t, storkapi.VolumeFileRestoreStageFinal, fileRestore.Status.Stage, "Unexpected final stage")
+	require.Equal(t, storkapi.VolumeFileRestoreStatusSuccessful, fileRestore.Status.Status, "Unexpected final status")
+	require.Equal(t, [][]string{getCopyFilesCommand(fileRestore, []string{"dir", "file"})}, exec.commands,
+		"Cleaned paths should be copied")
+	_, err = core.Instance().GetPodByName("filerestore-restore-uid", "ns")
+	require.Equal(t, schederrors.ErrPodsNotFound, err, "Helper pod should be deleted")
+	_, err = core.Instance().GetPersistentVolumeClaim("filerestore-restore-uid", "ns")
+	require.True(t, errors.IsNotFound(err), "Mount PVC should be deleted")
+	require.True(t, driver.cleanedUp, "Driver resources should be cleaned up")
+}
+
+func TestVolumeFileRestoreExport(t *testing.T) {
+	fileRestore := newTestFileRestore(storkapi.VolumeFileRestoreSpec{
+		BackupName:      "backup",
+		SourceNamespace: "app",
+		SourcePVC:       "data",
+		Paths:           []string{"file"},
+		Export:          true,
+	})
+	controller, driver, exec, bucket := newFileRestoreTestController(t, fileRestore)
+	driver.ready = true
+	exec.output = "tarball"
+
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error starting file restore")
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error checking mount")
+	pod, err := core.Instance().GetPodByName("filerestore-restore-uid", "ns")
+	require.NoError(t, err, "Helper pod should be created")
+	require.Len(t, pod.Spec.Volumes, 1, "Helper pod should only mount the backup when exporting")
+
+	setHelperPodPhase(t, fileRestore, v1.PodRunning)
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error exporting files")
+	require.Equal(t, storkapi.VolumeFileRestoreStatusSuccessful, fileRestore.Status.Status, "Unexpected final status")
+	require.Equal(t, [][]string{getExportFilesCommand([]string{"file"})}, exec.commands, "Unexpected export command")
+	require.Equal(t, "ns/filerestore/restore/restore-uid/files.tar.gz", fileRestore.Status.ExportPath, "Unexpected export path")
+
+	reader, err := bucket.NewReader(context.TODO(), fileRestore.Status.ExportPath, nil)
+	require.NoError(t, err, "Error reading exported files")
+	defer reader.Close() // nolint: errcheck
+	decompressor, err := gzip.NewReader(reader)
+	require.NoError(t, err, "Exported files should be gzipped")
+	data, err := ioutil.ReadAll(decompressor)
+	require.NoError(t, err, "Error decompressing exported files")
+	require.Equal(t, "tarball", string(data), "Unexpected exported files")
+}
+
+func TestVolumeFileRestoreFailures(t *testing.T) {
+	fileRestore := newTestFileRestore(storkapi.VolumeFileRestoreSpec{
+		BackupName: "backup",
+		SourcePVC:  "data",
+		Paths:      []string{"../file"},
+		TargetPVC:  "target",
+	})
+	controller, driver, _, _ := newFileRestoreTestController(t, fileRestore)
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error starting file restore")
+	require.Equal(t, storkapi.VolumeFileRestoreStatusFailed, fileRestore.Status.Status, "Invalid spec should fail")
+	require.Nil(t, driver.pvc, "Nothing should be provisioned for an invalid spec")
+
+	fileRestore = newTestFileRestore(storkapi.VolumeFileRestoreSpec{
+		BackupName: "backup",
+		SourcePVC:  "data",
+		Paths:      []string{"file"},
+		TargetPVC:  "target",
+	})
+	controller, _, _, _ = newFileRestoreTestController(t, fileRestore)
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error starting file restore")
+	require.Equal(t, storkapi.VolumeFileRestoreStatusFailed, fileRestore.Status.Status, "PVC missing from backup should fail")
+	require.Contains(t, fileRestore.Status.Reason, "pvc ns/data not found", "Unexpected reason")
+
+	fileRestore = newTestFileRestore(storkapi.VolumeFileRestoreSpec{
+		BackupName:      "backup",
+		SourceNamespace: "app",
+		SourcePVC:       "data",
+		Paths:           []string{"file"},
+		TargetPVC:       "target",
+	})
+	controller, driver, exec, _ := newFileRestoreTestController(t, fileRestore)
+	driver.ready = true
+	exec.stderr = "tar: file: No such file or directory"
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error starting file restore")
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error checking mount")
+	setHelperPodPhase(t, fileRestore, v1.PodRunning)
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error copying files")
+	require.Equal(t, storkapi.VolumeFileRestoreStatusFailed, fileRestore.Status.Status, "Failed copy should fail the file restore")
+	require.True(t, strings.Contains(fileRestore.Status.Reason, "No such file or directory"),
+		"Reason should include the output of the command: %v", fileRestore.Status.Reason)
+	require.True(t, driver.cleanedUp, "Driver resources should be cleaned up on failure")
+
+	// The helper pod failing also fails the file restore
+	fileRestore = newTestFileRestore(fileRestore.Spec)
+	controller, driver, _, _ = newFileRestoreTestController(t, fileRestore)
+	driver.ready = true
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error starting file restore")
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error checking mount")
+	setHelperPodPhase(t, fileRestore, v1.PodFailed)
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error checking helper pod")
+	require.Equal(t, storkapi.VolumeFileRestoreStatusFailed, fileRestore.Status.Status, "Failed pod should fail the file restore")
+
+	// So does the helper pod being deleted
+	fileRestore = newTestFileRestore(fileRestore.Spec)
+	controller, driver, _, _ = newFileRestoreTestController(t, fileRestore)
+	driver.ready = true
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error starting file restore")
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error checking mount")
+	require.NoError(t, core.Instance().DeletePod(getFileRestoreResourceName(fileRestore), "ns", true), "Error deleting helper pod")
+	require.NoError(t, controller.handle(context.TODO(), fileRestore), "Error checking helper pod")
+	require.Equal(t, storkapi.VolumeFileRestoreStatusFailed, fileRestore.Status.Status, "Deleted pod should fail the file restore")
+	require.Contains(t, fileRestore.Status.Reason, "was deleted", "Unexpected reason")
+}
@@ -28,6 +28,10 @@ type FakeStorkV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeStorkV1alpha1) Actions(namespace string) v1alpha1.ActionInterface {
+	return &FakeActions{c, namespace}
+}
+
 func (c *FakeStorkV1alpha1) ApplicationBackups(namespace string) v1alpha1.ApplicationBackupInterface {
 	return &FakeApplicationBackups{c, namespace}
 }
@@ -96,6 +100,10 @@ func (c *FakeStorkV1alpha1) SchedulePolicies() v1alpha1.SchedulePolicyInterface
 	return &FakeSchedulePolicies{c}
 }
 
+func (c *FakeStorkV1alpha1) VolumeFileRestores(namespace string) v1alpha1.VolumeFileRestoreInterface {
+	return &FakeVolumeFileRestores{c, namespace}
+}
+
 func (c *FakeStorkV1alpha1) VolumeSnapshotRestores(namespace string) v1alpha1.VolumeSnapshotRestoreInterface {
 	return &FakeVolumeSnapshotRestores{c, namespace}
 }
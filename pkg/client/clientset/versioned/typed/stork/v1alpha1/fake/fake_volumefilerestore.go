@@ -0,0 +1,142 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeVolumeFileRestores implements VolumeFileRestoreInterface
+type FakeVolumeFileRestores struct {
+	Fake *FakeStorkV1alpha1
+	ns   string
+}
+
+var volumefilerestoresResource = schema.GroupVersionResource{Group: "stork.libopenstorage.org", Version: "v1alpha1", Resource: "volumefilerestores"}
+
+var volumefilerestoresKind = schema.GroupVersionKind{Group: "stork.libopenstorage.org", Version: "v1alpha1", Kind: "VolumeFileRestore"}
+
+// Get takes name of the volumeFileRestore, and returns the corresponding volumeFileRestore object, and an error if there is any.
+func (c *FakeVolumeFileRestores) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.VolumeFileRestore, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(volumefilerestoresResource, c.ns, name), &v1alpha1.VolumeFileRestore{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VolumeFileRestore), err
+}
+
+// List takes label and field selectors, and returns the list of VolumeFileRestores that match those selectors.
+func (c *FakeVolumeFileRestores) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.VolumeFileRestoreList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(volumefilerestoresResource, volumefilerestoresKind, c.ns, opts), &v1alpha1.VolumeFileRestoreList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.VolumeFileRestoreList{ListMeta: obj.(*v1alpha1.VolumeFileRestoreList).ListMeta}
+	for _, item := range obj.(*v1alpha1.VolumeFileRestoreList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested volumeFileRestores.
+func (c *FakeVolumeFileRestores) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(volumefilerestoresResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a volumeFileRestore and creates it.  Returns the server's representation of the volumeFileRestore, and an error, if there is any.
+func (c *FakeVolumeFileRestores) Create(ctx context.Context, volumeFileRestore *v1alpha1.VolumeFileRestore, opts v1.CreateOptions) (result *v1alpha1.VolumeFileRestore, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(volumefilerestoresResource, c.ns, volumeFileRestore), &v1alpha1.VolumeFileRestore{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VolumeFileRestore), err
+}
+
+// Update takes the representation of a volumeFileRestore and updates it. Returns the server's representation of the volumeFileRestore, and an error, if there is any.
+func (c *FakeVolumeFileRestores) Update(ctx context.Context, volumeFileRestore *v1alpha1.VolumeFileRestore, opts v1.UpdateOptions) (result *v1alpha1.VolumeFileRestore, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(volumefilerestoresResource, c.ns, volumeFileRestore), &v1alpha1.VolumeFileRestore{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VolumeFileRestore), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVolumeFileRestores) UpdateStatus(ctx context.Context, volumeFileRestore *v1alpha1.VolumeFileRestore, opts v1.UpdateOptions) (*v1alpha1.VolumeFileRestore, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(volumefilerestoresResource, "status", c.ns, volumeFileRestore), &v1alpha1.VolumeFileRestore{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VolumeFileRestore), err
+}
+
+// Delete takes name of the volumeFileRestore and deletes it. Returns an error if one occurs.
+func (c *FakeVolumeFileRestores) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(volumefilerestoresResource, c.ns, name), &v1alpha1.VolumeFileRestore{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeVolumeFileRestores) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(volumefilerestoresResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.VolumeFileRestoreList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched volumeFileRestore.
+func (c *FakeVolumeFileRestores) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.VolumeFileRestore, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(volumefilerestoresResource, c.ns, name, pt, data, subresources...), &v1alpha1.VolumeFileRestore{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VolumeFileRestore), err
+}
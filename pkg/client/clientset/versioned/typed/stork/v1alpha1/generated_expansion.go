@@ -18,6 +18,8 @@ limitations under the License.
 
 package v1alpha1
 
+type ActionExpansion interface{}
+
 type ApplicationBackupExpansion interface{}
 
 type ApplicationBackupScheduleExpansion interface{}
@@ -52,6 +54,8 @@ type RuleExpansion interface{}
 
 type SchedulePolicyExpansion interface{}
 
+type VolumeFileRestoreExpansion interface{}
+
 type VolumeSnapshotRestoreExpansion interface{}
 
 type VolumeSnapshotScheduleExpansion interface{}
@@ -26,6 +26,7 @@ import (
 
 type StorkV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	ActionsGetter
 	ApplicationBackupsGetter
 	ApplicationBackupSchedulesGetter
 	ApplicationClonesGetter
@@ -43,6 +44,7 @@ type StorkV1alpha1Interface interface {
 	ResourceTransformationsGetter
 	RulesGetter
 	SchedulePoliciesGetter
+	VolumeFileRestoresGetter
 	VolumeSnapshotRestoresGetter
 	VolumeSnapshotSchedulesGetter
 }
@@ -52,6 +54,10 @@ type StorkV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *StorkV1alpha1Client) Actions(namespace string) ActionInterface {
+	return newActions(c, namespace)
+}
+
 func (c *StorkV1alpha1Client) ApplicationBackups(namespace string) ApplicationBackupInterface {
 	return newApplicationBackups(c, namespace)
 }
@@ -120,6 +126,10 @@ func (c *StorkV1alpha1Client) SchedulePolicies() SchedulePolicyInterface {
 	return newSchedulePolicies(c)
 }
 
+func (c *StorkV1alpha1Client) VolumeFileRestores(namespace string) VolumeFileRestoreInterface {
+	return newVolumeFileRestores(c, namespace)
+}
+
 func (c *StorkV1alpha1Client) VolumeSnapshotRestores(namespace string) VolumeSnapshotRestoreInterface {
 	return newVolumeSnapshotRestores(c, namespace)
 }
@@ -0,0 +1,195 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	scheme "github.com/libopenstorage/stork/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// VolumeFileRestoresGetter has a method to return a VolumeFileRestoreInterface.
+// A group's client should implement this interface.
+type VolumeFileRestoresGetter interface {
+	VolumeFileRestores(namespace string) VolumeFileRestoreInterface
+}
+
+// VolumeFileRestoreInterface has methods to work with VolumeFileRestore resources.
+type VolumeFileRestoreInterface interface {
+	Create(ctx context.Context, volumeFileRestore *v1alpha1.VolumeFileRestore, opts v1.CreateOptions) (*v1alpha1.VolumeFileRestore, error)
+	Update(ctx context.Context, volumeFileRestore *v1alpha1.VolumeFileRestore, opts v1.UpdateOptions) (*v1alpha1.VolumeFileRestore, error)
+	UpdateStatus(ctx context.Context, volumeFileRestore *v1alpha1.VolumeFileRestore, opts v1.UpdateOptions) (*v1alpha1.VolumeFileRestore, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.VolumeFileRestore, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.VolumeFileRestoreList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.VolumeFileRestore, err error)
+	VolumeFileRestoreExpansion
+}
+
+// volumeFileRestores implements VolumeFileRestoreInterface
+type volumeFileRestores struct {
+	client rest.Interface
+	ns     string
+}
+
+// newVolumeFileRestores returns a VolumeFileRestores
+func newVolumeFileRestores(c *StorkV1alpha1Client, namespace string) *volumeFileRestores {
+	return &volumeFileRestores{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the volumeFileRestore, and returns the corresponding volumeFileRestore object, and an error if there is any.
+func (c *volumeFileRestores) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.VolumeFileRestore, err error) {
+	result = &v1alpha1.VolumeFileRestore{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("volumefilerestores").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of VolumeFileRestores that match those selectors.
+func (c *volumeFileRestores) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.VolumeFileRestoreList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.VolumeFileRestoreList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("volumefilerestores").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested volumeFileRestores.
+func (c *volumeFileRestores) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("volumefilerestores").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a volumeFileRestore and creates it.  Returns the server's representation of the volumeFileRestore, and an error, if there is any.
+func (c *volumeFileRestores) Create(ctx context.Context, volumeFileRestore *v1alpha1.VolumeFileRestore, opts v1.CreateOptions) (result *v1alpha1.VolumeFileRestore, err error) {
+	result = &v1alpha1.VolumeFileRestore{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("volumefilerestores").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(volumeFileRestore).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a volumeFileRestore and updates it. Returns the server's representation of the volumeFileRestore, and an error, if there is any.
+func (c *volumeFileRestores) Update(ctx context.Context, volumeFileRestore *v1alpha1.VolumeFileRestore, opts v1.UpdateOptions) (result *v1alpha1.VolumeFileRestore, err error) {
+	result = &v1alpha1.VolumeFileRestore{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("volumefilerestores").
+		Name(volumeFileRestore.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(volumeFileRestore).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *volumeFileRestores) UpdateStatus(ctx context.Context, volumeFileRestore *v1alpha1.VolumeFileRestore, opts v1.UpdateOptions) (result *v1alpha1.VolumeFileRestore, err error) {
+	result = &v1alpha1.VolumeFileRestore{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("volumefilerestores").
+		Name(volumeFileRestore.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(volumeFileRestore).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the volumeFileRestore and deletes it. Returns an error if one occurs.
+func (c *volumeFileRestores) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("volumefilerestores").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *volumeFileRestores) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("volumefilerestores").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched volumeFileRestore.
+func (c *volumeFileRestores) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.VolumeFileRestore, err error) {
+	result = &v1alpha1.VolumeFileRestore{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("volumefilerestores").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -53,6 +53,8 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=stork.libopenstorage.org, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("actions"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().Actions().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("applicationbackups"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().ApplicationBackups().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("applicationbackupschedules"):
@@ -87,6 +89,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().Rules().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("schedulepolicies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().SchedulePolicies().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("volumefilerestores"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().VolumeFileRestores().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("volumesnapshotrestores"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Stork().V1alpha1().VolumeSnapshotRestores().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("volumesnapshotschedules"):
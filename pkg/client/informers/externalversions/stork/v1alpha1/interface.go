@@ -24,6 +24,8 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// Actions returns a ActionInformer.
+	Actions() ActionInformer
 	// ApplicationBackups returns a ApplicationBackupInformer.
 	ApplicationBackups() ApplicationBackupInformer
 	// ApplicationBackupSchedules returns a ApplicationBackupScheduleInformer.
@@ -58,6 +60,8 @@ type Interface interface {
 	Rules() RuleInformer
 	// SchedulePolicies returns a SchedulePolicyInformer.
 	SchedulePolicies() SchedulePolicyInformer
+	// VolumeFileRestores returns a VolumeFileRestoreInformer.
+	VolumeFileRestores() VolumeFileRestoreInformer
 	// VolumeSnapshotRestores returns a VolumeSnapshotRestoreInformer.
 	VolumeSnapshotRestores() VolumeSnapshotRestoreInformer
 	// VolumeSnapshotSchedules returns a VolumeSnapshotScheduleInformer.
@@ -75,6 +79,11 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// Actions returns a ActionInformer.
+func (v *version) Actions() ActionInformer {
+	return &actionInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ApplicationBackups returns a ApplicationBackupInformer.
 func (v *version) ApplicationBackups() ApplicationBackupInformer {
 	return &applicationBackupInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -160,6 +169,11 @@ func (v *version) SchedulePolicies() SchedulePolicyInformer {
 	return &schedulePolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// VolumeFileRestores returns a VolumeFileRestoreInformer.
+func (v *version) VolumeFileRestores() VolumeFileRestoreInformer {
+	return &volumeFileRestoreInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // VolumeSnapshotRestores returns a VolumeSnapshotRestoreInformer.
 func (v *version) VolumeSnapshotRestores() VolumeSnapshotRestoreInformer {
 	return &volumeSnapshotRestoreInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
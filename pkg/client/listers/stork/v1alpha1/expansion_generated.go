@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1alpha1
 
+// ActionListerExpansion allows custom methods to be added to
+// ActionLister.
+type ActionListerExpansion interface{}
+
+// ActionNamespaceListerExpansion allows custom methods to be added to
+// ActionNamespaceLister.
+type ActionNamespaceListerExpansion interface{}
+
 // ApplicationBackupListerExpansion allows custom methods to be added to
 // ApplicationBackupLister.
 type ApplicationBackupListerExpansion interface{}
@@ -138,6 +146,14 @@ type RuleNamespaceListerExpansion interface{}
 // SchedulePolicyLister.
 type SchedulePolicyListerExpansion interface{}
 
+// VolumeFileRestoreListerExpansion allows custom methods to be added to
+// VolumeFileRestoreLister.
+type VolumeFileRestoreListerExpansion interface{}
+
+// VolumeFileRestoreNamespaceListerExpansion allows custom methods to be added to
+// VolumeFileRestoreNamespaceLister.
+type VolumeFileRestoreNamespaceListerExpansion interface{}
+
 // VolumeSnapshotRestoreListerExpansion allows custom methods to be added to
 // VolumeSnapshotRestoreLister.
 type VolumeSnapshotRestoreListerExpansion interface{}
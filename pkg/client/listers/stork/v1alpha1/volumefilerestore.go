@@ -0,0 +1,99 @@
+/*
+Copyright 2018 Openstorage.org
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// VolumeFileRestoreLister helps list VolumeFileRestores.
+// All objects returned here must be treated as read-only.
+type VolumeFileRestoreLister interface {
+	// List lists all VolumeFileRestores in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.VolumeFileRestore, err error)
+	// VolumeFileRestores returns an object that can list and get VolumeFileRestores.
+	VolumeFileRestores(namespace string) VolumeFileRestoreNamespaceLister
+	VolumeFileRestoreListerExpansion
+}
+
+// volumeFileRestoreLister implements the VolumeFileRestoreLister interface.
+type volumeFileRestoreLister struct {
+	indexer cache.Indexer
+}
+
+// NewVolumeFileRestoreLister returns a new VolumeFileRestoreLister.
+func NewVolumeFileRestoreLister(indexer cache.Indexer) VolumeFileRestoreLister {
+	return &volumeFileRestoreLister{indexer: indexer}
+}
+
+// List lists all VolumeFileRestores in the indexer.
+func (s *volumeFileRestoreLister) List(selector labels.Selector) (ret []*v1alpha1.VolumeFileRestore, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.VolumeFileRestore))
+	})
+	return ret, err
+}
+
+// VolumeFileRestores returns an object that can list and get VolumeFileRestores.
+func (s *volumeFileRestoreLister) VolumeFileRestores(namespace string) VolumeFileRestoreNamespaceLister {
+	return volumeFileRestoreNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// VolumeFileRestoreNamespaceLister helps list and get VolumeFileRestores.
+// All objects returned here must be treated as read-only.
+type VolumeFileRestoreNamespaceLister interface {
+	// List lists all VolumeFileRestores in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.VolumeFileRestore, err error)
+	// Get retrieves the VolumeFileRestore from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.VolumeFileRestore, error)
+	VolumeFileRestoreNamespaceListerExpansion
+}
+
+// volumeFileRestoreNamespaceLister implements the VolumeFileRestoreNamespaceLister
+// interface.
+type volumeFileRestoreNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all VolumeFileRestores in the indexer for a given namespace.
+func (s volumeFileRestoreNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.VolumeFileRestore, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.VolumeFileRestore))
+	})
+	return ret, err
+}
+
+// Get retrieves the VolumeFileRestore from the indexer for a given namespace and name.
+func (s volumeFileRestoreNamespaceLister) Get(name string) (*v1alpha1.VolumeFileRestore, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("volumefilerestore"), name)
+	}
+	return obj.(*v1alpha1.VolumeFileRestore), nil
+}
@@ -14,16 +14,20 @@ const (
 	// StatusFileFormat is the format specifier used to generate the status file path
 	StatusFileFormat = "/tmp/stork-cmd-done-%s"
 	// KillFileFormat is the format specifier used to generate the kill file path
-	KillFileFormat     = "/tmp/killme-%s"
-	cmdWaitFormat      = "touch %s && tail -f /dev/null;"
-	cmdStatusFormat    = "stat %s"
-	waitScriptFormat   = "/tmp/wait-%s.sh"
-	waitCmdPlaceholder = "${WAIT_CMD}"
+	KillFileFormat  = "/tmp/killme-%s"
+	cmdWaitFormat   = "touch %s && tail -f /dev/null;"
+	cmdStatusFormat = "stat %s"
+	// cmdStatusWaitFormat waits for the status file for at most the given
+	// number of seconds
+	cmdStatusWaitFormat = "i=0; while [ ! -f %s ] && [ $i -lt %d ]; do sleep 1; i=$((i+1)); done"
+	waitScriptFormat    = "/tmp/wait-%s.sh"
+	waitCmdPlaceholder  = "${WAIT_CMD}"
 )
 
 const (
 	cmdStatusCheckInitialDelay = 2 * time.Second
 	cmdStatusCheckFactor       = 1
+	cmdStatusRemoveTimeout     = 1 * time.Minute
 )
 
 // Executor is an interface to start and wait for async commands in pods
@@ -104,18 +108,59 @@ func (c *cmdExecutor) Wait(timeout time.Duration) error {
 			c.command, c.podNamespace, c.podName)
 	}
 
-	cmdStatuCheckSteps := int(timeout / cmdStatusCheckInitialDelay)
-	if cmdStatuCheckSteps == 0 {
-		cmdStatuCheckSteps = 1
+	// Wait for the status file with a single exec that blocks until the file
+	// is created, instead of polling the pod with an exec every few seconds.
+	// If that fails, for e.g. if the connection to the pod is dropped, fall
+	// back to polling for the remaining time.
+	start := time.Now()
+	if err := c.waitForStatusFile(timeout); err != nil {
+		logrus.Warnf("failed to wait for status file: %s in pod: [%s] %s, falling back to polling: %v",
+			c.statusFile, c.podNamespace, c.podName, err)
+		if err := c.pollStatusFile(timeout - time.Since(start)); err != nil {
+			return err
+		}
 	}
 
-	cmdCheckBackoff := wait.Backoff{
-		Duration: cmdStatusCheckInitialDelay,
-		Factor:   cmdStatusCheckFactor,
-		Jitter:   0.1,
-		Steps:    cmdStatuCheckSteps,
+	// Remove status file
+	if err := wait.ExponentialBackoff(c.getBackoff(cmdStatusRemoveTimeout), func() (bool, error) {
+		_, err := core.Instance().RunCommandInPod([]string{
+			"/bin/sh",
+			"-c",
+			fmt.Sprintf("rm -rf %s", c.statusFile)}, c.podName, c.container, c.podNamespace)
+		if err != nil {
+			return false, nil
+		}
+
+		return true, nil
+	}); err != nil {
+		logrus.Warnf("failed to remove status file: %s due to: %v", c.statusFile, err)
+	}
+
+	return nil
+}
+
+// waitForStatusFile runs a command in the pod that returns once the status
+// file is created or the timeout expires
+func (c *cmdExecutor) waitForStatusFile(timeout time.Duration) error {
+	logrus.Infof("wait for status file: %s on pod: [%s] %s with timeout: %v",
+		c.statusFile, c.podNamespace, c.podName, timeout)
+	waitCmd := fmt.Sprintf(cmdStatusWaitFormat, c.statusFile, int(timeout.Seconds()))
+	if _, err := core.Instance().RunCommandInPod([]string{"/bin/sh", "-c", waitCmd},
+		c.podName, c.container, c.podNamespace); err != nil {
+		return err
+	}
+	statusCmd := fmt.Sprintf(cmdStatusFormat, c.statusFile)
+	if _, err := core.Instance().RunCommandInPod([]string{"/bin/sh", "-c", statusCmd},
+		c.podName, c.container, c.podNamespace); err != nil {
+		return fmt.Errorf("status file was not created in %v: %v", timeout, err)
 	}
+	return nil
+}
 
+// pollStatusFile checks if the status file has been created in the pod every
+// few seconds until the timeout expires
+func (c *cmdExecutor) pollStatusFile(timeout time.Duration) error {
+	cmdCheckBackoff := c.getBackoff(timeout)
 	logrus.Infof("check status on pod: [%s] %s with backoff: %v and status file: %s",
 		c.podNamespace, c.podName, cmdCheckBackoff, c.statusFile)
 
@@ -133,23 +178,21 @@ func (c *cmdExecutor) Wait(timeout time.Duration) error {
 			statusCmd, c.podNamespace, c.podName, err)
 		return err
 	}
+	return nil
+}
 
-	// Remove status file
-	if err := wait.ExponentialBackoff(cmdCheckBackoff, func() (bool, error) {
-		_, err := core.Instance().RunCommandInPod([]string{
-			"/bin/sh",
-			"-c",
-			fmt.Sprintf("rm -rf %s", c.statusFile)}, c.podName, c.container, c.podNamespace)
-		if err != nil {
-			return false, nil
-		}
-
-		return true, nil
-	}); err != nil {
-		logrus.Warnf("failed to remove status file: %s due to: %v", c.statusFile, err)
+func (c *cmdExecutor) getBackoff(timeout time.Duration) wait.Backoff {
+	cmdStatuCheckSteps := int(timeout / cmdStatusCheckInitialDelay)
+	if cmdStatuCheckSteps <= 0 {
+		cmdStatuCheckSteps = 1
 	}
 
-	return nil
+	return wait.Backoff{
+		Duration: cmdStatusCheckInitialDelay,
+		Factor:   cmdStatusCheckFactor,
+		Jitter:   0.1,
+		Steps:    cmdStatuCheckSteps,
+	}
 }
 
 func (c *cmdExecutor) GetPod() (string, string) {
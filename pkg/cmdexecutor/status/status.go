@@ -2,12 +2,14 @@ package status
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/portworx/sched-ops/k8s/core"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 const (
@@ -79,3 +81,135 @@ func Get(key string) (string, error) {
 	}
 	return status, nil
 }
+
+// TaskState is the state of the commands run by a command executor for a task
+type TaskState string
+
+const (
+	// TaskRunning is when the commands are still being run
+	TaskRunning TaskState = "Running"
+	// TaskSucceeded is when the commands have reached the wait placeholder in
+	// all the pods
+	TaskSucceeded TaskState = "Succeeded"
+	// TaskFailed is when the command failed in any of the pods
+	TaskFailed TaskState = "Failed"
+
+	// taskConfigMapPrefix is the prefix of the config maps the command
+	// executor uses to report the status of the commands for a task
+	taskConfigMapPrefix = "cmdexecutor-task-"
+	// taskStateKey is the key with the state of the task in the config map
+	taskStateKey = "state"
+	// taskMessageKey is the key with the reason for the state of the task in
+	// the config map
+	taskMessageKey = "message"
+	// taskPodKeyPrefix is the prefix for the keys with the state of the
+	// command in each pod
+	taskPodKeyPrefix = "pod."
+
+	// TaskConfigMapNamespace is the namespace of the config maps with the
+	// status of the commands for a task
+	TaskConfigMapNamespace = meta_v1.NamespaceSystem
+)
+
+// taskStatusBackoff is used to retry updates to the config map for a task
+// since the status for multiple pods can be updated at the same time
+var taskStatusBackoff = wait.Backoff{
+	Duration: 100 * time.Millisecond,
+	Factor:   1.5,
+	Jitter:   0.1,
+	Steps:    10,
+}
+
+// TaskConfigMapName returns the name of the config map with the status of
+// the commands for a task
+func TaskConfigMapName(taskID string) string {
+	return taskConfigMapPrefix + taskID
+}
+
+// CreateTaskStatus creates the config map for the status of the commands for
+// a task. The config map is owned by the command executor pod so that it is
+// deleted along with it.
+func CreateTaskStatus(taskID string, owner *v1.Pod) error {
+	cm := &v1.ConfigMap{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      TaskConfigMapName(taskID),
+			Namespace: TaskConfigMapNamespace,
+		},
+		Data: map[string]string{
+			taskStateKey: string(TaskRunning),
+		},
+	}
+	if owner != nil {
+		cm.OwnerReferences = []meta_v1.OwnerReference{
+			{
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Name:       owner.Name,
+				UID:        owner.UID,
+			},
+		}
+	}
+	_, err := core.Instance().CreateConfigMap(cm)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// PersistPodStatus records the state of the command in a pod for a task
+func PersistPodStatus(taskID, podNamespace, podName string, state TaskState, message string) error {
+	value := string(state)
+	if message != "" {
+		value += ": " + message
+	}
+	// Config map keys can't have a '/' so use the same separator as the
+	// namespaced name of a pod in the config map keys
+	return updateTaskStatus(taskID, map[string]string{
+		taskPodKeyPrefix + podNamespace + "." + podName: value,
+	})
+}
+
+// PersistTaskStatus records the state of the commands for a task
+func PersistTaskStatus(taskID string, state TaskState, message string) error {
+	return updateTaskStatus(taskID, map[string]string{
+		taskStateKey:   string(state),
+		taskMessageKey: message,
+	})
+}
+
+func updateTaskStatus(taskID string, data map[string]string) error {
+	var lastErr error
+	err := wait.ExponentialBackoff(taskStatusBackoff, func() (bool, error) {
+		cm, err := core.Instance().GetConfigMap(TaskConfigMapName(taskID), TaskConfigMapNamespace)
+		if err != nil {
+			return false, err
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		for key, value := range data {
+			cm.Data[key] = value
+		}
+		if _, err = core.Instance().UpdateConfigMap(cm); err != nil {
+			if errors.IsConflict(err) {
+				lastErr = err
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return lastErr
+	}
+	return err
+}
+
+// GetTaskStatus returns the state of the commands for a task and the reason
+// for the state from the config map
+func GetTaskStatus(cm *v1.ConfigMap) (TaskState, string) {
+	if cm == nil || cm.Data == nil {
+		return "", ""
+	}
+	return TaskState(cm.Data[taskStateKey]), cm.Data[taskMessageKey]
+}
@@ -0,0 +1,106 @@
+package cmdexecutor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libopenstorage/stork/pkg/cmdexecutor/status"
+	"github.com/libopenstorage/stork/pkg/k8sutils"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// ErrTaskWatchClosed is returned by WaitForTask if the watches are closed
+// before the task completes. The caller can fall back to polling the command
+// executor pod.
+var ErrTaskWatchClosed = fmt.Errorf("watch for command executor task was closed")
+
+// WaitForTask waits for the command executor pod running the commands for the
+// task to report that the commands have reached the wait placeholder in all
+// the pods, or failed. The status is read from the config map for the task
+// using a watch instead of polling. Command executors that don't report the
+// status in a config map are handled by watching the phase of the pod. The
+// wait is stopped early if the context is done.
+func WaitForTask(ctx context.Context, taskID string, executorPod *v1.Pod, timeout time.Duration) error {
+	_, client, err := k8sutils.GetKubernetesClient()
+	if err != nil {
+		return err
+	}
+
+	cmWatch, err := client.CoreV1().ConfigMaps(status.TaskConfigMapNamespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", status.TaskConfigMapName(taskID)).String(),
+	})
+	if err != nil {
+		return err
+	}
+	defer cmWatch.Stop()
+
+	podWatch, err := client.CoreV1().Pods(executorPod.Namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", executorPod.Name).String(),
+	})
+	if err != nil {
+		return err
+	}
+	defer podWatch.Stop()
+
+	logrus.Infof("Waiting for command executor task: %s in pod: [%s] %s with timeout: %v",
+		taskID, executorPod.Namespace, executorPod.Name, timeout)
+	// The task state is used when the pod completes since the update to the
+	// config map could be received after the pod update
+	var taskState status.TaskState
+	var taskMessage string
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case event, ok := <-cmWatch.ResultChan():
+			if !ok {
+				return ErrTaskWatchClosed
+			}
+			cm, ok := event.Object.(*v1.ConfigMap)
+			if !ok || event.Type == watch.Deleted {
+				continue
+			}
+			taskState, taskMessage = status.GetTaskStatus(cm)
+			switch taskState {
+			case status.TaskSucceeded:
+				logrus.Infof("Command executor task: %s succeeded", taskID)
+				return nil
+			case status.TaskFailed:
+				return fmt.Errorf("command executor task: %s failed: %s", taskID, taskMessage)
+			}
+		case event, ok := <-podWatch.ResultChan():
+			if !ok {
+				return ErrTaskWatchClosed
+			}
+			pod, ok := event.Object.(*v1.Pod)
+			if !ok {
+				continue
+			}
+			if event.Type == watch.Deleted {
+				return fmt.Errorf("command executor pod: [%s] %s was deleted", pod.Namespace, pod.Name)
+			}
+			switch pod.Status.Phase {
+			case v1.PodSucceeded:
+				if taskState == status.TaskFailed {
+					return fmt.Errorf("command executor task: %s failed: %s", taskID, taskMessage)
+				}
+				logrus.Infof("Pod: [%s] %s succeeded", pod.Namespace, pod.Name)
+				return nil
+			case v1.PodFailed:
+				if taskMessage != "" {
+					return fmt.Errorf("command executor task: %s failed: %s", taskID, taskMessage)
+				}
+				return fmt.Errorf("command executor pod: [%s] %s failed", pod.Namespace, pod.Name)
+			}
+		case <-timer.C:
+			return fmt.Errorf("timed out after %v waiting for command executor task: %s", timeout, taskID)
+		case <-ctx.Done():
+			return fmt.Errorf("stopped waiting for command executor task: %s: %v", taskID, ctx.Err())
+		}
+	}
+}
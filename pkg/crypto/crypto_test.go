@@ -4,11 +4,19 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/rand"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/portworx/sched-ops/k8s/core"
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
 )
 
 func TestEncryptDecrypt(t *testing.T) {
@@ -58,3 +66,196 @@ func TestDecryptInvalidiData(t *testing.T) {
 	require.Error(t, err, "Decrypting data should have failed")
 	require.Nil(t, decryptedData, "Decrypted data should be nil on error")
 }
+
+func TestStreamEncryptDecrypt(t *testing.T) {
+	passphrase := "testkey"
+	for _, size := range []int{0, 128, streamChunkSize, 3*streamChunkSize + 17} {
+		originalData := make([]byte, size)
+		_, err := io.ReadFull(rand.Reader, originalData)
+		require.NoError(t, err, "Error generating test data")
+
+		var encrypted bytes.Buffer
+		writer, err := NewEncryptWriter(&encrypted, passphrase)
+		require.NoError(t, err, "Error creating encrypt writer")
+		// Write in odd sized pieces to cross chunk boundaries
+		for data := originalData; len(data) > 0; {
+			n := 4099
+			if n > len(data) {
+				n = len(data)
+			}
+			_, err = writer.Write(data[:n])
+			require.NoError(t, err, "Error encrypting data")
+			data = data[n:]
+		}
+		require.NoError(t, writer.Close(), "Error closing encrypt writer")
+
+		reader, err := NewDecryptReader(&encrypted, passphrase)
+		require.NoError(t, err, "Error creating decrypt reader")
+		decryptedData, err := ioutil.ReadAll(reader)
+		require.NoError(t, err, "Error decrypting data")
+		require.Equal(t, originalData, decryptedData, "Original and descrypted data mismatch")
+	}
+}
+
+func TestStreamDecryptInvalidKey(t *testing.T) {
+	var encrypted bytes.Buffer
+	writer, err := NewEncryptWriter(&encrypted, "testkey")
+	require.NoError(t, err, "Error creating encrypt writer")
+	_, err = writer.Write([]byte("test data"))
+	require.NoError(t, err, "Error encrypting data")
+	require.NoError(t, writer.Close(), "Error closing encrypt writer")
+
+	reader, err := NewDecryptReader(&encrypted, "invalidKey")
+	require.NoError(t, err, "Error creating decrypt reader")
+	_, err = ioutil.ReadAll(reader)
+	require.Error(t, err, "Decrypting data should have failed")
+}
+
+func TestStreamDecryptTruncated(t *testing.T) {
+	passphrase := "testkey"
+	originalData := make([]byte, 2*streamChunkSize+1)
+	_, err := io.ReadFull(rand.Reader, originalData)
+	require.NoError(t, err, "Error generating test data")
+
+	var encrypted bytes.Buffer
+	writer, err := NewEncryptWriter(&encrypted, passphrase)
+	require.NoError(t, err, "Error creating encrypt writer")
+	_, err = writer.Write(originalData)
+	require.NoError(t, err, "Error encrypting data")
+	require.NoError(t, writer.Close(), "Error closing encrypt writer")
+
+	// Drop the final chunk
+	chunkLength := 5 + 12 + streamChunkSize + 16
+	truncated := encrypted.Bytes()[:2*chunkLength]
+	reader, err := NewDecryptReader(bytes.NewReader(truncated), passphrase)
+	require.NoError(t, err, "Error creating decrypt reader")
+	_, err = ioutil.ReadAll(reader)
+	require.Error(t, err, "Decrypting truncated data should have failed")
+}
+
+func writeTransitKey(t *testing.T, keyPath, keyID, version string) {
+	key := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, key)
+	require.NoError(t, err, "Error generating key")
+	require.NoError(t, os.MkdirAll(filepath.Join(keyPath, keyID), 0700), "Error creating key dir")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(keyPath, keyID, version), key, 0600), "Error writing key")
+}
+
+func newTransitKeyring(t *testing.T, keyPath string, currentKeyID string, previousKeyIDs ...string) *Keyring {
+	provider, err := GetKeyProvider(TransitKeyProviderName, "test", map[string]string{TransitKeyPathOption: keyPath})
+	require.NoError(t, err, "Error getting transit key provider")
+	return &Keyring{
+		Provider:       provider,
+		CurrentKeyID:   currentKeyID,
+		PreviousKeyIDs: previousKeyIDs,
+	}
+}
+
+func TestEnvelopeEncryptDecrypt(t *testing.T) {
+	keyPath, err := ioutil.TempDir("", "transit")
+	require.NoError(t, err, "Error creating key dir")
+	defer os.RemoveAll(keyPath) // nolint: errcheck
+	writeTransitKey(t, keyPath, "key1", "v1")
+	keyring := newTransitKeyring(t, keyPath, "key1")
+
+	originalData := make([]byte, 128)
+	_, err = io.ReadFull(rand.Reader, originalData)
+	require.NoError(t, err, "Error generating test data")
+
+	dataKey, err := keyring.NewDataKey()
+	require.NoError(t, err, "Error generating data key")
+	require.Equal(t, "key1", dataKey.KeyID)
+	require.Contains(t, string(dataKey.WrappedKey), "vault:v1:", "Wrapped key should use the transit format")
+
+	encryptedData, err := EncryptEnvelope(originalData, dataKey)
+	require.NoError(t, err, "Error encrypting data")
+	require.True(t, IsEnvelope(encryptedData), "Encrypted data should have the envelope header")
+
+	decryptedData, err := DecryptEnvelope(encryptedData, keyring)
+	require.NoError(t, err, "Error decrypting data")
+	require.Equal(t, originalData, decryptedData, "Original and descrypted data mismatch")
+
+	// Tampering with the header should be detected
+	encryptedData[len(envelopeMagic)+2] ^= 1
+	_, err = DecryptEnvelope(encryptedData, keyring)
+	require.Error(t, err, "Decrypting tampered data should have failed")
+
+	// Legacy passphrase encrypted data isn't an envelope
+	legacyData, err := Encrypt(originalData, "testkey")
+	require.NoError(t, err, "Error encrypting data")
+	require.False(t, IsEnvelope(legacyData), "Legacy data should not have the envelope header")
+	decryptedData, err = Decrypt(legacyData, "testkey")
+	require.NoError(t, err, "Error decrypting legacy data")
+	require.Equal(t, originalData, decryptedData, "Original and descrypted data mismatch")
+}
+
+func TestEnvelopeKeyRotation(t *testing.T) {
+	keyPath, err := ioutil.TempDir("", "transit")
+	require.NoError(t, err, "Error creating key dir")
+	defer os.RemoveAll(keyPath) // nolint: errcheck
+	writeTransitKey(t, keyPath, "key1", "v1")
+	originalData := []byte("test data")
+
+	dataKey, err := newTransitKeyring(t, keyPath, "key1").NewDataKey()
+	require.NoError(t, err, "Error generating data key")
+	oldData, err := EncryptEnvelope(originalData, dataKey)
+	require.NoError(t, err, "Error encrypting data")
+
+	// Rotating the version of the transit key keeps older versions readable
+	writeTransitKey(t, keyPath, "key1", "v2")
+	dataKey, err = newTransitKeyring(t, keyPath, "key1").NewDataKey()
+	require.NoError(t, err, "Error generating data key")
+	require.Contains(t, string(dataKey.WrappedKey), "vault:v2:", "Data key should be wrapped with the latest version")
+	decryptedData, err := DecryptEnvelope(oldData, newTransitKeyring(t, keyPath, "key1"))
+	require.NoError(t, err, "Error decrypting data wrapped with older version")
+	require.Equal(t, originalData, decryptedData, "Original and descrypted data mismatch")
+
+	// Rotating to a new key ID needs the old one to be a previous key
+	writeTransitKey(t, keyPath, "key2", "v1")
+	decryptedData, err = DecryptEnvelope(oldData, newTransitKeyring(t, keyPath, "key2", "key1"))
+	require.NoError(t, err, "Error decrypting data with previous key")
+	require.Equal(t, originalData, decryptedData, "Original and descrypted data mismatch")
+
+	_, err = DecryptEnvelope(oldData, newTransitKeyring(t, keyPath, "key2"))
+	require.Error(t, err, "Decrypting data with a retired key should have failed")
+}
+
+func TestSecretKeyProvider(t *testing.T) {
+	core.SetInstance(core.New(kubernetes.NewSimpleClientset(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "keys",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			"key1": []byte("secretkey1"),
+		},
+	})))
+
+	_, err := GetKeyProvider(SecretKeyProviderName, "test", nil)
+	require.Error(t, err, "Getting secret key provider without secret name should have failed")
+	provider, err := GetKeyProvider(SecretKeyProviderName, "test", map[string]string{SecretNameOption: "keys"})
+	require.NoError(t, err, "Error getting secret key provider")
+	keyring := &Keyring{
+		Provider:     provider,
+		CurrentKeyID: "key1",
+	}
+
+	var encrypted bytes.Buffer
+	dataKey, err := keyring.NewDataKey()
+	require.NoError(t, err, "Error generating data key")
+	writer, err := NewDataKeyEncryptWriter(&encrypted, dataKey)
+	require.NoError(t, err, "Error creating encrypt writer")
+	_, err = writer.Write([]byte("test data"))
+	require.NoError(t, err, "Error encrypting data")
+	require.NoError(t, writer.Close(), "Error closing encrypt writer")
+
+	reader, err := NewEnvelopeDecryptReader(bytes.NewReader(encrypted.Bytes()), keyring)
+	require.NoError(t, err, "Error creating decrypt reader")
+	decryptedData, err := ioutil.ReadAll(reader)
+	require.NoError(t, err, "Error decrypting data")
+	require.Equal(t, []byte("test data"), decryptedData, "Original and descrypted data mismatch")
+
+	keyring.CurrentKeyID = "key2"
+	_, err = keyring.NewDataKey()
+	require.Error(t, err, "Wrapping with a missing key should have failed")
+}
@@ -0,0 +1,286 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Envelope encrypted data starts with a header made up of the magic string,
+// the ID of the key encryption key and the data key wrapped with it
+//
+//	magic | key ID length (2 bytes) | key ID | wrapped key length (2 bytes) | wrapped key
+//
+// The header is followed by the nonce and ciphertext for data encrypted with
+// EncryptEnvelope, or by the chunks for data encrypted with
+// NewDataKeyEncryptWriter. Since every object carries its wrapped data key it
+// can be decrypted with just the key encryption key.
+const (
+	envelopeMagic = "STORKEV1"
+	dataKeySize   = 32
+)
+
+// KeyProvider wraps and unwraps data keys with a key encryption key
+type KeyProvider interface {
+	// WrapKey encrypts the data key with the key encryption key identified
+	// by keyID
+	WrapKey(keyID string, dataKey []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key that was wrapped with the key
+	// encryption key identified by keyID
+	UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// KeyProviderInitFunc initializes a key provider for a namespace with the
+// given options
+type KeyProviderInitFunc func(namespace string, options map[string]string) (KeyProvider, error)
+
+var (
+	keyProvidersLock sync.Mutex
+	keyProviders     = make(map[string]KeyProviderInitFunc)
+)
+
+// RegisterKeyProvider registers a key provider with the given name
+func RegisterKeyProvider(name string, initFunc KeyProviderInitFunc) error {
+	keyProvidersLock.Lock()
+	defer keyProvidersLock.Unlock()
+	if _, ok := keyProviders[name]; ok {
+		return fmt.Errorf("key provider %v is already registered", name)
+	}
+	keyProviders[name] = initFunc
+	return nil
+}
+
+// GetKeyProvider returns an instance of the key provider with the given name
+func GetKeyProvider(name string, namespace string, options map[string]string) (KeyProvider, error) {
+	keyProvidersLock.Lock()
+	initFunc, ok := keyProviders[name]
+	keyProvidersLock.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("key provider %v not found", name)
+	}
+	return initFunc(namespace, options)
+}
+
+// DataKey is the key used to encrypt the objects for a backup
+type DataKey struct {
+	// KeyID is the ID of the key encryption key used to wrap the data key
+	KeyID string
+	// Key is the plaintext data key
+	Key []byte
+	// WrappedKey is the data key encrypted with the key encryption key
+	WrappedKey []byte
+}
+
+// Keyring unwraps data keys for a set of active key encryption keys. New
+// data keys are wrapped with the current key, older ones can be unwrapped as
+// long as their key ID is still active.
+type Keyring struct {
+	Provider     KeyProvider
+	CurrentKeyID string
+	// PreviousKeyIDs are keys that can still be used to unwrap data keys
+	PreviousKeyIDs []string
+}
+
+// NewDataKey generates a random data key and wraps it with the current key
+func (k *Keyring) NewDataKey() (*DataKey, error) {
+	if k.CurrentKeyID == "" {
+		return nil, fmt.Errorf("current key ID is not set")
+	}
+	key := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("error generating data key: %v", err)
+	}
+	wrappedKey, err := k.Provider.WrapKey(k.CurrentKeyID, key)
+	if err != nil {
+		return nil, fmt.Errorf("error wrapping data key with key %v: %v", k.CurrentKeyID, err)
+	}
+	return &DataKey{
+		KeyID:      k.CurrentKeyID,
+		Key:        key,
+		WrappedKey: wrappedKey,
+	}, nil
+}
+
+// UnwrapDataKey unwraps a data key that was wrapped with one of the active
+// keys
+func (k *Keyring) UnwrapDataKey(keyID string, wrappedKey []byte) (*DataKey, error) {
+	if !k.isActive(keyID) {
+		return nil, fmt.Errorf("key %v is not an active key", keyID)
+	}
+	key, err := k.Provider.UnwrapKey(keyID, wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("error unwrapping data key with key %v: %v", keyID, err)
+	}
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("invalid data key size %v", len(key))
+	}
+	return &DataKey{
+		KeyID:      keyID,
+		Key:        key,
+		WrappedKey: wrappedKey,
+	}, nil
+}
+
+func (k *Keyring) isActive(keyID string) bool {
+	if keyID == "" {
+		return false
+	}
+	if keyID == k.CurrentKeyID {
+		return true
+	}
+	for _, id := range k.PreviousKeyIDs {
+		if keyID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEnvelope checks whether the data was encrypted with a data key
+func IsEnvelope(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(envelopeMagic))
+}
+
+// EncryptEnvelope encrypts the data with the data key and prepends the
+// wrapped data key
+func EncryptEnvelope(data []byte, dataKey *DataKey) ([]byte, error) {
+	header, err := envelopeHeader(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := getDataKeyCipher(dataKey.Key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce for encryption: %v", err)
+	}
+	out := make([]byte, 0, len(header)+len(nonce)+len(data)+gcm.Overhead())
+	out = append(append(out, header...), nonce...)
+	return gcm.Seal(out, nonce, data, header), nil
+}
+
+// DecryptEnvelope decrypts data that was encrypted with EncryptEnvelope,
+// using the keyring to unwrap the data key
+func DecryptEnvelope(data []byte, keyring *Keyring) ([]byte, error) {
+	r := bytes.NewReader(data)
+	dataKey, header, err := readEnvelopeHeader(r, keyring)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := getDataKeyCipher(dataKey.Key)
+	if err != nil {
+		return nil, err
+	}
+	data = data[len(header):]
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("envelope encrypted data is truncated")
+	}
+	nonce, encryptedData := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, encryptedData, header)
+}
+
+// NewDataKeyEncryptWriter returns a writer that writes the envelope header
+// to w and then encrypts the data written to it in chunks with the data key.
+// Close must be called to flush the last chunk, it does not close w.
+func NewDataKeyEncryptWriter(w io.Writer, dataKey *DataKey) (io.WriteCloser, error) {
+	header, err := envelopeHeader(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := getDataKeyCipher(dataKey.Key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return newEncryptWriter(w, gcm), nil
+}
+
+// NewEnvelopeDecryptReader returns a reader that decrypts data written by
+// the writer returned from NewDataKeyEncryptWriter, using the keyring to
+// unwrap the data key
+func NewEnvelopeDecryptReader(r io.Reader, keyring *Keyring) (io.Reader, error) {
+	dataKey, _, err := readEnvelopeHeader(r, keyring)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := getDataKeyCipher(dataKey.Key)
+	if err != nil {
+		return nil, err
+	}
+	return newDecryptReader(r, gcm), nil
+}
+
+func envelopeHeader(dataKey *DataKey) ([]byte, error) {
+	if dataKey == nil || len(dataKey.Key) != dataKeySize {
+		return nil, fmt.Errorf("invalid data key")
+	}
+	if len(dataKey.KeyID) > 0xffff || len(dataKey.WrappedKey) > 0xffff {
+		return nil, fmt.Errorf("key ID or wrapped key too long")
+	}
+	header := []byte(envelopeMagic)
+	header = appendUint16(header, len(dataKey.KeyID))
+	header = append(header, dataKey.KeyID...)
+	header = appendUint16(header, len(dataKey.WrappedKey))
+	return append(header, dataKey.WrappedKey...), nil
+}
+
+// readEnvelopeHeader reads the envelope header from r and unwraps the data
+// key. The raw header is returned since it is used as additional data
+func readEnvelopeHeader(r io.Reader, keyring *Keyring) (*DataKey, []byte, error) {
+	header := make([]byte, len(envelopeMagic))
+	if _, err := io.ReadFull(r, header); err != nil || !IsEnvelope(header) {
+		return nil, nil, fmt.Errorf("envelope encryption header is missing")
+	}
+	readField := func() ([]byte, error) {
+		length := make([]byte, 2)
+		if _, err := io.ReadFull(r, length); err != nil {
+			return nil, err
+		}
+		field := make([]byte, binary.BigEndian.Uint16(length))
+		if _, err := io.ReadFull(r, field); err != nil {
+			return nil, err
+		}
+		header = append(header, length...)
+		header = append(header, field...)
+		return field, nil
+	}
+	keyID, err := readField()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading key ID from envelope header: %v", err)
+	}
+	wrappedKey, err := readField()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading wrapped key from envelope header: %v", err)
+	}
+	if keyring == nil {
+		return nil, nil, fmt.Errorf("data is encrypted with key %v but no keyring was provided", string(keyID))
+	}
+	dataKey, err := keyring.UnwrapDataKey(string(keyID), wrappedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dataKey, header, nil
+}
+
+func appendUint16(b []byte, v int) []byte {
+	length := make([]byte, 2)
+	binary.BigEndian.PutUint16(length, uint16(v))
+	return append(b, length...)
+}
+
+func getDataKeyCipher(key []byte) (cipher.AEAD, error) {
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(c)
+}
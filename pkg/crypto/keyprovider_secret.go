@@ -0,0 +1,86 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SecretKeyProviderName is the name of the key provider that stores the
+	// key encryption keys in a Kubernetes secret
+	SecretKeyProviderName = "secret"
+	// SecretNameOption is the option used to pass the name of the secret
+	// that has the keys. Every entry in the secret is a key, the name of the
+	// entry being the key ID
+	SecretNameOption = "secretName"
+)
+
+type secretKeyProvider struct {
+	secretName string
+	namespace  string
+}
+
+func newSecretKeyProvider(namespace string, options map[string]string) (KeyProvider, error) {
+	secretName := options[SecretNameOption]
+	if secretName == "" {
+		return nil, fmt.Errorf("%v option is required for %v key provider", SecretNameOption, SecretKeyProviderName)
+	}
+	return &secretKeyProvider{
+		secretName: secretName,
+		namespace:  namespace,
+	}, nil
+}
+
+func (s *secretKeyProvider) getKey(keyID string) ([]byte, error) {
+	secret, err := core.Instance().GetSecret(s.secretName, s.namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error getting secret %v/%v: %v", s.namespace, s.secretName, err)
+	}
+	key, ok := secret.Data[keyID]
+	if !ok || len(key) == 0 {
+		return nil, fmt.Errorf("key %v not found in secret %v/%v", keyID, s.namespace, s.secretName)
+	}
+	return key, nil
+}
+
+func (s *secretKeyProvider) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	key, err := s.getKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := getCipher(string(key))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce for encryption: %v", err)
+	}
+	return gcm.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+func (s *secretKeyProvider) UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error) {
+	key, err := s.getKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := getCipher(string(key))
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) < gcm.NonceSize() {
+		return nil, fmt.Errorf("wrapped key is too short")
+	}
+	nonce, encryptedKey := wrappedKey[:gcm.NonceSize()], wrappedKey[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, encryptedKey, []byte(keyID))
+}
+
+func init() {
+	if err := RegisterKeyProvider(SecretKeyProviderName, newSecretKeyProvider); err != nil {
+		logrus.Panicf("Error registering %v key provider: %v", SecretKeyProviderName, err)
+	}
+}
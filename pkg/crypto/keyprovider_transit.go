@@ -0,0 +1,147 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// TransitKeyProviderName is the name of the key provider that stands in
+	// for the Vault transit secrets engine. Keys are read from a local
+	// directory and wrapped keys use the same "vault:v<version>:<ciphertext>"
+	// format as transit, so that backups can later be moved to Vault.
+	TransitKeyProviderName = "transit"
+	// TransitKeyPathOption is the option used to pass the directory with the
+	// keys. Every key ID is a sub-directory with one file per key version
+	// named v1, v2, etc. New data keys are wrapped with the latest version.
+	TransitKeyPathOption = "keyPath"
+	// DefaultTransitKeyPath is the directory used if TransitKeyPathOption
+	// isn't set
+	DefaultTransitKeyPath = "/var/lib/stork/transit"
+
+	transitPrefix = "vault:v"
+)
+
+type transitKeyProvider struct {
+	keyPath string
+}
+
+func newTransitKeyProvider(namespace string, options map[string]string) (KeyProvider, error) {
+	keyPath := options[TransitKeyPathOption]
+	if keyPath == "" {
+		keyPath = DefaultTransitKeyPath
+	}
+	return &transitKeyProvider{
+		keyPath: keyPath,
+	}, nil
+}
+
+func (t *transitKeyProvider) keyDir(keyID string) (string, error) {
+	if keyID == "" || strings.ContainsAny(keyID, `/\`) || keyID == "." || keyID == ".." {
+		return "", fmt.Errorf("invalid key ID %v", keyID)
+	}
+	return filepath.Join(t.keyPath, keyID), nil
+}
+
+func (t *transitKeyProvider) latestVersion(keyID string) (int, error) {
+	dir, err := t.keyDir(keyID)
+	if err != nil {
+		return 0, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("error reading versions for key %v: %v", keyID, err)
+	}
+	latest := 0
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name(), "v") {
+			continue
+		}
+		if version, err := strconv.Atoi(f.Name()[1:]); err == nil && version > latest {
+			latest = version
+		}
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("no versions found for key %v", keyID)
+	}
+	return latest, nil
+}
+
+func (t *transitKeyProvider) getKey(keyID string, version int) ([]byte, error) {
+	dir, err := t.keyDir(keyID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ioutil.ReadFile(filepath.Join(dir, fmt.Sprintf("v%d", version)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("version %v of key %v not found", version, keyID)
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (t *transitKeyProvider) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	version, err := t.latestVersion(keyID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := t.getKey(keyID, version)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := getCipher(string(key))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce for encryption: %v", err)
+	}
+	ciphertext := gcm.Seal(nonce, nonce, dataKey, []byte(keyID))
+	return []byte(fmt.Sprintf("%s%d:%s", transitPrefix, version, base64.StdEncoding.EncodeToString(ciphertext))), nil
+}
+
+func (t *transitKeyProvider) UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error) {
+	parts := strings.SplitN(strings.TrimPrefix(string(wrappedKey), transitPrefix), ":", 2)
+	if !strings.HasPrefix(string(wrappedKey), transitPrefix) || len(parts) != 2 {
+		return nil, fmt.Errorf("invalid transit ciphertext")
+	}
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid version in transit ciphertext: %v", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid transit ciphertext: %v", err)
+	}
+	key, err := t.getKey(keyID, version)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := getCipher(string(key))
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("wrapped key is too short")
+	}
+	nonce, encryptedKey := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, encryptedKey, []byte(keyID))
+}
+
+func init() {
+	if err := RegisterKeyProvider(TransitKeyProviderName, newTransitKeyProvider); err != nil {
+		logrus.Panicf("Error registering %v key provider: %v", TransitKeyProviderName, err)
+	}
+}
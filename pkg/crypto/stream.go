@@ -0,0 +1,183 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	// Size of the plaintext that is encrypted in each chunk
+	streamChunkSize = 1024 * 1024
+
+	chunkFlagMore  byte = 0
+	chunkFlagFinal byte = 1
+)
+
+// Each chunk written to the stream is laid out as
+//   flag (1 byte) | ciphertext length (4 bytes) | nonce | ciphertext
+// The chunk sequence number and flag are used as additional data so that
+// reordering, dropping or truncating chunks is detected on decryption.
+
+type encryptWriter struct {
+	w      io.Writer
+	gcm    cipher.AEAD
+	buf    []byte
+	seq    uint64
+	closed bool
+}
+
+// NewEncryptWriter returns a writer that encrypts the data written to it with
+// the passphrase and writes it to w in chunks. Close must be called to flush
+// the last chunk, it does not close w.
+func NewEncryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	gcm, err := getCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return newEncryptWriter(w, gcm), nil
+}
+
+func newEncryptWriter(w io.Writer, gcm cipher.AEAD) *encryptWriter {
+	return &encryptWriter{
+		w:   w,
+		gcm: gcm,
+		buf: make([]byte, 0, streamChunkSize),
+	}
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, fmt.Errorf("write to closed encrypt writer")
+	}
+	written := 0
+	for len(p) > 0 {
+		n := copy(e.buf[len(e.buf):cap(e.buf)], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+		// Only flush once there is more data, so that the last chunk can
+		// always be marked as final on Close
+		if len(e.buf) == cap(e.buf) && len(p) > 0 {
+			if err := e.writeChunk(chunkFlagMore); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.writeChunk(chunkFlagFinal)
+}
+
+func (e *encryptWriter) writeChunk(flag byte) error {
+	nonce := make([]byte, e.gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("error generating nonce for encryption: %v", err)
+	}
+	ciphertext := e.gcm.Seal(nil, nonce, e.buf, chunkAdditionalData(e.seq, flag))
+	header := make([]byte, 5)
+	header[0] = flag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(ciphertext)))
+	for _, b := range [][]byte{header, nonce, ciphertext} {
+		if _, err := e.w.Write(b); err != nil {
+			return err
+		}
+	}
+	e.seq++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+type decryptReader struct {
+	r    *bufio.Reader
+	gcm  cipher.AEAD
+	buf  []byte
+	seq  uint64
+	done bool
+}
+
+// NewDecryptReader returns a reader that decrypts data that was encrypted
+// with the passphrase by the writer returned from NewEncryptWriter
+func NewDecryptReader(r io.Reader, passphrase string) (io.Reader, error) {
+	gcm, err := getCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return newDecryptReader(r, gcm), nil
+}
+
+func newDecryptReader(r io.Reader, gcm cipher.AEAD) *decryptReader {
+	return &decryptReader{
+		r:   bufio.NewReader(r),
+		gcm: gcm,
+	}
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (d *decryptReader) readChunk() error {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("encrypted stream is truncated")
+		}
+		return err
+	}
+	flag := header[0]
+	if flag != chunkFlagMore && flag != chunkFlagFinal {
+		return fmt.Errorf("invalid chunk header in encrypted stream")
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > streamChunkSize+uint32(d.gcm.Overhead()) {
+		return fmt.Errorf("invalid chunk length %v in encrypted stream", length)
+	}
+	nonce := make([]byte, d.gcm.NonceSize())
+	if _, err := io.ReadFull(d.r, nonce); err != nil {
+		return fmt.Errorf("error reading nonce from encrypted stream: %v", err)
+	}
+	ciphertext := make([]byte, length)
+	if _, err := io.ReadFull(d.r, ciphertext); err != nil {
+		return fmt.Errorf("error reading chunk from encrypted stream: %v", err)
+	}
+	plaintext, err := d.gcm.Open(nil, nonce, ciphertext, chunkAdditionalData(d.seq, flag))
+	if err != nil {
+		return err
+	}
+	d.seq++
+	d.buf = plaintext
+	if flag == chunkFlagFinal {
+		if _, err := d.r.Peek(1); err != io.EOF {
+			return fmt.Errorf("unexpected data after final chunk in encrypted stream")
+		}
+		d.done = true
+	}
+	return nil
+}
+
+func chunkAdditionalData(seq uint64, flag byte) []byte {
+	ad := make([]byte, 9)
+	binary.BigEndian.PutUint64(ad, seq)
+	ad[8] = flag
+	return ad
+}
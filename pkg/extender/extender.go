@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -40,7 +39,8 @@ const (
 	// defaultScore Score assigned to a node which doesn't have data for any volume
 	defaultScore float64 = 5
 	// degradedNodeScorePenaltyPercentage is the percentage by which a node's score
-	// will take a hit if the node's status is degraded
+	// will take a hit if the node's status is degraded. The scores above and
+	// the penalty are the defaults, they can be overridden by a SchedulerPolicy
 	degradedNodeScorePenaltyPercentage float64 = 50
 	schedulingFailureEventReason               = "FailedScheduling"
 	// annotation to check if only local nodes should be used to schedule a pod
@@ -80,6 +80,8 @@ type Extender struct {
 	server   *http.Server
 	lock     sync.Mutex
 	started  bool
+
+	policies schedulerPolicies
 }
 
 // Start Starts the extender
@@ -105,6 +107,9 @@ func (e *Extender) Start() error {
 	if err := e.collectExtenderMetrics(); err != nil {
 		return err
 	}
+	if err := e.policies.watch(); err != nil {
+		return err
+	}
 
 	e.started = true
 	return nil
@@ -139,7 +144,7 @@ func (e *Extender) serveHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func (e *Extender) getHostname(node *v1.Node) string {
+func getHostname(node *v1.Node) string {
 	for _, address := range node.Status.Addresses {
 		if address.Type == v1.NodeHostName {
 			return address.Address
@@ -215,15 +220,7 @@ func (e *Extender) processFilterRequest(w http.ResponseWriter, req *http.Request
 			storklog.PodLog(pod).Errorf("Error getting list of driver nodes, returning all nodes, err: %v", err)
 		} else {
 			for _, volumeInfo := range driverVolumes {
-				onlineNodeFound := false
-				for _, volumeNode := range volumeInfo.DataNodes {
-					for _, driverNode := range driverNodes {
-						if volumeNode == driverNode.StorageID && driverNode.Status == volume.NodeOnline {
-							onlineNodeFound = true
-						}
-					}
-				}
-				if !onlineNodeFound && len(volumeInfo.DataNodes) > 0 {
+				if !hasOnlineReplicas(volumeInfo, driverNodes) {
 					// Volume has a list of DataNodes where it has a replica present and none of
 					// those nodes are online
 					storklog.PodLog(pod).Errorf("No online storage nodes have replica for volume, returning error")
@@ -234,14 +231,7 @@ func (e *Extender) processFilterRequest(w http.ResponseWriter, req *http.Request
 				}
 			}
 
-			preferLocalOnly := false
-			if pod.Annotations != nil {
-				if value, ok := pod.Annotations[preferLocalNodeOnlyAnnotation]; ok {
-					if preferLocalOnly, err = strconv.ParseBool(value); err != nil {
-						preferLocalOnly = false
-					}
-				}
-			}
+			preferLocalOnly := preferLocalNodeOnly(pod)
 
 			nodeVolumeCounts := make(map[string]int)
 			if preferLocalOnly {
@@ -392,74 +382,6 @@ func (e *Extender) collectExtenderMetrics() error {
 	return nil
 }
 
-func (e *Extender) getNodeScore(
-	node v1.Node,
-	volumeInfo *volume.Info,
-	rackInfo *localityInfo,
-	zoneInfo *localityInfo,
-	regionInfo *localityInfo,
-	storageNode *volume.NodeInfo,
-) float64 {
-	for _, address := range node.Status.Addresses {
-		if address.Type != v1.NodeHostName {
-			continue
-		}
-		nodeRack := rackInfo.HostnameMap[address.Address]
-		nodeZone := zoneInfo.HostnameMap[address.Address]
-		nodeRegion := regionInfo.HostnameMap[address.Address]
-
-		for _, region := range regionInfo.PreferredLocality {
-			if region == nodeRegion || nodeRegion == "" {
-				for _, zone := range zoneInfo.PreferredLocality {
-					if zone == nodeZone || nodeZone == "" {
-						for _, rack := range rackInfo.PreferredLocality {
-							if rack == nodeRack || nodeRack == "" {
-								for _, datanodeID := range volumeInfo.DataNodes {
-									if storageNode.StorageID == datanodeID {
-										if storageNode.Status == volume.NodeDegraded {
-											// Even if the volume data is local to the node
-											// the node is in degraded state. So the app won't benefit
-											// from hyperconvergence on this node. So we will not use
-											// the nodePriorityScore but instead rackPriorityScore and
-											// penalize based on that.
-											return rackPriorityScore * (degradedNodeScorePenaltyPercentage / 100)
-										}
-										return nodePriorityScore
-									}
-								}
-								if nodeRack != "" {
-									if storageNode.Status == volume.NodeDegraded {
-										return rackPriorityScore * (degradedNodeScorePenaltyPercentage / 100)
-									}
-									return rackPriorityScore
-								}
-							}
-						}
-						if nodeZone != "" {
-							if storageNode.Status == volume.NodeDegraded {
-								return zonePriorityScore * (degradedNodeScorePenaltyPercentage / 100)
-							}
-							return zonePriorityScore
-						}
-					}
-				}
-				if nodeRegion != "" {
-					if storageNode.Status == volume.NodeDegraded {
-						return regionPriorityScore * (degradedNodeScorePenaltyPercentage / 100)
-					}
-					return regionPriorityScore
-				}
-			}
-		}
-	}
-	return 0
-}
-
-type localityInfo struct {
-	HostnameMap       map[string]string
-	PreferredLocality []string
-}
-
 func (e *Extender) processPrioritizeRequest(w http.ResponseWriter, req *http.Request) {
 	decoder := json.NewDecoder(req.Body)
 	defer func() {
@@ -494,16 +416,7 @@ func (e *Extender) processPrioritizeRequest(w http.ResponseWriter, req *http.Req
 	}
 
 	// Score all nodes the same if hyperconvergence is disabled
-	disableHyperconvergence := false
-	var err error
-	if pod.Annotations != nil {
-		if value, ok := pod.Annotations[disableHyperconvergenceAnnotation]; ok {
-			if disableHyperconvergence, err = strconv.ParseBool(value); err != nil {
-				disableHyperconvergence = false
-			}
-		}
-	}
-	if disableHyperconvergence {
+	if hyperconvergenceDisabled(pod) {
 		goto sendResponse
 	}
 
@@ -525,12 +438,6 @@ func (e *Extender) processPrioritizeRequest(w http.ResponseWriter, req *http.Req
 				goto sendResponse
 			}
 
-			// Create a map for ID->Node and Hostname->Rack/Zone/Region
-			idMap := make(map[string]*volume.NodeInfo)
-			var rackInfo, zoneInfo, regionInfo localityInfo
-			rackInfo.HostnameMap = make(map[string]string)
-			zoneInfo.HostnameMap = make(map[string]string)
-			regionInfo.HostnameMap = make(map[string]string)
 			// Create a map for k8s node index to StorageNode
 			k8sNodeIndexStorageNodeMap := make(map[int]*volume.NodeInfo)
 			for _, dnode := range driverNodes {
@@ -538,73 +445,39 @@ func (e *Extender) processPrioritizeRequest(w http.ResponseWriter, req *http.Req
 				// easier to match nodes when calculating scores
 				for k8sNodeIndex, knode := range args.Nodes.Items {
 					if volume.IsNodeMatch(&knode, dnode) {
-						dnode.Hostname = e.getHostname(&knode)
+						dnode.Hostname = getHostname(&knode)
 						k8sNodeIndexStorageNodeMap[k8sNodeIndex] = dnode
 						break
 					}
 				}
-				idMap[dnode.StorageID] = dnode
 				storklog.PodLog(pod).Debugf("nodeInfo: %v", dnode)
-				// For any node that is offline remove the locality info so that we
-				// don't prioritize nodes close to it
-				if dnode.Status == volume.NodeOnline || dnode.Status == volume.NodeDegraded {
-					// Add region info into zone and zone info into rack so that we can
-					// differentiate same names in different localities
-					regionInfo.HostnameMap[dnode.Hostname] = dnode.Region
-					if regionInfo.HostnameMap[dnode.Hostname] != "" {
-						zoneInfo.HostnameMap[dnode.Hostname] = regionInfo.HostnameMap[dnode.Hostname] + "-" + dnode.Zone
-					} else {
-						zoneInfo.HostnameMap[dnode.Hostname] = dnode.Zone
-					}
-					if zoneInfo.HostnameMap[dnode.Hostname] != "" {
-						rackInfo.HostnameMap[dnode.Hostname] = zoneInfo.HostnameMap[dnode.Hostname] + "-" + dnode.Rack
-					} else {
-						rackInfo.HostnameMap[dnode.Hostname] = dnode.Rack
-					}
-				} else {
-					rackInfo.HostnameMap[dnode.Hostname] = ""
-					zoneInfo.HostnameMap[dnode.Hostname] = ""
-					regionInfo.HostnameMap[dnode.Hostname] = ""
-				}
 			}
 
-			storklog.PodLog(pod).Debugf("rackMap: %v", rackInfo.HostnameMap)
-			storklog.PodLog(pod).Debugf("zoneMap: %v", zoneInfo.HostnameMap)
-			storklog.PodLog(pod).Debugf("regionMap: %v", regionInfo.HostnameMap)
+			// Create a map for Hostname->Rack/Zone/Region
+			locality := newLocality(driverNodes)
+			storklog.PodLog(pod).Debugf("rackMap: %v", locality.racks)
+			storklog.PodLog(pod).Debugf("zoneMap: %v", locality.zones)
+			storklog.PodLog(pod).Debugf("regionMap: %v", locality.regions)
+
+			policy := e.getSchedulerPolicy(pod)
+			storklog.PodLog(pod).Debugf("scheduler policy: %+v", policy)
 
 			for _, volume := range driverVolumes {
-				skipVolumeScoring := false
-				if value, exists := volume.Labels[skipScoringLabel]; exists {
-					if skipVolumeScoring, err = strconv.ParseBool(value); err != nil {
-						skipVolumeScoring = false
-					}
-				}
-				if skipVolumeScoring {
+				if skipVolumeScoring(volume) {
 					storklog.PodLog(pod).Debugf("Skipping volume %v from scoring", volume.VolumeName)
 					continue
 				}
 				storklog.PodLog(pod).Debugf("Volume %v allocated on nodes:", volume.VolumeName)
 				// Get the racks, zones and regions where the volume is located
-				rackInfo.PreferredLocality = rackInfo.PreferredLocality[:0]
-				zoneInfo.PreferredLocality = zoneInfo.PreferredLocality[:0]
-				regionInfo.PreferredLocality = regionInfo.PreferredLocality[:0]
-				for _, node := range volume.DataNodes {
-					if _, ok := idMap[node]; ok {
-						log.Debugf("ID: %v Hostname: %v", node, idMap[node].Hostname)
-						regionInfo.PreferredLocality = append(regionInfo.PreferredLocality, regionInfo.HostnameMap[idMap[node].Hostname])
-						zoneInfo.PreferredLocality = append(zoneInfo.PreferredLocality, zoneInfo.HostnameMap[idMap[node].Hostname])
-						rackInfo.PreferredLocality = append(rackInfo.PreferredLocality, rackInfo.HostnameMap[idMap[node].Hostname])
-					} else {
-						log.Warnf("Node %v not found in list of nodes, skipping", node)
-					}
-				}
-				storklog.PodLog(pod).Debugf("Volume %v allocated on racks: %v", volume.VolumeName, rackInfo.PreferredLocality)
-				storklog.PodLog(pod).Debugf("Volume %v allocated in zones: %v", volume.VolumeName, zoneInfo.PreferredLocality)
-				storklog.PodLog(pod).Debugf("Volume %v allocated in regions: %v", volume.VolumeName, regionInfo.PreferredLocality)
+				volumeLocality := locality.getVolumeLocality(volume)
+				storklog.PodLog(pod).Debugf("Volume %v allocated on racks: %v", volume.VolumeName, volumeLocality.racks)
+				storklog.PodLog(pod).Debugf("Volume %v allocated in zones: %v", volume.VolumeName, volumeLocality.zones)
+				storklog.PodLog(pod).Debugf("Volume %v allocated in regions: %v", volume.VolumeName, volumeLocality.regions)
 
-				for k8sNodeIndex, node := range args.Nodes.Items {
+				for k8sNodeIndex := range args.Nodes.Items {
+					node := &args.Nodes.Items[k8sNodeIndex]
 					storageNode := k8sNodeIndexStorageNodeMap[k8sNodeIndex]
-					priorityMap[node.Name] += int(e.getNodeScore(node, volume, &rackInfo, &zoneInfo, &regionInfo, storageNode))
+					priorityMap[node.Name] += int(locality.getNodeScore(node, volume, volumeLocality, storageNode, policy))
 				}
 			}
 		}
@@ -335,6 +335,8 @@ func TestExtender(t *testing.T) {
 	t.Run("restorePVCTest", restorePVCTest)
 	t.Run("preferLocalNodeTest", preferLocalNodeTest)
 	t.Run("extenderMetricsTest", extenderMetricsTest)
+	t.Run("schedulerPolicyTest", schedulerPolicyTest)
+	t.Run("pluginTest", pluginTest)
 	t.Run("teardown", teardown)
 }
 
@@ -1396,3 +1398,93 @@ func extenderMetricsTest(t *testing.T) {
 	time.Sleep(3 * time.Second)
 	require.Equal(t, testutil.ToFloat64(NonHyperConvergePodsCounter), float64(1), "non_hyperconverged_pods_total not matched")
 }
+
+// Create a pod with a PVC using the mock storage class.
+// Place the data on nodes n1, n2. Send requests with node n1, n2, n3, n4, n5
+// with different scheduler policies selected for the pod. Invalid policies
+// and policies that don't exist should fall back to the default scores
+func schedulerPolicyTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1", "node1", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2", "node2", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3", "node3", "192.168.0.3", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4", "node4", "192.168.0.4", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node5", "node5", "192.168.0.5", "rack3", "", ""))
+
+	if err := driver.CreateCluster(5, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	pod := newPod("schedulerPolicyPod", map[string]bool{"schedulerPolicyVolume": false})
+	if err := driver.ProvisionVolume("schedulerPolicyVolume", []int{0, 1}, 1, nil); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	if err := driver.UpdateNodeCapacity(0, 100, 75); err != nil {
+		t.Fatalf("Error updating node capacity: %v", err)
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SchedulerPolicyConfigMapName,
+			Namespace: SchedulerPolicyConfigMapNamespace,
+		},
+		Data: map[string]string{
+			"locality": "nodePriorityScore: 200\nrackPriorityScore: 0",
+			"capacity": `{"considerNodeCapacity": true}`,
+			"invalid":  "degradedNodePenaltyPercentage: 150",
+		},
+	}
+	_, err := core.Instance().CreateConfigMap(cm)
+	require.NoError(t, err, "Error creating scheduler policy config map")
+	pod.Annotations[schedulerPolicyAnnotation] = "locality"
+	for i := 0; i < 10 && extender.getSchedulerPolicy(pod).NodePriorityScore != 200; i++ {
+		time.Sleep(time.Second)
+	}
+
+	prioritizeResponse, err := sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]float64{200,
+			200,
+			defaultScore,
+			defaultScore,
+			defaultScore},
+		prioritizeResponse)
+
+	pod.Annotations[schedulerPolicyAnnotation] = "capacity"
+	prioritizeResponse, err = sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(
+		t,
+		nodes,
+		[]float64{nodePriorityScore / 4,
+			nodePriorityScore,
+			rackPriorityScore,
+			rackPriorityScore,
+			defaultScore},
+		prioritizeResponse)
+
+	defaultScores := []float64{nodePriorityScore,
+		nodePriorityScore,
+		rackPriorityScore,
+		rackPriorityScore,
+		defaultScore}
+	for _, policyName := range []string{"invalid", "missing"} {
+		pod.Annotations[schedulerPolicyAnnotation] = policyName
+		prioritizeResponse, err = sendPrioritizeRequest(pod, nodes)
+		require.NoError(t, err, "Error sending prioritize request")
+		verifyPrioritizeResponse(t, nodes, defaultScores, prioritizeResponse)
+	}
+
+	// The built-in scores are used once the config map is deleted
+	err = core.Instance().DeleteConfigMap(SchedulerPolicyConfigMapName, SchedulerPolicyConfigMapNamespace)
+	require.NoError(t, err, "Error deleting scheduler policy config map")
+	pod.Annotations[schedulerPolicyAnnotation] = "locality"
+	for i := 0; i < 10 && extender.getSchedulerPolicy(pod).NodePriorityScore == 200; i++ {
+		time.Sleep(time.Second)
+	}
+	prioritizeResponse, err = sendPrioritizeRequest(pod, nodes)
+	require.NoError(t, err, "Error sending prioritize request")
+	verifyPrioritizeResponse(t, nodes, defaultScores, prioritizeResponse)
+}
@@ -0,0 +1,205 @@
+package extender
+
+import (
+	"strconv"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// locality has the rack, zone and region of the storage nodes, keyed by the
+// hostname of the node. It is used by both the extender and the scheduler
+// plugin to score nodes based on how close they are to the data for a volume.
+type locality struct {
+	idMap   map[string]*volume.NodeInfo
+	racks   map[string]string
+	zones   map[string]string
+	regions map[string]string
+}
+
+// volumeLocality has the racks, zones and regions where a volume is located
+type volumeLocality struct {
+	racks   []string
+	zones   []string
+	regions []string
+}
+
+// newLocality creates the locality for the storage nodes. The hostname of the
+// storage nodes should already have been replaced with the kubernetes
+// hostname to make it easier to match nodes when calculating scores.
+func newLocality(driverNodes []*volume.NodeInfo) *locality {
+	l := &locality{
+		idMap:   make(map[string]*volume.NodeInfo),
+		racks:   make(map[string]string),
+		zones:   make(map[string]string),
+		regions: make(map[string]string),
+	}
+	for _, dnode := range driverNodes {
+		l.idMap[dnode.StorageID] = dnode
+		// For any node that is offline remove the locality info so that we
+		// don't prioritize nodes close to it
+		if dnode.Status == volume.NodeOnline || dnode.Status == volume.NodeDegraded {
+			// Add region info into zone and zone info into rack so that we can
+			// differentiate same names in different localities
+			l.regions[dnode.Hostname] = dnode.Region
+			if l.regions[dnode.Hostname] != "" {
+				l.zones[dnode.Hostname] = l.regions[dnode.Hostname] + "-" + dnode.Zone
+			} else {
+				l.zones[dnode.Hostname] = dnode.Zone
+			}
+			if l.zones[dnode.Hostname] != "" {
+				l.racks[dnode.Hostname] = l.zones[dnode.Hostname] + "-" + dnode.Rack
+			} else {
+				l.racks[dnode.Hostname] = dnode.Rack
+			}
+		} else {
+			l.racks[dnode.Hostname] = ""
+			l.zones[dnode.Hostname] = ""
+			l.regions[dnode.Hostname] = ""
+		}
+	}
+	return l
+}
+
+// getVolumeLocality returns the racks, zones and regions where the volume is
+// located
+func (l *locality) getVolumeLocality(volumeInfo *volume.Info) *volumeLocality {
+	vl := &volumeLocality{}
+	for _, node := range volumeInfo.DataNodes {
+		if _, ok := l.idMap[node]; ok {
+			log.Debugf("ID: %v Hostname: %v", node, l.idMap[node].Hostname)
+			vl.regions = append(vl.regions, l.regions[l.idMap[node].Hostname])
+			vl.zones = append(vl.zones, l.zones[l.idMap[node].Hostname])
+			vl.racks = append(vl.racks, l.racks[l.idMap[node].Hostname])
+		} else {
+			log.Warnf("Node %v not found in list of nodes, skipping", node)
+		}
+	}
+	return vl
+}
+
+// getNodeScore returns the score of the node for a volume
+func (l *locality) getNodeScore(
+	node *v1.Node,
+	volumeInfo *volume.Info,
+	vl *volumeLocality,
+	storageNode *volume.NodeInfo,
+	policy *SchedulerPolicy,
+) float64 {
+	return policy.scaleByCapacity(
+		l.getLocalityScore(node, volumeInfo, vl, storageNode, policy),
+		storageNode)
+}
+
+func (l *locality) getLocalityScore(
+	node *v1.Node,
+	volumeInfo *volume.Info,
+	vl *volumeLocality,
+	storageNode *volume.NodeInfo,
+	policy *SchedulerPolicy,
+) float64 {
+	for _, address := range node.Status.Addresses {
+		if address.Type != v1.NodeHostName {
+			continue
+		}
+		nodeRack := l.racks[address.Address]
+		nodeZone := l.zones[address.Address]
+		nodeRegion := l.regions[address.Address]
+
+		for _, region := range vl.regions {
+			if region == nodeRegion || nodeRegion == "" {
+				for _, zone := range vl.zones {
+					if zone == nodeZone || nodeZone == "" {
+						for _, rack := range vl.racks {
+							if rack == nodeRack || nodeRack == "" {
+								for _, datanodeID := range volumeInfo.DataNodes {
+									if storageNode.StorageID == datanodeID {
+										if storageNode.Status == volume.NodeDegraded {
+											// Even if the volume data is local to the node
+											// the node is in degraded state. So the app won't benefit
+											// from hyperconvergence on this node. So we will not use
+											// the nodePriorityScore but instead rackPriorityScore and
+											// penalize based on that.
+											return policy.degrade(policy.RackPriorityScore)
+										}
+										return policy.NodePriorityScore
+									}
+								}
+								if nodeRack != "" {
+									if storageNode.Status == volume.NodeDegraded {
+										return policy.degrade(policy.RackPriorityScore)
+									}
+									return policy.RackPriorityScore
+								}
+							}
+						}
+						if nodeZone != "" {
+							if storageNode.Status == volume.NodeDegraded {
+								return policy.degrade(policy.ZonePriorityScore)
+							}
+							return policy.ZonePriorityScore
+						}
+					}
+				}
+				if nodeRegion != "" {
+					if storageNode.Status == volume.NodeDegraded {
+						return policy.degrade(policy.RegionPriorityScore)
+					}
+					return policy.RegionPriorityScore
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// skipVolumeScoring returns true if the volume and its replicas shouldn't be
+// considered when scoring nodes
+func skipVolumeScoring(volumeInfo *volume.Info) bool {
+	if value, exists := volumeInfo.Labels[skipScoringLabel]; exists {
+		if skip, err := strconv.ParseBool(value); err == nil {
+			return skip
+		}
+	}
+	return false
+}
+
+// preferLocalNodeOnly returns true if the pod should only be scheduled on
+// nodes that have a replica for all its volumes
+func preferLocalNodeOnly(pod *v1.Pod) bool {
+	return getBoolAnnotation(pod, preferLocalNodeOnlyAnnotation)
+}
+
+// hyperconvergenceDisabled returns true if all nodes should be scored the
+// same for the pod
+func hyperconvergenceDisabled(pod *v1.Pod) bool {
+	return getBoolAnnotation(pod, disableHyperconvergenceAnnotation)
+}
+
+func getBoolAnnotation(pod *v1.Pod, annotation string) bool {
+	if pod.Annotations != nil {
+		if value, ok := pod.Annotations[annotation]; ok {
+			if enabled, err := strconv.ParseBool(value); err == nil {
+				return enabled
+			}
+		}
+	}
+	return false
+}
+
+// hasOnlineReplicas returns false if the volume has replicas and none of the
+// nodes with a replica are online
+func hasOnlineReplicas(volumeInfo *volume.Info, driverNodes []*volume.NodeInfo) bool {
+	if len(volumeInfo.DataNodes) == 0 {
+		return true
+	}
+	for _, volumeNode := range volumeInfo.DataNodes {
+		for _, driverNode := range driverNodes {
+			if volumeNode == driverNode.StorageID && driverNode.Status == volume.NodeOnline {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+package extender
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	storklog "github.com/libopenstorage/stork/pkg/log"
+	restore "github.com/libopenstorage/stork/pkg/snapshot/controllers"
+	"github.com/portworx/sched-ops/k8s/core"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const (
+	// PluginName is the name of the scheduler framework plugin
+	PluginName = "Stork"
+	// pluginStateKey is the key for the state cached by PreFilter in the
+	// cycle state of a pod
+	pluginStateKey framework.StateKey = PluginName + "/state"
+)
+
+// Plugin is a scheduler framework plugin that filters and scores nodes for
+// pods using the same locality logic as the extender. The volumes of the pod
+// and the storage nodes are fetched from the driver once per scheduling cycle
+// in PreFilter, instead of on every filter and prioritize request.
+type Plugin struct {
+	Driver   volume.Driver
+	handle   framework.Handle
+	policies schedulerPolicies
+}
+
+var _ framework.PreFilterPlugin = &Plugin{}
+var _ framework.FilterPlugin = &Plugin{}
+var _ framework.ScorePlugin = &Plugin{}
+var _ framework.ScoreExtensions = &Plugin{}
+
+// NewPluginFactory returns the factory used to register the plugin with the
+// scheduler for the given driver. It is registered in cmd/stork-scheduler
+// with app.WithPlugin(PluginName, ...)
+func NewPluginFactory(driver volume.Driver) func(runtime.Object, framework.Handle) (framework.Plugin, error) {
+	return func(_ runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+		p := &Plugin{
+			Driver: driver,
+			handle: handle,
+		}
+		if err := p.policies.watch(); err != nil {
+			return nil, fmt.Errorf("error watching scheduler policies: %v", err)
+		}
+		return p, nil
+	}
+}
+
+// pluginState is the state for a pod computed in PreFilter and used by Filter
+// and Score. It isn't modified once created so it isn't deep copied on Clone.
+type pluginState struct {
+	// skip is set if the pod doesn't use any volumes from the driver, or
+	// the driver couldn't be queried. All nodes are allowed in that case
+	skip          bool
+	driverVolumes []*volume.Info
+	// storageNodes are the storage nodes keyed by the name of the kubernetes
+	// node they are running on
+	storageNodes     map[string]*volume.NodeInfo
+	preferLocalOnly  bool
+	nodeVolumeCounts map[string]int
+	locality         *locality
+	policy           *SchedulerPolicy
+	disableScoring   bool
+}
+
+// Clone returns the state, which is never modified after PreFilter
+func (s *pluginState) Clone() framework.StateData {
+	return s
+}
+
+// Name returns the name of the plugin
+func (p *Plugin) Name() string {
+	return PluginName
+}
+
+// PreFilter gets the volumes for the pod and the storage nodes from the
+// driver and caches them in the cycle state
+func (p *Plugin) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod) *framework.Status {
+	state := &pluginState{}
+	cycleState.Write(pluginStateKey, state)
+
+	for _, vol := range pod.Spec.Volumes {
+		// if any of pvc has restore annotation skip scheduling pod
+		if vol.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := core.Instance().GetPersistentVolumeClaim(vol.PersistentVolumeClaim.ClaimName, pod.Namespace)
+		if err != nil {
+			msg := fmt.Sprintf("Unable to find PVC %s, err: %v", vol.Name, err)
+			storklog.PodLog(pod).Warnf(msg)
+			return framework.NewStatus(framework.Unschedulable, msg)
+		} else if pvc.Annotations != nil && pvc.Annotations[restore.RestoreAnnotation] == "true" {
+			msg := "Volume restore is in progress for pvc: " + pvc.Name
+			storklog.PodLog(pod).Warnf(msg)
+			return framework.NewStatus(framework.Unschedulable, msg)
+		}
+	}
+
+	driverVolumes, WFFCVolumes, err := p.Driver.GetPodVolumes(&pod.Spec, pod.Namespace, true)
+	if err != nil {
+		storklog.PodLog(pod).Warnf("Error getting volumes for Pod for driver: %v", err)
+		if _, ok := err.(*volume.ErrPVCPending); ok {
+			return framework.NewStatus(framework.Unschedulable, "Waiting for PVC to be bound")
+		}
+		state.skip = true
+		return nil
+	}
+	if len(driverVolumes) == 0 && len(WFFCVolumes) == 0 {
+		state.skip = true
+		return nil
+	}
+
+	driverNodes, err := p.Driver.GetNodes()
+	if err != nil {
+		storklog.PodLog(pod).Errorf("Error getting list of driver nodes, allowing all nodes, err: %v", err)
+		state.skip = true
+		return nil
+	}
+	for _, volumeInfo := range driverVolumes {
+		if !hasOnlineReplicas(volumeInfo, driverNodes) {
+			storklog.PodLog(pod).Errorf("No online storage nodes have replica for volume %v", volumeInfo.VolumeName)
+			return framework.NewStatus(framework.Unschedulable, "No online node found with volume replica")
+		}
+	}
+
+	nodeInfos, err := p.handle.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return framework.NewStatus(framework.Error, fmt.Sprintf("error listing nodes: %v", err))
+	}
+	state.storageNodes = make(map[string]*volume.NodeInfo)
+	for _, dnode := range driverNodes {
+		// Replace driver's hostname with the kubernetes hostname to make it
+		// easier to match nodes when calculating scores
+		for _, nodeInfo := range nodeInfos {
+			node := nodeInfo.Node()
+			if node != nil && volume.IsNodeMatch(node, dnode) {
+				dnode.Hostname = getHostname(node)
+				state.storageNodes[node.Name] = dnode
+				break
+			}
+		}
+	}
+
+	state.driverVolumes = driverVolumes
+	state.preferLocalOnly = preferLocalNodeOnly(pod)
+	if state.preferLocalOnly {
+		// Get nodes that have replicas for all the volumes
+		state.nodeVolumeCounts = make(map[string]int)
+		for _, volumeInfo := range driverVolumes {
+			for _, volumeNode := range volumeInfo.DataNodes {
+				state.nodeVolumeCounts[volumeNode]++
+			}
+		}
+	}
+	state.locality = newLocality(driverNodes)
+	state.policy = p.policies.get(pod)
+	state.disableScoring = hyperconvergenceDisabled(pod)
+	return nil
+}
+
+// PreFilterExtensions returns nil since the state doesn't depend on the other
+// pods on a node
+func (p *Plugin) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+// Filter filters out nodes where the storage driver isn't running, and nodes
+// without a replica for all the volumes if the pod prefers local nodes only
+func (p *Plugin) Filter(
+	ctx context.Context,
+	cycleState *framework.CycleState,
+	pod *v1.Pod,
+	nodeInfo *framework.NodeInfo,
+) *framework.Status {
+	state, err := getPluginState(cycleState)
+	if err != nil {
+		return framework.NewStatus(framework.Error, err.Error())
+	}
+	if state.skip {
+		return nil
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+	storageNode, ok := state.storageNodes[node.Name]
+	if !ok || (storageNode.Status != volume.NodeOnline && storageNode.Status != volume.NodeDegraded) {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, "Storage driver is not running on node")
+	}
+	// If only nodes with replicas are to be preferred, filter out all nodes
+	// that don't have a replica for all the volumes
+	if state.preferLocalOnly && state.nodeVolumeCounts[storageNode.StorageID] != len(state.driverVolumes) {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, "Node does not have a replica for all volumes")
+	}
+	return nil
+}
+
+// Score scores the node based on how close it is to the data for the volumes
+// of the pod
+func (p *Plugin) Score(
+	ctx context.Context,
+	cycleState *framework.CycleState,
+	pod *v1.Pod,
+	nodeName string,
+) (int64, *framework.Status) {
+	state, err := getPluginState(cycleState)
+	if err != nil {
+		return 0, framework.NewStatus(framework.Error, err.Error())
+	}
+	if state.skip || state.disableScoring || len(state.driverVolumes) == 0 {
+		return int64(defaultScore), nil
+	}
+	nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("error getting node %v: %v", nodeName, err))
+	}
+	storageNode, ok := state.storageNodes[nodeName]
+	if !ok {
+		return int64(defaultScore), nil
+	}
+
+	score := 0
+	for _, volumeInfo := range state.driverVolumes {
+		if skipVolumeScoring(volumeInfo) {
+			continue
+		}
+		volumeLocality := state.locality.getVolumeLocality(volumeInfo)
+		score += int(state.locality.getNodeScore(nodeInfo.Node(), volumeInfo, volumeLocality, storageNode, state.policy))
+	}
+	// Nodes that don't have data for any volume get a default score so that
+	// they don't get completely ignored
+	if score == 0 {
+		score = int(defaultScore)
+	}
+	return int64(score), nil
+}
+
+// ScoreExtensions returns the plugin, which normalizes the scores
+func (p *Plugin) ScoreExtensions() framework.ScoreExtensions {
+	return p
+}
+
+// NormalizeScore scales the scores to the range expected by the scheduler,
+// since the scores for multiple volumes are added up
+func (p *Plugin) NormalizeScore(
+	ctx context.Context,
+	cycleState *framework.CycleState,
+	pod *v1.Pod,
+	scores framework.NodeScoreList,
+) *framework.Status {
+	var highest int64
+	for _, score := range scores {
+		if score.Score > highest {
+			highest = score.Score
+		}
+	}
+	if highest <= framework.MaxNodeScore {
+		return nil
+	}
+	for i := range scores {
+		scores[i].Score = scores[i].Score * framework.MaxNodeScore / highest
+	}
+	return nil
+}
+
+func getPluginState(cycleState *framework.CycleState) (*pluginState, error) {
+	data, err := cycleState.Read(pluginStateKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %v from cycle state: %v", pluginStateKey, err)
+	}
+	state, ok := data.(*pluginState)
+	if !ok {
+		return nil, fmt.Errorf("invalid state for %v: %T", pluginStateKey, data)
+	}
+	return state, nil
+}
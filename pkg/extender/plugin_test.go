@@ -0,0 +1,150 @@
+//go:build unittest
+// +build unittest
+
+package extender
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+type fakeHandle struct {
+	framework.Handle
+	nodes []*framework.NodeInfo
+}
+
+func (f *fakeHandle) SnapshotSharedLister() framework.SharedLister {
+	return f
+}
+
+func (f *fakeHandle) NodeInfos() framework.NodeInfoLister {
+	return f
+}
+
+func (f *fakeHandle) List() ([]*framework.NodeInfo, error) {
+	return f.nodes, nil
+}
+
+func (f *fakeHandle) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *fakeHandle) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *fakeHandle) Get(nodeName string) (*framework.NodeInfo, error) {
+	for _, nodeInfo := range f.nodes {
+		if nodeInfo.Node().Name == nodeName {
+			return nodeInfo, nil
+		}
+	}
+	return nil, fmt.Errorf("node %v not found", nodeName)
+}
+
+func newPlugin(t *testing.T, nodes *v1.NodeList) *Plugin {
+	handle := &fakeHandle{}
+	for i := range nodes.Items {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(&nodes.Items[i])
+		handle.nodes = append(handle.nodes, nodeInfo)
+	}
+	p := &Plugin{
+		Driver: driver,
+		handle: handle,
+	}
+	require.NoError(t, p.policies.load(), "Error loading scheduler policies")
+	return p
+}
+
+// runPlugin runs the plugin for the pod and returns the nodes that passed
+// the filter along with the normalized scores for them
+func runPlugin(t *testing.T, p *Plugin, pod *v1.Pod) ([]string, []int64) {
+	state := framework.NewCycleState()
+	ctx := context.TODO()
+	status := p.PreFilter(ctx, state, pod)
+	require.True(t, status.IsSuccess(), "PreFilter failed: %v", status.Message())
+
+	filtered := make([]string, 0)
+	nodeInfos, err := p.handle.SnapshotSharedLister().NodeInfos().List()
+	require.NoError(t, err, "Error listing nodes")
+	for _, nodeInfo := range nodeInfos {
+		if p.Filter(ctx, state, pod, nodeInfo).IsSuccess() {
+			filtered = append(filtered, nodeInfo.Node().Name)
+		}
+	}
+
+	scores := make(framework.NodeScoreList, 0)
+	for _, name := range filtered {
+		score, status := p.Score(ctx, state, pod, name)
+		require.True(t, status.IsSuccess(), "Score failed: %v", status.Message())
+		scores = append(scores, framework.NodeScore{Name: name, Score: score})
+	}
+	status = p.ScoreExtensions().NormalizeScore(ctx, state, pod, scores)
+	require.True(t, status.IsSuccess(), "NormalizeScore failed: %v", status.Message())
+	normalized := make([]int64, 0)
+	for _, score := range scores {
+		normalized = append(normalized, score.Score)
+	}
+	return filtered, normalized
+}
+
+// Run the scheduler plugin for a pod with 2 PVCs using the mock storage
+// class. Place the data for volume1 on nodes n1, n2 and for volume2 on nodes
+// n2, n3. Node n4 is offline. The plugin should filter out n4 and score the
+// nodes the same way as the extender, normalized to the maximum node score.
+func pluginTest(t *testing.T) {
+	nodes := &v1.NodeList{}
+	nodes.Items = append(nodes.Items, *newNode("node1.domain", "node1.domain", "192.168.0.1", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node2.domain", "node2.domain", "192.168.0.2", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node3.domain", "node3.domain", "192.168.0.3", "rack1", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node4.domain", "node4.domain", "192.168.0.4", "rack2", "", ""))
+	nodes.Items = append(nodes.Items, *newNode("node5.domain", "node5.domain", "192.168.0.5", "rack3", "", ""))
+
+	if err := driver.CreateCluster(5, nodes); err != nil {
+		t.Fatalf("Error creating cluster: %v", err)
+	}
+	pod := newPod("pluginTest", map[string]bool{"pluginVolume1": false, "pluginVolume2": false})
+	if err := driver.ProvisionVolume("pluginVolume1", []int{0, 1}, 1, nil); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	if err := driver.ProvisionVolume("pluginVolume2", []int{1, 2}, 1, nil); err != nil {
+		t.Fatalf("Error provisioning volume: %v", err)
+	}
+	if err := driver.UpdateNodeStatus(3, volume.NodeOffline); err != nil {
+		t.Fatalf("Error setting node status to Offline: %v", err)
+	}
+
+	p := newPlugin(t, nodes)
+	filtered, scores := runPlugin(t, p, pod)
+	require.Equal(t, []string{"node1.domain", "node2.domain", "node3.domain", "node5.domain"}, filtered)
+	highest := 2 * nodePriorityScore
+	require.Equal(t, []int64{
+		int64((nodePriorityScore + rackPriorityScore) * 100 / highest),
+		100,
+		int64((nodePriorityScore + rackPriorityScore) * 100 / highest),
+		int64(defaultScore * 100 / highest),
+	}, scores)
+
+	// All nodes are scored the same if hyperconvergence is disabled
+	pod.Annotations[disableHyperconvergenceAnnotation] = "true"
+	_, scores = runPlugin(t, p, pod)
+	require.Equal(t, []int64{int64(defaultScore), int64(defaultScore), int64(defaultScore), int64(defaultScore)}, scores)
+	delete(pod.Annotations, disableHyperconvergenceAnnotation)
+
+	// Only nodes with a replica for all the volumes pass the filter
+	pod.Annotations[preferLocalNodeOnlyAnnotation] = "true"
+	filtered, _ = runPlugin(t, p, pod)
+	require.Equal(t, []string{"node2.domain"}, filtered)
+
+	// Pods without driver volumes can be scheduled on any node
+	filtered, scores = runPlugin(t, p, newPod("pluginNoVolumeTest", nil))
+	require.Equal(t, []string{"node1.domain", "node2.domain", "node3.domain", "node4.domain", "node5.domain"}, filtered)
+	require.Equal(t, []int64{int64(defaultScore), int64(defaultScore), int64(defaultScore), int64(defaultScore), int64(defaultScore)}, scores)
+}
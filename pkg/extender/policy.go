@@ -0,0 +1,198 @@
+package extender
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/libopenstorage/stork/drivers/volume"
+	"github.com/libopenstorage/stork/pkg/k8sutils"
+	"github.com/portworx/sched-ops/k8s/core"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// SchedulerPolicyConfigMapName is the name of the config map with the
+	// policies used to score nodes. Each key is the name of a policy and the
+	// value is the policy in YAML or JSON
+	SchedulerPolicyConfigMapName = "stork-scheduler-policies"
+	// SchedulerPolicyConfigMapNamespace is the namespace of the config map
+	// with the policies used to score nodes
+	SchedulerPolicyConfigMapNamespace = k8sutils.DefaultAdminNamespace
+	// DefaultSchedulerPolicyName is the name of the policy used for pods that
+	// don't select one. If it isn't present in the config map the built-in
+	// scores are used
+	DefaultSchedulerPolicyName = "default"
+	// annotation to select the policy used to score nodes for a pod
+	schedulerPolicyAnnotation = "stork.libopenstorage.org/schedulerPolicy"
+)
+
+// SchedulerPolicy has the scores used to prioritize nodes for a pod. Fields
+// that aren't set in a policy keep the built-in defaults
+type SchedulerPolicy struct {
+	// NodePriorityScore is the score by which a node is bumped if it has data
+	// for a volume
+	NodePriorityScore float64 `json:"nodePriorityScore"`
+	// RackPriorityScore is the score by which a node is bumped if it is in
+	// the same rack as a node which has data for a volume
+	RackPriorityScore float64 `json:"rackPriorityScore"`
+	// ZonePriorityScore is the score by which a node is bumped if it is in
+	// the same zone as a node which has data for a volume
+	ZonePriorityScore float64 `json:"zonePriorityScore"`
+	// RegionPriorityScore is the score by which a node is bumped if it is in
+	// the same region as a node which has data for a volume
+	RegionPriorityScore float64 `json:"regionPriorityScore"`
+	// DegradedNodePenaltyPercentage is the percentage by which the score of a
+	// degraded node is reduced
+	DegradedNodePenaltyPercentage float64 `json:"degradedNodePenaltyPercentage"`
+	// ConsiderNodeCapacity scales the score of a node by the fraction of its
+	// storage capacity that is free, for drivers that report the capacity
+	ConsiderNodeCapacity bool `json:"considerNodeCapacity"`
+}
+
+func defaultSchedulerPolicy() *SchedulerPolicy {
+	return &SchedulerPolicy{
+		NodePriorityScore:             nodePriorityScore,
+		RackPriorityScore:             rackPriorityScore,
+		ZonePriorityScore:             zonePriorityScore,
+		RegionPriorityScore:           regionPriorityScore,
+		DegradedNodePenaltyPercentage: degradedNodeScorePenaltyPercentage,
+	}
+}
+
+// Validate validates a SchedulerPolicy
+func (s *SchedulerPolicy) Validate() error {
+	if s.NodePriorityScore < 0 || s.RackPriorityScore < 0 || s.ZonePriorityScore < 0 || s.RegionPriorityScore < 0 {
+		return fmt.Errorf("scores can't be negative")
+	}
+	if s.DegradedNodePenaltyPercentage < 0 || s.DegradedNodePenaltyPercentage > 100 {
+		return fmt.Errorf("degradedNodePenaltyPercentage should be between 0 and 100")
+	}
+	return nil
+}
+
+// degrade returns the score for a node that is degraded
+func (s *SchedulerPolicy) degrade(score float64) float64 {
+	return score * (s.DegradedNodePenaltyPercentage / 100)
+}
+
+// scaleByCapacity scales the score by the fraction of the storage capacity of
+// the node that is free
+func (s *SchedulerPolicy) scaleByCapacity(score float64, storageNode *volume.NodeInfo) float64 {
+	if !s.ConsiderNodeCapacity || storageNode == nil || storageNode.TotalCapacity == 0 {
+		return score
+	}
+	if storageNode.UsedCapacity >= storageNode.TotalCapacity {
+		return 0
+	}
+	free := storageNode.TotalCapacity - storageNode.UsedCapacity
+	return score * float64(free) / float64(storageNode.TotalCapacity)
+}
+
+// parseSchedulerPolicies parses the policies in the config map. Policies that
+// are invalid are skipped so that they don't affect the others
+func parseSchedulerPolicies(cm *v1.ConfigMap) map[string]*SchedulerPolicy {
+	policies := map[string]*SchedulerPolicy{
+		DefaultSchedulerPolicyName: defaultSchedulerPolicy(),
+	}
+	if cm == nil {
+		return policies
+	}
+	for name, value := range cm.Data {
+		policy := defaultSchedulerPolicy()
+		if err := yaml.NewYAMLOrJSONDecoder(strings.NewReader(value), len(value)+1).Decode(policy); err != nil {
+			log.Errorf("Error parsing scheduler policy %v, skipping: %v", name, err)
+			continue
+		}
+		if err := policy.Validate(); err != nil {
+			log.Errorf("Invalid scheduler policy %v, skipping: %v", name, err)
+			continue
+		}
+		policies[name] = policy
+	}
+	return policies
+}
+
+// schedulerPolicies are the policies loaded from the config map, shared by
+// the extender and the scheduler plugin
+type schedulerPolicies struct {
+	sync.RWMutex
+	policies map[string]*SchedulerPolicy
+}
+
+// load reads the policies from the config map. The built-in scores are used
+// if the config map doesn't exist
+func (s *schedulerPolicies) load() error {
+	cm, err := core.Instance().GetConfigMap(SchedulerPolicyConfigMapName, SchedulerPolicyConfigMapNamespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		cm = nil
+	}
+	policies := parseSchedulerPolicies(cm)
+	s.Lock()
+	s.policies = policies
+	s.Unlock()
+	log.Infof("Loaded %v scheduler policies", len(policies))
+	return nil
+}
+
+// watch reloads the policies whenever the config map is created, updated or
+// deleted
+func (s *schedulerPolicies) watch() error {
+	if err := s.load(); err != nil {
+		return err
+	}
+	fn := func(object runtime.Object) error {
+		if _, ok := object.(*v1.ConfigMap); !ok {
+			return fmt.Errorf("invalid object type on configmap watch: %v", object)
+		}
+		if err := s.load(); err != nil {
+			log.Errorf("Error reloading scheduler policies: %v", err)
+			return err
+		}
+		return nil
+	}
+	return core.Instance().WatchConfigMap(
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      SchedulerPolicyConfigMapName,
+				Namespace: SchedulerPolicyConfigMapNamespace,
+			},
+		},
+		fn)
+}
+
+// get returns the policy selected by the pod, or the default policy if it
+// doesn't select one or the one it selects doesn't exist
+func (s *schedulerPolicies) get(pod *v1.Pod) *SchedulerPolicy {
+	s.RLock()
+	defer s.RUnlock()
+	name := DefaultSchedulerPolicyName
+	if pod.Annotations != nil {
+		if value, ok := pod.Annotations[schedulerPolicyAnnotation]; ok && value != "" {
+			name = value
+		}
+	}
+	if policy, ok := s.policies[name]; ok {
+		return policy
+	}
+	if name != DefaultSchedulerPolicyName {
+		log.Warnf("Scheduler policy %v for pod %v/%v not found, using default policy", name, pod.Namespace, pod.Name)
+	}
+	if policy, ok := s.policies[DefaultSchedulerPolicyName]; ok {
+		return policy
+	}
+	return defaultSchedulerPolicy()
+}
+
+// getSchedulerPolicy returns the policy used to score nodes for the pod
+func (e *Extender) getSchedulerPolicy(pod *v1.Pod) *SchedulerPolicy {
+	return e.policies.get(pod)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -46,6 +48,11 @@ const (
 	volumeSnapshotInitialDelay = 2 * time.Second
 	volumeSnapshotFactor       = 1
 	volumeSnapshotSteps        = 60
+
+	// GroupSnapshotPVCNamespaceAnnotation is the annotation on the volume
+	// snapshots of a group snapshot across namespaces with the namespace of
+	// the PVC that was snapshotted
+	GroupSnapshotPVCNamespaceAnnotation = "stork.libopenstorage.org/groupsnapshot-pvc-namespace"
 )
 
 var snapDeleteBackoff = wait.Backoff{
@@ -71,15 +78,18 @@ type GroupSnapshotController struct {
 	recorder            record.EventRecorder
 	bgChannelsForRules  map[string]chan bool
 	minResourceVersions map[string]string
+	adminNamespace      string
 }
 
 // Init Initialize the groupSnapshot controller
-func (m *GroupSnapshotController) Init(mgr manager.Manager) error {
+func (m *GroupSnapshotController) Init(mgr manager.Manager, adminNamespace string) error {
 	err := m.createCRD()
 	if err != nil {
 		return err
 	}
 
+	m.adminNamespace = adminNamespace
+
 	m.bgChannelsForRules = make(map[string]chan bool)
 	m.minResourceVersions = make(map[string]string)
 
@@ -269,13 +279,25 @@ func (m *GroupSnapshotController) handleInitial(groupSnap *stork_api.GroupVolume
 		err = fmt.Errorf("matchLabels are required for group snapshots. Refer to spec examples")
 	}
 
+	if !m.namespaceGroupSnapshotAllowed(groupSnap) {
+		err = fmt.Errorf("group snapshots can only select PVCs from other namespaces if they are "+
+			"created in the admin namespace: %v", m.adminNamespace)
+	}
+
+	if validateErr := validateRestoreNamespaces(groupSnap.Spec.RestoreNamespaces); validateErr != nil {
+		err = validateErr
+	}
+
 	if err != nil {
 		groupSnap.Status.Status = stork_api.GroupSnapshotFailed
 		groupSnap.Status.Stage = stork_api.GroupSnapshotStageFinal
 		return updateCRD, err
 	}
 
-	_, err = k8sutils.GetPVCsForGroupSnapshot(groupSnap.Namespace, groupSnap.Spec.PVCSelector.MatchLabels)
+	namespaces, err := getPVCNamespaces(groupSnap)
+	if err == nil {
+		_, err = k8sutils.GetPVCsForGroupSnapshotInNamespaces(namespaces, groupSnap.Spec.PVCSelector.MatchLabels)
+	}
 	if err != nil {
 		if groupSnap.Status.Status == stork_api.GroupSnapshotPending {
 			return !updateCRD, err
@@ -285,21 +307,29 @@ func (m *GroupSnapshotController) handleInitial(groupSnap *stork_api.GroupVolume
 		groupSnap.Status.Stage = stork_api.GroupSnapshotStagePreChecks
 	} else {
 		// Validate pre and post snap rules
+		var preSnapRule, postSnapRule *stork_api.Rule
 		preSnapRuleName := groupSnap.Spec.PreExecRule
 		if len(preSnapRuleName) > 0 {
-			if _, err := storkops.Instance().GetRule(preSnapRuleName, groupSnap.Namespace); err != nil {
+			if preSnapRule, err = storkops.Instance().GetRule(preSnapRuleName, groupSnap.Namespace); err != nil {
 				return !updateCRD, err
 			}
 		}
 
 		postSnapRuleName := groupSnap.Spec.PostExecRule
 		if len(postSnapRuleName) > 0 {
-			if _, err := storkops.Instance().GetRule(postSnapRuleName, groupSnap.Namespace); err != nil {
+			if postSnapRule, err = storkops.Instance().GetRule(postSnapRuleName, groupSnap.Namespace); err != nil {
 				return !updateCRD, err
 			}
 		}
 
+		if err := rule.ValidateRules(preSnapRule, postSnapRule); err != nil {
+			return !updateCRD, err
+		}
+
 		groupSnap.Status.Status = stork_api.GroupSnapshotInProgress
+		// Use the same namespaces for the rules and the snapshots even if
+		// the namespace labels change
+		groupSnap.Status.Namespaces = namespaces
 
 		if len(preSnapRuleName) > 0 {
 			// done with pre-checks, move to pre-snapshot stage
@@ -313,6 +343,88 @@ func (m *GroupSnapshotController) handleInitial(groupSnap *stork_api.GroupVolume
 	return updateCRD, err
 }
 
+// namespaceGroupSnapshotAllowed returns false if the group snapshot selects
+// PVCs from other namespaces but isn't in the admin namespace
+func (m *GroupSnapshotController) namespaceGroupSnapshotAllowed(groupSnap *stork_api.GroupVolumeSnapshot) bool {
+	if groupSnap.Namespace == m.adminNamespace {
+		return true
+	}
+	if len(groupSnap.Spec.NamespaceSelector) > 0 {
+		return false
+	}
+	for _, ns := range groupSnap.Spec.Namespaces {
+		if ns != groupSnap.Namespace {
+			return false
+		}
+	}
+	return true
+}
+
+// getPVCNamespaces returns the namespaces in which the PVCs are selected for
+// the group snapshot. The namespaces are sorted so that the rules are run in
+// the same order every time.
+func getPVCNamespaces(groupSnap *stork_api.GroupVolumeSnapshot) ([]string, error) {
+	if len(groupSnap.Spec.Namespaces) == 0 && len(groupSnap.Spec.NamespaceSelector) == 0 {
+		return []string{groupSnap.Namespace}, nil
+	}
+
+	namespaces := make(map[string]bool)
+	for _, ns := range groupSnap.Spec.Namespaces {
+		namespaces[ns] = true
+	}
+	if len(groupSnap.Spec.NamespaceSelector) > 0 {
+		nsList, err := core.Instance().ListNamespaces(groupSnap.Spec.NamespaceSelector)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nsList.Items {
+			namespaces[ns.Name] = true
+		}
+	}
+	if len(namespaces) == 0 {
+		return nil, fmt.Errorf("found no namespaces for group snapshot with given namespace selector: %v",
+			groupSnap.Spec.NamespaceSelector)
+	}
+
+	namespaceList := make([]string, 0, len(namespaces))
+	for ns := range namespaces {
+		namespaceList = append(namespaceList, ns)
+	}
+	sort.Strings(namespaceList)
+	return namespaceList, nil
+}
+
+// validateRestoreNamespaces checks that the restore namespaces are valid
+// namespace names
+func validateRestoreNamespaces(restoreNamespaces []string) error {
+	for _, ns := range restoreNamespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return fmt.Errorf("invalid restore namespace %q: %v", ns, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// getRestoreNamespaces returns the namespaces to which the snapshots of the
+// group snapshot can be restored. Snapshots of a group snapshot across
+// namespaces can always be restored to the namespaces of the PVCs since the
+// snapshots are created in the namespace of the group snapshot.
+func getRestoreNamespaces(groupSnap *stork_api.GroupVolumeSnapshot) []string {
+	if !groupSnap.IsCrossNamespace() {
+		return groupSnap.Spec.RestoreNamespaces
+	}
+	restoreNamespaces := make([]string, 0)
+	present := make(map[string]bool)
+	for _, ns := range append(groupSnap.Spec.RestoreNamespaces, groupSnap.GetPVCNamespaces()...) {
+		if ns == groupSnap.Namespace || present[ns] {
+			continue
+		}
+		present[ns] = true
+		restoreNamespaces = append(restoreNamespaces, ns)
+	}
+	return restoreNamespaces
+}
+
 func (m *GroupSnapshotController) handlePreSnap(groupSnap *stork_api.GroupVolumeSnapshot) (
 	*stork_api.GroupVolumeSnapshot, bool, error) {
 	ruleName := groupSnap.Spec.PreExecRule
@@ -329,12 +441,12 @@ func (m *GroupSnapshotController) handlePreSnap(groupSnap *stork_api.GroupVolume
 		return nil, !updateCRD, err
 	}
 
-	backgroundCommandTermChan, err := rule.ExecuteRule(r, rule.PreExecRule, groupSnap, groupSnap.Namespace)
+	backgroundCommandTermChan, result, err := rule.ExecuteRuleInNamespaces(r, rule.PreExecRule, groupSnap, groupSnap.GetPVCNamespaces())
 	if err != nil {
 		if backgroundCommandTermChan != nil {
 			backgroundCommandTermChan <- true // terminate background commands if running
 		}
-
+		m.updateRuleRuns(groupSnap, result)
 		return nil, !updateCRD, err
 	}
 
@@ -343,6 +455,8 @@ func (m *GroupSnapshotController) handlePreSnap(groupSnap *stork_api.GroupVolume
 	if err != nil {
 		return nil, !updateCRD, err
 	}
+	groupSnap.Status.RuleActionFailures = append(groupSnap.Status.RuleActionFailures, result.Failures...)
+	groupSnap.Status.RuleRuns = rule.AppendRuleRuns(groupSnap.Status.RuleRuns, result)
 
 	if backgroundCommandTermChan != nil {
 		snapUID := string(groupSnap.ObjectMeta.UID)
@@ -483,24 +597,36 @@ func (m *GroupSnapshotController) createSnapAndDataObjects(
 	}
 	parentUUID := groupSnap.GetUID()
 	snapLabels := groupSnap.GetLabels()
-	snapAnnotations := groupSnap.GetAnnotations()
 	createSnapObjects := make([]*crdv1.VolumeSnapshot, 0)
+	restoreNamespaces := getRestoreNamespaces(groupSnap)
 
-	if len(groupSnap.Spec.RestoreNamespaces) > 0 {
-		if len(snapAnnotations) == 0 {
-			snapAnnotations = make(map[string]string)
+	for _, snapshot := range snapshots {
+		// Drivers that create the volume snapshots themselves don't return a
+		// data source
+		if snapshot.DataSource == nil {
+			updatedStatues = append(updatedStatues, snapshot)
+			continue
 		}
 
-		snapAnnotations[snapshotcontrollers.StorkSnapshotRestoreNamespacesAnnotation] = strings.Join(groupSnap.Spec.RestoreNamespaces, ",")
-	}
-
-	for _, snapshot := range snapshots {
-		parentPVCOrVolID, err := m.getPVCNameFromVolumeID(snapshot.ParentVolumeID)
+		parentPVCOrVolID, parentPVCNamespace, err := m.getPVCNameFromVolumeID(snapshot.ParentVolumeID)
 		if err != nil {
 			return nil, err
 		}
 
+		snapAnnotations := make(map[string]string)
+		for k, v := range groupSnap.GetAnnotations() {
+			snapAnnotations[k] = v
+		}
+		if len(restoreNamespaces) > 0 {
+			snapAnnotations[snapshotcontrollers.StorkSnapshotRestoreNamespacesAnnotation] = strings.Join(restoreNamespaces, ",")
+		}
+
 		volumeSnapshotName := fmt.Sprintf("%s-%s-%s", parentName, parentPVCOrVolID, parentUUID)
+		// PVCs in different namespaces can have the same name
+		if groupSnap.IsCrossNamespace() && parentPVCNamespace != "" {
+			volumeSnapshotName = fmt.Sprintf("%s-%s-%s-%s", parentName, parentPVCNamespace, parentPVCOrVolID, parentUUID)
+			snapAnnotations[GroupSnapshotPVCNamespaceAnnotation] = parentPVCNamespace
+		}
 
 		var lastCondition crdv1.VolumeSnapshotDataCondition
 		if snapshot.Conditions != nil && len(snapshot.Conditions) > 0 {
@@ -636,26 +762,27 @@ func revertSnapObjs(snapObjs []*crdv1.VolumeSnapshot) {
 	logrus.Infof("Successfully reverted volumesnapshots")
 }
 
-// this is best effort as can be vol ID if PVC is deleted
-func (m *GroupSnapshotController) getPVCNameFromVolumeID(volID string) (string, error) {
+// this is best effort as can be vol ID if PVC is deleted. The namespace of
+// the PVC is empty in that case
+func (m *GroupSnapshotController) getPVCNameFromVolumeID(volID string) (string, string, error) {
 	volInfo, err := m.volDriver.InspectVolume(volID)
 	if err != nil {
 		logrus.Warnf("Volume: %s not found due to: %v", volID, err)
-		return volID, nil
+		return volID, "", nil
 	}
 
 	parentPV, err := core.Instance().GetPersistentVolume(volInfo.VolumeName)
 	if err != nil {
 		logrus.Warnf("Parent PV: %s not found due to: %v", volInfo.VolumeName, err)
-		return volID, nil
+		return volID, "", nil
 	}
 
 	pvc, err := core.Instance().GetPersistentVolumeClaim(parentPV.Spec.ClaimRef.Name, parentPV.Spec.ClaimRef.Namespace)
 	if err != nil {
-		return volID, nil
+		return volID, "", nil
 	}
 
-	return pvc.GetName(), nil
+	return pvc.GetName(), pvc.GetNamespace(), nil
 
 }
 
@@ -676,8 +803,9 @@ func (m *GroupSnapshotController) handlePostSnap(groupSnap *stork_api.GroupVolum
 		return nil, !updateCRD, err
 	}
 
-	_, err = rule.ExecuteRule(r, rule.PostExecRule, groupSnap, groupSnap.Namespace)
+	_, result, err := rule.ExecuteRuleInNamespaces(r, rule.PostExecRule, groupSnap, groupSnap.GetPVCNamespaces())
 	if err != nil {
+		m.updateRuleRuns(groupSnap, result)
 		return nil, !updateCRD, err
 	}
 
@@ -686,6 +814,8 @@ func (m *GroupSnapshotController) handlePostSnap(groupSnap *stork_api.GroupVolum
 	if err != nil {
 		return nil, !updateCRD, err
 	}
+	groupSnap.Status.RuleActionFailures = append(groupSnap.Status.RuleActionFailures, result.Failures...)
+	groupSnap.Status.RuleRuns = rule.AppendRuleRuns(groupSnap.Status.RuleRuns, result)
 
 	// done with post-snapshot, move to final stage
 	if groupSnap.Status.Status != stork_api.GroupSnapshotFailed {
@@ -695,12 +825,33 @@ func (m *GroupSnapshotController) handlePostSnap(groupSnap *stork_api.GroupVolum
 	return groupSnap, updateCRD, nil
 }
 
+// updateRuleRuns records the runs of a rule that failed in the group snapshot,
+// since it isn't updated when a stage fails
+func (m *GroupSnapshotController) updateRuleRuns(groupSnap *stork_api.GroupVolumeSnapshot, result *rule.Result) {
+	latest, err := storkops.Instance().GetGroupSnapshot(groupSnap.GetName(), groupSnap.GetNamespace())
+	if err != nil {
+		log.GroupSnapshotLog(groupSnap).Warnf("Failed to get group snapshot to record rule runs: %v", err)
+		return
+	}
+	latest.Status.RuleRuns = rule.AppendRuleRuns(latest.Status.RuleRuns, result)
+	SetKind(latest)
+	if err := m.client.Update(context.TODO(), latest); err != nil {
+		log.GroupSnapshotLog(groupSnap).Warnf("Failed to record rule runs in group snapshot: %v", err)
+	}
+}
+
 func (m *GroupSnapshotController) handleFinal(groupSnap *stork_api.GroupVolumeSnapshot) error {
-	// Check if user has updated restore namespace
-	childSnapshots := groupSnap.Status.VolumeSnapshots
+	// Check if user has updated restore namespace. This only applies to the
+	// volume snapshots created by the group snapshot controller
+	childSnapshots := make([]*stork_api.VolumeSnapshotStatus, 0)
+	for _, childSnap := range groupSnap.Status.VolumeSnapshots {
+		if childSnap.DataSource != nil {
+			childSnapshots = append(childSnapshots, childSnap)
+		}
+	}
 	if len(childSnapshots) > 0 {
 		currentRestoreNamespaces := ""
-		latestRestoreNamespacesInCSV := strings.Join(groupSnap.Spec.RestoreNamespaces, ",")
+		latestRestoreNamespacesInCSV := strings.Join(getRestoreNamespaces(groupSnap), ",")
 
 		vsObject, err := k8sextops.Instance().GetSnapshot(childSnapshots[0].VolumeSnapshotName, groupSnap.GetNamespace())
 		if err != nil {
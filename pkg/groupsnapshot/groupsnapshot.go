@@ -20,10 +20,10 @@ type GroupSnapshot struct {
 }
 
 // Init init
-func (m *GroupSnapshot) Init(mgr manager.Manager) error {
+func (m *GroupSnapshot) Init(mgr manager.Manager, adminNamespace string) error {
 	r := controllers.NewGroupSnapshot(mgr, m.Driver, m.Recorder)
 
-	if err := r.Init(mgr); err != nil {
+	if err := r.Init(mgr, adminNamespace); err != nil {
 		return fmt.Errorf("initializing groupSnapshot controller: %v", err)
 	}
 
@@ -0,0 +1,142 @@
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+)
+
+var (
+	clientLock       sync.Mutex
+	kubernetesConfig *rest.Config
+	kubernetesClient kubernetes.Interface
+)
+
+// GetKubernetesClient returns the config and a client for the cluster. It
+// uses the same config as the sched-ops clients, from the kubeconfig if set or
+// the service account otherwise. It is used for requests that need more
+// control than the sched-ops clients provide, like watches that need to be
+// stopped or reading the streams of an exec separately.
+func GetKubernetesClient() (*rest.Config, kubernetes.Interface, error) {
+	clientLock.Lock()
+	defer clientLock.Unlock()
+	if kubernetesClient != nil {
+		return kubernetesConfig, kubernetesClient, nil
+	}
+	config, err := clientcmd.BuildConfigFromFlags("", os.Getenv("KUBECONFIG"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting cluster config: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting kubernetes client: %v", err)
+	}
+	kubernetesConfig = config
+	kubernetesClient = client
+	return kubernetesConfig, kubernetesClient, nil
+}
+
+// ExecInPod runs the command in the container of the pod and streams its
+// stdout and stderr to the writers. The container can be left empty for pods
+// with a single container. The error returned for a command that exits with a
+// non-zero code wraps an exec.CodeExitError.
+func ExecInPod(cmd []string, podName, container, namespace string, stdout, stderr io.Writer) error {
+	return ExecInPodWithContext(context.Background(), cmd, podName, container, namespace, stdout, stderr)
+}
+
+// ExecInPodWithContext is like ExecInPod but closes the connection to the
+// pod when the context is done, so the exec doesn't outlive the caller. The
+// command itself can keep running in the container.
+func ExecInPodWithContext(
+	ctx context.Context,
+	cmd []string,
+	podName, container, namespace string,
+	stdout, stderr io.Writer,
+) error {
+	config, client, err := GetKubernetesClient()
+	if err != nil {
+		return err
+	}
+	if container == "" {
+		pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if len(pod.Spec.Containers) != 1 {
+			return fmt.Errorf("could not determine which container to use")
+		}
+		container = pod.Spec.Containers[0].Name
+	}
+
+	req := client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec")
+	req.VersionedParams(&v1.PodExecOptions{
+		Container: container,
+		Command:   cmd,
+		Stdout:    stdout != nil,
+		Stderr:    stderr != nil,
+	}, scheme.ParameterCodec)
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return fmt.Errorf("failed to init executor: %v", err)
+	}
+	executor, err := remotecommand.NewSPDYExecutorForTransports(
+		transport,
+		&contextUpgrader{ctx: ctx, upgrader: upgrader},
+		"POST",
+		req.URL(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to init executor: %v", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err = executor.Stream(remotecommand.StreamOptions{
+		Stdout: stdout,
+		Stderr: stderr,
+		Tty:    false,
+	})
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return fmt.Errorf("%v: %v", ctxErr, err)
+	}
+	return err
+}
+
+// contextUpgrader closes the connections it creates when the context is
+// done, which makes the stream waiting on them return
+type contextUpgrader struct {
+	ctx      context.Context
+	upgrader spdy.Upgrader
+}
+
+func (u *contextUpgrader) NewConnection(resp *http.Response) (httpstream.Connection, error) {
+	conn, err := u.upgrader.NewConnection(resp)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		select {
+		case <-u.ctx.Done():
+			_ = conn.Close()
+		case <-conn.CloseChan():
+		}
+	}()
+	return conn, nil
+}
@@ -53,30 +53,46 @@ type JSONPatchOp struct {
 
 // GetPVCsForGroupSnapshot returns all PVCs in given namespace that match the given matchLabels. All PVCs need to be bound.
 func GetPVCsForGroupSnapshot(namespace string, matchLabels map[string]string) ([]v1.PersistentVolumeClaim, error) {
-	pvcList, err := core.Instance().GetPersistentVolumeClaims(namespace, matchLabels)
-	if err != nil {
-		return nil, err
+	return GetPVCsForGroupSnapshotInNamespaces([]string{namespace}, matchLabels)
+}
+
+// GetPVCsForGroupSnapshotInNamespaces returns all PVCs in the given namespaces that match the given matchLabels.
+// All PVCs need to be bound.
+func GetPVCsForGroupSnapshotInNamespaces(namespaces []string, matchLabels map[string]string) ([]v1.PersistentVolumeClaim, error) {
+	pvcs := make([]v1.PersistentVolumeClaim, 0)
+	for _, namespace := range namespaces {
+		pvcList, err := core.Instance().GetPersistentVolumeClaims(namespace, matchLabels)
+		if err != nil {
+			return nil, err
+		}
+		pvcs = append(pvcs, pvcList.Items...)
 	}
 
-	if len(pvcList.Items) == 0 {
+	if len(pvcs) == 0 {
 		return nil, fmt.Errorf("found no PVCs for group snapshot with given label selectors: %v", matchLabels)
 	}
 
 	// Check if no PVCs are in pending state
-	for _, pvc := range pvcList.Items {
+	for _, pvc := range pvcs {
 		if pvc.Status.Phase == v1.ClaimPending {
 			return nil, fmt.Errorf("PVC: [%s] %s is still in %s phase. Group snapshot will trigger after all PVCs are bound",
 				pvc.Namespace, pvc.Name, pvc.Status.Phase)
 		}
 	}
 
-	return pvcList.Items, nil
+	return pvcs, nil
 }
 
 // GetVolumeNamesFromLabelSelector returns PV names for all PVCs in given namespace that match the given
 // labels
 func GetVolumeNamesFromLabelSelector(namespace string, labels map[string]string) ([]string, error) {
-	pvcs, err := GetPVCsForGroupSnapshot(namespace, labels)
+	return GetVolumeNamesFromLabelSelectorInNamespaces([]string{namespace}, labels)
+}
+
+// GetVolumeNamesFromLabelSelectorInNamespaces returns PV names for all PVCs in the given namespaces that match
+// the given labels
+func GetVolumeNamesFromLabelSelectorInNamespaces(namespaces []string, labels map[string]string) ([]string, error) {
+	pvcs, err := GetPVCsForGroupSnapshotInNamespaces(namespaces, labels)
 	if err != nil {
 		return nil, err
 	}
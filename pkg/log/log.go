@@ -301,3 +301,27 @@ func BackupLocationLog(location *storkv1.BackupLocation) *logrus.Entry {
 
 	return logrus.WithFields(logrus.Fields{})
 }
+
+// VolumeFileRestoreLog formats a log message with volumefilerestore information
+func VolumeFileRestoreLog(fileRestore *storkv1.VolumeFileRestore) *logrus.Entry {
+	if fileRestore != nil {
+		return logrus.WithFields(logrus.Fields{
+			"VolumeFileRestoreName": fileRestore.Name,
+			"VolumeFileRestoreUID":  string(fileRestore.UID),
+			"Namespace":             fileRestore.Namespace,
+		})
+	}
+	return logrus.WithFields(logrus.Fields{})
+}
+
+// ActionLog formats a log message with action information
+func ActionLog(action *storkv1.Action) *logrus.Entry {
+	if action != nil {
+		return logrus.WithFields(logrus.Fields{
+			"ActionName": action.Name,
+			"ActionType": action.Spec.ActionType,
+			"Namespace":  action.Namespace,
+		})
+	}
+	return logrus.WithFields(logrus.Fields{})
+}
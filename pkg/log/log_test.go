@@ -33,6 +33,8 @@ func TestLog(t *testing.T) {
 	t.Run("applicationBackupScheduleLogTest", applicationBackupScheduleLogTest)
 	t.Run("volumeSnapshotRestoreLogTest", volumeSnapshotRestoreLogTest)
 	t.Run("backupLocationLogTest", backupLocationLogTest)
+	t.Run("volumeFileRestoreLogTest", volumeFileRestoreLogTest)
+	t.Run("actionLogTest", actionLogTest)
 }
 
 func podLogTest(t *testing.T) {
@@ -284,3 +286,30 @@ func backupLocationLogTest(t *testing.T) {
 	BackupLocationLog(backupLocation).Infof("backuplocation log")
 	BackupLocationLog(nil).Infof("backuplocation nil log")
 }
+
+func volumeFileRestoreLogTest(t *testing.T) {
+	metadata := metav1.ObjectMeta{
+		Name:      "testvolumefilerestore",
+		Namespace: "testnamespace",
+	}
+	fileRestore := &storkv1.VolumeFileRestore{
+		ObjectMeta: metadata,
+	}
+	VolumeFileRestoreLog(fileRestore).Infof("volumefilerestore log")
+	VolumeFileRestoreLog(nil).Infof("volumefilerestore nil log")
+}
+
+func actionLogTest(t *testing.T) {
+	metadata := metav1.ObjectMeta{
+		Name:      "testaction",
+		Namespace: "testnamespace",
+	}
+	action := &storkv1.Action{
+		ObjectMeta: metadata,
+		Spec: storkv1.ActionSpec{
+			ActionType: storkv1.ActionTypeFailover,
+		},
+	}
+	ActionLog(action).Infof("action log")
+	ActionLog(nil).Infof("action nil log")
+}
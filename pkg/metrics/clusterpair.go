@@ -5,6 +5,7 @@ import (
 
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -18,6 +19,26 @@ var (
 		Name: "stork_clusterpair_storage_status",
 		Help: "Status of storage clusterpair",
 	}, []string{metricName, metricNamespace})
+	// clusterpairConditionCounters for the conditions from the clusterpair probe
+	clusterpairConditionCounters = map[stork_api.ClusterPairConditionType]*prometheus.GaugeVec{
+		stork_api.ClusterPairConditionRemoteReachable: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "stork_clusterpair_remote_reachable",
+			Help: "Whether the remote cluster of the clusterpair is reachable (0: False, 1: True, 2: Unknown)",
+		}, []string{metricName, metricNamespace}),
+		stork_api.ClusterPairConditionRemotePermissions: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "stork_clusterpair_remote_permissions",
+			Help: "Whether the clusterpair has the permissions required on the remote cluster (0: False, 1: True, 2: Unknown)",
+		}, []string{metricName, metricNamespace}),
+		stork_api.ClusterPairConditionStoragePaired: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "stork_clusterpair_storage_paired",
+			Help: "Whether the storage of the clusterpair is still paired (0: False, 1: True, 2: Unknown)",
+		}, []string{metricName, metricNamespace}),
+	}
+	// clusterpairLastProbeCounter for the time of the last clusterpair probe
+	clusterpairLastProbeCounter = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "stork_clusterpair_last_probe_timestamp_seconds",
+		Help: "Time of the last probe of the clusterpair",
+	}, []string{metricName, metricNamespace})
 )
 
 var (
@@ -31,6 +52,12 @@ var (
 		stork_api.ClusterPairStatusDeleting:    5,
 		stork_api.ClusterPairStatusNotProvided: 6,
 	}
+	// clusterpairConditionStatus map of clusterpair condition status
+	clusterpairConditionStatus = map[metav1.ConditionStatus]float64{
+		metav1.ConditionFalse:   0,
+		metav1.ConditionTrue:    1,
+		metav1.ConditionUnknown: 2,
+	}
 )
 
 func watchclusterpairCR(object runtime.Object) error {
@@ -45,15 +72,32 @@ func watchclusterpairCR(object runtime.Object) error {
 	if clusterpair.DeletionTimestamp != nil {
 		clusterpairSchedStatusCounter.Delete(labels)
 		clusterpairStorageStatusCounter.Delete(labels)
+		for _, counter := range clusterpairConditionCounters {
+			counter.Delete(labels)
+		}
+		clusterpairLastProbeCounter.Delete(labels)
 		return nil
 	}
 	// Set clusterpair Status counter
 	clusterpairSchedStatusCounter.With(labels).Set(clusterpairStatus[clusterpair.Status.SchedulerStatus])
 	clusterpairStorageStatusCounter.With(labels).Set(clusterpairStatus[clusterpair.Status.StorageStatus])
+	// Set clusterpair probe counters
+	for _, condition := range clusterpair.Status.Conditions {
+		if counter, ok := clusterpairConditionCounters[condition.Type]; ok {
+			counter.With(labels).Set(clusterpairConditionStatus[condition.Status])
+		}
+	}
+	if !clusterpair.Status.LastProbeTimestamp.IsZero() {
+		clusterpairLastProbeCounter.With(labels).Set(float64(clusterpair.Status.LastProbeTimestamp.Unix()))
+	}
 	return nil
 }
 
 func init() {
 	prometheus.MustRegister(clusterpairSchedStatusCounter)
 	prometheus.MustRegister(clusterpairStorageStatusCounter)
+	for _, counter := range clusterpairConditionCounters {
+		prometheus.MustRegister(counter)
+	}
+	prometheus.MustRegister(clusterpairLastProbeCounter)
 }
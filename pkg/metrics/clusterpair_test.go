@@ -12,6 +12,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func createClusterPair(name, ns string, storage, sched storkv1.ClusterPairStatusType) (*storkv1.ClusterPair, error) {
@@ -59,3 +60,39 @@ func TestClusterPairFailureMetrics(t *testing.T) {
 	err = stork.Instance().DeleteClusterPair("test", "test-fail")
 	require.NoError(t, err)
 }
+
+func TestClusterPairProbeMetrics(t *testing.T) {
+	defer resetTest()
+	pair, err := createClusterPair("test", "test-probe", storkv1.ClusterPairStatusReady, storkv1.ClusterPairStatusReady)
+	require.NoError(t, err)
+	probeTime := metav1.NewTime(time.Now().Truncate(time.Second))
+	pair.Status.LastProbeTimestamp = probeTime
+	pair.Status.Conditions = []storkv1.ClusterPairCondition{
+		{
+			Type:   storkv1.ClusterPairConditionRemoteReachable,
+			Status: metav1.ConditionTrue,
+		},
+		{
+			Type:   storkv1.ClusterPairConditionRemotePermissions,
+			Status: metav1.ConditionFalse,
+		},
+		{
+			Type:   storkv1.ClusterPairConditionStoragePaired,
+			Status: metav1.ConditionUnknown,
+		},
+	}
+	_, err = stork.Instance().UpdateClusterPair(pair)
+	require.NoError(t, err)
+	time.Sleep(3 * time.Second)
+
+	labels := make(prometheus.Labels)
+	labels[metricName] = "test"
+	labels[metricNamespace] = "test-probe"
+	require.Equal(t, float64(1), testutil.ToFloat64(clusterpairConditionCounters[storkv1.ClusterPairConditionRemoteReachable].With(labels)), "clusterpair_remote_reachable does not matched")
+	require.Equal(t, float64(0), testutil.ToFloat64(clusterpairConditionCounters[storkv1.ClusterPairConditionRemotePermissions].With(labels)), "clusterpair_remote_permissions does not matched")
+	require.Equal(t, float64(2), testutil.ToFloat64(clusterpairConditionCounters[storkv1.ClusterPairConditionStoragePaired].With(labels)), "clusterpair_storage_paired does not matched")
+	require.Equal(t, float64(probeTime.Unix()), testutil.ToFloat64(clusterpairLastProbeCounter.With(labels)), "clusterpair_last_probe_timestamp does not matched")
+
+	err = stork.Instance().DeleteClusterPair("test", "test-probe")
+	require.NoError(t, err)
+}
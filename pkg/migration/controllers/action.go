@@ -0,0 +1,734 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/controllers"
+	"github.com/libopenstorage/stork/pkg/k8sutils"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/libopenstorage/stork/pkg/schedule"
+	"github.com/libopenstorage/stork/pkg/version"
+	"github.com/portworx/sched-ops/k8s/apiextensions"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// StorkReverseMigrationScheduleAnnotation is the annotation used to keep
+	// track of the schedule a reverse migration schedule was created from
+	StorkReverseMigrationScheduleAnnotation = "stork.libopenstorage.org/reverse-migration-schedule"
+	reverseMigrationScheduleSuffix          = "-reverse"
+	// Timeout for the requests to the remote cluster so that an unreachable
+	// cluster doesn't block the action
+	remoteClusterTimeout = 30 * time.Second
+)
+
+// NewAction creates a new instance of ActionController.
+func NewAction(mgr manager.Manager, r record.EventRecorder) *ActionController {
+	return &ActionController{
+		client:   mgr.GetClient(),
+		recorder: r,
+		newRemoteOps: func(config *rest.Config) (storkops.Ops, error) {
+			return storkops.NewForConfig(config)
+		},
+		newActivator: func() (applicationActivator, error) {
+			config, err := rest.InClusterConfig()
+			if err != nil {
+				return nil, err
+			}
+			return NewApplicationActivator(config, nil)
+		},
+		newRemoteActivator: func(config *rest.Config) (applicationActivator, error) {
+			return newRemoteApplicationActivator(config, nil)
+		},
+	}
+}
+
+// applicationActivator activates and deactivates the migrated applications
+// in a namespace
+type applicationActivator interface {
+	RecordApplications(resources []*stork_api.MigrationResourceInfo) error
+	UpdateNamespace(namespace string, activate bool) error
+}
+
+// ActionController reconciles Action objects
+type ActionController struct {
+	client runtimeclient.Client
+
+	recorder record.EventRecorder
+	// newRemoteOps, newActivator and newRemoteActivator create the clients
+	// for the remote cluster and the activators for this cluster and the
+	// remote cluster
+	newRemoteOps       func(config *rest.Config) (storkops.Ops, error)
+	newActivator       func() (applicationActivator, error)
+	newRemoteActivator func(config *rest.Config) (applicationActivator, error)
+}
+
+// Init Initialize the action controller
+func (a *ActionController) Init(mgr manager.Manager) error {
+	err := a.createCRD()
+	if err != nil {
+		return err
+	}
+
+	return controllers.RegisterTo(mgr, "action-controller", a, &stork_api.Action{})
+}
+
+// Reconcile manages Action resources.
+func (a *ActionController) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	logrus.Tracef("Reconciling Action %s/%s", request.Namespace, request.Name)
+
+	action := &stork_api.Action{}
+	err := a.client.Get(context.TODO(), request.NamespacedName, action)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{RequeueAfter: controllers.DefaultRequeueError}, err
+	}
+
+	if err = a.handle(context.TODO(), action); err != nil {
+		logrus.Errorf("%s: %s/%s: %s", reflect.TypeOf(a), action.Namespace, action.Name, err)
+		return reconcile.Result{RequeueAfter: controllers.DefaultRequeueError}, err
+	}
+
+	return reconcile.Result{RequeueAfter: controllers.DefaultRequeue}, nil
+}
+
+// handle runs the stage the action is in. The progress is saved in the
+// status after every step so that the action resumes from where it was
+// after a restart
+func (a *ActionController) handle(ctx context.Context, action *stork_api.Action) error {
+	if action.DeletionTimestamp != nil {
+		return nil
+	}
+
+	switch action.Status.Stage {
+	case stork_api.ActionStageInitial:
+		return a.start(action)
+	case stork_api.ActionStageSuspendSchedule:
+		return a.suspendSchedule(action)
+	case stork_api.ActionStageFinalMigration:
+		return a.runFinalMigration(action)
+	case stork_api.ActionStageDeactivate:
+		return a.deactivateRemote(action)
+	case stork_api.ActionStageActivate:
+		return a.activate(action)
+	case stork_api.ActionStageReverseSchedule:
+		return a.reverseSchedule(action)
+	case stork_api.ActionStageFinal:
+		return nil
+	default:
+		log.ActionLog(action).Errorf("Invalid stage for action: %v", action.Status.Stage)
+	}
+	return nil
+}
+
+func (a *ActionController) start(action *stork_api.Action) error {
+	if action.Spec.ActionType != stork_api.ActionTypeFailover &&
+		action.Spec.ActionType != stork_api.ActionTypeFailback {
+		return a.fail(action, fmt.Sprintf("invalid action type %v", action.Spec.ActionType))
+	}
+	if action.Spec.MigrationSchedule == "" {
+		return a.fail(action, "migrationSchedule needs to be specified")
+	}
+	if action.Spec.ClusterPair == "" {
+		return a.fail(action, "clusterPair needs to be specified")
+	}
+	// The ClusterPair isn't created by the action, it needs to pair this
+	// cluster with the remote cluster before the action is created
+	clusterPair, err := storkops.Instance().GetClusterPair(action.Spec.ClusterPair, action.Namespace)
+	if errors.IsNotFound(err) {
+		return a.fail(action, fmt.Sprintf("clusterpair %v not found, it needs to be created in namespace %v "+
+			"to pair this cluster with the remote cluster", action.Spec.ClusterPair, action.Namespace))
+	} else if err != nil {
+		return err
+	}
+
+	var remoteSchedule *stork_api.MigrationSchedule
+	remoteOps, err := a.getRemoteOps(action)
+	if err == nil {
+		remoteSchedule, err = remoteOps.GetMigrationSchedule(action.Spec.MigrationSchedule, action.Namespace)
+		if errors.IsNotFound(err) {
+			return a.fail(action, fmt.Sprintf("migrationschedule %v not found on remote cluster", action.Spec.MigrationSchedule))
+		}
+	}
+	remoteReachable := err == nil
+	if remoteReachable && !isClusterPairReady(clusterPair) {
+		// The reverse schedule is resumed right away when the remote
+		// cluster is reachable and needs the pair to be ready
+		return a.fail(action, fmt.Sprintf("clusterpair %v isn't ready, scheduler status: %v, storage status: %v",
+			clusterPair.Name, clusterPair.Status.SchedulerStatus, clusterPair.Status.StorageStatus))
+	}
+	if !remoteReachable {
+		if action.Spec.ActionType == stork_api.ActionTypeFailback {
+			return a.fail(action, fmt.Sprintf("remote cluster needs to be reachable for failback: %v", err))
+		}
+		msg := fmt.Sprintf("Remote cluster is unreachable, applications will only be activated on this cluster: %v", err)
+		a.recorder.Event(action,
+			v1.EventTypeWarning,
+			string(stork_api.ActionStatusInProgress),
+			msg)
+		log.ActionLog(action).Warn(msg)
+	}
+
+	namespaces := action.Spec.Namespaces
+	if len(namespaces) == 0 {
+		if remoteSchedule == nil {
+			remoteSchedule, err = a.getLocalScheduleCopy(action)
+			if err != nil {
+				return err
+			}
+		}
+		if remoteSchedule != nil {
+			namespaces = remoteSchedule.Spec.Template.Spec.Namespaces
+		}
+	}
+	if len(namespaces) == 0 {
+		return a.fail(action, "namespaces need to be specified since the migrationschedule couldn't be found")
+	}
+
+	action.Status.RemoteReachable = remoteReachable
+	action.Status.Namespaces = make([]*stork_api.ActionNamespaceStatus, 0)
+	for _, ns := range namespaces {
+		action.Status.Namespaces = append(action.Status.Namespaces, &stork_api.ActionNamespaceStatus{
+			Namespace: ns,
+			Status:    stork_api.ActionStatusInitial,
+		})
+	}
+	action.Status.Status = stork_api.ActionStatusInProgress
+	action.Status.Stage = stork_api.ActionStageActivate
+	if action.Status.RemoteReachable {
+		action.Status.Stage = stork_api.ActionStageSuspendSchedule
+	}
+	a.recorder.Event(action,
+		v1.EventTypeNormal,
+		string(stork_api.ActionStatusInProgress),
+		fmt.Sprintf("Started %v for migrationschedule %v", action.Spec.ActionType, action.Spec.MigrationSchedule))
+	return a.updateStatus(action)
+}
+
+// suspendSchedule suspends the schedule on the remote cluster and waits for
+// the migrations it already started to complete
+func (a *ActionController) suspendSchedule(action *stork_api.Action) error {
+	remoteOps, err := a.getRemoteOps(action)
+	if err != nil {
+		return err
+	}
+	migrationSchedule, err := remoteOps.GetMigrationSchedule(action.Spec.MigrationSchedule, action.Namespace)
+	if err != nil {
+		return err
+	}
+	if migrationSchedule.Spec.Suspend == nil || !*migrationSchedule.Spec.Suspend {
+		suspend := true
+		migrationSchedule.Spec.Suspend = &suspend
+		if migrationSchedule, err = remoteOps.UpdateMigrationSchedule(migrationSchedule); err != nil {
+			return fmt.Errorf("error suspending migrationschedule on remote cluster: %v", err)
+		}
+		log.ActionLog(action).Infof("Suspended migrationschedule %v on remote cluster", migrationSchedule.Name)
+	}
+	for _, policyMigration := range migrationSchedule.Status.Items {
+		for _, migration := range policyMigration {
+			if migration.Status == stork_api.MigrationStatusPending ||
+				migration.Status == stork_api.MigrationStatusInProgress {
+				log.ActionLog(action).Infof("Waiting for migration %v on remote cluster to complete", migration.Name)
+				return nil
+			}
+		}
+	}
+
+	action.Status.Stage = stork_api.ActionStageFinalMigration
+	if action.Spec.SkipFinalMigration {
+		action.Status.Stage = stork_api.ActionStageDeactivate
+	}
+	return a.updateStatus(action)
+}
+
+// runFinalMigration starts a migration on the remote cluster from the
+// template of the schedule and waits for it to complete. The name of the
+// migration is saved before it is created so that it isn't started again
+// after a restart
+func (a *ActionController) runFinalMigration(action *stork_api.Action) error {
+	if action.Status.Migration == "" {
+		action.Status.Migration = fmt.Sprintf("%v-%v-%v",
+			action.Spec.MigrationSchedule,
+			action.Spec.ActionType,
+			schedule.GetCurrentTime().Format(nameTimeSuffixFormat))
+		return a.updateStatus(action)
+	}
+
+	remoteOps, err := a.getRemoteOps(action)
+	if err != nil {
+		return err
+	}
+	migration, err := remoteOps.GetMigration(action.Status.Migration, action.Namespace)
+	if errors.IsNotFound(err) {
+		migrationSchedule, err := remoteOps.GetMigrationSchedule(action.Spec.MigrationSchedule, action.Namespace)
+		if err != nil {
+			return err
+		}
+		migration = &stork_api.Migration{
+			ObjectMeta: meta.ObjectMeta{
+				Name:      action.Status.Migration,
+				Namespace: action.Namespace,
+				Annotations: map[string]string{
+					StorkMigrationScheduleName: migrationSchedule.Name,
+				},
+				OwnerReferences: []meta.OwnerReference{
+					{
+						Name:       migrationSchedule.Name,
+						UID:        migrationSchedule.UID,
+						Kind:       reflect.TypeOf(stork_api.MigrationSchedule{}).Name(),
+						APIVersion: stork_api.SchemeGroupVersion.String(),
+					},
+				},
+			},
+			Spec: *migrationSchedule.Spec.Template.Spec.DeepCopy(),
+		}
+		startApplications := false
+		migration.Spec.StartApplications = &startApplications
+		if _, err := remoteOps.CreateMigration(migration); err != nil {
+			return fmt.Errorf("error creating migration on remote cluster: %v", err)
+		}
+		msg := fmt.Sprintf("Started migration %v on remote cluster", migration.Name)
+		a.recorder.Event(action,
+			v1.EventTypeNormal,
+			string(stork_api.ActionStatusInProgress),
+			msg)
+		log.ActionLog(action).Info(msg)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	switch migration.Status.Status {
+	case stork_api.MigrationStatusSuccessful, stork_api.MigrationStatusPartialSuccess:
+		action.Status.Stage = stork_api.ActionStageDeactivate
+		return a.updateStatus(action)
+	case stork_api.MigrationStatusFailed:
+		return a.fail(action, fmt.Sprintf("migration %v on remote cluster failed, "+
+			"the action can be created again with skipFinalMigration to fail over anyway", migration.Name))
+	}
+	return nil
+}
+
+// deactivateRemote scales down the applications on the remote cluster that
+// were migrated to this cluster. Their replicas are recorded first the same
+// way a migration does so that they can be activated again on failback.
+// Namespaces that couldn't be deactivated aren't activated on this cluster so
+// that the applications don't run on both the clusters. The action only fails
+// if none of the namespaces could be deactivated
+func (a *ActionController) deactivateRemote(action *stork_api.Action) error {
+	remoteConfig, err := a.getRemoteConfig(action)
+	if err != nil {
+		return err
+	}
+	activator, err := a.newRemoteActivator(remoteConfig)
+	if err != nil {
+		return err
+	}
+	resources, err := a.getMigratedResources(action)
+	if err != nil {
+		return err
+	}
+	failed := make([]string, 0)
+	for _, nsStatus := range action.Status.Namespaces {
+		if nsStatus.Stage == stork_api.ActionStageDeactivate &&
+			nsStatus.Status == stork_api.ActionStatusSuccessful {
+			continue
+		}
+		nsStatus.Stage = stork_api.ActionStageDeactivate
+		nsStatus.Status = stork_api.ActionStatusSuccessful
+		nsStatus.Reason = "Applications deactivated on remote cluster"
+		err := activator.RecordApplications(resources[nsStatus.Namespace])
+		if err == nil {
+			err = activator.UpdateNamespace(nsStatus.Namespace, false)
+		}
+		if err != nil {
+			nsStatus.Status = stork_api.ActionStatusFailed
+			nsStatus.Reason = fmt.Sprintf("Error deactivating applications on remote cluster, "+
+				"not activating them on this cluster: %v", err)
+			failed = append(failed, nsStatus.Namespace)
+			log.ActionLog(action).Errorf("Error deactivating applications in namespace %v on remote cluster: %v", nsStatus.Namespace, err)
+		}
+	}
+	if len(failed) == len(action.Status.Namespaces) {
+		return a.fail(action, "applications couldn't be deactivated on remote cluster in any of the namespaces, "+
+			"not activating them on this cluster")
+	}
+	if len(failed) != 0 {
+		msg := fmt.Sprintf("Applications couldn't be deactivated on remote cluster in namespaces %v, "+
+			"they won't be activated on this cluster", strings.Join(failed, ", "))
+		a.recorder.Event(action,
+			v1.EventTypeWarning,
+			string(stork_api.ActionStatusInProgress),
+			msg)
+		log.ActionLog(action).Warn(msg)
+	}
+	action.Status.Stage = stork_api.ActionStageActivate
+	return a.updateStatus(action)
+}
+
+// getMigratedResources returns the resources, by namespace, that were
+// migrated from the remote cluster by the final migration, or by the last
+// successful migration of the schedule if the final migration was skipped
+func (a *ActionController) getMigratedResources(action *stork_api.Action) (map[string][]*stork_api.MigrationResourceInfo, error) {
+	remoteOps, err := a.getRemoteOps(action)
+	if err != nil {
+		return nil, err
+	}
+	migrationName := action.Status.Migration
+	if migrationName == "" {
+		migrationSchedule, err := remoteOps.GetMigrationSchedule(action.Spec.MigrationSchedule, action.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		var lastFinished meta.Time
+		for _, migrations := range migrationSchedule.Status.Items {
+			for _, m := range migrations {
+				if (m.Status == stork_api.MigrationStatusSuccessful || m.Status == stork_api.MigrationStatusPartialSuccess) &&
+					lastFinished.Before(&m.FinishTimestamp) {
+					migrationName = m.Name
+					lastFinished = m.FinishTimestamp
+				}
+			}
+		}
+	}
+	resources := make(map[string][]*stork_api.MigrationResourceInfo)
+	if migrationName == "" {
+		log.ActionLog(action).Warnf("No successful migration found for migrationschedule %v, only applications "+
+			"created by a migration will be deactivated on remote cluster", action.Spec.MigrationSchedule)
+		return resources, nil
+	}
+	migration, err := remoteOps.GetMigration(migrationName, action.Namespace)
+	if errors.IsNotFound(err) {
+		log.ActionLog(action).Warnf("Migration %v not found on remote cluster, only applications "+
+			"created by a migration will be deactivated on remote cluster", migrationName)
+		return resources, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting migration %v from remote cluster: %v", migrationName, err)
+	}
+	for _, resource := range migration.Status.Resources {
+		resources[resource.Namespace] = append(resources[resource.Namespace], resource)
+	}
+	return resources, nil
+}
+
+// activate activates the applications on this cluster in the namespaces
+// that were deactivated on the remote cluster, or in all the namespaces if
+// the remote cluster wasn't reachable
+func (a *ActionController) activate(action *stork_api.Action) error {
+	activator, err := a.newActivator()
+	if err != nil {
+		return err
+	}
+	for _, nsStatus := range action.Status.Namespaces {
+		if nsStatus.Stage == stork_api.ActionStageActivate || isDeactivateFailed(nsStatus) {
+			continue
+		}
+		nsStatus.Stage = stork_api.ActionStageActivate
+		nsStatus.Status = stork_api.ActionStatusSuccessful
+		nsStatus.Reason = "Applications activated"
+		if err := activator.UpdateNamespace(nsStatus.Namespace, true); err != nil {
+			nsStatus.Status = stork_api.ActionStatusFailed
+			nsStatus.Reason = fmt.Sprintf("Error activating applications: %v", err)
+			log.ActionLog(action).Errorf("Error activating applications in namespace %v: %v", nsStatus.Namespace, err)
+		}
+		// Save the progress after every namespace since activation isn't
+		// retried
+		if err := a.updateStatus(action); err != nil {
+			return err
+		}
+	}
+
+	// Mark the copy of the schedule on this cluster as activated like
+	// storkctl does when activating migrations
+	localSchedule, err := a.getLocalScheduleCopy(action)
+	if err != nil {
+		return err
+	}
+	if localSchedule != nil && !localSchedule.Status.ApplicationActivated {
+		localSchedule.Status.ApplicationActivated = true
+		if _, err := storkops.Instance().UpdateMigrationSchedule(localSchedule); err != nil {
+			return err
+		}
+	}
+
+	action.Status.Stage = stork_api.ActionStageReverseSchedule
+	return a.updateStatus(action)
+}
+
+// reverseSchedule resumes or creates the schedule that migrates the
+// applications from this cluster back to the remote cluster. The schedule is
+// left suspended if the remote cluster wasn't reachable since the
+// applications are still running there
+func (a *ActionController) reverseSchedule(action *stork_api.Action) error {
+	var migrationSchedule *stork_api.MigrationSchedule
+	var err error
+	if action.Status.RemoteReachable {
+		remoteOps, err := a.getRemoteOps(action)
+		if err != nil {
+			return err
+		}
+		if migrationSchedule, err = remoteOps.GetMigrationSchedule(action.Spec.MigrationSchedule, action.Namespace); err != nil {
+			return err
+		}
+	} else if migrationSchedule, err = a.getLocalScheduleCopy(action); err != nil {
+		return err
+	}
+
+	name := action.Spec.ReverseMigrationSchedule
+	if name == "" && migrationSchedule != nil {
+		name = migrationSchedule.Annotations[StorkReverseMigrationScheduleAnnotation]
+	}
+	if name == "" {
+		name = action.Spec.MigrationSchedule + reverseMigrationScheduleSuffix
+	}
+
+	// The reverse schedule uses the same ClusterPair to migrate the
+	// applications back, so it is only resumed if the pair is still ready
+	resume := action.Status.RemoteReachable
+	if resume {
+		clusterPair, err := storkops.Instance().GetClusterPair(action.Spec.ClusterPair, action.Namespace)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if err != nil || !isClusterPairReady(clusterPair) {
+			resume = false
+			msg := fmt.Sprintf("ClusterPair %v isn't ready, migrationschedule %v will be left suspended", action.Spec.ClusterPair, name)
+			a.recorder.Event(action,
+				v1.EventTypeWarning,
+				string(stork_api.ActionStatusInProgress),
+				msg)
+			log.ActionLog(action).Warn(msg)
+		}
+	}
+
+	reverseSchedule, err := storkops.Instance().GetMigrationSchedule(name, action.Namespace)
+	if err == nil {
+		if resume && reverseSchedule.Spec.Suspend != nil && *reverseSchedule.Spec.Suspend {
+			suspend := false
+			reverseSchedule.Spec.Suspend = &suspend
+			if _, err := storkops.Instance().UpdateMigrationSchedule(reverseSchedule); err != nil {
+				return fmt.Errorf("error resuming migrationschedule %v: %v", name, err)
+			}
+		}
+	} else if errors.IsNotFound(err) {
+		if migrationSchedule == nil {
+			msg := "Reverse migrationschedule wasn't created since the migrationschedule couldn't be found"
+			a.recorder.Event(action,
+				v1.EventTypeWarning,
+				string(stork_api.ActionStatusInProgress),
+				msg)
+			log.ActionLog(action).Warn(msg)
+			return a.finish(action)
+		}
+		if err := a.createReverseSchedule(action, migrationSchedule, name, resume); err != nil {
+			return fmt.Errorf("error creating migrationschedule %v: %v", name, err)
+		}
+	} else {
+		return err
+	}
+
+	action.Status.ReverseMigrationSchedule = name
+	return a.finish(action)
+}
+
+// createReverseSchedule creates the reverse schedule for the namespaces that
+// were activated on this cluster. It is created suspended unless resume is set
+func (a *ActionController) createReverseSchedule(
+	action *stork_api.Action,
+	migrationSchedule *stork_api.MigrationSchedule,
+	name string,
+	resume bool,
+) error {
+	if action.Status.RemoteReachable {
+		if err := a.copySchedulePolicy(action, migrationSchedule.Spec.SchedulePolicyName); err != nil {
+			return err
+		}
+	}
+	suspend := !resume
+	startApplications := false
+	reverseSchedule := &stork_api.MigrationSchedule{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: action.Namespace,
+			Annotations: map[string]string{
+				StorkReverseMigrationScheduleAnnotation: migrationSchedule.Name,
+			},
+		},
+		Spec: *migrationSchedule.Spec.DeepCopy(),
+	}
+	reverseSchedule.Spec.Suspend = &suspend
+	reverseSchedule.Spec.Template.Spec.ClusterPair = action.Spec.ClusterPair
+	reverseSchedule.Spec.Template.Spec.AdminClusterPair = ""
+	reverseSchedule.Spec.Template.Spec.StartApplications = &startApplications
+	reverseSchedule.Spec.Template.Spec.Namespaces = make([]string, 0)
+	for _, nsStatus := range action.Status.Namespaces {
+		if isDeactivateFailed(nsStatus) {
+			continue
+		}
+		reverseSchedule.Spec.Template.Spec.Namespaces = append(reverseSchedule.Spec.Template.Spec.Namespaces, nsStatus.Namespace)
+	}
+	if _, err := storkops.Instance().CreateMigrationSchedule(reverseSchedule); err != nil {
+		return err
+	}
+	log.ActionLog(action).Infof("Created reverse migrationschedule %v", name)
+	return nil
+}
+
+// copySchedulePolicy copies the policy of the schedule from the remote
+// cluster if it doesn't exist on this cluster
+func (a *ActionController) copySchedulePolicy(action *stork_api.Action, policyName string) error {
+	if policyName == "" {
+		return nil
+	}
+	if _, err := storkops.Instance().GetSchedulePolicy(policyName); err == nil {
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	remoteOps, err := a.getRemoteOps(action)
+	if err != nil {
+		return err
+	}
+	policy, err := remoteOps.GetSchedulePolicy(policyName)
+	if err != nil {
+		return fmt.Errorf("error getting schedulepolicy %v from remote cluster: %v", policyName, err)
+	}
+	policy.ResourceVersion = ""
+	policy.UID = ""
+	if _, err := storkops.Instance().CreateSchedulePolicy(policy); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// getLocalScheduleCopy returns the copy of the schedule created on this
+// cluster when the schedule has autoSuspend enabled, or nil if there isn't one
+func (a *ActionController) getLocalScheduleCopy(action *stork_api.Action) (*stork_api.MigrationSchedule, error) {
+	migrationSchedule, err := storkops.Instance().GetMigrationSchedule(action.Spec.MigrationSchedule, action.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if _, ok := migrationSchedule.Annotations[StorkMigrationScheduleCopied]; !ok {
+		return nil, nil
+	}
+	return migrationSchedule, nil
+}
+
+// isDeactivateFailed returns true if the applications in the namespace
+// couldn't be deactivated on the remote cluster
+func isDeactivateFailed(nsStatus *stork_api.ActionNamespaceStatus) bool {
+	return nsStatus.Stage == stork_api.ActionStageDeactivate &&
+		nsStatus.Status == stork_api.ActionStatusFailed
+}
+
+func isClusterPairReady(clusterPair *stork_api.ClusterPair) bool {
+	return clusterPair.Status.SchedulerStatus == stork_api.ClusterPairStatusReady &&
+		(clusterPair.Status.StorageStatus == stork_api.ClusterPairStatusReady ||
+			clusterPair.Status.StorageStatus == stork_api.ClusterPairStatusNotProvided)
+}
+
+func (a *ActionController) getRemoteConfig(action *stork_api.Action) (*rest.Config, error) {
+	remoteConfig, err := getClusterPairSchedulerConfig(action.Spec.ClusterPair, action.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	remoteConfig.Timeout = remoteClusterTimeout
+	return remoteConfig, nil
+}
+
+func (a *ActionController) getRemoteOps(action *stork_api.Action) (storkops.Ops, error) {
+	remoteConfig, err := a.getRemoteConfig(action)
+	if err != nil {
+		return nil, err
+	}
+	return a.newRemoteOps(remoteConfig)
+}
+
+func (a *ActionController) finish(action *stork_api.Action) error {
+	action.Status.Stage = stork_api.ActionStageFinal
+	action.Status.Status = stork_api.ActionStatusSuccessful
+	action.Status.Reason = fmt.Sprintf("Completed %v for migrationschedule %v", action.Spec.ActionType, action.Spec.MigrationSchedule)
+	for _, nsStatus := range action.Status.Namespaces {
+		if nsStatus.Status == stork_api.ActionStatusFailed {
+			action.Status.Status = stork_api.ActionStatusPartialSuccess
+			action.Status.Reason = "Applications in some namespaces couldn't be deactivated on remote cluster or activated"
+			break
+		}
+	}
+	action.Status.FinishTimestamp = meta.Now()
+	a.recorder.Event(action,
+		v1.EventTypeNormal,
+		string(action.Status.Status),
+		action.Status.Reason)
+	log.ActionLog(action).Info(action.Status.Reason)
+	return a.updateStatus(action)
+}
+
+func (a *ActionController) fail(action *stork_api.Action, reason string) error {
+	action.Status.Stage = stork_api.ActionStageFinal
+	action.Status.Status = stork_api.ActionStatusFailed
+	action.Status.Reason = reason
+	action.Status.FinishTimestamp = meta.Now()
+	a.recorder.Event(action,
+		v1.EventTypeWarning,
+		string(stork_api.ActionStatusFailed),
+		reason)
+	log.ActionLog(action).Errorf("%v failed: %v", action.Spec.ActionType, reason)
+	return a.updateStatus(action)
+}
+
+func (a *ActionController) updateStatus(action *stork_api.Action) error {
+	action.Status.LastUpdateTimestamp = meta.Now()
+	return a.client.Update(context.TODO(), action)
+}
+
+func (a *ActionController) createCRD() error {
+	resource := apiextensions.CustomResource{
+		Name:    stork_api.ActionResourceName,
+		Plural:  stork_api.ActionResourcePlural,
+		Group:   stork_api.SchemeGroupVersion.Group,
+		Version: stork_api.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Kind:    reflect.TypeOf(stork_api.Action{}).Name(),
+	}
+	ok, err := version.RequiresV1Registration()
+	if err != nil {
+		return err
+	}
+	if ok {
+		err := k8sutils.CreateCRD(resource)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		return apiextensions.Instance().ValidateCRD(resource.Plural+"."+resource.Group, validateCRDTimeout, validateCRDInterval)
+	}
+	err = apiextensions.Instance().CreateCRDV1beta1(resource)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return apiextensions.Instance().ValidateCRDV1beta1(resource, validateCRDTimeout, validateCRDInterval)
+}
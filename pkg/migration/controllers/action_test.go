@@ -0,0 +1,381 @@
+//go:build unittest
+// +build unittest
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeActivator records the namespaces that were activated or deactivated
+// and fails for the namespaces in failed
+type fakeActivator struct {
+	failed   map[string]bool
+	updated  map[string]bool
+	recorded []*stork_api.MigrationResourceInfo
+}
+
+func newFakeActivator(failed ...string) *fakeActivator {
+	activator := &fakeActivator{
+		failed:  make(map[string]bool),
+		updated: make(map[string]bool),
+	}
+	for _, ns := range failed {
+		activator.failed[ns] = true
+	}
+	return activator
+}
+
+func (f *fakeActivator) RecordApplications(resources []*stork_api.MigrationResourceInfo) error {
+	f.recorded = append(f.recorded, resources...)
+	return nil
+}
+
+func (f *fakeActivator) UpdateNamespace(namespace string, activate bool) error {
+	if f.failed[namespace] {
+		return fmt.Errorf("error updating namespace %v", namespace)
+	}
+	f.updated[namespace] = activate
+	return nil
+}
+
+// actionTestEnv is the local and remote cluster an action runs against. The
+// controller can be created again to simulate a restart of stork
+type actionTestEnv struct {
+	client          runtimeclient.Client
+	remoteOps       *storkops.Client
+	remoteReachable bool
+	activator       *fakeActivator
+	remoteActivator *fakeActivator
+}
+
+func newActionTestEnv(t *testing.T) *actionTestEnv {
+	kubeClient := kubernetes.NewSimpleClientset()
+	core.SetInstance(core.New(kubeClient))
+	storkops.SetInstance(storkops.New(kubeClient, fakeclient.NewSimpleClientset(), nil))
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, stork_api.AddToScheme(scheme), "Error adding stork types to scheme")
+	env := &actionTestEnv{
+		client:          fake.NewClientBuilder().WithScheme(scheme).Build(),
+		remoteOps:       storkops.New(kubernetes.NewSimpleClientset(), fakeclient.NewSimpleClientset(), nil),
+		remoteReachable: true,
+		activator:       newFakeActivator(),
+		remoteActivator: newFakeActivator(),
+	}
+
+	_, err := storkops.Instance().CreateClusterPair(&stork_api.ClusterPair{
+		ObjectMeta: metav1.ObjectMeta{Name: "pair", Namespace: "admin"},
+		Spec: stork_api.ClusterPairSpec{
+			Config: newRemoteConfig("https://remote.example:6443", &clientcmdapi.AuthInfo{Token: "token"}),
+		},
+		Status: stork_api.ClusterPairStatus{
+			SchedulerStatus: stork_api.ClusterPairStatusReady,
+			StorageStatus:   stork_api.ClusterPairStatusNotProvided,
+		},
+	})
+	require.NoError(t, err, "Error creating clusterpair")
+	return env
+}
+
+func (e *actionTestEnv) newController() *ActionController {
+	return &ActionController{
+		client:   e.client,
+		recorder: record.NewFakeRecorder(100),
+		newRemoteOps: func(config *rest.Config) (storkops.Ops, error) {
+			if !e.remoteReachable {
+				return nil, fmt.Errorf("remote cluster unreachable")
+			}
+			return e.remoteOps, nil
+		},
+		newActivator: func() (applicationActivator, error) {
+			return e.activator, nil
+		},
+		newRemoteActivator: func(config *rest.Config) (applicationActivator, error) {
+			return e.remoteActivator, nil
+		},
+	}
+}
+
+func (e *actionTestEnv) createRemoteSchedule(t *testing.T, namespaces ...string) {
+	_, err := e.remoteOps.CreateMigrationSchedule(&stork_api.MigrationSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "schedule", Namespace: "admin"},
+		Spec: stork_api.MigrationScheduleSpec{
+			Template: stork_api.MigrationTemplateSpec{
+				Spec: stork_api.MigrationSpec{
+					ClusterPair: "remotepair",
+					Namespaces:  namespaces,
+				},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating migrationschedule on remote cluster")
+}
+
+func (e *actionTestEnv) createAction(t *testing.T, action *stork_api.Action) *stork_api.Action {
+	require.NoError(t, e.client.Create(context.TODO(), action), "Error creating action")
+	return action
+}
+
+// getAction reads the action back like a restarted controller would
+func (e *actionTestEnv) getAction(t *testing.T, name string) *stork_api.Action {
+	action := &stork_api.Action{}
+	require.NoError(t, e.client.Get(context.TODO(), runtimeclient.ObjectKey{Name: name, Namespace: "admin"}, action),
+		"Error getting action")
+	return action
+}
+
+func newTestAction(name string, namespaces ...string) *stork_api.Action {
+	return &stork_api.Action{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "admin"},
+		Spec: stork_api.ActionSpec{
+			ActionType:        stork_api.ActionTypeFailover,
+			MigrationSchedule: "schedule",
+			ClusterPair:       "pair",
+			Namespaces:        namespaces,
+		},
+	}
+}
+
+func getActionNamespaceStatus(action *stork_api.Action, namespace string) *stork_api.ActionNamespaceStatus {
+	for _, nsStatus := range action.Status.Namespaces {
+		if nsStatus.Namespace == namespace {
+			return nsStatus
+		}
+	}
+	return nil
+}
+
+func TestActionFailoverStages(t *testing.T) {
+	env := newActionTestEnv(t)
+	env.createRemoteSchedule(t, "ns1", "ns2")
+	action := env.createAction(t, newTestAction("failover"))
+	controller := env.newController()
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error starting action")
+	require.Equal(t, stork_api.ActionStageSuspendSchedule, action.Status.Stage, "Unexpected stage after start")
+	require.Equal(t, stork_api.ActionStatusInProgress, action.Status.Status, "Unexpected status after start")
+	require.True(t, action.Status.RemoteReachable, "Remote cluster should be reachable")
+	require.Len(t, action.Status.Namespaces, 2, "Namespaces should default to the namespaces of the schedule")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error suspending schedule")
+	require.Equal(t, stork_api.ActionStageFinalMigration, action.Status.Stage, "Unexpected stage after suspending schedule")
+	remoteSchedule, err := env.remoteOps.GetMigrationSchedule("schedule", "admin")
+	require.NoError(t, err, "Error getting migrationschedule on remote cluster")
+	require.True(t, *remoteSchedule.Spec.Suspend, "Schedule on remote cluster should be suspended")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error running final migration")
+	require.NotEmpty(t, action.Status.Migration, "Name of the final migration should be saved")
+	migrations, err := env.remoteOps.ListMigrations("admin")
+	require.NoError(t, err, "Error listing migrations on remote cluster")
+	require.Empty(t, migrations.Items, "Migration shouldn't be created before its name is saved")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error running final migration")
+	migration, err := env.remoteOps.GetMigration(action.Status.Migration, "admin")
+	require.NoError(t, err, "Final migration should be created on remote cluster")
+	require.False(t, *migration.Spec.StartApplications, "Final migration shouldn't start the applications")
+	require.Equal(t, "remotepair", migration.Spec.ClusterPair, "Final migration should use the template of the schedule")
+	require.Equal(t, stork_api.ActionStageFinalMigration, action.Status.Stage, "Action should wait for the final migration")
+
+	migration.Status.Status = stork_api.MigrationStatusSuccessful
+	migration.Status.Resources = []*stork_api.MigrationResourceInfo{
+		{
+			Namespace: "ns1",
+			Status:    stork_api.MigrationStatusSuccessful,
+		},
+	}
+	_, err = env.remoteOps.UpdateMigration(migration)
+	require.NoError(t, err, "Error updating migration on remote cluster")
+	require.NoError(t, controller.handle(context.TODO(), action), "Error running final migration")
+	require.Equal(t, stork_api.ActionStageDeactivate, action.Status.Stage, "Unexpected stage after final migration")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error deactivating remote cluster")
+	require.Equal(t, stork_api.ActionStageActivate, action.Status.Stage, "Unexpected stage after deactivating")
+	require.Equal(t, map[string]bool{"ns1": false, "ns2": false}, env.remoteActivator.updated,
+		"Namespaces should be deactivated on remote cluster")
+	require.Len(t, env.remoteActivator.recorded, 1, "Resources of the final migration should be recorded")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error activating")
+	require.Equal(t, stork_api.ActionStageReverseSchedule, action.Status.Stage, "Unexpected stage after activating")
+	require.Equal(t, map[string]bool{"ns1": true, "ns2": true}, env.activator.updated, "Namespaces should be activated")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error creating reverse schedule")
+	require.Equal(t, stork_api.ActionStageFinal, action.Status.Stage, "Unexpected final stage")
+	require.Equal(t, stork_api.ActionStatusSuccessful, action.Status.Status, "Unexpected final status")
+	require.Equal(t, "schedule-reverse", action.Status.ReverseMigrationSchedule, "Unexpected reverse schedule")
+	reverseSchedule, err := storkops.Instance().GetMigrationSchedule("schedule-reverse", "admin")
+	require.NoError(t, err, "Reverse schedule should be created")
+	require.False(t, *reverseSchedule.Spec.Suspend, "Reverse schedule should be resumed")
+	require.Equal(t, "pair", reverseSchedule.Spec.Template.Spec.ClusterPair, "Reverse schedule should use the clusterpair of the action")
+	require.Equal(t, []string{"ns1", "ns2"}, reverseSchedule.Spec.Template.Spec.Namespaces, "Unexpected namespaces in reverse schedule")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error handling finished action")
+	require.Equal(t, stork_api.ActionStatusSuccessful, env.getAction(t, "failover").Status.Status, "Finished action shouldn't change")
+}
+
+func TestActionResumeAfterRestart(t *testing.T) {
+	env := newActionTestEnv(t)
+	env.createRemoteSchedule(t, "ns1", "ns2")
+	env.createAction(t, newTestAction("failover"))
+
+	// Restart the controller after every step, the action only continues
+	// from what was saved in its status
+	handle := func(msg string) *stork_api.Action {
+		action := env.getAction(t, "failover")
+		require.NoError(t, env.newController().handle(context.TODO(), action), msg)
+		return env.getAction(t, "failover")
+	}
+	handle("Error starting action")
+	handle("Error suspending schedule")
+	action := handle("Error saving final migration name")
+	migrationName := action.Status.Migration
+	require.NotEmpty(t, migrationName, "Name of the final migration should be saved")
+
+	for i := 0; i < 3; i++ {
+		action = handle("Error running final migration")
+		require.Equal(t, migrationName, action.Status.Migration, "Final migration shouldn't be renamed after a restart")
+		require.Equal(t, stork_api.ActionStageFinalMigration, action.Status.Stage, "Action should wait for the final migration")
+	}
+	migrations, err := env.remoteOps.ListMigrations("admin")
+	require.NoError(t, err, "Error listing migrations on remote cluster")
+	require.Len(t, migrations.Items, 1, "Final migration should only be created once")
+
+	migration := &migrations.Items[0]
+	migration.Status.Status = stork_api.MigrationStatusSuccessful
+	_, err = env.remoteOps.UpdateMigration(migration)
+	require.NoError(t, err, "Error updating migration on remote cluster")
+	handle("Error running final migration")
+	handle("Error deactivating remote cluster")
+
+	// Simulate a restart after the first namespace was activated
+	action = env.getAction(t, "failover")
+	require.Equal(t, stork_api.ActionStageActivate, action.Status.Stage, "Unexpected stage after deactivating")
+	nsStatus := getActionNamespaceStatus(action, "ns1")
+	nsStatus.Stage = stork_api.ActionStageActivate
+	nsStatus.Status = stork_api.ActionStatusSuccessful
+	require.NoError(t, env.client.Update(context.TODO(), action), "Error updating action")
+	action = handle("Error activating")
+	require.Equal(t, map[string]bool{"ns2": true}, env.activator.updated,
+		"Only the namespace that wasn't activated before the restart should be activated")
+
+	action = handle("Error creating reverse schedule")
+	require.Equal(t, stork_api.ActionStatusSuccessful, action.Status.Status, "Unexpected final status")
+}
+
+func TestActionDeactivatePartialFailure(t *testing.T) {
+	env := newActionTestEnv(t)
+	env.createRemoteSchedule(t, "ns1", "ns2")
+	env.remoteActivator = newFakeActivator("ns2")
+	action := newTestAction("failover")
+	action.Spec.SkipFinalMigration = true
+	action = env.createAction(t, action)
+	controller := env.newController()
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error starting action")
+	require.NoError(t, controller.handle(context.TODO(), action), "Error suspending schedule")
+	require.Equal(t, stork_api.ActionStageDeactivate, action.Status.Stage, "Final migration should be skipped")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error deactivating remote cluster")
+	require.Equal(t, stork_api.ActionStageActivate, action.Status.Stage, "Action should continue when some namespaces were deactivated")
+	require.Equal(t, stork_api.ActionStatusSuccessful, getActionNamespaceStatus(action, "ns1").Status, "ns1 should be deactivated")
+	require.Equal(t, stork_api.ActionStatusFailed, getActionNamespaceStatus(action, "ns2").Status, "ns2 should fail to deactivate")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error activating")
+	require.Equal(t, map[string]bool{"ns1": true}, env.activator.updated,
+		"Namespaces that couldn't be deactivated on remote cluster shouldn't be activated")
+
+	// The reverse schedule is left suspended if the pair isn't ready anymore
+	clusterPair, err := storkops.Instance().GetClusterPair("pair", "admin")
+	require.NoError(t, err, "Error getting clusterpair")
+	clusterPair.Status.SchedulerStatus = stork_api.ClusterPairStatusError
+	_, err = storkops.Instance().UpdateClusterPair(clusterPair)
+	require.NoError(t, err, "Error updating clusterpair")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error creating reverse schedule")
+	require.Equal(t, stork_api.ActionStatusPartialSuccess, action.Status.Status, "Unexpected final status")
+	reverseSchedule, err := storkops.Instance().GetMigrationSchedule("schedule-reverse", "admin")
+	require.NoError(t, err, "Reverse schedule should be created")
+	require.True(t, *reverseSchedule.Spec.Suspend, "Reverse schedule should be suspended when the clusterpair isn't ready")
+	require.Equal(t, []string{"ns1"}, reverseSchedule.Spec.Template.Spec.Namespaces,
+		"Namespaces that weren't activated shouldn't be in the reverse schedule")
+}
+
+func TestActionDeactivateFailed(t *testing.T) {
+	env := newActionTestEnv(t)
+	env.createRemoteSchedule(t, "ns1", "ns2")
+	env.remoteActivator = newFakeActivator("ns1", "ns2")
+	action := newTestAction("failover")
+	action.Spec.SkipFinalMigration = true
+	action = env.createAction(t, action)
+	controller := env.newController()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, controller.handle(context.TODO(), action), "Error handling action")
+	}
+	require.Equal(t, stork_api.ActionStageFinal, action.Status.Stage, "Unexpected stage")
+	require.Equal(t, stork_api.ActionStatusFailed, action.Status.Status, "Action should fail when no namespace was deactivated")
+	require.Empty(t, env.activator.updated, "Namespaces shouldn't be activated")
+}
+
+func TestActionFailoverRemoteUnreachable(t *testing.T) {
+	env := newActionTestEnv(t)
+	env.remoteReachable = false
+	_, err := storkops.Instance().CreateMigrationSchedule(&stork_api.MigrationSchedule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "schedule",
+			Namespace:   "admin",
+			Annotations: map[string]string{StorkMigrationScheduleCopied: "true"},
+		},
+		Spec: stork_api.MigrationScheduleSpec{
+			Template: stork_api.MigrationTemplateSpec{
+				Spec: stork_api.MigrationSpec{
+					ClusterPair: "remotepair",
+					Namespaces:  []string{"ns1"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating copy of migrationschedule")
+	action := env.createAction(t, newTestAction("failover"))
+	controller := env.newController()
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error starting action")
+	require.False(t, action.Status.RemoteReachable, "Remote cluster shouldn't be reachable")
+	require.Equal(t, stork_api.ActionStageActivate, action.Status.Stage, "Action should go straight to activating")
+	require.Len(t, action.Status.Namespaces, 1, "Namespaces should default to the copy of the schedule")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error activating")
+	require.Equal(t, map[string]bool{"ns1": true}, env.activator.updated, "Namespace should be activated")
+	require.Empty(t, env.remoteActivator.updated, "Remote cluster shouldn't be deactivated")
+	localSchedule, err := storkops.Instance().GetMigrationSchedule("schedule", "admin")
+	require.NoError(t, err, "Error getting copy of migrationschedule")
+	require.True(t, localSchedule.Status.ApplicationActivated, "Copy of the schedule should be marked as activated")
+
+	require.NoError(t, controller.handle(context.TODO(), action), "Error creating reverse schedule")
+	require.Equal(t, stork_api.ActionStatusSuccessful, action.Status.Status, "Unexpected final status")
+	reverseSchedule, err := storkops.Instance().GetMigrationSchedule("schedule-reverse", "admin")
+	require.NoError(t, err, "Reverse schedule should be created")
+	require.True(t, *reverseSchedule.Spec.Suspend, "Reverse schedule should be suspended when the remote cluster is unreachable")
+
+	failback := newTestAction("failback")
+	failback.Spec.ActionType = stork_api.ActionTypeFailback
+	failback = env.createAction(t, failback)
+	require.NoError(t, controller.handle(context.TODO(), failback), "Error starting failback")
+	require.Equal(t, stork_api.ActionStatusFailed, failback.Status.Status, "Failback should need the remote cluster")
+}
@@ -0,0 +1,695 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/inflect"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/portworx/sched-ops/k8s/apps"
+	"github.com/portworx/sched-ops/k8s/batch"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/sched-ops/k8s/dynamic"
+	"github.com/portworx/sched-ops/k8s/openshift"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sdynamic "k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+var ibpKinds = []string{"IBPPeer", "IBPCA", "IBPOrderer", "IBPConsole"}
+
+// ApplicationActivator activates or deactivates the applications that were
+// created by a migration. It is used by storkctl to activate and deactivate
+// migrations and by the Action controller to fail over and fail back.
+//
+// Only the applications that have their replicas recorded in the
+// StorkMigrationReplicasAnnotation, and the CRs that have their suspend
+// options recorded in annotations, are updated. The annotations are left in
+// place on deactivation so that the applications can be activated again.
+// CronJobs in the namespace are suspended and resumed.
+type ApplicationActivator struct {
+	appsOps      apps.Ops
+	batchOps     batch.Ops
+	coreOps      core.Ops
+	openshiftOps openshift.Ops
+	storkOps     storkops.Ops
+	dynamicOps   dynamic.Ops
+	// crdClient is used for the CRs registered in ApplicationRegistrations
+	// since their resource names can't always be derived by dynamicOps
+	crdClient k8sdynamic.Interface
+	// printFunc is called with a message for every application that is
+	// updated
+	printFunc func(string)
+}
+
+// NewApplicationActivator returns an ApplicationActivator for the cluster
+// that the clients in this process are set up for. The config of the same
+// cluster is used for the CRs. printFunc, if not nil, is called with a
+// message for every application that is updated.
+func NewApplicationActivator(config *rest.Config, printFunc func(string)) (*ApplicationActivator, error) {
+	crdClient, err := k8sdynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return newApplicationActivator(
+		apps.Instance(),
+		batch.Instance(),
+		core.Instance(),
+		openshift.Instance(),
+		storkops.Instance(),
+		dynamic.Instance(),
+		crdClient,
+		printFunc,
+	), nil
+}
+
+// newRemoteApplicationActivator returns an ApplicationActivator for the
+// cluster with the given config
+func newRemoteApplicationActivator(config *rest.Config, printFunc func(string)) (*ApplicationActivator, error) {
+	appsOps, err := apps.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	batchOps, err := batch.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	coreOps, err := core.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	openshiftOps, err := openshift.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	storkOps, err := storkops.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicOps, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	crdClient, err := k8sdynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return newApplicationActivator(appsOps, batchOps, coreOps, openshiftOps, storkOps, dynamicOps, crdClient, printFunc), nil
+}
+
+func newApplicationActivator(
+	appsOps apps.Ops,
+	batchOps batch.Ops,
+	coreOps core.Ops,
+	openshiftOps openshift.Ops,
+	storkOps storkops.Ops,
+	dynamicOps dynamic.Ops,
+	crdClient k8sdynamic.Interface,
+	printFunc func(string),
+) *ApplicationActivator {
+	if printFunc == nil {
+		printFunc = func(string) {}
+	}
+	return &ApplicationActivator{
+		appsOps:      appsOps,
+		batchOps:     batchOps,
+		coreOps:      coreOps,
+		openshiftOps: openshiftOps,
+		storkOps:     storkOps,
+		dynamicOps:   dynamicOps,
+		crdClient:    crdClient,
+		printFunc:    printFunc,
+	}
+}
+
+// UpdateNamespace activates or deactivates the migrated applications in a
+// namespace. All the applications are updated even if some of them fail and
+// the errors are returned together
+func (a *ApplicationActivator) UpdateNamespace(namespace string, activate bool) error {
+	var failures []string
+	for _, update := range []func(string, bool) []error{
+		a.updateStatefulSets,
+		a.updateDeployments,
+		a.updateDeploymentConfigs,
+		a.updateIBPObjects,
+		a.updateCRDObjects,
+		a.updateCronJobs,
+	} {
+		for _, err := range update(namespace, activate) {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) != 0 {
+		return fmt.Errorf("%v", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// RecordApplications records the replicas and suspend options of the given
+// applications in the same annotations that a migration sets on the
+// applications it creates. This allows applications that weren't created by
+// a migration, like the ones on the source cluster of a migration, to be
+// deactivated and activated again. Applications that already have the
+// annotations, or are scaled down, are left untouched.
+func (a *ApplicationActivator) RecordApplications(resources []*stork_api.MigrationResourceInfo) error {
+	suspendOptions, err := a.getSuspendOptions()
+	if err != nil {
+		return err
+	}
+	ruleset := getActivationRuleset()
+	var failures []string
+	for _, resource := range resources {
+		kind := resource.Kind
+		gvk := schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: kind}
+		suspendOpts, isCR := suspendOptions[gvk]
+		if !isCR && !isReplicasKind(kind) {
+			continue
+		}
+		var err error
+		o := &unstructured.Unstructured{}
+		if isCR {
+			o, err = a.crdClient.Resource(gvk.GroupVersion().WithResource(ruleset.Pluralize(strings.ToLower(kind)))).
+				Namespace(resource.Namespace).
+				Get(context.TODO(), resource.Name, metav1.GetOptions{})
+		} else {
+			o.SetGroupVersionKind(gvk)
+			o.SetName(resource.Name)
+			o.SetNamespace(resource.Namespace)
+			var object runtime.Object
+			if object, err = a.dynamicOps.GetObject(o); err == nil {
+				var ok bool
+				if o, ok = object.(*unstructured.Unstructured); !ok {
+					err = fmt.Errorf("unexpected type %T", object)
+				}
+			}
+		}
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			failures = append(failures, fmt.Sprintf("error getting %v %v/%v: %v", strings.ToLower(kind), resource.Namespace, resource.Name, err))
+			continue
+		}
+		var update bool
+		if isCR {
+			update, err = recordSuspendOptions(o, suspendOpts)
+		} else {
+			update = recordReplicas(o)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("error recording suspend options for %v %v/%v: %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), err))
+			continue
+		}
+		if !update {
+			continue
+		}
+		if isCR {
+			_, err = a.crdClient.Resource(gvk.GroupVersion().WithResource(ruleset.Pluralize(strings.ToLower(kind)))).
+				Namespace(resource.Namespace).
+				Update(context.TODO(), o, metav1.UpdateOptions{})
+		} else {
+			_, err = a.dynamicOps.UpdateObject(o)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("error updating %v %v/%v: %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), err))
+		}
+	}
+	if len(failures) != 0 {
+		return fmt.Errorf("%v", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (a *ApplicationActivator) updateStatefulSets(namespace string, activate bool) []error {
+	statefulSets, err := a.appsOps.ListStatefulSets(namespace, metav1.ListOptions{})
+	if err != nil {
+		return []error{fmt.Errorf("error listing statefulsets: %v", err)}
+	}
+	var errs []error
+	for _, statefulSet := range statefulSets.Items {
+		replicas, update, err := getUpdatedReplicaCount(statefulSet.Annotations, activate)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error parsing replicas for statefulset %v/%v: %v", statefulSet.Namespace, statefulSet.Name, err))
+			continue
+		}
+		if !update || (statefulSet.Spec.Replicas != nil && *statefulSet.Spec.Replicas == replicas) {
+			continue
+		}
+		statefulSet.Spec.Replicas = &replicas
+		if _, err := a.appsOps.UpdateStatefulSet(&statefulSet); err != nil {
+			errs = append(errs, fmt.Errorf("error updating replicas for statefulset %v/%v: %v", statefulSet.Namespace, statefulSet.Name, err))
+			continue
+		}
+		a.printFunc(fmt.Sprintf("Updated replicas for statefulset %v/%v to %v", statefulSet.Namespace, statefulSet.Name, replicas))
+	}
+	return errs
+}
+
+func (a *ApplicationActivator) updateDeployments(namespace string, activate bool) []error {
+	deployments, err := a.appsOps.ListDeployments(namespace, metav1.ListOptions{})
+	if err != nil {
+		return []error{fmt.Errorf("error listing deployments: %v", err)}
+	}
+	var errs []error
+	for _, deployment := range deployments.Items {
+		replicas, update, err := getUpdatedReplicaCount(deployment.Annotations, activate)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error parsing replicas for deployment %v/%v: %v", deployment.Namespace, deployment.Name, err))
+			continue
+		}
+		if !update || (deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == replicas) {
+			continue
+		}
+		deployment.Spec.Replicas = &replicas
+		if _, err := a.appsOps.UpdateDeployment(&deployment); err != nil {
+			errs = append(errs, fmt.Errorf("error updating replicas for deployment %v/%v: %v", deployment.Namespace, deployment.Name, err))
+			continue
+		}
+		a.printFunc(fmt.Sprintf("Updated replicas for deployment %v/%v to %v", deployment.Namespace, deployment.Name, replicas))
+	}
+	return errs
+}
+
+func (a *ApplicationActivator) updateDeploymentConfigs(namespace string, activate bool) []error {
+	deployments, err := a.openshiftOps.ListDeploymentConfigs(namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return []error{fmt.Errorf("error listing deploymentconfigs: %v", err)}
+	}
+	var errs []error
+	for _, deployment := range deployments.Items {
+		replicas, update, err := getUpdatedReplicaCount(deployment.Annotations, activate)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error parsing replicas for deploymentconfig %v/%v: %v", deployment.Namespace, deployment.Name, err))
+			continue
+		}
+		if !update || deployment.Spec.Replicas == replicas {
+			continue
+		}
+		deployment.Spec.Replicas = replicas
+		if _, err := a.openshiftOps.UpdateDeploymentConfig(&deployment); err != nil {
+			errs = append(errs, fmt.Errorf("error updating replicas for deploymentconfig %v/%v: %v", deployment.Namespace, deployment.Name, err))
+			continue
+		}
+		a.printFunc(fmt.Sprintf("Updated replicas for deploymentconfig %v/%v to %v", deployment.Namespace, deployment.Name, replicas))
+	}
+	return errs
+}
+
+func (a *ApplicationActivator) updateIBPObjects(namespace string, activate bool) []error {
+	var errs []error
+	for _, kind := range ibpKinds {
+		objects, err := a.dynamicOps.ListObjects(
+			&metav1.ListOptions{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       kind,
+					APIVersion: "ibp.com/v1alpha1",
+				},
+			},
+			namespace)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("error listing %v: %v", strings.ToLower(kind), err))
+			continue
+		}
+		for _, o := range objects.Items {
+			replicas, update, err := getUpdatedReplicaCount(o.GetAnnotations(), activate)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("error parsing replicas for %v %v/%v: %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), err))
+				continue
+			}
+			if !update {
+				continue
+			}
+			if err := unstructured.SetNestedField(o.Object, int64(replicas), "spec", "replicas"); err != nil {
+				errs = append(errs, fmt.Errorf("error updating replicas for %v %v/%v: %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), err))
+				continue
+			}
+			if _, err := a.dynamicOps.UpdateObject(&o); err != nil {
+				errs = append(errs, fmt.Errorf("error updating replicas for %v %v/%v: %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), err))
+				continue
+			}
+			a.printFunc(fmt.Sprintf("Updated replicas for %v %v/%v to %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), replicas))
+		}
+	}
+	return errs
+}
+
+func (a *ApplicationActivator) updateCRDObjects(namespace string, activate bool) []error {
+	suspendOptions, err := a.getSuspendOptions()
+	if err != nil {
+		return []error{err}
+	}
+	ruleset := getActivationRuleset()
+	var errs []error
+	for gvk, suspendOpts := range suspendOptions {
+		kind := strings.ToLower(gvk.Kind)
+		client := a.crdClient.Resource(gvk.GroupVersion().WithResource(ruleset.Pluralize(kind))).Namespace(namespace)
+		objects, err := client.List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("error listing %v: %v", kind, err))
+			continue
+		}
+		for _, o := range objects.Items {
+			update, err := setSuspendOptions(&o, suspendOpts, activate)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("error updating %v %v/%v: %v", kind, o.GetNamespace(), o.GetName(), err))
+				continue
+			}
+			if !update {
+				continue
+			}
+			if _, err := client.Update(context.TODO(), &o, metav1.UpdateOptions{}); err != nil {
+				errs = append(errs, fmt.Errorf("error updating %v %v/%v: %v", kind, o.GetNamespace(), o.GetName(), err))
+				continue
+			}
+			a.printFunc(fmt.Sprintf("Updated CR for %v %v/%v", kind, o.GetNamespace(), o.GetName()))
+			if activate {
+				continue
+			}
+			// The pods of the CR aren't always deleted by its operator
+			// when it is suspended
+			for _, podsPath := range suspendOpts.podsPaths {
+				pods, found, err := unstructured.NestedStringSlice(o.Object, strings.Split(podsPath, ".")...)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("error getting pods for %v %v/%v: %v", kind, o.GetNamespace(), o.GetName(), err))
+					continue
+				}
+				if !found {
+					continue
+				}
+				for _, pod := range pods {
+					if err := a.coreOps.DeletePod(o.GetNamespace(), pod, true); err != nil && !errors.IsNotFound(err) {
+						errs = append(errs, fmt.Errorf("error deleting pod %v for %v %v/%v: %v", pod, kind, o.GetNamespace(), o.GetName(), err))
+					}
+				}
+			}
+		}
+	}
+	return errs
+}
+
+func (a *ApplicationActivator) updateCronJobs(namespace string, activate bool) []error {
+	cronJobs, err := a.batchOps.ListCronJobs(namespace, metav1.ListOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return []error{fmt.Errorf("error listing cronjobs: %v", err)}
+	}
+	var errs []error
+	for _, cronJob := range cronJobs.Items {
+		suspend := !activate
+		if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend == suspend {
+			continue
+		}
+		cronJob.Spec.Suspend = &suspend
+		if _, err := a.batchOps.UpdateCronJob(&cronJob); err != nil {
+			errs = append(errs, fmt.Errorf("error updating suspend option for cronjob %v/%v: %v", cronJob.Namespace, cronJob.Name, err))
+			continue
+		}
+		a.printFunc(fmt.Sprintf("Updated suspend option for cronjob %v/%v to %v", cronJob.Namespace, cronJob.Name, suspend))
+	}
+	return errs
+}
+
+// crdSuspendOptions are the suspend options of a CR from all the
+// ApplicationRegistrations
+type crdSuspendOptions struct {
+	options   []stork_api.SuspendOptions
+	podsPaths []string
+}
+
+// getSuspendOptions returns the suspend options of the CRs registered in the
+// ApplicationRegistrations. CRs without suspend options are skipped
+func (a *ApplicationActivator) getSuspendOptions() (map[schema.GroupVersionKind]*crdSuspendOptions, error) {
+	appRegs, err := a.storkOps.ListApplicationRegistrations()
+	if err != nil {
+		return nil, fmt.Errorf("error listing applicationregistrations: %v", err)
+	}
+	suspendOptions := make(map[schema.GroupVersionKind]*crdSuspendOptions)
+	for _, appReg := range appRegs.Items {
+		for _, crd := range appReg.Resources {
+			options := append([]stork_api.SuspendOptions{}, crd.NestedSuspendOptions...)
+			if crd.SuspendOptions.Path != "" {
+				options = append(options, crd.SuspendOptions)
+			}
+			if len(options) == 0 {
+				continue
+			}
+			gvk := schema.GroupVersionKind{Group: crd.Group, Version: crd.Version, Kind: crd.Kind}
+			if suspendOptions[gvk] == nil {
+				suspendOptions[gvk] = &crdSuspendOptions{}
+			}
+			suspendOptions[gvk].options = append(suspendOptions[gvk].options, options...)
+			if crd.PodsPath != "" {
+				suspendOptions[gvk].podsPaths = append(suspendOptions[gvk].podsPaths, crd.PodsPath)
+			}
+		}
+	}
+	return suspendOptions, nil
+}
+
+func getActivationRuleset() *inflect.Ruleset {
+	ruleset := inflect.NewDefaultRuleset()
+	ruleset.AddPlural("quota", "quotas")
+	ruleset.AddPlural("prometheus", "prometheuses")
+	ruleset.AddPlural("mongodbcommunity", "mongodbcommunity")
+	return ruleset
+}
+
+// GetCRResourceName returns the resource name used for a kind registered
+// with an ApplicationRegistration
+func GetCRResourceName(kind string) string {
+	return getActivationRuleset().Pluralize(strings.ToLower(kind))
+}
+
+// isReplicasKind returns true for the kinds whose replicas are recorded in
+// the StorkMigrationReplicasAnnotation by a migration
+func isReplicasKind(kind string) bool {
+	switch kind {
+	case "Deployment", "StatefulSet", "DeploymentConfig":
+		return true
+	}
+	for _, ibpKind := range ibpKinds {
+		if kind == ibpKind {
+			return true
+		}
+	}
+	return false
+}
+
+// getUpdatedReplicaCount returns the replicas to set for an application that
+// was created by a migration. On activation the replicas are restored from
+// the annotation and on deactivation they are set to 0. Applications without
+// the annotation weren't created by a migration and are left untouched.
+func getUpdatedReplicaCount(annotations map[string]string, activate bool) (int32, bool, error) {
+	recorded, present := annotations[StorkMigrationReplicasAnnotation]
+	if !present {
+		return 0, false, nil
+	}
+	if !activate {
+		return 0, true, nil
+	}
+	replicas, err := strconv.ParseInt(recorded, 10, 32)
+	if err != nil {
+		return 0, false, err
+	}
+	return int32(replicas), true, nil
+}
+
+// recordReplicas records the current replicas of an application in the
+// StorkMigrationReplicasAnnotation. Returns false if the annotation is already
+// present or the application is scaled down.
+func recordReplicas(object *unstructured.Unstructured) bool {
+	annotations := object.GetAnnotations()
+	if _, present := annotations[StorkMigrationReplicasAnnotation]; present {
+		return false
+	}
+	replicas, found, err := unstructured.NestedInt64(object.Object, "spec", "replicas")
+	if err != nil {
+		return false
+	}
+	if !found {
+		replicas = 1
+	}
+	if replicas == 0 {
+		return false
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[StorkMigrationReplicasAnnotation] = strconv.FormatInt(replicas, 10)
+	object.SetAnnotations(annotations)
+	return true
+}
+
+// recordSuspendOptions records the current values of the suspend options of
+// a CR in annotations the same way as a migration does. Options that are
+// already recorded are left untouched.
+func recordSuspendOptions(object *unstructured.Unstructured, suspendOpts *crdSuspendOptions) (bool, error) {
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	update := false
+	for _, suspend := range suspendOpts.options {
+		fields := strings.Split(suspend.Path, ".")
+		if len(fields) <= 1 {
+			continue
+		}
+		if _, present := annotations[StorkAnnotationPrefix+suspend.Path]; present {
+			continue
+		}
+		var currVal string
+		switch suspend.Type {
+		case "bool":
+		case "int":
+			curr, found, err := unstructured.NestedInt64(object.Object, fields...)
+			if err != nil || !found {
+				return false, fmt.Errorf("unable to find suspend path %v: %v", suspend.Path, err)
+			}
+			currVal = strconv.FormatInt(curr, 10)
+		case "string":
+			curr, _, err := unstructured.NestedString(object.Object, fields...)
+			if err != nil {
+				return false, fmt.Errorf("unable to find suspend path %v: %v", suspend.Path, err)
+			}
+			currVal = curr
+		default:
+			return false, fmt.Errorf("invalid type %v to suspend cr", suspend.Type)
+		}
+		annotations[StorkAnnotationPrefix+suspend.Path] = currVal + "," + suspend.Value
+		update = true
+	}
+	object.SetAnnotations(annotations)
+	return update, nil
+}
+
+// setSuspendOptions sets the fields of a CR that was created by a migration
+// from the suspend options of its ApplicationRegistration. The active and
+// inactive values are read from the annotations set by the migration. CRs
+// without the annotations weren't created by a migration and are left
+// untouched.
+func setSuspendOptions(object *unstructured.Unstructured, suspendOpts *crdSuspendOptions, activate bool) (bool, error) {
+	annotations := object.GetAnnotations()
+	if len(annotations) == 0 {
+		return false, nil
+	}
+	update := false
+	for _, suspend := range suspendOpts.options {
+		fields := strings.Split(suspend.Path, ".")
+		if len(fields) <= 1 {
+			continue
+		}
+		var value interface{}
+		switch suspend.Type {
+		case "bool":
+			if !isSuspendOptionRecorded(annotations, suspend.Path) {
+				continue
+			}
+			disable, err := strconv.ParseBool(suspend.Value)
+			if err != nil {
+				disable = true
+			}
+			value = disable
+			if activate {
+				value = !disable
+			}
+		case "int":
+			replicas, present, err := getSuspendIntOpts(annotations, activate, suspend.Path)
+			if err != nil {
+				return false, err
+			}
+			if !present {
+				continue
+			}
+			value = replicas
+		case "string":
+			suspendValue, present, err := getSuspendStringOpts(annotations, activate, suspend.Path)
+			if err != nil {
+				return false, err
+			}
+			if !present {
+				continue
+			}
+			value = suspendValue
+		default:
+			return false, fmt.Errorf("invalid type %v to suspend cr", suspend.Type)
+		}
+		if err := unstructured.SetNestedField(object.Object, value, fields...); err != nil {
+			return false, fmt.Errorf("error updating %q to %v: %v", suspend.Path, value, err)
+		}
+		update = true
+	}
+	return update, nil
+}
+
+// isSuspendOptionRecorded returns true if a migration recorded the suspend
+// option for the path, or the values to activate and deactivate the CR for
+// CRs migrated by older versions
+func isSuspendOptionRecorded(annotations map[string]string, path string) bool {
+	if _, present := annotations[StorkAnnotationPrefix+path]; present {
+		return true
+	}
+	_, present := annotations[StorkMigrationCRDActivateAnnotation]
+	return present
+}
+
+// getSuspendStringOpts returns the value to set for a string suspend option.
+// Returns false if the values weren't recorded by a migration.
+func getSuspendStringOpts(annotations map[string]string, activate bool, path string) (string, bool, error) {
+	if val, present := annotations[StorkAnnotationPrefix+path]; present {
+		suspend := strings.Split(val, ",")
+		if len(suspend) != 2 {
+			return "", false, fmt.Errorf("migrated annotation does not have proper values %s/%s", StorkAnnotationPrefix+path, val)
+		}
+		if activate {
+			return suspend[0], true, nil
+		}
+		return suspend[1], true, nil
+	}
+	// for backward compatibility of old migrated cr's
+	crdOpts := StorkMigrationCRDActivateAnnotation
+	if !activate {
+		crdOpts = StorkMigrationCRDDeactivateAnnotation
+	}
+	suspend, present := annotations[crdOpts]
+	return suspend, present, nil
+}
+
+// getSuspendIntOpts returns the value to set for an int suspend option.
+// Returns false if the values weren't recorded by a migration.
+func getSuspendIntOpts(annotations map[string]string, activate bool, path string) (int64, bool, error) {
+	intOpts := ""
+	if val, present := annotations[StorkAnnotationPrefix+path]; present {
+		intOpts = strings.Split(val, ",")[0]
+	} else if val, present := annotations[StorkMigrationCRDActivateAnnotation]; present {
+		// for old migrated cr compatibility
+		intOpts = val
+	} else {
+		return 0, false, nil
+	}
+	if !activate {
+		return 0, true, nil
+	}
+	replicas, err := strconv.ParseInt(intOpts, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("error parsing replicas for %v: %v", path, err)
+	}
+	return replicas, true, nil
+}
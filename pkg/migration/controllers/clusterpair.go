@@ -3,18 +3,23 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"os/exec"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/libopenstorage/stork/drivers/volume"
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/controllers"
+	storkerrors "github.com/libopenstorage/stork/pkg/errors"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
 	"github.com/libopenstorage/stork/pkg/version"
 	"github.com/portworx/sched-ops/k8s/apiextensions"
 	"github.com/portworx/sched-ops/k8s/core"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/sirupsen/logrus"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	v1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -31,8 +36,34 @@ import (
 const (
 	validateCRDInterval time.Duration = 5 * time.Second
 	validateCRDTimeout  time.Duration = 1 * time.Minute
+
+	// ClusterPairCredentialsVersionAnnotation is the resource version of the
+	// credentials secret the config of the pair was last loaded from
+	ClusterPairCredentialsVersionAnnotation = "stork.libopenstorage.org/credentials-version"
+
+	clusterPairProbeInterval      = 2 * time.Minute
+	clusterPairProbeTimeout       = 30 * time.Second
+	clusterPairDefaultCredentials = "kubeconfig"
 )
 
+// Permissions required on the remote cluster on cluster scoped resources to
+// migrate applications
+var clusterPairRequiredClusterPermissions = []authorizationv1.ResourceAttributes{
+	{Verb: "create", Resource: "namespaces"},
+	{Verb: "create", Resource: "persistentvolumes"},
+}
+
+// Permissions required on the remote cluster to migrate applications. They
+// are checked in the namespace of the pair and the namespaces migrated with
+// it, since pairs created with storkctl are only given permissions in those
+var clusterPairRequiredPermissions = []authorizationv1.ResourceAttributes{
+	{Verb: "create", Resource: "persistentvolumeclaims"},
+	{Verb: "create", Resource: "secrets"},
+	{Verb: "create", Resource: "services"},
+	{Verb: "create", Group: "apps", Resource: "deployments"},
+	{Verb: "create", Group: "apps", Resource: "statefulsets"},
+}
+
 // NewClusterPair creates a new instance of ClusterPairController.
 func NewClusterPair(mgr manager.Manager, d volume.Driver, r record.EventRecorder) *ClusterPairController {
 	return &ClusterPairController{
@@ -115,6 +146,10 @@ func (c *ClusterPairController) handle(ctx context.Context, clusterPair *stork_a
 		return nil
 	}
 
+	if refreshed, err := c.refreshCredentials(clusterPair); err != nil || refreshed {
+		return err
+	}
+
 	if _, ok := clusterPair.Spec.Options["token"]; !ok {
 		clusterPair.Status.StorageStatus = stork_api.ClusterPairStatusNotProvided
 		c.recorder.Event(clusterPair,
@@ -150,22 +185,29 @@ func (c *ClusterPairController) handle(ctx context.Context, clusterPair *stork_a
 	}
 	if clusterPair.Status.SchedulerStatus != stork_api.ClusterPairStatusReady {
 		clusterPair.Status.SchedulerStatus = stork_api.ClusterPairStatusError
-		remoteConfig, err := getClusterPairSchedulerConfig(clusterPair.Name, clusterPair.Namespace)
-		if err != nil {
-			return err
-		}
-
-		client, err := kubernetes.NewForConfig(remoteConfig)
-		if err != nil {
-			return err
-		}
-		if _, err = client.ServerVersion(); err != nil {
+		// Pairs that aren't ready aren't probed periodically, so refresh the
+		// conditions from each attempt to pair the scheduler instead
+		reachable, permissions := c.probeRemote(clusterPair)
+		setClusterPairCondition(clusterPair, reachable)
+		setClusterPairCondition(clusterPair, permissions)
+		clusterPair.Status.LastProbeTimestamp = metav1.Now()
+		if reachable.Status != metav1.ConditionTrue {
 			c.recorder.Event(clusterPair,
 				v1.EventTypeWarning,
 				string(clusterPair.Status.SchedulerStatus),
-				err.Error())
+				reachable.Message)
 			return c.client.Update(context.TODO(), clusterPair)
 		}
+		if permissions.Status == metav1.ConditionFalse {
+			c.recorder.Event(clusterPair,
+				v1.EventTypeWarning,
+				string(stork_api.ClusterPairConditionRemotePermissions),
+				permissions.Message)
+		}
+		remoteConfig, err := getClusterPairConfig(clusterPair)
+		if err != nil {
+			return err
+		}
 		if err := c.createBackupLocationOnRemote(remoteConfig, clusterPair); err != nil {
 			c.recorder.Event(clusterPair,
 				v1.EventTypeWarning,
@@ -185,14 +227,302 @@ func (c *ClusterPairController) handle(ctx context.Context, clusterPair *stork_a
 		}
 	}
 
+	return c.probe(clusterPair)
+}
+
+// refreshCredentials loads the config of the pair from the credentials secret
+// if the secret changed since it was last loaded. The scheduler is paired
+// again with the new config
+func (c *ClusterPairController) refreshCredentials(clusterPair *stork_api.ClusterPair) (bool, error) {
+	if clusterPair.Spec.CredentialsSecret == nil {
+		return false, nil
+	}
+	secret, err := core.Instance().GetSecret(clusterPair.Spec.CredentialsSecret.Name, clusterPair.Namespace)
+	if err != nil {
+		c.recorder.Event(clusterPair,
+			v1.EventTypeWarning,
+			string(stork_api.ClusterPairStatusError),
+			fmt.Sprintf("Error getting credentials secret: %v", err))
+		return false, fmt.Errorf("error getting credentials secret %v: %v", clusterPair.Spec.CredentialsSecret.Name, err)
+	}
+	if clusterPair.Annotations[ClusterPairCredentialsVersionAnnotation] == secret.ResourceVersion {
+		return false, nil
+	}
+	key := clusterPair.Spec.CredentialsSecret.Key
+	if key == "" {
+		key = clusterPairDefaultCredentials
+	}
+	data, ok := secret.Data[key]
+	if !ok {
+		return false, fmt.Errorf("key %v not found in credentials secret %v", key, secret.Name)
+	}
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return false, fmt.Errorf("error loading kubeconfig from credentials secret %v: %v", secret.Name, err)
+	}
+	clusterPair.Spec.Config = *config
+	if err := validateClusterPairExecPlugin(clusterPair); err != nil {
+		c.recorder.Event(clusterPair,
+			v1.EventTypeWarning,
+			string(stork_api.ClusterPairStatusError),
+			err.Error())
+	}
+	if clusterPair.Annotations == nil {
+		clusterPair.Annotations = make(map[string]string)
+	}
+	clusterPair.Annotations[ClusterPairCredentialsVersionAnnotation] = secret.ResourceVersion
+	clusterPair.Status.SchedulerStatus = stork_api.ClusterPairStatusPending
+	c.recorder.Event(clusterPair,
+		v1.EventTypeNormal,
+		string(clusterPair.Status.SchedulerStatus),
+		fmt.Sprintf("Credentials refreshed from secret %v", secret.Name))
+	return true, c.client.Update(context.TODO(), clusterPair)
+}
+
+// probe checks periodically that the remote cluster can still be reached with
+// the config of the pair and has the permissions needed for migrations, and
+// that the storage is still paired. Kubeconfigs that use an exec plugin get
+// their token refreshed by the plugin when probing
+func (c *ClusterPairController) probe(clusterPair *stork_api.ClusterPair) error {
+	if clusterPair.Status.SchedulerStatus != stork_api.ClusterPairStatusReady ||
+		time.Since(clusterPair.Status.LastProbeTimestamp.Time) < clusterPairProbeInterval {
+		return nil
+	}
+
+	reachable, permissions := c.probeRemote(clusterPair)
+	setClusterPairCondition(clusterPair, reachable)
+	setClusterPairCondition(clusterPair, permissions)
+	if reachable.Status == metav1.ConditionFalse {
+		clusterPair.Status.SchedulerStatus = stork_api.ClusterPairStatusDegraded
+		c.recorder.Event(clusterPair,
+			v1.EventTypeWarning,
+			string(clusterPair.Status.SchedulerStatus),
+			fmt.Sprintf("Remote cluster not reachable: %v", reachable.Message))
+	} else if permissions.Status == metav1.ConditionFalse {
+		c.recorder.Event(clusterPair,
+			v1.EventTypeWarning,
+			string(stork_api.ClusterPairConditionRemotePermissions),
+			permissions.Message)
+	}
+
+	if _, ok := clusterPair.Spec.Options["token"]; ok && clusterPair.Status.StorageStatus == stork_api.ClusterPairStatusReady {
+		storage := stork_api.ClusterPairCondition{
+			Type:   stork_api.ClusterPairConditionStoragePaired,
+			Status: metav1.ConditionTrue,
+			Reason: "Paired",
+		}
+		if err := c.volDriver.ValidatePair(clusterPair); err != nil {
+			if _, ok := err.(*storkerrors.ErrNotSupported); ok {
+				storage.Status = metav1.ConditionUnknown
+				storage.Reason = "NotSupported"
+			} else {
+				storage.Status = metav1.ConditionFalse
+				storage.Reason = "PairNotFound"
+				storage.Message = err.Error()
+				clusterPair.Status.StorageStatus = stork_api.ClusterPairStatusDegraded
+				c.recorder.Event(clusterPair,
+					v1.EventTypeWarning,
+					string(clusterPair.Status.StorageStatus),
+					fmt.Sprintf("Storage pair not valid: %v", err))
+			}
+		}
+		setClusterPairCondition(clusterPair, storage)
+	}
+
+	clusterPair.Status.LastProbeTimestamp = metav1.Now()
+	return c.client.Update(context.TODO(), clusterPair)
+}
+
+// probeRemote checks that the remote cluster is reachable with the config of
+// the pair and that it has the permissions needed to migrate applications
+func (c *ClusterPairController) probeRemote(
+	clusterPair *stork_api.ClusterPair,
+) (stork_api.ClusterPairCondition, stork_api.ClusterPairCondition) {
+	reachable := stork_api.ClusterPairCondition{
+		Type:   stork_api.ClusterPairConditionRemoteReachable,
+		Status: metav1.ConditionFalse,
+	}
+	permissions := stork_api.ClusterPairCondition{
+		Type:    stork_api.ClusterPairConditionRemotePermissions,
+		Status:  metav1.ConditionUnknown,
+		Reason:  "RemoteNotReachable",
+		Message: "Permissions can't be checked since the remote cluster is not reachable",
+	}
+	if err := validateClusterPairExecPlugin(clusterPair); err != nil {
+		reachable.Reason = "ExecPluginError"
+		reachable.Message = err.Error()
+		return reachable, permissions
+	}
+	remoteConfig, err := getClusterPairConfig(clusterPair)
+	if err != nil {
+		reachable.Reason = "InvalidConfig"
+		reachable.Message = err.Error()
+		return reachable, permissions
+	}
+	remoteConfig.Timeout = clusterPairProbeTimeout
+	client, err := kubernetes.NewForConfig(remoteConfig)
+	if err != nil {
+		reachable.Reason = "InvalidConfig"
+		reachable.Message = err.Error()
+		return reachable, permissions
+	}
+	if _, err := client.ServerVersion(); err != nil {
+		reachable.Reason = "Unreachable"
+		if errors.IsUnauthorized(err) {
+			reachable.Reason = "Unauthorized"
+		}
+		reachable.Message = err.Error()
+		return reachable, permissions
+	}
+	reachable.Status = metav1.ConditionTrue
+	reachable.Reason = "Reachable"
+
+	namespaces, err := GetClusterPairMigrationNamespaces(storkops.Instance(), clusterPair.Name, clusterPair.Namespace)
+	if err != nil {
+		permissions.Reason = "CheckFailed"
+		permissions.Message = fmt.Sprintf("Error getting migrated namespaces: %v", err)
+		return reachable, permissions
+	}
+	return reachable, CheckClusterPairPermissions(client, append([]string{clusterPair.Namespace}, namespaces...))
+}
+
+// CheckClusterPairPermissions checks that the client has the permissions
+// needed to migrate applications to the given namespaces, and returns the
+// result as the RemotePermissions condition of a pair
+func CheckClusterPairPermissions(client kubernetes.Interface, namespaces []string) stork_api.ClusterPairCondition {
+	permissions := stork_api.ClusterPairCondition{
+		Type:   stork_api.ClusterPairConditionRemotePermissions,
+		Status: metav1.ConditionUnknown,
+	}
+	checks := make([]authorizationv1.ResourceAttributes, 0)
+	checks = append(checks, clusterPairRequiredClusterPermissions...)
+	checked := make(map[string]bool)
+	for _, ns := range namespaces {
+		if checked[ns] {
+			continue
+		}
+		checked[ns] = true
+		for _, attributes := range clusterPairRequiredPermissions {
+			attributes.Namespace = ns
+			checks = append(checks, attributes)
+		}
+	}
+
+	denied := make([]string, 0)
+	for _, attributes := range checks {
+		attributes := attributes
+		review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(context.TODO(), &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &attributes,
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			permissions.Reason = "CheckFailed"
+			permissions.Message = fmt.Sprintf("Error checking permissions: %v", err)
+			return permissions
+		}
+		if !review.Status.Allowed {
+			resource := attributes.Resource
+			if attributes.Group != "" {
+				resource = resource + "." + attributes.Group
+			}
+			if attributes.Namespace != "" {
+				resource = resource + " in " + attributes.Namespace
+			}
+			denied = append(denied, attributes.Verb+" "+resource)
+		}
+	}
+	if len(denied) != 0 {
+		permissions.Status = metav1.ConditionFalse
+		permissions.Reason = "PermissionDenied"
+		permissions.Message = fmt.Sprintf("Missing permissions on remote cluster: %v", strings.Join(denied, ", "))
+		return permissions
+	}
+	permissions.Status = metav1.ConditionTrue
+	permissions.Reason = "Allowed"
+	return permissions
+}
+
+// GetClusterPairMigrationNamespaces returns the namespaces migrated by the
+// Migrations and MigrationSchedules that use the pair
+func GetClusterPairMigrationNamespaces(storkClient storkops.Ops, name, namespace string) ([]string, error) {
+	namespaces := make([]string, 0)
+	found := make(map[string]bool)
+	add := func(migrationNamespaces []string) {
+		for _, ns := range migrationNamespaces {
+			if !found[ns] {
+				found[ns] = true
+				namespaces = append(namespaces, ns)
+			}
+		}
+	}
+	migrations, err := storkClient.ListMigrations(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error listing migrations: %v", err)
+	}
+	for _, migration := range migrations.Items {
+		if migration.Spec.ClusterPair == name {
+			add(migration.Spec.Namespaces)
+		}
+	}
+	schedules, err := storkClient.ListMigrationSchedules(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error listing migrationschedules: %v", err)
+	}
+	for _, schedule := range schedules.Items {
+		if schedule.Spec.Template.Spec.ClusterPair == name {
+			add(schedule.Spec.Template.Spec.Namespaces)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// validateClusterPairExecPlugin checks that the command of the exec plugin
+// used by the current context of the pair can be run by stork. The plugin is
+// run by the client to get a new token whenever the previous one expires
+func validateClusterPairExecPlugin(clusterPair *stork_api.ClusterPair) error {
+	config := clusterPair.Spec.Config
+	currContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return nil
+	}
+	authInfo, ok := config.AuthInfos[currContext.AuthInfo]
+	if !ok || authInfo.Exec == nil {
+		return nil
+	}
+	if _, err := exec.LookPath(authInfo.Exec.Command); err != nil {
+		return fmt.Errorf("command %v of the exec plugin in the kubeconfig can't be run by stork: %v", authInfo.Exec.Command, err)
+	}
 	return nil
 }
 
+// setClusterPairCondition updates the condition of the same type in the
+// status of the pair, keeping the transition time if the status didn't change
+func setClusterPairCondition(clusterPair *stork_api.ClusterPair, condition stork_api.ClusterPairCondition) {
+	condition.LastTransitionTime = metav1.Now()
+	for i, existing := range clusterPair.Status.Conditions {
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		clusterPair.Status.Conditions[i] = condition
+		return
+	}
+	clusterPair.Status.Conditions = append(clusterPair.Status.Conditions, condition)
+}
+
 func getClusterPairSchedulerConfig(clusterPairName string, namespace string) (*restclient.Config, error) {
 	clusterPair, err := storkops.Instance().GetClusterPair(clusterPairName, namespace)
 	if err != nil {
 		return nil, fmt.Errorf("error getting clusterpair (%v/%v): %v", namespace, clusterPairName, err)
 	}
+	return getClusterPairConfig(clusterPair)
+}
+
+func getClusterPairConfig(clusterPair *stork_api.ClusterPair) (*restclient.Config, error) {
 	remoteClientConfig := clientcmd.NewNonInteractiveClientConfig(
 		clusterPair.Spec.Config,
 		clusterPair.Spec.Config.CurrentContext,
@@ -0,0 +1,296 @@
+//go:build unittest
+// +build unittest
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	"github.com/portworx/sched-ops/k8s/core"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeRemoteCluster is an API server that only serves the version and
+// SelfSubjectAccessReviews, which is all that is used to probe a pair
+type fakeRemoteCluster struct {
+	*httptest.Server
+	token  string
+	denied map[string]bool
+}
+
+func newFakeRemoteCluster(t *testing.T, token string) *fakeRemoteCluster {
+	remote := &fakeRemoteCluster{
+		token:  token,
+		denied: make(map[string]bool),
+	}
+	remote.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer "+remote.token {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Unauthorized","code":401}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/version":
+			_, _ = w.Write([]byte(`{"major":"1","minor":"21","gitVersion":"v1.21.4"}`))
+		case "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews":
+			review := &authorizationv1.SelfSubjectAccessReview{}
+			body, _ := ioutil.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, review), "Error decoding access review")
+			attributes := review.Spec.ResourceAttributes
+			review.Status.Allowed = !remote.denied[attributes.Namespace+"/"+attributes.Resource]
+			w.WriteHeader(http.StatusCreated)
+			require.NoError(t, json.NewEncoder(w).Encode(review), "Error encoding access review")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(remote.Close)
+	return remote
+}
+
+func newRemoteConfig(server string, authInfo *clientcmdapi.AuthInfo) clientcmdapi.Config {
+	return clientcmdapi.Config{
+		CurrentContext: "remote",
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"remote": {Server: server, InsecureSkipTLSVerify: true},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			"remote": {Cluster: "remote", AuthInfo: "remote"},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"remote": authInfo,
+		},
+	}
+}
+
+func newTestClusterPairController(t *testing.T, objects ...runtime.Object) (*ClusterPairController, *kubernetes.Clientset, *storkops.Client) {
+	kubeClient := kubernetes.NewSimpleClientset(objects...)
+	core.SetInstance(core.New(kubeClient))
+	storkClient := storkops.New(kubeClient, fakeclient.NewSimpleClientset(), nil)
+	storkops.SetInstance(storkClient)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, stork_api.AddToScheme(scheme), "Error adding stork types to scheme")
+	return &ClusterPairController{
+		client:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+		recorder: record.NewFakeRecorder(100),
+	}, kubeClient, storkClient
+}
+
+func getClusterPairCondition(clusterPair *stork_api.ClusterPair, conditionType stork_api.ClusterPairConditionType) *stork_api.ClusterPairCondition {
+	for i := range clusterPair.Status.Conditions {
+		if clusterPair.Status.Conditions[i].Type == conditionType {
+			return &clusterPair.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func TestClusterPairProbeRemote(t *testing.T) {
+	remote := newFakeRemoteCluster(t, "sa-token")
+	c, _, storkClient := newTestClusterPairController(t)
+	_, err := storkClient.CreateMigrationSchedule(&stork_api.MigrationSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "schedule", Namespace: "admin"},
+		Spec: stork_api.MigrationScheduleSpec{
+			Template: stork_api.MigrationTemplateSpec{
+				Spec: stork_api.MigrationSpec{
+					ClusterPair: "pair1",
+					Namespaces:  []string{"ns1"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating migrationschedule")
+
+	clusterPair := &stork_api.ClusterPair{
+		ObjectMeta: metav1.ObjectMeta{Name: "pair1", Namespace: "admin"},
+		Spec: stork_api.ClusterPairSpec{
+			Config: newRemoteConfig(remote.URL, &clientcmdapi.AuthInfo{Token: "sa-token"}),
+		},
+	}
+	reachable, permissions := c.probeRemote(clusterPair)
+	require.Equal(t, metav1.ConditionTrue, reachable.Status, "Remote should be reachable: %v", reachable.Message)
+	require.Equal(t, metav1.ConditionTrue, permissions.Status, "Permissions should be allowed: %v", permissions.Message)
+
+	remote.denied["ns1/secrets"] = true
+	remote.denied["/persistentvolumes"] = true
+	_, permissions = c.probeRemote(clusterPair)
+	require.Equal(t, metav1.ConditionFalse, permissions.Status, "Permissions should be denied")
+	require.Equal(t, "PermissionDenied", permissions.Reason, "Unexpected reason for denied permissions")
+	require.Contains(t, permissions.Message, "create secrets in ns1", "Denied permission in migrated namespace should be reported")
+	require.Contains(t, permissions.Message, "create persistentvolumes", "Denied cluster scoped permission should be reported")
+	require.NotContains(t, permissions.Message, "in admin", "Permissions in the pair namespace should be allowed")
+
+	clusterPair.Spec.Config = newRemoteConfig(remote.URL, &clientcmdapi.AuthInfo{Token: "expired-token"})
+	reachable, permissions = c.probeRemote(clusterPair)
+	require.Equal(t, metav1.ConditionFalse, reachable.Status, "Remote shouldn't be reachable with an expired token")
+	require.Equal(t, "Unauthorized", reachable.Reason, "Unexpected reason for expired token")
+	require.Equal(t, metav1.ConditionUnknown, permissions.Status, "Permissions can't be checked when not reachable")
+
+	clusterPair.Spec.Config = newRemoteConfig("https://127.0.0.1:1", &clientcmdapi.AuthInfo{Token: "sa-token"})
+	reachable, _ = c.probeRemote(clusterPair)
+	require.Equal(t, metav1.ConditionFalse, reachable.Status, "Remote shouldn't be reachable")
+	require.Equal(t, "Unreachable", reachable.Reason, "Unexpected reason for unreachable remote")
+
+	clusterPair.Spec.Config = newRemoteConfig(remote.URL, &clientcmdapi.AuthInfo{Token: "sa-token"})
+	clusterPair.Spec.Config.CurrentContext = "missing"
+	reachable, _ = c.probeRemote(clusterPair)
+	require.Equal(t, metav1.ConditionFalse, reachable.Status, "Remote shouldn't be reachable with invalid config")
+	require.Equal(t, "InvalidConfig", reachable.Reason, "Unexpected reason for invalid config")
+}
+
+func TestClusterPairProbeExecPlugin(t *testing.T) {
+	remote := newFakeRemoteCluster(t, "exec-token")
+	c, _, _ := newTestClusterPairController(t)
+
+	plugin := filepath.Join(t.TempDir(), "get-token")
+	err := ioutil.WriteFile(plugin, []byte(`#!/bin/sh
+echo '{"apiVersion":"client.authentication.k8s.io/v1beta1","kind":"ExecCredential","status":{"token":"exec-token"}}'
+`), 0755)
+	require.NoError(t, err, "Error writing exec plugin")
+
+	clusterPair := &stork_api.ClusterPair{
+		ObjectMeta: metav1.ObjectMeta{Name: "pair1", Namespace: "admin"},
+		Spec: stork_api.ClusterPairSpec{
+			Config: newRemoteConfig(remote.URL, &clientcmdapi.AuthInfo{
+				Exec: &clientcmdapi.ExecConfig{
+					Command:    plugin,
+					APIVersion: "client.authentication.k8s.io/v1beta1",
+				},
+			}),
+		},
+	}
+	reachable, permissions := c.probeRemote(clusterPair)
+	require.Equal(t, metav1.ConditionTrue, reachable.Status, "Remote should be reachable with token from exec plugin: %v", reachable.Message)
+	require.Equal(t, metav1.ConditionTrue, permissions.Status, "Permissions should be allowed: %v", permissions.Message)
+
+	clusterPair.Spec.Config.AuthInfos["remote"].Exec.Command = filepath.Join(t.TempDir(), "missing-plugin")
+	reachable, permissions = c.probeRemote(clusterPair)
+	require.Equal(t, metav1.ConditionFalse, reachable.Status, "Remote shouldn't be reachable without the exec plugin")
+	require.Equal(t, "ExecPluginError", reachable.Reason, "Unexpected reason for missing exec plugin")
+	require.Contains(t, reachable.Message, "missing-plugin", "Message should have the exec plugin command")
+	require.Equal(t, metav1.ConditionUnknown, permissions.Status, "Permissions can't be checked when not reachable")
+}
+
+func TestClusterPairRefreshCredentials(t *testing.T) {
+	newSecret := func(server, resourceVersion string) *v1.Secret {
+		kubeconfig := `apiVersion: v1
+kind: Config
+current-context: remote
+clusters:
+- name: remote
+  cluster:
+    server: ` + server + `
+    insecure-skip-tls-verify: true
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: remote
+users:
+- name: remote
+  user:
+    token: sa-token
+`
+		return &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "admin", ResourceVersion: resourceVersion},
+			Data:       map[string][]byte{"kubeconfig": []byte(kubeconfig)},
+		}
+	}
+	c, kubeClient, _ := newTestClusterPairController(t, newSecret("https://remote-1:6443", "1"))
+
+	clusterPair := &stork_api.ClusterPair{
+		ObjectMeta: metav1.ObjectMeta{Name: "pair1", Namespace: "admin"},
+		Spec: stork_api.ClusterPairSpec{
+			CredentialsSecret: &stork_api.ClusterPairCredentialsSecret{Name: "credentials"},
+		},
+		Status: stork_api.ClusterPairStatus{
+			SchedulerStatus: stork_api.ClusterPairStatusReady,
+		},
+	}
+	require.NoError(t, c.client.Create(context.TODO(), clusterPair), "Error creating clusterpair")
+
+	refreshed, err := c.refreshCredentials(clusterPair)
+	require.NoError(t, err, "Error refreshing credentials")
+	require.True(t, refreshed, "Credentials should be refreshed from the secret")
+	require.Equal(t, "https://remote-1:6443", clusterPair.Spec.Config.Clusters["remote"].Server, "Config should be loaded from the secret")
+	require.Equal(t, "1", clusterPair.Annotations[ClusterPairCredentialsVersionAnnotation], "Secret version should be recorded")
+	require.Equal(t, stork_api.ClusterPairStatusPending, clusterPair.Status.SchedulerStatus, "Scheduler should be paired again")
+
+	refreshed, err = c.refreshCredentials(clusterPair)
+	require.NoError(t, err, "Error refreshing credentials")
+	require.False(t, refreshed, "Credentials shouldn't be refreshed when the secret didn't change")
+
+	_, err = kubeClient.CoreV1().Secrets("admin").Update(context.TODO(), newSecret("https://remote-2:6443", "2"), metav1.UpdateOptions{})
+	require.NoError(t, err, "Error updating secret")
+	refreshed, err = c.refreshCredentials(clusterPair)
+	require.NoError(t, err, "Error refreshing credentials")
+	require.True(t, refreshed, "Credentials should be refreshed when the secret changed")
+	require.Equal(t, "https://remote-2:6443", clusterPair.Spec.Config.Clusters["remote"].Server, "Config should be reloaded from the secret")
+
+	clusterPair.Spec.CredentialsSecret.Key = "missing"
+	clusterPair.Annotations[ClusterPairCredentialsVersionAnnotation] = ""
+	_, err = c.refreshCredentials(clusterPair)
+	require.Error(t, err, "Expected error for missing key")
+	require.Contains(t, err.Error(), "key missing not found", "Unexpected error for missing key")
+
+	clusterPair.Spec.CredentialsSecret = &stork_api.ClusterPairCredentialsSecret{Name: "missing"}
+	_, err = c.refreshCredentials(clusterPair)
+	require.Error(t, err, "Expected error for missing secret")
+
+	clusterPair.Spec.CredentialsSecret = nil
+	refreshed, err = c.refreshCredentials(clusterPair)
+	require.NoError(t, err, "Error refreshing credentials without secret")
+	require.False(t, refreshed, "Credentials shouldn't be refreshed without secret")
+}
+
+func TestClusterPairConditionsRefreshedWhenNotReady(t *testing.T) {
+	remote := newFakeRemoteCluster(t, "sa-token")
+	c, _, _ := newTestClusterPairController(t)
+
+	clusterPair := &stork_api.ClusterPair{
+		ObjectMeta: metav1.ObjectMeta{Name: "pair1", Namespace: "admin"},
+		Spec: stork_api.ClusterPairSpec{
+			Config: newRemoteConfig(remote.URL, &clientcmdapi.AuthInfo{Token: "expired-token"}),
+		},
+		Status: stork_api.ClusterPairStatus{
+			SchedulerStatus: stork_api.ClusterPairStatusError,
+		},
+	}
+	require.NoError(t, c.client.Create(context.TODO(), clusterPair), "Error creating clusterpair")
+
+	require.NoError(t, c.handle(context.TODO(), clusterPair), "Error handling clusterpair")
+	require.Equal(t, stork_api.ClusterPairStatusError, clusterPair.Status.SchedulerStatus, "Scheduler shouldn't be paired")
+	reachable := getClusterPairCondition(clusterPair, stork_api.ClusterPairConditionRemoteReachable)
+	require.NotNil(t, reachable, "Reachable condition should be set for pair that isn't ready")
+	require.Equal(t, metav1.ConditionFalse, reachable.Status, "Remote shouldn't be reachable")
+	require.Equal(t, "Unauthorized", reachable.Reason, "Unexpected reason for expired token")
+	require.False(t, clusterPair.Status.LastProbeTimestamp.IsZero(), "Probe timestamp should be set")
+
+	clusterPair.Spec.Config = newRemoteConfig(remote.URL, &clientcmdapi.AuthInfo{Token: "sa-token"})
+	require.NoError(t, c.handle(context.TODO(), clusterPair), "Error handling clusterpair")
+	require.Equal(t, stork_api.ClusterPairStatusReady, clusterPair.Status.SchedulerStatus, "Scheduler should be paired")
+	reachable = getClusterPairCondition(clusterPair, stork_api.ClusterPairConditionRemoteReachable)
+	require.Equal(t, metav1.ConditionTrue, reachable.Status, "Remote should be reachable")
+	permissions := getClusterPairCondition(clusterPair, stork_api.ClusterPairConditionRemotePermissions)
+	require.NotNil(t, permissions, "Permissions condition should be set")
+	require.Equal(t, metav1.ConditionTrue, permissions.Status, "Permissions should be allowed")
+}
@@ -437,8 +437,9 @@ func (m *MigrationController) handle(ctx context.Context, migration *stork_api.M
 			}
 		}
 		// Make sure the rules exist if configured
+		var preExecRule, postExecRule *stork_api.Rule
 		if migration.Spec.PreExecRule != "" {
-			_, err := storkops.Instance().GetRule(migration.Spec.PreExecRule, migration.Namespace)
+			preExecRule, err = storkops.Instance().GetRule(migration.Spec.PreExecRule, migration.Namespace)
 			if err != nil {
 				message := fmt.Sprintf("Error getting PreExecRule %v: %v", migration.Spec.PreExecRule, err)
 				log.MigrationLog(migration).Errorf(message)
@@ -450,7 +451,7 @@ func (m *MigrationController) handle(ctx context.Context, migration *stork_api.M
 			}
 		}
 		if migration.Spec.PostExecRule != "" {
-			_, err := storkops.Instance().GetRule(migration.Spec.PostExecRule, migration.Namespace)
+			postExecRule, err = storkops.Instance().GetRule(migration.Spec.PostExecRule, migration.Namespace)
 			if err != nil {
 				message := fmt.Sprintf("Error getting PostExecRule %v: %v", migration.Spec.PreExecRule, err)
 				log.MigrationLog(migration).Errorf(message)
@@ -461,6 +462,15 @@ func (m *MigrationController) handle(ctx context.Context, migration *stork_api.M
 				return nil
 			}
 		}
+		if err := rule.ValidateRules(preExecRule, postExecRule); err != nil {
+			message := fmt.Sprintf("Error validating rules: %v", err)
+			log.MigrationLog(migration).Errorf(message)
+			m.recorder.Event(migration,
+				v1.EventTypeWarning,
+				string(stork_api.MigrationStatusFailed),
+				message)
+			return nil
+		}
 		fallthrough
 	case stork_api.MigrationStagePreExecRule:
 		terminationChannels, err = m.runPreExecRule(migration)
@@ -599,6 +609,7 @@ func (m *MigrationController) purgeMigratedResources(
 			Name:      nm,
 			Namespace: ns,
 			Status:    stork_api.MigrationStatusPurged,
+			Action:    stork_api.MigrationResourceActionPurged,
 		}
 		resourceInfo.Kind = kind
 		migration.Status.Resources = append(migration.Status.Resources, resourceInfo)
@@ -872,7 +883,9 @@ func (m *MigrationController) runPreExecRule(migration *stork_api.Migration) ([]
 			return nil, err
 		}
 
-		ch, err := rule.ExecuteRule(r, rule.PreExecRule, migration, ns)
+		ch, result, err := rule.ExecuteRule(r, rule.PreExecRule, migration, ns)
+		migration.Status.RuleActionFailures = append(migration.Status.RuleActionFailures, result.Failures...)
+		migration.Status.RuleRuns = rule.AppendRuleRuns(migration.Status.RuleRuns, result)
 		if err != nil {
 			for _, channel := range terminationChannels {
 				channel <- true
@@ -893,7 +906,9 @@ func (m *MigrationController) runPostExecRule(migration *stork_api.Migration) er
 			return err
 		}
 
-		_, err = rule.ExecuteRule(r, rule.PostExecRule, migration, ns)
+		_, result, err := rule.ExecuteRule(r, rule.PostExecRule, migration, ns)
+		migration.Status.RuleActionFailures = append(migration.Status.RuleActionFailures, result.Failures...)
+		migration.Status.RuleRuns = rule.AppendRuleRuns(migration.Status.RuleRuns, result)
 		if err != nil {
 			return fmt.Errorf("error executing PreExecRule for namespace %v: %v", ns, err)
 		}
@@ -1103,7 +1118,7 @@ func (m *MigrationController) prepareResources(
 			if ns, found := resPatch[metadata.GetNamespace()]; found {
 				// if transformspec present for current resource kind
 				if kind, ok := ns[resource.Kind]; ok {
-					err := resourcecollector.TransformResources(o, kind, metadata.GetName(), metadata.GetNamespace())
+					err := resourcecollector.TransformResources(o, kind, metadata.GetName(), metadata.GetNamespace(), clusterPair)
 					if err != nil {
 						return fmt.Errorf("error updating %v resource %v: %v", o.GetObjectKind().GroupVersionKind().Kind, metadata.GetName(), err)
 					}
@@ -1690,12 +1705,15 @@ func (m *MigrationController) applyResources(
 		pv.Annotations[StorkMigrationTime] = time.Now().Format(nameTimeSuffixFormat)
 		pv.Annotations = m.getParsedAnnotations(pv.Annotations, clusterPair)
 		pv.Labels = m.getParsedLabels(pv.Labels, clusterPair)
+		resourceAction := stork_api.MigrationResourceActionCreated
+		resourceDiff := ""
 		_, err = adminClient.CoreV1().PersistentVolumes().Create(context.TODO(), &pv, metav1.CreateOptions{})
 		if err != nil {
 			if err != nil && errors.IsAlreadyExists(err) {
 				var respPV *v1.PersistentVolume
 				respPV, err = adminClient.CoreV1().PersistentVolumes().Get(context.TODO(), pv.Name, metav1.GetOptions{})
 				if err == nil {
+					resourceAction, resourceDiff = getResourceAction(pv.Kind, getPVUpdateFields(respPV), getPVUpdateFields(&pv), "", 0)
 					// allow only annotation and reclaim policy update
 					// TODO: idle way should be to use Patch
 					if respPV.GetAnnotations() == nil {
@@ -1725,6 +1743,7 @@ func (m *MigrationController) applyResources(
 			obj,
 			stork_api.MigrationStatusSuccessful,
 			"Resource migrated successfully")
+		m.updateResourceChange(migration, obj, resourceAction, resourceDiff)
 	}
 	// apply pvc objects
 	for _, obj := range pvcObjects {
@@ -1764,12 +1783,26 @@ func (m *MigrationController) applyResources(
 						obj,
 						stork_api.MigrationStatusSuccessful,
 						"Resource migrated successfully")
+					m.updateResourceChange(migration, obj, stork_api.MigrationResourceActionUnchanged, "")
 					objRef.UID = resp.GetUID()
 					pvMapping[pvc.Spec.VolumeName] = objRef
 					continue
 				}
 			}
 		}
+		resourceAction := stork_api.MigrationResourceActionCreated
+		resourceDiff := ""
+		if err == nil && resp != nil {
+			existing, convErr := runtime.DefaultUnstructuredConverter.ToUnstructured(resp)
+			if convErr == nil {
+				resourceAction, resourceDiff = getResourceAction(
+					pvc.Kind,
+					existing,
+					obj.UnstructuredContent(),
+					resp.Annotations[resourcecollector.StorkResourceHash],
+					objHash)
+			}
+		}
 		pvMapping[pvc.Spec.VolumeName] = objRef
 		deleteStart := metav1.Now()
 		isDeleted := false
@@ -1832,6 +1865,7 @@ func (m *MigrationController) applyResources(
 			obj,
 			stork_api.MigrationStatusSuccessful,
 			"Resource migrated successfully")
+		m.updateResourceChange(migration, obj, resourceAction, resourceDiff)
 	}
 	// revert pv objects reclaim policy
 	for _, obj := range pvObjects {
@@ -2005,10 +2039,20 @@ func (m *MigrationController) applyResources(
 			}
 
 			retries := 0
+			resourceAction := stork_api.MigrationResourceActionCreated
+			resourceDiff := ""
 			log.MigrationLog(migration).Infof("Applying %v %v", objectType.GetKind(), metadata.GetName())
 			for {
 				_, err = dynamicClient.Create(context.TODO(), unstructured, metav1.CreateOptions{})
 				if err != nil && (errors.IsAlreadyExists(err) || strings.Contains(err.Error(), portallocator.ErrAllocated.Error())) {
+					if existing, getErr := dynamicClient.Get(context.TODO(), metadata.GetName(), metav1.GetOptions{}); getErr == nil {
+						resourceAction, resourceDiff = getResourceAction(
+							objectType.GetKind(),
+							existing.Object,
+							unstructured.Object,
+							existing.GetAnnotations()[resourcecollector.StorkResourceHash],
+							objHash)
+					}
 					switch objectType.GetKind() {
 					case "ServiceAccount":
 						err = m.checkAndUpdateDefaultSA(migration, o)
@@ -2066,6 +2110,7 @@ func (m *MigrationController) applyResources(
 					o,
 					stork_api.MigrationStatusSuccessful,
 					"Resource migrated successfully")
+				m.updateResourceChange(migration, o, resourceAction, resourceDiff)
 			}
 			errorChan <- nil
 		}
@@ -2147,6 +2192,16 @@ func (m *MigrationController) getMigrationSummary(migration *stork_api.Migration
 			if resource.Status == stork_api.MigrationStatusSuccessful {
 				doneResources++
 			}
+			switch resource.Action {
+			case stork_api.MigrationResourceActionCreated:
+				migrationSummary.NumberOfCreatedResources++
+			case stork_api.MigrationResourceActionUpdated:
+				migrationSummary.NumberOfUpdatedResources++
+			case stork_api.MigrationResourceActionUnchanged:
+				migrationSummary.NumberOfUnchangedResources++
+			case stork_api.MigrationResourceActionPurged:
+				migrationSummary.NumberOfPurgedResources++
+			}
 		}
 		if totalResources > 0 {
 			migrationSummary.TotalNumberOfResources = totalResources
@@ -82,6 +82,7 @@ func (m *MigrationScheduleController) Reconcile(ctx context.Context, request rec
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			schedule.DeleteFromQueue(schedule.GetQueueKey(stork_api.MigrationScheduleResourceName, request.Namespace, request.Name))
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -102,8 +103,10 @@ func (m *MigrationScheduleController) Reconcile(ctx context.Context, request rec
 }
 
 func (m *MigrationScheduleController) handle(ctx context.Context, migrationSchedule *stork_api.MigrationSchedule) error {
+	queueKey := schedule.GetQueueKey(stork_api.MigrationScheduleResourceName, migrationSchedule.Namespace, migrationSchedule.Name)
 	// Delete any migrations created by the schedule
 	if migrationSchedule.DeletionTimestamp != nil {
+		schedule.DeleteFromQueue(queueKey)
 		if controllers.ContainsFinalizer(migrationSchedule, controllers.FinalizerCleanup) {
 			if err := m.deleteMigrations(migrationSchedule); err != nil {
 				logrus.Errorf("%s: cleanup: %s", reflect.TypeOf(m), err)
@@ -219,8 +222,11 @@ func (m *MigrationScheduleController) handle(ctx context.Context, migrationSched
 		log.MigrationScheduleLog(migrationSchedule).Error(msg)
 		return err
 	}
+	schedule.UpdateRunning(queueKey, m.getRunningMigrations(migrationSchedule))
 
 	// Then check if any of the policies require a trigger if it is enabled
+	start := false
+	policyType := stork_api.SchedulePolicyTypeInvalid
 	if migrationSchedule.Spec.Suspend == nil || !*migrationSchedule.Spec.Suspend {
 		var err error
 		var clusterDomains *stork_api.ClusterDomains
@@ -249,7 +255,7 @@ func (m *MigrationScheduleController) handle(ctx context.Context, migrationSched
 			}
 		}
 
-		policyType, start, err := m.shouldStartMigration(migrationSchedule)
+		policyType, start, err = m.shouldStartMigration(migrationSchedule)
 		if err != nil {
 			msg := fmt.Sprintf("Error checking if migration should be triggered: %v", err)
 			m.recorder.Event(migrationSchedule,
@@ -259,9 +265,41 @@ func (m *MigrationScheduleController) handle(ctx context.Context, migrationSched
 			log.MigrationScheduleLog(migrationSchedule).Error(msg)
 			return nil
 		}
-
-		// Start a migration for a policy if required
-		if start {
+	}
+	if !start {
+		schedule.RemoveFromQueue(queueKey)
+		if migrationSchedule.Status.Pending != nil {
+			migrationSchedule.Status.Pending = nil
+			if err := m.client.Update(context.TODO(), migrationSchedule); err != nil {
+				return err
+			}
+		}
+	} else {
+		// Blackout windows and the limit on concurrent scheduled operations
+		// can defer the trigger
+		pending, err := schedule.AdmitTrigger(
+			migrationSchedule.Spec.SchedulePolicyName,
+			migrationSchedule.Namespace,
+			policyType,
+			queueKey,
+			migrationSchedule.Status.Pending,
+		)
+		if err != nil {
+			msg := fmt.Sprintf("Error checking if migration can be triggered: %v", err)
+			m.recorder.Event(migrationSchedule,
+				v1.EventTypeWarning,
+				string(stork_api.MigrationStatusFailed),
+				msg)
+			log.MigrationScheduleLog(migrationSchedule).Error(msg)
+			return nil
+		}
+		if pending != nil {
+			if err := m.updatePendingStatus(migrationSchedule, pending); err != nil {
+				return err
+			}
+		} else {
+			// Start a migration for the policy
+			migrationSchedule.Status.Pending = nil
 			err := m.startMigration(migrationSchedule, policyType)
 			if err != nil {
 				msg := fmt.Sprintf("Error triggering migration for schedule(%v): %v", policyType, err)
@@ -273,7 +311,6 @@ func (m *MigrationScheduleController) handle(ctx context.Context, migrationSched
 				return err
 			}
 		}
-
 	}
 
 	// Finally, prune any old migrations that were triggered for this
@@ -358,6 +395,40 @@ func (m *MigrationScheduleController) isMigrationComplete(status stork_api.Migra
 	return true
 }
 
+func (m *MigrationScheduleController) getRunningMigrations(migrationSchedule *stork_api.MigrationSchedule) int {
+	running := 0
+	for _, policyMigration := range migrationSchedule.Status.Items {
+		for _, migration := range policyMigration {
+			if !m.isMigrationComplete(migration.Status) {
+				running++
+			}
+		}
+	}
+	return running
+}
+
+// updatePendingStatus records why a migration that is due hasn't been
+// started. The schedule is only updated if the reason or message changed
+func (m *MigrationScheduleController) updatePendingStatus(
+	migrationSchedule *stork_api.MigrationSchedule,
+	pending *stork_api.SchedulePendingStatus,
+) error {
+	current := migrationSchedule.Status.Pending
+	if current != nil && current.Reason == pending.Reason && current.Message == pending.Message {
+		return nil
+	}
+	if current == nil || current.Reason != pending.Reason {
+		msg := fmt.Sprintf("Migration for schedule(%v) is pending: %v", pending.PolicyType, pending.Message)
+		m.recorder.Event(migrationSchedule,
+			v1.EventTypeNormal,
+			string(pending.Reason),
+			msg)
+		log.MigrationScheduleLog(migrationSchedule).Info(msg)
+	}
+	migrationSchedule.Status.Pending = pending
+	return m.client.Update(context.TODO(), migrationSchedule)
+}
+
 // Returns if a migration should be triggered given the status and times of the
 // previous migrations. If a migration should be triggered it also returns the
 // type of polivy that should trigger it.
@@ -376,6 +447,11 @@ func (m *MigrationScheduleController) shouldStartMigration(
 		}
 	}
 
+	// A trigger that was deferred is still due
+	if migrationSchedule.Status.Pending != nil {
+		return migrationSchedule.Status.Pending.PolicyType, true, nil
+	}
+
 	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
 		var latestMigrationTimestamp meta.Time
 		policyMigration, present := migrationSchedule.Status.Items[policyType]
@@ -462,6 +538,13 @@ func (m *MigrationScheduleController) startMigration(
 }
 
 func (m *MigrationScheduleController) pruneMigrations(migrationSchedule *stork_api.MigrationSchedule) error {
+	retention, location, err := schedule.GetGFSRetention(migrationSchedule.Spec.SchedulePolicyName, migrationSchedule.Namespace)
+	if err != nil {
+		return err
+	}
+	if retention != nil {
+		return m.pruneMigrationsGFS(migrationSchedule, retention, location)
+	}
 	updated := false
 	for policyType, policyMigration := range migrationSchedule.Status.Items {
 		// Keep only one successful migration status and all failed migrations
@@ -496,6 +579,39 @@ func (m *MigrationScheduleController) pruneMigrations(migrationSchedule *stork_a
 
 }
 
+// pruneMigrationsGFS deletes the migrations, across all policy types, that
+// aren't retained by the GFS retention of the policy
+func (m *MigrationScheduleController) pruneMigrationsGFS(
+	migrationSchedule *stork_api.MigrationSchedule,
+	retention *stork_api.GFSRetention,
+	location *time.Location,
+) error {
+	deleted, err := schedule.PruneGFSRetention(
+		retention,
+		location,
+		schedule.GetMigrationRetentionItems(migrationSchedule.Status.Items),
+		func(name string) error {
+			return storkops.Instance().DeleteMigration(name, migrationSchedule.Namespace)
+		},
+	)
+	if err != nil {
+		log.MigrationScheduleLog(migrationSchedule).Warnf("Error pruning migrations: %v", err)
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+	for policyType, policyMigration := range migrationSchedule.Status.Items {
+		retained := make([]*stork_api.ScheduledMigrationStatus, 0, len(policyMigration))
+		for _, migration := range policyMigration {
+			if !deleted[migration.Name] {
+				retained = append(retained, migration)
+			}
+		}
+		migrationSchedule.Status.Items[policyType] = retained
+	}
+	return m.client.Update(context.TODO(), migrationSchedule)
+}
+
 func (m *MigrationScheduleController) deleteMigrations(migrationSchedule *stork_api.MigrationSchedule) error {
 	var lastError error
 	for _, policyMigration := range migrationSchedule.Status.Items {
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/resourcecollector"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// Max number of changed fields listed in the diff of a resource
+	maxDiffFields = 10
+	// Max length of the values printed in the diff of a resource
+	maxDiffValueLength = 32
+)
+
+var (
+	// Fields set by the cluster or by every migration that shouldn't show
+	// up in the diff
+	ignoredDiffFields = map[string]bool{
+		"status":                                     true,
+		"metadata.resourceVersion":                   true,
+		"metadata.uid":                               true,
+		"metadata.creationTimestamp":                 true,
+		"metadata.generation":                        true,
+		"metadata.managedFields":                     true,
+		"metadata.selfLink":                          true,
+		"metadata.annotations." + StorkMigrationName: true,
+		"metadata.annotations." + StorkMigrationTime: true,
+		"metadata.annotations." + resourcecollector.StorkResourceHash: true,
+	}
+	// Kinds for which the values of the changed fields aren't recorded
+	sensitiveDiffKinds = map[string]bool{
+		"Secret": true,
+	}
+)
+
+// getResourceDiff returns a short summary of the fields of a resource that
+// will be changed on the destination cluster. Only the fields set in the
+// updated resource are compared, fields that are only set on the
+// destination, like the ones assigned by the cluster, are ignored
+func getResourceDiff(kind string, existing, updated map[string]interface{}) string {
+	changes := make([]string, 0)
+	diffFields("", existing, updated, !sensitiveDiffKinds[kind], &changes)
+	if len(changes) > maxDiffFields {
+		return fmt.Sprintf("%v and %v more", strings.Join(changes[:maxDiffFields], ", "), len(changes)-maxDiffFields)
+	}
+	return strings.Join(changes, ", ")
+}
+
+func diffFields(path string, existing, updated interface{}, showValues bool, changes *[]string) {
+	switch u := updated.(type) {
+	case map[string]interface{}:
+		e, ok := existing.(map[string]interface{})
+		if !ok {
+			*changes = append(*changes, fmt.Sprintf("%v: changed", path))
+			return
+		}
+		keys := make([]string, 0, len(u))
+		for k := range u {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			if ignoredDiffFields[child] {
+				continue
+			}
+			ev, found := e[k]
+			if !found {
+				*changes = append(*changes, fmt.Sprintf("%v: added", child))
+				continue
+			}
+			diffFields(child, ev, u[k], showValues, changes)
+		}
+	case []interface{}:
+		e, ok := existing.([]interface{})
+		if !ok || len(e) != len(u) {
+			*changes = append(*changes, fmt.Sprintf("%v: %v -> %v items", path, len(e), len(u)))
+			return
+		}
+		for i := range u {
+			diffFields(fmt.Sprintf("%v[%v]", path, i), e[i], u[i], showValues, changes)
+		}
+	default:
+		if reflect.DeepEqual(existing, updated) {
+			return
+		}
+		if !showValues {
+			*changes = append(*changes, fmt.Sprintf("%v: changed", path))
+			return
+		}
+		*changes = append(*changes, fmt.Sprintf("%v: %v -> %v", path, formatDiffValue(existing), formatDiffValue(updated)))
+	}
+}
+
+func formatDiffValue(value interface{}) string {
+	formatted := fmt.Sprintf("%v", value)
+	if len(formatted) > maxDiffValueLength {
+		return formatted[:maxDiffValueLength] + "..."
+	}
+	return formatted
+}
+
+// updateResourceChange records the change made to a resource on the
+// destination cluster
+func (m *MigrationController) updateResourceChange(
+	migration *stork_api.Migration,
+	object runtime.Unstructured,
+	action stork_api.MigrationResourceActionType,
+	diff string,
+) {
+	metadata, err := meta.Accessor(object)
+	if err != nil {
+		return
+	}
+	gkv := object.GetObjectKind().GroupVersionKind()
+	for _, resource := range migration.Status.Resources {
+		if resource.Name == metadata.GetName() &&
+			resource.Namespace == metadata.GetNamespace() &&
+			(resource.Group == gkv.Group || (resource.Group == "core" && gkv.Group == "")) &&
+			resource.Version == gkv.Version &&
+			resource.Kind == gkv.Kind {
+			resource.Action = action
+			resource.Diff = diff
+			return
+		}
+	}
+}
+
+// getResourceAction returns whether a resource that already exists on the
+// destination cluster was updated or unchanged, and the diff if it was
+// updated
+func getResourceAction(
+	kind string,
+	existing, updated map[string]interface{},
+	existingHash string,
+	hash uint64,
+) (stork_api.MigrationResourceActionType, string) {
+	if existingHash != "" && existingHash == fmt.Sprintf("%v", hash) {
+		return stork_api.MigrationResourceActionUnchanged, ""
+	}
+	diff := getResourceDiff(kind, existing, updated)
+	if diff == "" {
+		return stork_api.MigrationResourceActionUnchanged, ""
+	}
+	return stork_api.MigrationResourceActionUpdated, diff
+}
+
+// getPVUpdateFields returns the fields of a PV that are updated if it already
+// exists on the destination cluster
+func getPVUpdateFields(pv *v1.PersistentVolume) map[string]interface{} {
+	annotations := make(map[string]interface{})
+	for k, v := range pv.Annotations {
+		annotations[k] = v
+	}
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+		"spec": map[string]interface{}{
+			"persistentVolumeReclaimPolicy": string(pv.Spec.PersistentVolumeReclaimPolicy),
+		},
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/go-openapi/inflect"
@@ -23,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/record"
@@ -183,9 +185,24 @@ func (r *ResourceTransformationController) validateSpecPath(transform *stork_api
 				return fmt.Errorf("json patch for resources is not supported, operation: %s", path.Operation)
 			}
 			if !(path.Operation == stork_api.AddResourcePath || path.Operation == stork_api.DeleteResourcePath ||
-				path.Operation == stork_api.ModifyResourcePathValue) {
+				path.Operation == stork_api.ModifyResourcePathValue || path.Operation == stork_api.RegexReplaceResourcePath) {
 				return fmt.Errorf("unsupported resource patch operation given for kind :%s, operation: %s", kind, path.Operation)
 			}
+			if path.Template {
+				if _, err := resourcecollector.ParseTransformTemplate(path.Value); err != nil {
+					return fmt.Errorf("invalid template for resource %s, path %s: %v", kind, path.Path, err)
+				}
+			}
+			if path.Operation == stork_api.RegexReplaceResourcePath {
+				if path.Pattern == "" {
+					return fmt.Errorf("pattern needs to be provided for resource %s, path %s, operation: %s", kind, path.Path, path.Operation)
+				}
+				if _, err := regexp.Compile(path.Pattern); err != nil {
+					return fmt.Errorf("invalid pattern for resource %s, path %s: %v", kind, path.Path, err)
+				}
+				// The type of the values is detected when replacing them
+				continue
+			}
 			if !(path.Type == stork_api.BoolResourceType || path.Type == stork_api.IntResourceType ||
 				path.Type == stork_api.StringResourceType || path.Type == stork_api.SliceResourceType ||
 				path.Type == stork_api.KeyPairResourceType) {
@@ -204,6 +221,11 @@ func (r *ResourceTransformationController) validateTransformResource(ctx context
 		if err != nil {
 			return fmt.Errorf("invalid resource type should be in format <group>/<version>/<kind>, actual: %s", spec.Resource)
 		}
+		if spec.When != "" {
+			if _, err := resourcecollector.CompileTransformCondition(spec.When); err != nil {
+				return fmt.Errorf("invalid condition for resource %s: %v", spec.Resource, err)
+			}
+		}
 		resource := metav1.APIResource{
 			Name:       strings.ToLower(inflect.Pluralize(kind)),
 			Kind:       kind,
@@ -228,6 +250,18 @@ func (r *ResourceTransformationController) validateTransformResource(ctx context
 			log.TransformLog(transform).Errorf("Error getting resources kind:%s, err: %v", kind, err)
 			return err
 		}
+		// Only the objects matching the condition are patched during
+		// migration, so skip the others in the dry-run
+		matchedObjects := make([]runtime.Unstructured, 0, len(objects.Items))
+		for _, object := range objects.Items {
+			matched, err := resourcecollector.MatchTransformCondition(spec.When, object.UnstructuredContent())
+			if err != nil {
+				return fmt.Errorf("error evaluating condition for resource %s: %v", spec.Resource, err)
+			}
+			if matched {
+				matchedObjects = append(matchedObjects, object)
+			}
+		}
 		// TODO: we can pass in remote config and dry run on remote cluster as well
 		localconfig, err := clientcmd.BuildConfigFromFlags("", "")
 		if err != nil {
@@ -240,10 +274,10 @@ func (r *ResourceTransformationController) validateTransformResource(ctx context
 		for _, path := range spec.Paths {
 			// This can be handle by CRD validation- v1 version crd support
 			if !(path.Operation == stork_api.AddResourcePath || path.Operation == stork_api.DeleteResourcePath ||
-				path.Operation == stork_api.ModifyResourcePathValue) {
+				path.Operation == stork_api.ModifyResourcePathValue || path.Operation == stork_api.RegexReplaceResourcePath) {
 				return fmt.Errorf("unsupported operation type for given path : %s", path.Operation)
 			}
-			for _, object := range objects.Items {
+			for _, object := range matchedObjects {
 				metadata, err := meta.Accessor(object)
 				if err != nil {
 					log.TransformLog(transform).Errorf("Unable to read metadata for resource %v, err: %v", kind, err)
@@ -255,8 +289,8 @@ func (r *ResourceTransformationController) validateTransformResource(ctx context
 					GroupVersionKind: metav1.GroupVersionKind(object.GetObjectKind().GroupVersionKind()),
 					Specs:            spec,
 				}
-				if err := resourcecollector.TransformResources(object, []stork_api.TransformResourceInfo{*resInfo}, metadata.GetName(), metadata.GetNamespace()); err != nil {
-					log.TransformLog(transform).Errorf("Unable to apply patch path %s on resource kind: %s/,%s/%s,  err: %v", path, kind, resInfo.Namespace, resInfo.Name, err)
+				if err := resourcecollector.TransformResources(object, []stork_api.TransformResourceInfo{*resInfo}, metadata.GetName(), metadata.GetNamespace(), nil); err != nil {
+					log.TransformLog(transform).Errorf("Unable to apply patch path %s on resource kind: %s/,%s/%s,  err: %v", path.Path, kind, resInfo.Namespace, resInfo.Name, err)
 					resInfo.Status = stork_api.ResourceTransformationStatusFailed
 					resInfo.Reason = err.Error()
 				}
@@ -277,11 +311,11 @@ func (r *ResourceTransformationController) validateTransformResource(ctx context
 					metadata.GetName())
 				_, err = dynamicClient.Create(context.TODO(), unstructured, metav1.CreateOptions{DryRun: []string{"All"}})
 				if err != nil {
-					log.TransformLog(transform).Errorf("Unable to apply patch path %s on resource kind: %s/,%s/%s,  err: %v", path, kind, resInfo.Namespace, resInfo.Name, err)
+					log.TransformLog(transform).Errorf("Unable to apply patch path %s on resource kind: %s/,%s/%s,  err: %v", path.Path, kind, resInfo.Namespace, resInfo.Name, err)
 					resInfo.Status = stork_api.ResourceTransformationStatusFailed
 					resInfo.Reason = err.Error()
 				} else {
-					log.TransformLog(transform).Infof("Applied patch path %s on resource kind: %s/,%s/%s", path, kind, resInfo.Namespace, resInfo.Name)
+					log.TransformLog(transform).Infof("Applied patch path %s on resource kind: %s/,%s/%s", path.Path, kind, resInfo.Namespace, resInfo.Name)
 					resInfo.Status = stork_api.ResourceTransformationStatusReady
 					resInfo.Reason = ""
 				}
@@ -18,6 +18,7 @@ type Migration struct {
 	clusterPairController       *controllers.ClusterPairController
 	migrationController         *controllers.MigrationController
 	migrationScheduleController *controllers.MigrationScheduleController
+	actionController            *controllers.ActionController
 }
 
 // Init init
@@ -39,5 +40,11 @@ func (m *Migration) Init(mgr manager.Manager, migrationAdminNamespace string, mi
 	if err != nil {
 		return fmt.Errorf("error initializing migration schedule controller: %v", err)
 	}
+
+	m.actionController = controllers.NewAction(mgr, m.Recorder)
+	err = m.actionController.Init(mgr)
+	if err != nil {
+		return fmt.Errorf("error initializing action controller: %v", err)
+	}
 	return nil
 }
@@ -0,0 +1,91 @@
+package objectstore
+
+import (
+	"fmt"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+)
+
+// GetKeyring returns the keyring used for envelope encryption by the backup
+// location. Returns nil if the backup location doesn't use envelope
+// encryption
+func GetKeyring(backupLocation *stork_api.BackupLocation) (*crypto.Keyring, error) {
+	config := backupLocation.Location.EncryptionKeys
+	if config == nil {
+		return nil, nil
+	}
+	provider, err := crypto.GetKeyProvider(config.Provider, backupLocation.Namespace, config.Options)
+	if err != nil {
+		return nil, fmt.Errorf("error getting key provider for backuplocation %v: %v", backupLocation.Name, err)
+	}
+	return &crypto.Keyring{
+		Provider:       provider,
+		CurrentKeyID:   config.CurrentKeyID,
+		PreviousKeyIDs: config.PreviousKeyIDs,
+	}, nil
+}
+
+// GetDataKey returns the data key used to encrypt the objects for a backup.
+// The data key recorded in the backup status is used if present, otherwise a
+// new one is generated and recorded in the status. The caller is responsible
+// for updating the backup. Returns nil if the backup location doesn't use
+// envelope encryption.
+func GetDataKey(
+	backupLocation *stork_api.BackupLocation,
+	backup *stork_api.ApplicationBackup,
+) (*crypto.DataKey, error) {
+	keyring, err := GetKeyring(backupLocation)
+	if err != nil || keyring == nil {
+		return nil, err
+	}
+	if backup != nil && backup.Status.EncryptionKeyID != "" {
+		return keyring.UnwrapDataKey(backup.Status.EncryptionKeyID, backup.Status.WrappedDataKey)
+	}
+	dataKey, err := keyring.NewDataKey()
+	if err != nil {
+		return nil, err
+	}
+	if backup != nil {
+		backup.Status.EncryptionKeyID = dataKey.KeyID
+		backup.Status.WrappedDataKey = dataKey.WrappedKey
+	}
+	return dataKey, nil
+}
+
+// Encrypt encrypts data before it is uploaded to the backup location. The
+// data key is used if set, otherwise the data is encrypted with the
+// EncryptionV2Key of the backup location if one is configured.
+func Encrypt(
+	backupLocation *stork_api.BackupLocation,
+	dataKey *crypto.DataKey,
+	data []byte,
+) ([]byte, error) {
+	if backupLocation.Location.EncryptionKey != "" {
+		return nil, fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
+	}
+	if dataKey != nil {
+		return crypto.EncryptEnvelope(data, dataKey)
+	}
+	if backupLocation.Location.EncryptionV2Key != "" {
+		return crypto.Encrypt(data, backupLocation.Location.EncryptionV2Key)
+	}
+	return data, nil
+}
+
+// Decrypt decrypts data downloaded from the backup location. Envelope
+// encrypted data is decrypted with the keyring of the backup location, any
+// other data with the EncryptionV2Key if one is configured.
+func Decrypt(backupLocation *stork_api.BackupLocation, data []byte) ([]byte, error) {
+	if crypto.IsEnvelope(data) {
+		keyring, err := GetKeyring(backupLocation)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.DecryptEnvelope(data, keyring)
+	}
+	if backupLocation.Location.EncryptionV2Key != "" {
+		return crypto.Decrypt(data, backupLocation.Location.EncryptionV2Key)
+	}
+	return data, nil
+}
@@ -0,0 +1,363 @@
+package objectstore
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"gocloud.dev/blob"
+	"gocloud.dev/gcerrors"
+)
+
+// ManifestObjectName is the name of the object that lists all the objects
+// uploaded for a backup
+const ManifestObjectName = "manifest.json"
+
+// Manifest lists the objects uploaded for a backup along with their size and
+// checksum, so that the backup can be verified later
+type Manifest struct {
+	Objects []ManifestObject `json:"objects"`
+	// KeyID and WrappedKey are the data key used to sign the manifest if the
+	// backup location uses envelope encryption
+	KeyID      string `json:"keyID,omitempty"`
+	WrappedKey []byte `json:"wrappedKey,omitempty"`
+	// MAC is the HMAC-SHA256 of the objects keyed with the encryption key of
+	// the backup location. Only set if the backup location has encryption
+	// configured
+	MAC string `json:"mac,omitempty"`
+}
+
+// ManifestObject is the entry for one object in the manifest
+type ManifestObject struct {
+	// Name of the object relative to the backup path
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// VerifyStatus is the result of verifying an object in the manifest
+type VerifyStatus string
+
+const (
+	// VerifyStatusOK means the object matches the manifest
+	VerifyStatusOK VerifyStatus = "OK"
+	// VerifyStatusMissing means the object doesn't exist anymore
+	VerifyStatusMissing VerifyStatus = "Missing"
+	// VerifyStatusCorrupt means the size or checksum of the object don't
+	// match the manifest
+	VerifyStatusCorrupt VerifyStatus = "Corrupt"
+	// VerifyStatusUndecryptable means the object matches the manifest but
+	// can't be decrypted with the keys of the backup location
+	VerifyStatusUndecryptable VerifyStatus = "Undecryptable"
+)
+
+// VerifyResult is the result of verifying one object
+type VerifyResult struct {
+	Name   string
+	Status VerifyStatus
+	Reason string
+}
+
+// UploadObject uploads data to objectPath/objectName and records its size and
+// checksum in the manifest for objectPath. The checksum is computed while
+// uploading so that the object doesn't have to be downloaded again. The data
+// is expected to already be encrypted if required.
+func UploadObject(
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+	objectName string,
+	data []byte,
+) error {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	writer, err := bucket.NewWriter(ctx, filepath.Join(objectPath, objectName), nil)
+	if err != nil {
+		return err
+	}
+	checksum := newChecksumWriter(writer)
+	if _, err := checksum.Write(data); err != nil {
+		// Cancel before closing so that a partial object isn't committed
+		cancel()
+		_ = writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	_, err = UpdateManifest(bucket, backupLocation, objectPath, checksum.manifestObject(objectName))
+	return err
+}
+
+// UpdateManifest adds the objects to the manifest for the backup path,
+// replacing any existing entries with the same name, and uploads it. The
+// manifest is signed if the backup location has encryption configured.
+func UpdateManifest(
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+	objects ...ManifestObject,
+) (*Manifest, error) {
+	manifest, err := GetManifest(bucket, backupLocation, objectPath)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		manifest = &Manifest{}
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting manifest for %v: %v", objectPath, err)
+	}
+
+	updated := make(map[string]ManifestObject)
+	for _, object := range manifest.Objects {
+		updated[object.Name] = object
+	}
+	for _, object := range objects {
+		updated[object.Name] = object
+	}
+	manifest.Objects = make([]ManifestObject, 0, len(updated))
+	for _, object := range updated {
+		manifest.Objects = append(manifest.Objects, object)
+	}
+	sort.Slice(manifest.Objects, func(i, j int) bool {
+		return manifest.Objects[i].Name < manifest.Objects[j].Name
+	})
+	if err := signManifest(backupLocation, manifest); err != nil {
+		return nil, fmt.Errorf("error signing manifest for %v: %v", objectPath, err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", " ")
+	if err != nil {
+		return nil, err
+	}
+	if err := bucket.WriteAll(context.TODO(), filepath.Join(objectPath, ManifestObjectName), data, nil); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// GetManifest downloads the manifest for the backup path. If the backup
+// location has encryption configured the signature of the manifest is
+// verified too
+func GetManifest(
+	bucket *blob.Bucket,
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+) (*Manifest, error) {
+	data, err := bucket.ReadAll(context.TODO(), filepath.Join(objectPath, ManifestObjectName))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("error parsing manifest: %v", err)
+	}
+	if err := verifyManifestMAC(backupLocation, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// getManifestMACKey returns the key used to sign the manifest. The data key
+// recorded in the manifest is used if the backup location uses envelope
+// encryption, otherwise the EncryptionV2Key. Returns nil if the backup
+// location doesn't have encryption configured
+func getManifestMACKey(
+	backupLocation *stork_api.BackupLocation,
+	manifest *Manifest,
+	newKey bool,
+) ([]byte, error) {
+	keyring, err := GetKeyring(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	if keyring != nil {
+		var dataKey *crypto.DataKey
+		if newKey {
+			dataKey, err = keyring.NewDataKey()
+		} else {
+			dataKey, err = keyring.UnwrapDataKey(manifest.KeyID, manifest.WrappedKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+		manifest.KeyID = dataKey.KeyID
+		manifest.WrappedKey = dataKey.WrappedKey
+		return dataKey.Key, nil
+	}
+	if backupLocation.Location.EncryptionV2Key != "" {
+		return []byte(backupLocation.Location.EncryptionV2Key), nil
+	}
+	return nil, nil
+}
+
+func computeManifestMAC(key []byte, manifest *Manifest) (string, error) {
+	data, err := json.Marshal(manifest.Objects)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	if _, err := mac.Write(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func signManifest(backupLocation *stork_api.BackupLocation, manifest *Manifest) error {
+	manifest.KeyID = ""
+	manifest.WrappedKey = nil
+	manifest.MAC = ""
+	key, err := getManifestMACKey(backupLocation, manifest, true)
+	if err != nil || key == nil {
+		return err
+	}
+	manifest.MAC, err = computeManifestMAC(key, manifest)
+	return err
+}
+
+func verifyManifestMAC(backupLocation *stork_api.BackupLocation, manifest *Manifest) error {
+	if backupLocation.Location.EncryptionKeys == nil && backupLocation.Location.EncryptionV2Key == "" {
+		return nil
+	}
+	if manifest.MAC == "" {
+		return fmt.Errorf("manifest is not signed but the backup location has encryption configured")
+	}
+	key, err := getManifestMACKey(backupLocation, manifest, false)
+	if err != nil {
+		return fmt.Errorf("error getting key to verify manifest: %v", err)
+	}
+	expected, err := computeManifestMAC(key, manifest)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(expected), []byte(manifest.MAC)) {
+		return fmt.Errorf("manifest signature doesn't match, it may have been tampered with")
+	}
+	return nil
+}
+
+// checksumWriter computes the size and checksum of the data as it is written
+// to the bucket
+type checksumWriter struct {
+	w    io.Writer
+	hash hash.Hash
+	size int64
+}
+
+func newChecksumWriter(w io.Writer) *checksumWriter {
+	return &checksumWriter{
+		w:    w,
+		hash: sha256.New(),
+	}
+}
+
+func (c *checksumWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.size += int64(n)
+	_, _ = c.hash.Write(p[:n])
+	return n, err
+}
+
+func (c *checksumWriter) manifestObject(name string) ManifestObject {
+	return ManifestObject{
+		Name:   name,
+		Size:   c.size,
+		SHA256: hex.EncodeToString(c.hash.Sum(nil)),
+	}
+}
+
+// VerifyManifest downloads and hashes every object in the manifest for the
+// backup path. If checkDecrypt is set the objects are also decrypted with the
+// keys of the backup location
+func VerifyManifest(
+	backupLocation *stork_api.BackupLocation,
+	objectPath string,
+	checkDecrypt bool,
+) ([]VerifyResult, error) {
+	bucket, err := GetBucket(backupLocation)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := GetManifest(bucket, backupLocation, objectPath)
+	if err != nil {
+		return nil, fmt.Errorf("error getting manifest for %v: %v", objectPath, err)
+	}
+
+	results := make([]VerifyResult, 0, len(manifest.Objects))
+	for _, object := range manifest.Objects {
+		result := VerifyResult{
+			Name:   object.Name,
+			Status: VerifyStatusOK,
+		}
+		key := filepath.Join(objectPath, object.Name)
+		size, checksum, err := hashObject(bucket, key)
+		switch {
+		case gcerrors.Code(err) == gcerrors.NotFound:
+			result.Status = VerifyStatusMissing
+			result.Reason = "object not found"
+		case err != nil:
+			return nil, fmt.Errorf("error computing checksum for %v: %v", key, err)
+		case size != object.Size:
+			result.Status = VerifyStatusCorrupt
+			result.Reason = fmt.Sprintf("size %v doesn't match manifest size %v", size, object.Size)
+		case checksum != object.SHA256:
+			result.Status = VerifyStatusCorrupt
+			result.Reason = "checksum doesn't match manifest"
+		case checkDecrypt:
+			if err := checkObjectDecrypt(bucket, backupLocation, key); err != nil {
+				result.Status = VerifyStatusUndecryptable
+				result.Reason = err.Error()
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func hashObject(bucket *blob.Bucket, key string) (int64, string, error) {
+	reader, err := bucket.NewReader(context.TODO(), key, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	defer reader.Close() // nolint: errcheck
+	hash := sha256.New()
+	size, err := io.Copy(hash, reader)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// checkObjectDecrypt reads the whole object through the decryption used when
+// restoring it. Unencrypted objects are only checked if the backup location
+// has an encryption key
+func checkObjectDecrypt(bucket *blob.Bucket, backupLocation *stork_api.BackupLocation, key string) error {
+	reader, err := bucket.NewReader(context.TODO(), key, nil)
+	if err != nil {
+		return err
+	}
+	defer reader.Close() // nolint: errcheck
+	r := bufio.NewReader(reader)
+	if header, _ := r.Peek(len(streamMagic)); IsStream(header) {
+		stream, err := NewStreamReader(r, backupLocation)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(ioutil.Discard, stream)
+		return err
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	_, err = Decrypt(backupLocation, data)
+	return err
+}
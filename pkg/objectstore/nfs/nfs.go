@@ -0,0 +1,377 @@
+package nfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/objectstore/common"
+	"github.com/sirupsen/logrus"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/driver"
+	"gocloud.dev/gcerrors"
+)
+
+const (
+	// Prefix used for objects that are still being written. They are renamed
+	// to the actual key on close so that readers never see partial objects
+	tempFilePrefix  = ".stork-tmp-"
+	defaultPageSize = 1000
+	contentType     = "application/octet-stream"
+)
+
+var errNotImplemented = fmt.Errorf("not implemented for nfs backup location")
+
+func getMountPath(backupLocation *stork_api.BackupLocation) string {
+	if backupLocation.Location.NFSConfig != nil && backupLocation.Location.NFSConfig.MountPath != "" {
+		return backupLocation.Location.NFSConfig.MountPath
+	}
+	return stork_api.DefaultNFSMountPath
+}
+
+func getRootPath(backupLocation *stork_api.BackupLocation) string {
+	subPath := ""
+	if backupLocation.Location.NFSConfig != nil {
+		subPath = backupLocation.Location.NFSConfig.SubPath
+	}
+	return filepath.Join(getMountPath(backupLocation), subPath, backupLocation.Location.Path)
+}
+
+func checkMounted(backupLocation *stork_api.BackupLocation) error {
+	mountPath := getMountPath(backupLocation)
+	if _, err := os.Stat(mountPath); err != nil {
+		return fmt.Errorf("nfs export for backuplocation %v/%v not mounted at %v: %v",
+			backupLocation.Namespace, backupLocation.Name, mountPath, err)
+	}
+	return nil
+}
+
+// GetBucket gets a reference to the bucket for that backup location
+func GetBucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
+	if err := checkMounted(backupLocation); err != nil {
+		return nil, err
+	}
+	return blob.NewBucket(&bucket{root: getRootPath(backupLocation)}), nil
+}
+
+// CreateBucket creates a bucket for the bucket location
+func CreateBucket(backupLocation *stork_api.BackupLocation) error {
+	if err := checkMounted(backupLocation); err != nil {
+		return err
+	}
+	return os.MkdirAll(getRootPath(backupLocation), 0750)
+}
+
+// GetObjLockInfo fetches the object lock configuration of a bucket
+func GetObjLockInfo(backupLocation *stork_api.BackupLocation) (*common.ObjLockInfo, error) {
+	logrus.Infof("object lock is not supported for nfs provider")
+	return &common.ObjLockInfo{}, nil
+}
+
+// bucket implements driver.Bucket on top of a directory. Keys are relative
+// paths under the root directory
+type bucket struct {
+	root string
+}
+
+func (b *bucket) path(key string) (string, error) {
+	path := filepath.Join(b.root, filepath.FromSlash(key))
+	if path != b.root && !strings.HasPrefix(path, b.root+string(os.PathSeparator)) {
+		return "", fmt.Errorf("invalid key %v for nfs backup location", key)
+	}
+	return path, nil
+}
+
+func (b *bucket) ErrorCode(err error) gcerrors.ErrorCode {
+	switch {
+	case os.IsNotExist(err):
+		return gcerrors.NotFound
+	case err == errNotImplemented:
+		return gcerrors.Unimplemented
+	default:
+		return gcerrors.Unknown
+	}
+}
+
+func (b *bucket) As(i interface{}) bool {
+	return false
+}
+
+func (b *bucket) ErrorAs(err error, i interface{}) bool {
+	if perr, ok := err.(*os.PathError); ok {
+		if p, ok := i.(**os.PathError); ok {
+			*p = perr
+			return true
+		}
+	}
+	return false
+}
+
+func (b *bucket) Attributes(ctx context.Context, key string) (*driver.Attributes, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, &os.PathError{Op: "stat", Path: path, Err: os.ErrNotExist}
+	}
+	return &driver.Attributes{
+		ContentType: contentType,
+		ModTime:     info.ModTime(),
+		Size:        info.Size(),
+	}, nil
+}
+
+func (b *bucket) ListPaged(ctx context.Context, opts *driver.ListOptions) (*driver.ListPage, error) {
+	if opts.BeforeList != nil {
+		if err := opts.BeforeList(b.As); err != nil {
+			return nil, err
+		}
+	}
+	pageSize := opts.PageSize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+
+	objects := make([]*driver.ListObject, 0)
+	seenDirs := make(map[string]bool)
+	err := filepath.Walk(b.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// The root doesn't exist till the first object is written
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), tempFilePrefix) {
+			return nil
+		}
+		relPath, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relPath)
+		if !strings.HasPrefix(key, opts.Prefix) {
+			return nil
+		}
+		if opts.Delimiter != "" {
+			if i := strings.Index(key[len(opts.Prefix):], opts.Delimiter); i != -1 {
+				dirKey := key[:len(opts.Prefix)+i+len(opts.Delimiter)]
+				if !seenDirs[dirKey] {
+					seenDirs[dirKey] = true
+					objects = append(objects, &driver.ListObject{
+						Key:   dirKey,
+						IsDir: true,
+					})
+				}
+				return nil
+			}
+		}
+		objects = append(objects, &driver.ListObject{
+			Key:     key,
+			ModTime: info.ModTime(),
+			Size:    info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key < objects[j].Key
+	})
+
+	page := &driver.ListPage{}
+	for _, object := range objects {
+		if len(opts.PageToken) > 0 && object.Key <= string(opts.PageToken) {
+			continue
+		}
+		if len(page.Objects) == pageSize {
+			page.NextPageToken = []byte(page.Objects[pageSize-1].Key)
+			break
+		}
+		page.Objects = append(page.Objects, object)
+	}
+	return page, nil
+}
+
+func (b *bucket) NewRangeReader(ctx context.Context, key string, offset, length int64, opts *driver.ReaderOptions) (driver.Reader, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if opts.BeforeRead != nil {
+		if err := opts.BeforeRead(func(interface{}) bool { return false }); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	if offset > 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	var r io.Reader = f
+	if length >= 0 {
+		r = io.LimitReader(f, length)
+	}
+	return &reader{
+		r: r,
+		c: f,
+		attrs: driver.ReaderAttributes{
+			ContentType: contentType,
+			ModTime:     info.ModTime(),
+			Size:        info.Size(),
+		},
+	}, nil
+}
+
+func (b *bucket) NewTypedWriter(ctx context.Context, key, contentType string, opts *driver.WriterOptions) (driver.Writer, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), tempFilePrefix)
+	if err != nil {
+		return nil, err
+	}
+	if opts.BeforeWrite != nil {
+		if err := opts.BeforeWrite(func(interface{}) bool { return false }); err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+			return nil, err
+		}
+	}
+	return &writer{
+		ctx:  ctx,
+		f:    f,
+		path: path,
+	}, nil
+}
+
+func (b *bucket) Copy(ctx context.Context, dstKey, srcKey string, opts *driver.CopyOptions) error {
+	if opts.BeforeCopy != nil {
+		if err := opts.BeforeCopy(func(interface{}) bool { return false }); err != nil {
+			return err
+		}
+	}
+	r, err := b.NewRangeReader(ctx, srcKey, 0, -1, &driver.ReaderOptions{})
+	if err != nil {
+		return err
+	}
+	defer r.Close() // nolint: errcheck
+	w, err := b.NewTypedWriter(ctx, dstKey, contentType, &driver.WriterOptions{})
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		_ = w.(*writer).abort()
+		return err
+	}
+	return w.Close()
+}
+
+func (b *bucket) Delete(ctx context.Context, key string) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	// Remove any directories that were left empty, stopping at the root.
+	// Failures are ignored since another object could have been written
+	// to the directory in the meantime
+	for dir := filepath.Dir(path); dir != b.root && strings.HasPrefix(dir, b.root); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+func (b *bucket) SignedURL(ctx context.Context, key string, opts *driver.SignedURLOptions) (string, error) {
+	return "", errNotImplemented
+}
+
+func (b *bucket) Close() error {
+	return nil
+}
+
+type reader struct {
+	r     io.Reader
+	c     io.Closer
+	attrs driver.ReaderAttributes
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func (r *reader) Close() error {
+	return r.c.Close()
+}
+
+func (r *reader) Attributes() *driver.ReaderAttributes {
+	return &r.attrs
+}
+
+func (r *reader) As(i interface{}) bool {
+	return false
+}
+
+// writer writes to a temporary file which is renamed to the object path on
+// Close, so that an object is either completely written or not present
+type writer struct {
+	ctx  context.Context
+	f    *os.File
+	path string
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	return w.f.Write(p)
+}
+
+func (w *writer) Close() error {
+	if err := w.ctx.Err(); err != nil {
+		_ = w.abort()
+		return err
+	}
+	if err := w.f.Sync(); err != nil {
+		_ = w.abort()
+		return err
+	}
+	if err := w.f.Close(); err != nil {
+		_ = os.Remove(w.f.Name())
+		return err
+	}
+	return os.Rename(w.f.Name(), w.path)
+}
+
+func (w *writer) abort() error {
+	_ = w.f.Close()
+	return os.Remove(w.f.Name())
+}
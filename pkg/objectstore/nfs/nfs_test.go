@@ -0,0 +1,288 @@
+//go:build unittest
+// +build unittest
+
+package nfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/driver"
+	"gocloud.dev/gcerrors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestBucket(t *testing.T) (*blob.Bucket, string, func()) {
+	dir, err := ioutil.TempDir("", "nfs-test")
+	require.NoError(t, err, "Error creating mount directory")
+	backupLocation := &stork_api.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nfslocation",
+			Namespace: "default",
+		},
+		Location: stork_api.BackupLocationItem{
+			Type: stork_api.BackupLocationNFS,
+			Path: "bucket",
+			NFSConfig: &stork_api.NFSConfig{
+				SubPath:   "exports",
+				MountPath: dir,
+			},
+		},
+	}
+	require.NoError(t, CreateBucket(backupLocation), "Error creating bucket")
+	b, err := GetBucket(backupLocation)
+	require.NoError(t, err, "Error getting bucket")
+	return b, getRootPath(backupLocation), func() {
+		_ = b.Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func writeObject(t *testing.T, b *blob.Bucket, key string, data string) {
+	err := b.WriteAll(context.Background(), key, []byte(data), &blob.WriterOptions{ContentType: contentType})
+	require.NoError(t, err, "Error writing object %v", key)
+}
+
+func TestGetBucketNotMounted(t *testing.T) {
+	backupLocation := &stork_api.BackupLocation{
+		Location: stork_api.BackupLocationItem{
+			Type: stork_api.BackupLocationNFS,
+			NFSConfig: &stork_api.NFSConfig{
+				MountPath: "/nonexistent/stork/nfs",
+			},
+		},
+	}
+	_, err := GetBucket(backupLocation)
+	require.Error(t, err, "Expected error for unmounted export")
+	require.Contains(t, err.Error(), "not mounted at /nonexistent/stork/nfs")
+	require.Error(t, CreateBucket(backupLocation), "Expected error for unmounted export")
+}
+
+func TestPathTraversal(t *testing.T) {
+	b, root, cleanup := newTestBucket(t)
+	defer cleanup()
+
+	// A file next to the root that none of the keys should be able to reach
+	outside := filepath.Join(filepath.Dir(root), "outside")
+	require.NoError(t, ioutil.WriteFile(outside, []byte("secret"), 0600))
+
+	ctx := context.Background()
+	keys := []string{
+		"../outside",
+		"a/../../outside",
+		"../bucket-other/object",
+		"..",
+		"/../../outside",
+	}
+	for _, key := range keys {
+		_, err := b.NewWriter(ctx, key, &blob.WriterOptions{ContentType: contentType})
+		require.Error(t, err, "Expected error writing key %v", key)
+		require.Contains(t, err.Error(), "invalid key", "Unexpected error writing key %v", key)
+
+		_, err = b.ReadAll(ctx, key)
+		require.Error(t, err, "Expected error reading key %v", key)
+		require.Contains(t, err.Error(), "invalid key", "Unexpected error reading key %v", key)
+
+		_, err = b.Attributes(ctx, key)
+		require.Error(t, err, "Expected error getting attributes for key %v", key)
+
+		err = b.Delete(ctx, key)
+		require.Error(t, err, "Expected error deleting key %v", key)
+
+		err = b.Copy(ctx, "copy", key, nil)
+		require.Error(t, err, "Expected error copying from key %v", key)
+		err = b.Copy(ctx, key, "copy", nil)
+		require.Error(t, err, "Expected error copying to key %v", key)
+	}
+
+	data, err := ioutil.ReadFile(outside)
+	require.NoError(t, err, "Error reading file outside the bucket")
+	require.Equal(t, "secret", string(data))
+
+	// Keys that stay under the root after cleaning are allowed
+	writeObject(t, b, "a/../inside", "data")
+	data, err = b.ReadAll(ctx, "inside")
+	require.NoError(t, err, "Error reading object")
+	require.Equal(t, "data", string(data))
+}
+
+func TestAtomicWrite(t *testing.T) {
+	b, root, cleanup := newTestBucket(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	w, err := b.NewWriter(ctx, "dir/object", &blob.WriterOptions{ContentType: contentType})
+	require.NoError(t, err, "Error creating writer")
+	_, err = w.Write([]byte("partial"))
+	require.NoError(t, err, "Error writing object")
+
+	// The object shouldn't be visible till the writer is closed
+	_, err = b.Attributes(ctx, "dir/object")
+	require.Equal(t, gcerrors.NotFound, gcerrors.Code(err), "Object visible before close")
+	exists, err := b.Exists(ctx, "dir/object")
+	require.NoError(t, err)
+	require.False(t, exists, "Object visible before close")
+	page, err := (&bucket{root: root}).ListPaged(ctx, &driver.ListOptions{Prefix: "dir/"})
+	require.NoError(t, err, "Error listing objects")
+	require.Empty(t, page.Objects, "Temporary file should not be listed")
+
+	_, err = w.Write([]byte(" complete"))
+	require.NoError(t, err, "Error writing object")
+	require.NoError(t, w.Close(), "Error closing writer")
+
+	data, err := b.ReadAll(ctx, "dir/object")
+	require.NoError(t, err, "Error reading object")
+	require.Equal(t, "partial complete", string(data))
+	requireNoTempFiles(t, root)
+
+	// Overwriting an object replaces it as a whole
+	writeObject(t, b, "dir/object", "new")
+	data, err = b.ReadAll(ctx, "dir/object")
+	require.NoError(t, err, "Error reading object")
+	require.Equal(t, "new", string(data))
+	requireNoTempFiles(t, root)
+
+	// A writer whose context is cancelled shouldn't leave the object or the
+	// temporary file behind
+	cctx, cancel := context.WithCancel(ctx)
+	w, err = b.NewWriter(cctx, "dir/cancelled", &blob.WriterOptions{ContentType: contentType})
+	require.NoError(t, err, "Error creating writer")
+	_, err = w.Write([]byte("data"))
+	require.NoError(t, err, "Error writing object")
+	cancel()
+	require.Error(t, w.Close(), "Expected error closing cancelled writer")
+	exists, err = b.Exists(ctx, "dir/cancelled")
+	require.NoError(t, err)
+	require.False(t, exists, "Cancelled object should not exist")
+	requireNoTempFiles(t, root)
+}
+
+func requireNoTempFiles(t *testing.T, root string) {
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), tempFilePrefix) {
+			return fmt.Errorf("temporary file %v left behind", path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestListPaged(t *testing.T) {
+	b, root, cleanup := newTestBucket(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	keys := []string{
+		"app/backup1/resources.json",
+		"app/backup1/volumes.json",
+		"app/backup2/resources.json",
+		"app/backup3/resources.json",
+		"app/info.json",
+		"other/object",
+	}
+	for _, key := range keys {
+		writeObject(t, b, key, key)
+	}
+
+	testCases := []struct {
+		prefix    string
+		delimiter string
+		pageSize  int
+		expected  [][]string
+	}{
+		{
+			prefix:   "",
+			pageSize: 10,
+			expected: [][]string{keys},
+		},
+		{
+			prefix:   "app/",
+			pageSize: 2,
+			expected: [][]string{
+				{"app/backup1/resources.json", "app/backup1/volumes.json"},
+				{"app/backup2/resources.json", "app/backup3/resources.json"},
+				{"app/info.json"},
+			},
+		},
+		{
+			prefix:   "app/backup1/",
+			pageSize: 2,
+			expected: [][]string{
+				{"app/backup1/resources.json", "app/backup1/volumes.json"},
+			},
+		},
+		{
+			prefix:    "app/",
+			delimiter: "/",
+			pageSize:  3,
+			expected: [][]string{
+				{"app/backup1/", "app/backup2/", "app/backup3/"},
+				{"app/info.json"},
+			},
+		},
+		{
+			prefix:    "",
+			delimiter: "/",
+			pageSize:  1,
+			expected: [][]string{
+				{"app/"},
+				{"other/"},
+			},
+		},
+		{
+			prefix:   "missing/",
+			pageSize: 10,
+			expected: [][]string{{}},
+		},
+	}
+
+	for _, tc := range testCases {
+		opts := &driver.ListOptions{
+			Prefix:    tc.prefix,
+			Delimiter: tc.delimiter,
+			PageSize:  tc.pageSize,
+		}
+		for i, expected := range tc.expected {
+			page, err := (&bucket{root: root}).ListPaged(ctx, opts)
+			require.NoError(t, err, "Error listing prefix %v page %v", tc.prefix, i)
+			listed := make([]string, 0)
+			for _, object := range page.Objects {
+				listed = append(listed, object.Key)
+				require.Equal(t, strings.HasSuffix(object.Key, "/"), object.IsDir,
+					"Unexpected IsDir for %v", object.Key)
+			}
+			require.Equal(t, expected, listed, "Unexpected objects for prefix %v page %v", tc.prefix, i)
+			if i == len(tc.expected)-1 {
+				require.Empty(t, page.NextPageToken, "Unexpected next page for prefix %v", tc.prefix)
+			} else {
+				require.NotEmpty(t, page.NextPageToken, "Expected next page for prefix %v", tc.prefix)
+			}
+			opts.PageToken = page.NextPageToken
+		}
+	}
+
+	// The iterator should go through all the pages
+	iter := b.List(&blob.ListOptions{})
+	listed := make([]string, 0)
+	for {
+		object, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err, "Error iterating objects")
+		listed = append(listed, object.Key)
+	}
+	require.Equal(t, keys, listed)
+}
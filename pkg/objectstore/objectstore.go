@@ -7,6 +7,7 @@ import (
 	"github.com/libopenstorage/stork/pkg/objectstore/azure"
 	"github.com/libopenstorage/stork/pkg/objectstore/common"
 	"github.com/libopenstorage/stork/pkg/objectstore/google"
+	"github.com/libopenstorage/stork/pkg/objectstore/nfs"
 	"github.com/libopenstorage/stork/pkg/objectstore/s3"
 	"gocloud.dev/blob"
 )
@@ -24,6 +25,8 @@ func GetBucket(backupLocation *stork_api.BackupLocation) (*blob.Bucket, error) {
 		return azure.GetBucket(backupLocation)
 	case stork_api.BackupLocationS3:
 		return s3.GetBucket(backupLocation)
+	case stork_api.BackupLocationNFS:
+		return nfs.GetBucket(backupLocation)
 	default:
 		return nil, fmt.Errorf("invalid backupLocation type: %v", backupLocation.Location.Type)
 	}
@@ -42,6 +45,8 @@ func CreateBucket(backupLocation *stork_api.BackupLocation) error {
 		return azure.CreateBucket(backupLocation)
 	case stork_api.BackupLocationS3:
 		return s3.CreateBucket(backupLocation)
+	case stork_api.BackupLocationNFS:
+		return nfs.CreateBucket(backupLocation)
 	default:
 		return fmt.Errorf("invalid backupLocation type: %v", backupLocation.Location.Type)
 	}
@@ -59,6 +64,8 @@ func GetObjLockInfo(backupLocation *stork_api.BackupLocation) (*common.ObjLockIn
 		return azure.GetObjLockInfo(backupLocation)
 	case stork_api.BackupLocationS3:
 		return s3.GetObjLockInfo(backupLocation)
+	case stork_api.BackupLocationNFS:
+		return nfs.GetObjLockInfo(backupLocation)
 	default:
 		return nil, fmt.Errorf("invalid backupLocation type: %v", backupLocation.Location.Type)
 	}
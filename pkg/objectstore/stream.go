@@ -0,0 +1,195 @@
+package objectstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/crypto"
+	"gocloud.dev/blob"
+)
+
+// Objects uploaded with StreamWriter start with a header made up of the
+// magic string, the compression and how the rest of the stream is encrypted.
+// The header is never encrypted so that the stream can be read back without
+// looking at the backup spec.
+const (
+	streamMagic = "STORKRS1"
+
+	streamCompressionNone byte = 0
+	streamCompressionGzip byte = 1
+
+	streamEncryptionNone       byte = 0
+	streamEncryptionPassphrase byte = 1
+	streamEncryptionEnvelope   byte = 2
+)
+
+// StreamWriter compresses and encrypts the data written to it on the fly and
+// streams it to an object in the bucket, so that the whole object is never
+// held in memory
+type StreamWriter struct {
+	cancel       context.CancelFunc
+	key          string
+	bucketWriter *blob.Writer
+	checksum     *checksumWriter
+	encryptor    io.WriteCloser
+	compressor   io.WriteCloser
+	w            io.Writer
+}
+
+// NewStreamWriter returns a writer for the object with the given key. The
+// data key is used for encryption if set, otherwise the encryption key is
+// used if set
+func NewStreamWriter(
+	ctx context.Context,
+	bucket *blob.Bucket,
+	key string,
+	compression stork_api.ApplicationBackupCompressionType,
+	dataKey *crypto.DataKey,
+	encryptionKey string,
+) (*StreamWriter, error) {
+	header := []byte(streamMagic)
+	switch compression {
+	case stork_api.ApplicationBackupCompressionNone:
+		header = append(header, streamCompressionNone)
+	case stork_api.ApplicationBackupCompressionGzip, "":
+		header = append(header, streamCompressionGzip)
+	default:
+		return nil, fmt.Errorf("unsupported compression type %v", compression)
+	}
+	switch {
+	case dataKey != nil:
+		header = append(header, streamEncryptionEnvelope)
+	case encryptionKey != "":
+		header = append(header, streamEncryptionPassphrase)
+	default:
+		header = append(header, streamEncryptionNone)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	bucketWriter, err := bucket.NewWriter(ctx, key, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s := &StreamWriter{
+		cancel:       cancel,
+		key:          key,
+		bucketWriter: bucketWriter,
+		checksum:     newChecksumWriter(bucketWriter),
+	}
+	if _, err := s.checksum.Write(header); err != nil {
+		s.Abort()
+		return nil, err
+	}
+
+	s.w = s.checksum
+	switch {
+	case dataKey != nil:
+		s.encryptor, err = crypto.NewDataKeyEncryptWriter(s.w, dataKey)
+	case encryptionKey != "":
+		s.encryptor, err = crypto.NewEncryptWriter(s.w, encryptionKey)
+	}
+	if err != nil {
+		s.Abort()
+		return nil, err
+	}
+	if s.encryptor != nil {
+		s.w = s.encryptor
+	}
+	if header[len(streamMagic)] == streamCompressionGzip {
+		s.compressor = gzip.NewWriter(s.w)
+		s.w = s.compressor
+	}
+	return s, nil
+}
+
+// Write compresses and encrypts p and writes it to the object
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	return s.w.Write(p)
+}
+
+// Close flushes every stage of the pipeline. The object is only committed to
+// the bucket if all of them succeed
+func (s *StreamWriter) Close() error {
+	for _, w := range []io.WriteCloser{s.compressor, s.encryptor} {
+		if w == nil {
+			continue
+		}
+		if err := w.Close(); err != nil {
+			s.Abort()
+			return err
+		}
+	}
+	defer s.cancel()
+	return s.bucketWriter.Close()
+}
+
+// ManifestObject returns the manifest entry for the object relative to the
+// backup path, with the size and checksum of what was uploaded. Only valid
+// once the writer has been closed
+func (s *StreamWriter) ManifestObject(objectPath string) ManifestObject {
+	return s.checksum.manifestObject(strings.TrimPrefix(s.key, objectPath+"/"))
+}
+
+// Abort discards the object being written. Cancelling the context before
+// closing the bucket writer makes sure a partial object isn't committed
+func (s *StreamWriter) Abort() {
+	s.cancel()
+	_ = s.bucketWriter.Close()
+}
+
+// IsStream checks whether the data starts with the stream header
+func IsStream(header []byte) bool {
+	return bytes.HasPrefix(header, []byte(streamMagic))
+}
+
+// NewStreamReader returns a reader that decrypts and decompresses an object
+// written by StreamWriter. The keyring and EncryptionV2Key of the backup
+// location are used for decryption
+func NewStreamReader(r io.Reader, backupLocation *stork_api.BackupLocation) (io.Reader, error) {
+	header := make([]byte, len(streamMagic)+2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("error reading stream header: %v", err)
+	}
+	if !IsStream(header) {
+		return nil, fmt.Errorf("invalid stream header")
+	}
+	compression := header[len(streamMagic)]
+
+	var err error
+	switch header[len(streamMagic)+1] {
+	case streamEncryptionNone:
+	case streamEncryptionPassphrase:
+		if backupLocation.Location.EncryptionV2Key == "" {
+			return nil, fmt.Errorf("object is encrypted but no encryption key is set for the backup location")
+		}
+		if r, err = crypto.NewDecryptReader(r, backupLocation.Location.EncryptionV2Key); err != nil {
+			return nil, err
+		}
+	case streamEncryptionEnvelope:
+		keyring, err := GetKeyring(backupLocation)
+		if err != nil {
+			return nil, err
+		}
+		if r, err = crypto.NewEnvelopeDecryptReader(r, keyring); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encryption in stream")
+	}
+	switch compression {
+	case streamCompressionNone:
+	case streamCompressionGzip:
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression %v in stream", compression)
+	}
+	return r, nil
+}
@@ -875,6 +875,29 @@ func (r *ResourceCollector) ApplyResource(
 	return err
 }
 
+// ResourceExists checks whether the object already exists in the cluster
+func (r *ResourceCollector) ResourceExists(
+	dynamicInterface dynamic.Interface,
+	object runtime.Unstructured,
+) (bool, error) {
+	dynamicClient, err := r.getDynamicClient(dynamicInterface, object)
+	if err != nil {
+		return false, err
+	}
+	metadata, err := meta.Accessor(object)
+	if err != nil {
+		return false, err
+	}
+	_, err = dynamicClient.Get(context.TODO(), metadata.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // DeleteResources deletes given resources using the provided client interface
 func (r *ResourceCollector) DeleteResources(
 	dynamicInterface dynamic.Interface,
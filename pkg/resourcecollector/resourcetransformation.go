@@ -1,17 +1,38 @@
 package resourcecollector
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"regexp"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
+	"github.com/google/cel-go/cel"
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+const (
+	transformValidateTimeout       = 1 * time.Minute
+	transformValidateRetryInterval = 5 * time.Second
+
+	// The when conditions are evaluated for every object being transformed,
+	// so limit the cost and the time taken to evaluate them
+	transformConditionCostLimit         = 1000000
+	transformConditionTimeout           = 1 * time.Second
+	transformConditionInterruptInterval = 100
+)
+
 // Since we collect all resources from required migration namespace at once
 // getResourcePatch creates map of namespace: {kind: []resourceinfo{}}
 // to get transform spec for matching resources
@@ -42,16 +63,140 @@ func GetResourcePatch(transformName string, namespaces []string) (map[string]sto
 	return patch, nil
 }
 
+// TransformTemplateData is the data available to the templated values of
+// resource transformations
+type TransformTemplateData struct {
+	// Object being transformed
+	Object map[string]interface{}
+	// ClusterPair used to migrate the object, empty if the object isn't being
+	// migrated
+	ClusterPair metav1.ObjectMeta
+}
+
+var transformTemplateFuncs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"replace":    strings.ReplaceAll,
+	"trimPrefix": strings.TrimPrefix,
+	"trimSuffix": strings.TrimSuffix,
+	"regexReplace": func(pattern, replacement, value string) (string, error) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", err
+		}
+		return re.ReplaceAllString(value, replacement), nil
+	},
+}
+
+// ParseTransformTemplate parses the templated value of a resource
+// transformation path
+func ParseTransformTemplate(value string) (*template.Template, error) {
+	return template.New("value").Funcs(transformTemplateFuncs).Option("missingkey=error").Parse(value)
+}
+
+func renderTransformTemplate(value string, content map[string]interface{}, clusterPair *stork_api.ClusterPair) (string, error) {
+	tmpl, err := ParseTransformTemplate(value)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template %v: %v", value, err)
+	}
+	data := TransformTemplateData{
+		Object: content,
+	}
+	if clusterPair != nil {
+		data.ClusterPair = clusterPair.ObjectMeta
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("error rendering template %v: %v", value, err)
+	}
+	return rendered.String(), nil
+}
+
+// transformConditions caches the compiled when conditions of resource
+// transformations since they are evaluated for every object being transformed
+var transformConditions sync.Map
+
+// CompileTransformCondition compiles the CEL when condition of a resource
+// transformation spec. The object being transformed is available as object in
+// the expression, which needs to evaluate to a bool
+func CompileTransformCondition(when string) (cel.Program, error) {
+	if prg, ok := transformConditions.Load(when); ok {
+		return prg.(cel.Program), nil
+	}
+	env, err := cel.NewEnv(cel.Variable("object", cel.DynType))
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(when)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("error compiling condition %v: %v", when, issues.Err())
+	}
+	if t := ast.OutputType(); t != cel.DynType && !cel.BoolType.IsAssignableType(t) {
+		return nil, fmt.Errorf("condition %v evaluates to %v instead of bool", when, t)
+	}
+	prg, err := env.Program(ast,
+		cel.CostLimit(transformConditionCostLimit),
+		cel.InterruptCheckFrequency(transformConditionInterruptInterval),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling condition %v: %v", when, err)
+	}
+	transformConditions.Store(when, prg)
+	return prg, nil
+}
+
+// MatchTransformCondition evaluates the when condition of a resource
+// transformation spec against the content of the object. Objects always match
+// an empty condition
+func MatchTransformCondition(when string, content map[string]interface{}) (bool, error) {
+	if when == "" {
+		return true, nil
+	}
+	prg, err := CompileTransformCondition(when)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), transformConditionTimeout)
+	defer cancel()
+	out, _, err := prg.ContextEval(ctx, map[string]interface{}{"object": content})
+	if err != nil {
+		return false, fmt.Errorf("error evaluating condition %v: %v", when, err)
+	}
+	matched, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("condition %v evaluated to %v instead of bool", when, out.Value())
+	}
+	return matched, nil
+}
+
 // this method transform object as per resource transformation specified in each namespaces
 func TransformResources(
 	object runtime.Unstructured,
 	resPatch []stork_api.TransformResourceInfo,
 	objName, objNamespace string,
+	clusterPair *stork_api.ClusterPair,
 ) error {
 	for _, patch := range resPatch {
 		if patch.Name == objName && patch.Namespace == objNamespace {
 			content := object.UnstructuredContent()
+			matched, err := MatchTransformCondition(patch.Specs.When, content)
+			if err != nil {
+				logrus.Errorf("Unable to evaluate condition on resource kind: %s/,%s/%s,  err: %v", patch.Kind, patch.Namespace, patch.Name, err)
+				return err
+			}
+			if !matched {
+				logrus.Debugf("Skipping transformation of resource kind: %s/,%s/%s, condition %s not matched", patch.Kind, patch.Namespace, patch.Name, patch.Specs.When)
+				continue
+			}
 			for _, path := range patch.Specs.Paths {
+				if path.Template {
+					value, err := renderTransformTemplate(path.Value, content, clusterPair)
+					if err != nil {
+						logrus.Errorf("Unable to render value for path %s on resource kind: %s/,%s/%s,  err: %v", path.Path, patch.Kind, patch.Namespace, patch.Name, err)
+						return err
+					}
+					path.Value = value
+				}
 				switch path.Operation {
 				case stork_api.AddResourcePath:
 					value := getNewValueForPath(path.Value, string(path.Type))
@@ -59,26 +204,26 @@ func TransformResources(
 						updateMap := value.(map[string]string)
 						err := unstructured.SetNestedStringMap(content, updateMap, strings.Split(path.Path, ".")...)
 						if err != nil {
-							logrus.Errorf("Unable to apply patch path %s on resource kind: %s/,%s/%s,  err: %v", path, patch.Kind, patch.Namespace, patch.Name, err)
+							logrus.Errorf("Unable to apply patch path %s on resource kind: %s/,%s/%s,  err: %v", path.Path, patch.Kind, patch.Namespace, patch.Name, err)
 							return err
 						}
 					} else if path.Type == stork_api.SliceResourceType {
 						err := unstructured.SetNestedField(content, value, strings.Split(path.Path, ".")...)
 						if err != nil {
-							logrus.Errorf("Unable to apply patch path %s on resource kind: %s/,%s/%s,  err: %v", path, patch.Kind, patch.Namespace, patch.Name, err)
+							logrus.Errorf("Unable to apply patch path %s on resource kind: %s/,%s/%s,  err: %v", path.Path, patch.Kind, patch.Namespace, patch.Name, err)
 							return err
 						}
 					} else {
 						err := unstructured.SetNestedField(content, value, strings.Split(path.Path, ".")...)
 						if err != nil {
-							logrus.Errorf("Unable to perform operation %s on path %s on resource kind: %s/,%s/%s,  err: %v", path.Operation, path, patch.Kind, patch.Namespace, patch.Name, err)
+							logrus.Errorf("Unable to perform operation %s on path %s on resource kind: %s/,%s/%s,  err: %v", path.Operation, path.Path, patch.Kind, patch.Namespace, patch.Name, err)
 							return err
 						}
 					}
 
 				case stork_api.DeleteResourcePath:
 					unstructured.RemoveNestedField(content, strings.Split(path.Path, ".")...)
-					logrus.Debugf("Removed patch path %s on resource kind: %s/,%s/%s", path, patch.Kind, patch.Namespace, patch.Name)
+					logrus.Debugf("Removed patch path %s on resource kind: %s/,%s/%s", path.Path, patch.Kind, patch.Namespace, patch.Name)
 
 				case stork_api.ModifyResourcePathValue:
 					var value interface{}
@@ -108,9 +253,17 @@ func TransformResources(
 					}
 					err := unstructured.SetNestedField(content, value, strings.Split(path.Path, ".")...)
 					if err != nil {
-						logrus.Errorf("Unable to perform operation %s on path %s on resource kind: %s/,%s/%s,  err: %v", path.Operation, path, patch.Kind, patch.Namespace, patch.Name, err)
+						logrus.Errorf("Unable to perform operation %s on path %s on resource kind: %s/,%s/%s,  err: %v", path.Operation, path.Path, patch.Kind, patch.Namespace, patch.Name, err)
 						return err
 					}
+
+				case stork_api.RegexReplaceResourcePath:
+					re, err := regexp.Compile(path.Pattern)
+					if err != nil {
+						return fmt.Errorf("invalid pattern %s for path %s: %v", path.Pattern, path.Path, err)
+					}
+					regexReplaceField(content, strings.Split(path.Path, "."), re, path.Value)
+					logrus.Debugf("Replaced pattern %s in path %s on resource kind: %s/,%s/%s", path.Pattern, path.Path, patch.Kind, patch.Namespace, patch.Name)
 				}
 			}
 			object.SetUnstructuredContent(content)
@@ -120,6 +273,93 @@ func TransformResources(
 	return nil
 }
 
+// ValidateResourceTransformations re-runs the dry-run for the given
+// transformations in the namespace and waits for them to be ready, so that
+// specs added after the transformation was created are validated as well
+func ValidateResourceTransformations(
+	names []string,
+	namespace string,
+) ([]*stork_api.ResourceTransformation, error) {
+	transforms := make([]*stork_api.ResourceTransformation, 0, len(names))
+	for _, name := range names {
+		transform, err := storkops.Instance().GetResourceTransformation(name, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve transformation %s: %v", name, err)
+		}
+		transform.Status.Resources = []*stork_api.TransformResourceInfo{}
+		transform.Status.Status = stork_api.ResourceTransformationStatusInitial
+		if _, err := storkops.Instance().UpdateResourceTransformation(transform); err != nil {
+			return nil, fmt.Errorf("error updating transformation %s: %v", name, err)
+		}
+		if err := storkops.Instance().ValidateResourceTransformation(name, namespace, transformValidateTimeout, transformValidateRetryInterval); err != nil {
+			return nil, fmt.Errorf("transformation %s is not in ready state: %v", name, err)
+		}
+		transforms = append(transforms, transform)
+	}
+	return transforms, nil
+}
+
+// GetResourceTransformations returns the transformations with the given names
+// in the namespace
+func GetResourceTransformations(
+	names []string,
+	namespace string,
+) ([]*stork_api.ResourceTransformation, error) {
+	transforms := make([]*stork_api.ResourceTransformation, 0, len(names))
+	for _, name := range names {
+		transform, err := storkops.Instance().GetResourceTransformation(name, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve transformation %s: %v", name, err)
+		}
+		transforms = append(transforms, transform)
+	}
+	return transforms, nil
+}
+
+// TransformObject applies the specs from the transformations, in order, to
+// the object if it matches the resource type and selectors of the spec. Unlike
+// GetResourcePatch the object doesn't need to exist in the cluster, which is
+// the case for objects being restored from a backup
+func TransformObject(
+	object runtime.Unstructured,
+	transforms []*stork_api.ResourceTransformation,
+) error {
+	metadata, err := meta.Accessor(object)
+	if err != nil {
+		return err
+	}
+	gvk := object.GetObjectKind().GroupVersionKind()
+	for _, transform := range transforms {
+		for _, spec := range transform.Spec.Objects {
+			resource := strings.Split(spec.Resource, "/")
+			if len(resource) != 3 {
+				return fmt.Errorf("invalid resource kind :%s", spec.Resource)
+			}
+			group := resource[0]
+			if group == "core" {
+				group = ""
+			}
+			if group != gvk.Group || resource[1] != gvk.Version || resource[2] != gvk.Kind {
+				continue
+			}
+			if !labels.SelectorFromSet(spec.Selectors).Matches(labels.Set(metadata.GetLabels())) {
+				continue
+			}
+			patch := stork_api.TransformResourceInfo{
+				Name:             metadata.GetName(),
+				Namespace:        metadata.GetNamespace(),
+				GroupVersionKind: metav1.GroupVersionKind(gvk),
+				Specs:            spec,
+			}
+			if err := TransformResources(object, []stork_api.TransformResourceInfo{patch}, patch.Name, patch.Namespace, nil); err != nil {
+				return fmt.Errorf("error applying transformation %s to %v %v/%v: %v",
+					transform.Name, gvk.Kind, patch.Namespace, patch.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 func getNewValueForPath(oldVal, valType string) interface{} {
 	var updatedValue interface{}
 	if valType == string(stork_api.KeyPairResourceType) {
@@ -140,3 +380,36 @@ func getNewValueForPath(oldVal, valType string) interface{} {
 	}
 	return updatedValue
 }
+
+// regexReplaceField replaces the matches of the regex in the string values at
+// the path. Lists found along the path are traversed so that the path can
+// refer to fields in the entries of a list, for example the images of all the
+// containers with spec.template.spec.containers.image
+func regexReplaceField(value interface{}, fields []string, re *regexp.Regexp, replacement string) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for i := range v {
+			v[i] = regexReplaceField(v[i], fields, re, replacement)
+		}
+		return v
+	case map[string]interface{}:
+		if len(fields) == 0 {
+			// Replace the values of a keypair
+			for k := range v {
+				v[k] = regexReplaceField(v[k], fields, re, replacement)
+			}
+			return v
+		}
+		if child, ok := v[fields[0]]; ok {
+			v[fields[0]] = regexReplaceField(child, fields[1:], re, replacement)
+		}
+		return v
+	case string:
+		if len(fields) != 0 {
+			return v
+		}
+		return re.ReplaceAllString(v, replacement)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,316 @@
+//go:build unittest
+// +build unittest
+
+package resourcecollector
+
+import (
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTransformTestDeployment() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "web",
+				"namespace": "ns1",
+				"labels": map[string]interface{}{
+					"app":      "web",
+					"registry": "registry-a.corp",
+				},
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(3),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":  "web",
+								"image": "registry-a.corp/web:1.0",
+							},
+							map[string]interface{}{
+								"name":  "sidecar",
+								"image": "docker.io/sidecar:2.0",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func transformTestDeployment(specs stork_api.TransformSpecs, clusterPair *stork_api.ClusterPair) (*unstructured.Unstructured, error) {
+	object := newTransformTestDeployment()
+	patch := stork_api.TransformResourceInfo{
+		Name:      "web",
+		Namespace: "ns1",
+		GroupVersionKind: metav1.GroupVersionKind{
+			Group:   "apps",
+			Version: "v1",
+			Kind:    "Deployment",
+		},
+		Specs: specs,
+	}
+	err := TransformResources(object, []stork_api.TransformResourceInfo{patch}, "web", "ns1", clusterPair)
+	return object, err
+}
+
+func TestRegexReplace(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		pattern     string
+		value       string
+		field       []string
+		expected    interface{}
+		expectError bool
+	}{
+		{
+			name:     "list of containers",
+			path:     "spec.template.spec.containers.image",
+			pattern:  `^registry-a\.corp/(.*)$`,
+			value:    "registry-b.corp/$1",
+			field:    []string{"spec", "template", "spec", "containers"},
+			expected: []interface{}{"registry-b.corp/web:1.0", "docker.io/sidecar:2.0"},
+		},
+		{
+			name:    "keypair",
+			path:    "metadata.labels",
+			pattern: `registry-a`,
+			value:   "registry-b",
+			field:   []string{"metadata", "labels"},
+			expected: map[string]interface{}{
+				"app":      "web",
+				"registry": "registry-b.corp",
+			},
+		},
+		{
+			name:     "string",
+			path:     "metadata.labels.registry",
+			pattern:  `\.corp$`,
+			value:    ".example.com",
+			field:    []string{"metadata", "labels", "registry"},
+			expected: "registry-a.example.com",
+		},
+		{
+			name:     "non string value",
+			path:     "spec.replicas",
+			pattern:  `3`,
+			value:    "5",
+			field:    []string{"spec", "replicas"},
+			expected: int64(3),
+		},
+		{
+			name:     "missing path",
+			path:     "spec.template.spec.volumes.name",
+			pattern:  `.*`,
+			value:    "none",
+			field:    []string{"spec", "template", "spec", "volumes"},
+			expected: nil,
+		},
+		{
+			name:        "invalid pattern",
+			path:        "metadata.labels.registry",
+			pattern:     `(`,
+			value:       "none",
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			specs := stork_api.TransformSpecs{
+				Resource: "apps/v1/Deployment",
+				Paths: []stork_api.ResourcePaths{
+					{
+						Path:      test.path,
+						Pattern:   test.pattern,
+						Value:     test.value,
+						Operation: stork_api.RegexReplaceResourcePath,
+					},
+				},
+			}
+			object, err := transformTestDeployment(specs, nil)
+			if test.expectError {
+				require.Error(t, err, "Expected error for invalid pattern")
+				return
+			}
+			require.NoError(t, err, "Error transforming object")
+			value, _, err := unstructured.NestedFieldNoCopy(object.Object, test.field...)
+			require.NoError(t, err, "Error getting transformed field")
+			if containers, ok := value.([]interface{}); ok {
+				images := make([]interface{}, 0, len(containers))
+				for _, container := range containers {
+					images = append(images, container.(map[string]interface{})["image"])
+				}
+				value = images
+			}
+			require.Equal(t, test.expected, value, "Unexpected value after regexReplace")
+		})
+	}
+}
+
+func TestRenderTransformTemplate(t *testing.T) {
+	clusterPair := &stork_api.ClusterPair{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "remote",
+			Labels: map[string]string{
+				"registry": "registry-b.corp",
+			},
+		},
+	}
+	tests := []struct {
+		name        string
+		value       string
+		clusterPair *stork_api.ClusterPair
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "plain value",
+			value:    "web",
+			expected: "web",
+		},
+		{
+			name:     "object field",
+			value:    "{{ .Object.metadata.name }}-dr",
+			expected: "web-dr",
+		},
+		{
+			name:        "clusterpair metadata",
+			value:       `{{ index .ClusterPair.Labels "registry" }}`,
+			clusterPair: clusterPair,
+			expected:    "registry-b.corp",
+		},
+		{
+			name:     "without clusterpair",
+			value:    "{{ .ClusterPair.Name }}",
+			expected: "",
+		},
+		{
+			name:     "functions",
+			value:    `{{ upper .Object.metadata.name }}-{{ regexReplace "^registry-a" "registry-b" .Object.metadata.labels.registry }}`,
+			expected: "WEB-registry-b.corp",
+		},
+		{
+			name:        "missing key",
+			value:       "{{ .Object.metadata.missing }}",
+			expectError: true,
+		},
+		{
+			name:        "invalid template",
+			value:       "{{ .Object.metadata.name",
+			expectError: true,
+		},
+		{
+			name:        "invalid regex",
+			value:       `{{ regexReplace "(" "" .Object.metadata.name }}`,
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rendered, err := renderTransformTemplate(test.value, newTransformTestDeployment().Object, test.clusterPair)
+			if test.expectError {
+				require.Error(t, err, "Expected error rendering template")
+				return
+			}
+			require.NoError(t, err, "Error rendering template")
+			require.Equal(t, test.expected, rendered, "Unexpected rendered value")
+		})
+	}
+}
+
+func TestTransformCondition(t *testing.T) {
+	tests := []struct {
+		name        string
+		when        string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "no condition",
+			when:     "",
+			expected: "web-dr",
+		},
+		{
+			name:     "matched",
+			when:     `object.spec.replicas > 1 && object.metadata.labels.app == "web"`,
+			expected: "web-dr",
+		},
+		{
+			name:     "not matched",
+			when:     `object.metadata.labels.registry.startsWith("registry-b")`,
+			expected: "web",
+		},
+		{
+			name:     "has field",
+			when:     `has(object.metadata.annotations)`,
+			expected: "web",
+		},
+		{
+			name:        "not a bool",
+			when:        `object.metadata.name + "-dr"`,
+			expectError: true,
+		},
+		{
+			name:        "invalid expression",
+			when:        `object.spec.replicas >`,
+			expectError: true,
+		},
+		{
+			name:        "missing field",
+			when:        `object.spec.paused`,
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			specs := stork_api.TransformSpecs{
+				Resource: "apps/v1/Deployment",
+				When:     test.when,
+				Paths: []stork_api.ResourcePaths{
+					{
+						Path:      "metadata.labels.app",
+						Value:     "{{ .Object.metadata.name }}-dr",
+						Type:      stork_api.StringResourceType,
+						Operation: stork_api.ModifyResourcePathValue,
+						Template:  true,
+					},
+				},
+			}
+			object, err := transformTestDeployment(specs, nil)
+			if test.expectError {
+				require.Error(t, err, "Expected error evaluating condition")
+				return
+			}
+			require.NoError(t, err, "Error transforming object")
+			require.Equal(t, test.expected, object.GetLabels()["app"], "Unexpected value after transformation")
+		})
+	}
+}
+
+func TestTransformConditionCostLimit(t *testing.T) {
+	values := make([]interface{}, 0, 2000)
+	for i := 0; i < 2000; i++ {
+		values = append(values, int64(i))
+	}
+	content := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"values": values,
+		},
+	}
+	_, err := MatchTransformCondition(`object.spec.values.all(x, object.spec.values.all(y, x >= 0 && y >= 0))`, content)
+	require.Error(t, err, "Expected error for condition exceeding the cost limit")
+	require.Contains(t, err.Error(), "cost limit", "Unexpected error for condition exceeding the cost limit")
+
+	matched, err := MatchTransformCondition(`object.spec.values.exists(x, x == 10)`, content)
+	require.NoError(t, err, "Error evaluating condition within the cost limit")
+	require.True(t, matched, "Condition should have matched")
+}
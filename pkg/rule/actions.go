@@ -0,0 +1,638 @@
+package rule
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/log"
+	"github.com/portworx/sched-ops/k8s/apps"
+	"github.com/portworx/sched-ops/k8s/core"
+	"github.com/portworx/sched-ops/k8s/dynamic"
+	errors "github.com/portworx/sched-ops/k8s/errors"
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// actionsToRevertKey is the annotation on the owner used to track the
+	// actions that need to be reverted by the post exec rule, when the
+	// background actions are terminated, or during rule recovery
+	actionsToRevertKey = "stork.libopenstorage.org/rule-actions-to-revert"
+
+	defaultHTTPActionTimeout = 30 * time.Second
+	scaleDownTimeout         = 5 * time.Minute
+	scaleDownRetryInterval   = 5 * time.Second
+)
+
+// revertTask is an action that was performed by a rule and needs to be
+// reverted
+type revertTask struct {
+	TaskID     string                   `json:"taskID"`
+	Type       stork_api.RuleActionType `json:"type"`
+	Background bool                     `json:"background,omitempty"`
+	Namespace  string                   `json:"namespace"`
+	// Kind, Name and Replicas of the workload that was scaled down for
+	// scale actions
+	Kind     string `json:"kind,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Replicas int32  `json:"replicas,omitempty"`
+	// Pod, Container and Path of the filesystem that was frozen for
+	// fsfreeze actions
+	Pod       *Pod   `json:"pod,omitempty"`
+	Container string `json:"container,omitempty"`
+	Path      string `json:"path,omitempty"`
+}
+
+func (r *revertTask) String() string {
+	if r.Type == stork_api.RuleActionScale {
+		return fmt.Sprintf("scale %v [%v] %v to %v", r.Kind, r.Namespace, r.Name, r.Replicas)
+	}
+	return fmt.Sprintf("thaw %v in pod [%v] %v", r.Path, r.Namespace, r.Pod.UID)
+}
+
+// key identifies the action that was performed
+func (r *revertTask) key() string {
+	if r.Pod != nil {
+		return r.TaskID + "/" + r.Pod.UID + "/" + r.Path
+	}
+	return r.TaskID + "/" + r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// httpActionTarget has the fields that can be used in the URL template of an
+// http action
+type httpActionTarget struct {
+	PodName   string
+	PodIP     string
+	Namespace string
+}
+
+// validateAction validates an action based on its type. revertTypes are the
+// types of the actions in the post exec rule that revert the actions of a pre
+// exec rule. They are only checked when not nil.
+func validateAction(action *stork_api.RuleAction, ruleType Type, revertTypes map[stork_api.RuleActionType]bool) error {
+	switch action.Type {
+	case stork_api.RuleActionCommand, stork_api.RuleActionScale:
+	case stork_api.RuleActionHTTP:
+		if action.HTTP == nil || action.HTTP.URL == "" {
+			return fmt.Errorf("url is required for http actions")
+		}
+		if _, err := template.New("url").Parse(action.HTTP.URL); err != nil {
+			return fmt.Errorf("invalid url template %v for http action: %v", action.HTTP.URL, err)
+		}
+		if action.Background {
+			return fmt.Errorf("background is not supported for http actions")
+		}
+	case stork_api.RuleActionFsfreeze:
+		if !filepath.IsAbs(action.Value) || strings.Contains(action.Value, "'") {
+			return fmt.Errorf("value for fsfreeze actions should be the absolute path of the filesystem to freeze")
+		}
+	default:
+		return fmt.Errorf("unsupported action type: %s", action.Type)
+	}
+	if revertTypes != nil && ruleType == PreExecRule &&
+		(action.Type == stork_api.RuleActionScale || action.Type == stork_api.RuleActionFsfreeze) &&
+		!revertTypes[action.Type] {
+		return fmt.Errorf("%v actions in pre exec rules need a %v action in the post exec rule to revert them",
+			action.Type, action.Type)
+	}
+	if action.Background && ruleType == PostExecRule {
+		return fmt.Errorf("background actions are not supported for post exec rules")
+	}
+	if action.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeoutSeconds can't be negative")
+	}
+	if action.Retries != nil && *action.Retries < 0 {
+		return fmt.Errorf("retries can't be negative")
+	}
+	if action.OnFailure != "" &&
+		action.OnFailure != stork_api.RuleActionFailurePolicyFail &&
+		action.OnFailure != stork_api.RuleActionFailurePolicyContinue {
+		return fmt.Errorf("invalid onFailure policy %v, should be %v or %v", action.OnFailure,
+			stork_api.RuleActionFailurePolicyFail, stork_api.RuleActionFailurePolicyContinue)
+	}
+	return nil
+}
+
+// getItemOrder returns the indexes of the rule items in the order they should
+// be run
+func getItemOrder(rule *stork_api.Rule) []int {
+	order := make([]int, len(rule.Rules))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return rule.Rules[order[i]].Order < rule.Rules[order[j]].Order
+	})
+	return order
+}
+
+// getRetrySteps returns the number of times an action should be attempted on
+// each pod
+func getRetrySteps(action stork_api.RuleAction, defaultSteps int) int {
+	if action.Retries != nil {
+		return *action.Retries + 1
+	}
+	return defaultSteps
+}
+
+// runWithTimeout runs the action with a context that is cancelled once the
+// timeout for the action expires. The action is expected to stop its execs,
+// requests and waits when the context is done, so it has always returned by
+// the time the caller reverts the actions of a failed rule.
+func runWithTimeout(action stork_api.RuleAction, fn func(ctx context.Context) error) error {
+	if action.TimeoutSeconds <= 0 {
+		return fn(context.Background())
+	}
+	timeout := time.Duration(action.TimeoutSeconds) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := fn(ctx)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %v: %v", timeout, err)
+	}
+	return err
+}
+
+// getRevertTypes returns the types of the actions in the post exec rule that
+// revert the actions done by the pre exec rule
+func getRevertTypes(rule *stork_api.Rule) map[stork_api.RuleActionType]bool {
+	revertTypes := make(map[stork_api.RuleActionType]bool)
+	if rule == nil {
+		return revertTypes
+	}
+	for _, item := range rule.Rules {
+		for _, action := range item.Actions {
+			if isRevertAction(&action, PostExecRule) {
+				revertTypes[action.Type] = true
+			}
+		}
+	}
+	return revertTypes
+}
+
+// isRevertAction returns true for actions in post exec rules that revert the
+// actions done by the pre exec rule instead of running on the pods
+func isRevertAction(action *stork_api.RuleAction, rType Type) bool {
+	return rType == PostExecRule &&
+		(action.Type == stork_api.RuleActionScale || action.Type == stork_api.RuleActionFsfreeze)
+}
+
+// executeHTTPAction sends the request for the http action for each of the pods
+func executeHTTPAction(
+	ctx context.Context,
+	pods []v1.Pod,
+	rule *stork_api.Rule,
+	owner runtime.Object,
+	action stork_api.RuleAction,
+	recorder *runRecorder,
+) error {
+	urlTemplate, err := template.New("url").Parse(action.HTTP.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url template %v for http action: %v", action.HTTP.URL, err)
+	}
+	timeout := defaultHTTPActionTimeout
+	if action.HTTP.TimeoutSeconds > 0 {
+		timeout = time.Duration(action.HTTP.TimeoutSeconds) * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+	method := action.HTTP.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	for _, pod := range podsForAction(pods, action) {
+		var url bytes.Buffer
+		target := httpActionTarget{
+			PodName:   pod.Name,
+			PodIP:     pod.Status.PodIP,
+			Namespace: pod.Namespace,
+		}
+		if err := urlTemplate.Execute(&url, target); err != nil {
+			return fmt.Errorf("error generating url for pod [%v] %v: %v", pod.Namespace, pod.Name, err)
+		}
+		steps := getRetrySteps(action, 1)
+		run := recorder.newRun(&pod, "", action.Type, method+" "+url.String(), false)
+		for attempt := 1; ; attempt++ {
+			var body string
+			body, err = sendHTTPRequest(ctx, client, method, url.String(), action.HTTP)
+			if run != nil {
+				run.Stdout = truncateOutput(body)
+			}
+			if err == nil {
+				recorder.finish(run, nil)
+				break
+			}
+			if attempt >= steps {
+				recorder.finish(run, err)
+				return err
+			}
+			logrus.Warnf("%v. Will retry.", err)
+			select {
+			case <-time.After(execPodCmdRetryInterval):
+			case <-ctx.Done():
+				recorder.finish(run, err)
+				return err
+			}
+		}
+		log.RuleLog(rule, owner).Infof("%v request to %v for pod [%v] %v succeeded", method, url.String(), pod.Namespace, pod.Name)
+	}
+	return nil
+}
+
+// sendHTTPRequest sends the request for an http action and returns the body
+// of the response
+func sendHTTPRequest(
+	ctx context.Context,
+	client *http.Client,
+	method string,
+	url string,
+	action *stork_api.RuleHTTPAction,
+) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(action.Body))
+	if err != nil {
+		return "", fmt.Errorf("error creating request for %v: %v", url, err)
+	}
+	for key, value := range action.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%v request to %v failed: %v", method, url, err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if err := resp.Body.Close(); err != nil {
+		logrus.Warnf("Error closing response body for %v: %v", url, err)
+	}
+	if (action.ExpectedStatus != 0 && resp.StatusCode != action.ExpectedStatus) ||
+		(action.ExpectedStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299)) {
+		return string(body), fmt.Errorf("%v request to %v returned unexpected status %v: %v",
+			method, url, resp.Status, truncateOutput(string(body)))
+	}
+	return string(body), nil
+}
+
+// executeScaleAction scales the Deployments and StatefulSets that own the pods
+// to 0 and waits for their pods to be terminated. The replicas are recorded in
+// the owner before scaling down so that they can be restored.
+func executeScaleAction(
+	ctx context.Context,
+	pods []v1.Pod,
+	rule *stork_api.Rule,
+	owner runtime.Object,
+	action stork_api.RuleAction,
+	taskID string,
+	recorder *runRecorder,
+) error {
+	tasks := make(map[string]*revertTask)
+	for _, pod := range podsForAction(pods, action) {
+		kind, name, err := getPodWorkload(&pod)
+		if err != nil {
+			return err
+		}
+		if kind == "" {
+			return fmt.Errorf("pod [%v] %v is not owned by a Deployment or StatefulSet", pod.Namespace, pod.Name)
+		}
+		tasks[kind+"/"+name] = &revertTask{
+			TaskID:     taskID,
+			Type:       stork_api.RuleActionScale,
+			Background: action.Background,
+			Namespace:  pod.Namespace,
+			Kind:       kind,
+			Name:       name,
+		}
+	}
+
+	for _, task := range tasks {
+		run := recorder.newRun(nil, "", action.Type, fmt.Sprintf("scale %v [%v] %v to 0", task.Kind, task.Namespace, task.Name), action.Background)
+		if run != nil {
+			run.Namespace = task.Namespace
+		}
+		err := scaleDownWorkload(ctx, owner, task)
+		recorder.finish(run, err)
+		if err != nil {
+			return err
+		}
+		log.RuleLog(rule, owner).Infof("Scaled down %v [%v] %v from %v replicas", task.Kind, task.Namespace, task.Name, task.Replicas)
+	}
+	return nil
+}
+
+// scaleDownWorkload records the replicas of the workload in the owner and
+// scales it down to 0
+func scaleDownWorkload(ctx context.Context, owner runtime.Object, task *revertTask) error {
+	switch task.Kind {
+	case "Deployment":
+		deployment, err := apps.Instance().GetDeployment(task.Name, task.Namespace)
+		if err != nil {
+			return err
+		}
+		task.Replicas = 1
+		if deployment.Spec.Replicas != nil {
+			task.Replicas = *deployment.Spec.Replicas
+		}
+		if err := addRevertTask(ctx, owner, task); err != nil {
+			return err
+		}
+		replicas := int32(0)
+		deployment.Spec.Replicas = &replicas
+		if deployment, err = apps.Instance().UpdateDeployment(deployment); err != nil {
+			return err
+		}
+		return waitForWorkloadTermination(ctx, task, func() ([]v1.Pod, error) {
+			return apps.Instance().GetDeploymentPods(deployment)
+		})
+	case "StatefulSet":
+		statefulSet, err := apps.Instance().GetStatefulSet(task.Name, task.Namespace)
+		if err != nil {
+			return err
+		}
+		task.Replicas = 1
+		if statefulSet.Spec.Replicas != nil {
+			task.Replicas = *statefulSet.Spec.Replicas
+		}
+		if err := addRevertTask(ctx, owner, task); err != nil {
+			return err
+		}
+		replicas := int32(0)
+		statefulSet.Spec.Replicas = &replicas
+		if statefulSet, err = apps.Instance().UpdateStatefulSet(statefulSet); err != nil {
+			return err
+		}
+		return waitForWorkloadTermination(ctx, task, func() ([]v1.Pod, error) {
+			return apps.Instance().GetStatefulSetPods(statefulSet)
+		})
+	}
+	return nil
+}
+
+// waitForWorkloadTermination waits till the workload that was scaled down
+// doesn't have any pods left. The wait is stopped if the context is done.
+func waitForWorkloadTermination(ctx context.Context, task *revertTask, getPods func() ([]v1.Pod, error)) error {
+	waitCtx, cancel := context.WithTimeout(ctx, scaleDownTimeout)
+	defer cancel()
+	var lastErr error
+	err := wait.PollImmediateUntil(scaleDownRetryInterval, func() (bool, error) {
+		pods, err := getPods()
+		if err != nil {
+			lastErr = fmt.Errorf("failed to get pods: %v", err)
+			return false, nil
+		}
+		if len(pods) > 0 {
+			lastErr = fmt.Errorf("%v pods are still present", len(pods))
+			return false, nil
+		}
+		return true, nil
+	}, waitCtx.Done())
+	if err != nil {
+		if lastErr != nil {
+			err = lastErr
+		}
+		return fmt.Errorf("%v [%v] %v was not terminated: %v", task.Kind, task.Namespace, task.Name, err)
+	}
+	return nil
+}
+
+// getPodWorkload returns the kind and name of the Deployment or StatefulSet
+// that owns the pod. Returns an empty kind if the pod isn't owned by either.
+func getPodWorkload(pod *v1.Pod) (string, string, error) {
+	for _, ownerRef := range pod.OwnerReferences {
+		switch ownerRef.Kind {
+		case "StatefulSet":
+			return ownerRef.Kind, ownerRef.Name, nil
+		case "ReplicaSet":
+			replicaSet, err := apps.Instance().GetReplicaSet(ownerRef.Name, pod.Namespace)
+			if err != nil {
+				return "", "", err
+			}
+			for _, rsOwnerRef := range replicaSet.OwnerReferences {
+				if rsOwnerRef.Kind == "Deployment" {
+					return rsOwnerRef.Kind, rsOwnerRef.Name, nil
+				}
+			}
+		}
+	}
+	return "", "", nil
+}
+
+// executeFsfreezeAction freezes the filesystem at the path in the action in
+// each of the pods. Each pod is recorded in the owner before it is frozen so
+// that it can be thawed.
+func executeFsfreezeAction(
+	ctx context.Context,
+	pods []v1.Pod,
+	container string,
+	rule *stork_api.Rule,
+	owner runtime.Object,
+	action stork_api.RuleAction,
+	taskID string,
+	recorder *runRecorder,
+) error {
+	for _, pod := range podsForAction(pods, action) {
+		task := &revertTask{
+			TaskID:     taskID,
+			Type:       stork_api.RuleActionFsfreeze,
+			Background: action.Background,
+			Namespace:  pod.Namespace,
+			Pod: &Pod{
+				UID:       string(pod.UID),
+				Namespace: pod.Namespace,
+			},
+			Container: container,
+			Path:      action.Value,
+		}
+		if err := addRevertTask(ctx, owner, task); err != nil {
+			return err
+		}
+		if _, err := runCommandOnPods(ctx, []v1.Pod{pod}, container, fmt.Sprintf("fsfreeze -f '%s'", action.Value), getRetrySteps(action, execPodStepLow), true, recorder, action.Type); err != nil {
+			return err
+		}
+		log.RuleLog(rule, owner).Infof("Froze %v in pod [%v] %v", action.Value, pod.Namespace, pod.Name)
+	}
+	return nil
+}
+
+// podsForAction returns the pods on which the action should be performed
+func podsForAction(pods []v1.Pod, action stork_api.RuleAction) []v1.Pod {
+	if action.RunInSinglePod && len(pods) > 0 {
+		return []v1.Pod{pods[0]}
+	}
+	return pods
+}
+
+// revertTasks reverts the actions recorded in the owner for which match
+// returns true. Actions that are reverted are removed from the owner, the
+// ones that fail are left so that they can be reverted during recovery. The
+// reverted actions are recorded if a recorder is passed in.
+func revertTasks(owner runtime.Object, match func(*revertTask) bool, recorder *runRecorder) error {
+	ownerCopy, err := dynamic.Instance().GetObject(owner)
+	if err != nil {
+		return err
+	}
+	tasks, err := getRevertTasks(ownerCopy)
+	if err != nil {
+		return err
+	}
+
+	reverted := make(map[string]bool)
+	var lastErr error
+	for _, task := range tasks {
+		if !match(task) {
+			continue
+		}
+		if err := revertAction(task, recorder); err != nil {
+			log.RuleLog(nil, owner).Warnf("Failed to %v: %v", task, err)
+			lastErr = err
+			continue
+		}
+		log.RuleLog(nil, owner).Infof("Reverted rule action: %v", task)
+		reverted[task.key()] = true
+	}
+	if len(reverted) > 0 {
+		if err := updateRevertTasks(context.Background(), owner, func(tasks []*revertTask) []*revertTask {
+			remaining := make([]*revertTask, 0)
+			for _, task := range tasks {
+				if !reverted[task.key()] {
+					remaining = append(remaining, task)
+				}
+			}
+			return remaining
+		}); err != nil {
+			return err
+		}
+	}
+	if lastErr != nil {
+		return fmt.Errorf("failed to revert rule actions: %v", lastErr)
+	}
+	return nil
+}
+
+func revertAction(task *revertTask, recorder *runRecorder) error {
+	switch task.Type {
+	case stork_api.RuleActionScale:
+		run := recorder.newRun(nil, "", task.Type, task.String(), false)
+		if run != nil {
+			run.Namespace = task.Namespace
+		}
+		err := scaleWorkload(task)
+		recorder.finish(run, err)
+		return err
+	case stork_api.RuleActionFsfreeze:
+		pod, err := core.Instance().GetPodByUID(types.UID(task.Pod.UID), task.Pod.Namespace)
+		if err != nil {
+			if err == errors.ErrPodsNotFound {
+				return nil
+			}
+			return err
+		}
+		_, err = runCommandOnPods(context.Background(), []v1.Pod{*pod}, task.Container, fmt.Sprintf("fsfreeze -u '%s'", task.Path), execPodStepLow, true, recorder, task.Type)
+		return err
+	}
+	return fmt.Errorf("unsupported action type %v to revert", task.Type)
+}
+
+// scaleWorkload restores the replicas of the workload that was scaled down
+func scaleWorkload(task *revertTask) error {
+	replicas := task.Replicas
+	switch task.Kind {
+	case "Deployment":
+		deployment, err := apps.Instance().GetDeployment(task.Name, task.Namespace)
+		if err != nil {
+			if k8s_errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		deployment.Spec.Replicas = &replicas
+		_, err = apps.Instance().UpdateDeployment(deployment)
+		return err
+	case "StatefulSet":
+		statefulSet, err := apps.Instance().GetStatefulSet(task.Name, task.Namespace)
+		if err != nil {
+			if k8s_errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		statefulSet.Spec.Replicas = &replicas
+		_, err = apps.Instance().UpdateStatefulSet(statefulSet)
+		return err
+	}
+	return fmt.Errorf("unsupported kind %v to scale", task.Kind)
+}
+
+func getRevertTasks(owner runtime.Object) ([]*revertTask, error) {
+	metadata, err := meta.Accessor(owner)
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]*revertTask, 0)
+	if value := metadata.GetAnnotations()[actionsToRevertKey]; len(value) > 0 {
+		if err := json.Unmarshal([]byte(value), &tasks); err != nil {
+			return nil, fmt.Errorf("failed to parse annotation to track rule actions to revert due to: %v", err)
+		}
+	}
+	return tasks, nil
+}
+
+// addRevertTask records an action that needs to be reverted in the owner
+func addRevertTask(ctx context.Context, owner runtime.Object, task *revertTask) error {
+	return updateRevertTasks(ctx, owner, func(tasks []*revertTask) []*revertTask {
+		return append(tasks, task)
+	})
+}
+
+// updateRevertTasks updates the actions to revert that are recorded in the
+// latest version of the owner. Retries are stopped if the context is done.
+func updateRevertTasks(ctx context.Context, owner runtime.Object, update func([]*revertTask) []*revertTask) error {
+	return wait.ExponentialBackoffWithContext(ctx, ownerAPICallBackoff, func() (bool, error) {
+		ownerCopy, err := dynamic.Instance().GetObject(owner)
+		if err != nil {
+			log.RuleLog(nil, owner).Warnf("Failed to get latest owner due to: %v. Will retry.", err)
+			return false, nil
+		}
+		tasks, err := getRevertTasks(ownerCopy)
+		if err != nil {
+			return false, err
+		}
+		tasks = update(tasks)
+
+		metadata, err := meta.Accessor(ownerCopy)
+		if err != nil {
+			return false, err
+		}
+		annotations := metadata.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		if len(tasks) == 0 {
+			delete(annotations, actionsToRevertKey)
+		} else {
+			tasksBytes, err := json.Marshal(tasks)
+			if err != nil {
+				return false, err
+			}
+			annotations[actionsToRevertKey] = string(tasksBytes)
+		}
+		metadata.SetAnnotations(annotations)
+		if _, err := dynamic.Instance().UpdateObject(ownerCopy); err != nil {
+			log.RuleLog(nil, owner).Warnf("Failed to update owner due to: %v. Will retry.", err)
+			return false, nil
+		}
+		return true, nil
+	})
+}
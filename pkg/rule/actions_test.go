@@ -0,0 +1,352 @@
+//go:build unittest
+// +build unittest
+
+package rule
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateAction(t *testing.T) {
+	negative := -1
+	tests := []struct {
+		name        string
+		action      stork_api.RuleAction
+		ruleType    Type
+		revertTypes map[stork_api.RuleActionType]bool
+		expectError string
+	}{
+		{
+			name:     "command",
+			action:   stork_api.RuleAction{Type: stork_api.RuleActionCommand, Value: "sync", Background: true},
+			ruleType: PreExecRule,
+		},
+		{
+			name:        "background post exec",
+			action:      stork_api.RuleAction{Type: stork_api.RuleActionCommand, Value: "sync", Background: true},
+			ruleType:    PostExecRule,
+			expectError: "background actions are not supported",
+		},
+		{
+			name:        "unsupported type",
+			action:      stork_api.RuleAction{Type: "unknown"},
+			ruleType:    PreExecRule,
+			expectError: "unsupported action type",
+		},
+		{
+			name: "http",
+			action: stork_api.RuleAction{
+				Type: stork_api.RuleActionHTTP,
+				HTTP: &stork_api.RuleHTTPAction{URL: "http://{{.PodIP}}:8080/flush"},
+			},
+			ruleType: PreExecRule,
+		},
+		{
+			name:        "http without url",
+			action:      stork_api.RuleAction{Type: stork_api.RuleActionHTTP},
+			ruleType:    PreExecRule,
+			expectError: "url is required",
+		},
+		{
+			name: "http with invalid url template",
+			action: stork_api.RuleAction{
+				Type: stork_api.RuleActionHTTP,
+				HTTP: &stork_api.RuleHTTPAction{URL: "http://{{.PodIP"},
+			},
+			ruleType:    PreExecRule,
+			expectError: "invalid url template",
+		},
+		{
+			name: "http in background",
+			action: stork_api.RuleAction{
+				Type:       stork_api.RuleActionHTTP,
+				HTTP:       &stork_api.RuleHTTPAction{URL: "http://{{.PodIP}}:8080/flush"},
+				Background: true,
+			},
+			ruleType:    PreExecRule,
+			expectError: "background is not supported for http actions",
+		},
+		{
+			name:     "fsfreeze",
+			action:   stork_api.RuleAction{Type: stork_api.RuleActionFsfreeze, Value: "/data"},
+			ruleType: PreExecRule,
+		},
+		{
+			name:        "fsfreeze with relative path",
+			action:      stork_api.RuleAction{Type: stork_api.RuleActionFsfreeze, Value: "data"},
+			ruleType:    PreExecRule,
+			expectError: "absolute path",
+		},
+		{
+			name:        "fsfreeze with quote",
+			action:      stork_api.RuleAction{Type: stork_api.RuleActionFsfreeze, Value: "/data'; rm -rf /'"},
+			ruleType:    PreExecRule,
+			expectError: "absolute path",
+		},
+		{
+			name:        "negative timeout",
+			action:      stork_api.RuleAction{Type: stork_api.RuleActionCommand, TimeoutSeconds: -1},
+			ruleType:    PreExecRule,
+			expectError: "timeoutSeconds can't be negative",
+		},
+		{
+			name:        "negative retries",
+			action:      stork_api.RuleAction{Type: stork_api.RuleActionCommand, Retries: &negative},
+			ruleType:    PreExecRule,
+			expectError: "retries can't be negative",
+		},
+		{
+			name:        "invalid failure policy",
+			action:      stork_api.RuleAction{Type: stork_api.RuleActionCommand, OnFailure: "Ignore"},
+			ruleType:    PreExecRule,
+			expectError: "invalid onFailure policy",
+		},
+		{
+			name:        "scale with matching post exec action",
+			action:      stork_api.RuleAction{Type: stork_api.RuleActionScale},
+			ruleType:    PreExecRule,
+			revertTypes: map[stork_api.RuleActionType]bool{stork_api.RuleActionScale: true},
+		},
+		{
+			name:        "scale without matching post exec action",
+			action:      stork_api.RuleAction{Type: stork_api.RuleActionScale},
+			ruleType:    PreExecRule,
+			revertTypes: map[stork_api.RuleActionType]bool{stork_api.RuleActionFsfreeze: true},
+			expectError: "need a scale action in the post exec rule",
+		},
+		{
+			name:        "fsfreeze without matching post exec action",
+			action:      stork_api.RuleAction{Type: stork_api.RuleActionFsfreeze, Value: "/data"},
+			ruleType:    PreExecRule,
+			revertTypes: map[stork_api.RuleActionType]bool{},
+			expectError: "need a fsfreeze action in the post exec rule",
+		},
+		{
+			name:        "post exec scale",
+			action:      stork_api.RuleAction{Type: stork_api.RuleActionScale},
+			ruleType:    PostExecRule,
+			revertTypes: map[stork_api.RuleActionType]bool{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateAction(&test.action, test.ruleType, test.revertTypes)
+			if test.expectError != "" {
+				require.Error(t, err, "Expected error validating action")
+				require.Contains(t, err.Error(), test.expectError, "Unexpected error validating action")
+				return
+			}
+			require.NoError(t, err, "Error validating action")
+		})
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	newRule := func(name string, actions ...stork_api.RuleAction) *stork_api.Rule {
+		return &stork_api.Rule{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Rules: []stork_api.RuleItem{
+				{
+					PodSelector: map[string]string{"app": "db"},
+					Actions:     actions,
+				},
+			},
+		}
+	}
+	scale := stork_api.RuleAction{Type: stork_api.RuleActionScale}
+	fsfreeze := stork_api.RuleAction{Type: stork_api.RuleActionFsfreeze, Value: "/data"}
+	command := stork_api.RuleAction{Type: stork_api.RuleActionCommand, Value: "sync"}
+
+	require.NoError(t, ValidateRules(nil, nil), "Error validating without rules")
+	require.NoError(t, ValidateRules(newRule("pre", command), nil), "Error validating pre exec rule with commands")
+	require.NoError(t, ValidateRules(newRule("pre", scale, fsfreeze), newRule("post", fsfreeze, scale)),
+		"Error validating rules with matching actions")
+	require.NoError(t, ValidateRules(nil, newRule("post", scale)), "Error validating post exec rule")
+
+	err := ValidateRules(newRule("pre", scale), nil)
+	require.Error(t, err, "Expected error for scale action without post exec rule")
+	require.Contains(t, err.Error(), "in rule: [ns] pre", "Error should have the rule")
+
+	err = ValidateRules(newRule("pre", scale, fsfreeze), newRule("post", scale, command))
+	require.Error(t, err, "Expected error for fsfreeze action without matching post exec action")
+	require.Contains(t, err.Error(), "fsfreeze", "Unexpected error for missing post exec action")
+
+	err = ValidateRules(newRule("pre", command), newRule("post", stork_api.RuleAction{Type: stork_api.RuleActionCommand, Background: true}))
+	require.Error(t, err, "Expected error for invalid post exec rule")
+
+	// The pre exec rule alone can't be checked for matching actions
+	require.NoError(t, ValidateRule(newRule("pre", scale), PreExecRule), "Error validating pre exec rule")
+}
+
+func TestGetItemOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		orders   []int
+		expected []int
+	}{
+		{
+			name:     "no items",
+			orders:   []int{},
+			expected: []int{},
+		},
+		{
+			name:     "no order",
+			orders:   []int{0, 0, 0},
+			expected: []int{0, 1, 2},
+		},
+		{
+			name:     "reversed",
+			orders:   []int{3, 2, 1},
+			expected: []int{2, 1, 0},
+		},
+		{
+			name:     "stable for same order",
+			orders:   []int{1, 0, 1, 0, -1},
+			expected: []int{4, 1, 3, 0, 2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule := &stork_api.Rule{}
+			for _, order := range test.orders {
+				rule.Rules = append(rule.Rules, stork_api.RuleItem{Order: order})
+			}
+			require.Equal(t, test.expected, getItemOrder(rule), "Unexpected order of items")
+		})
+	}
+}
+
+func TestSendHTTPRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/echo":
+			if r.Header.Get("X-Token") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(r.Method + " " + string(body)))
+		case "/accepted":
+			w.WriteHeader(http.StatusAccepted)
+		case "/large":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(strings.Repeat("x", 10*maxRuleRunOutput) + "end"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	action := &stork_api.RuleHTTPAction{
+		Headers: map[string]string{"X-Token": "secret"},
+		Body:    "flush",
+	}
+	body, err := sendHTTPRequest(context.Background(), server.Client(), http.MethodPost, server.URL+"/echo", action)
+	require.NoError(t, err, "Error sending request")
+	require.Equal(t, "POST flush", body, "Unexpected response body")
+
+	_, err = sendHTTPRequest(context.Background(), server.Client(), http.MethodGet, server.URL+"/echo", &stork_api.RuleHTTPAction{})
+	require.Error(t, err, "Expected error for unauthorized request")
+	require.Contains(t, err.Error(), "unexpected status 401", "Unexpected error for unauthorized request")
+
+	_, err = sendHTTPRequest(context.Background(), server.Client(), http.MethodGet, server.URL+"/accepted", &stork_api.RuleHTTPAction{})
+	require.NoError(t, err, "Any 2xx status should be accepted by default")
+
+	_, err = sendHTTPRequest(context.Background(), server.Client(), http.MethodGet, server.URL+"/accepted",
+		&stork_api.RuleHTTPAction{ExpectedStatus: http.StatusOK})
+	require.Error(t, err, "Expected error when the status doesn't match the expected status")
+
+	_, err = sendHTTPRequest(context.Background(), server.Client(), http.MethodGet, server.URL+"/missing",
+		&stork_api.RuleHTTPAction{ExpectedStatus: http.StatusNotFound})
+	require.NoError(t, err, "Expected status should be accepted")
+
+	body, err = sendHTTPRequest(context.Background(), server.Client(), http.MethodGet, server.URL+"/large", &stork_api.RuleHTTPAction{})
+	require.Error(t, err, "Expected error for failed request")
+	require.Len(t, body, 10*maxRuleRunOutput+len("end"), "Body should be returned in full")
+	require.Contains(t, err.Error(), "end", "End of the body should be in the error")
+	require.Less(t, len(err.Error()), 2*maxRuleRunOutput, "Body in the error should be truncated")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = sendHTTPRequest(ctx, server.Client(), http.MethodGet, server.URL+"/accepted", &stork_api.RuleHTTPAction{})
+	require.Error(t, err, "Expected error for cancelled request")
+}
+
+func TestExecuteHTTPAction(t *testing.T) {
+	requests := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if strings.HasPrefix(r.URL.Path, "/fail/") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	rule := &stork_api.Rule{ObjectMeta: metav1.ObjectMeta{Name: "rule", Namespace: "ns"}}
+	owner := &stork_api.ApplicationBackup{ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "ns"}}
+	pods := []v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "ns"},
+			Status:     v1.PodStatus{PodIP: "10.0.0.1"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-1", Namespace: "ns"},
+			Status:     v1.PodStatus{PodIP: "10.0.0.2"},
+		},
+	}
+
+	action := stork_api.RuleAction{
+		Type: stork_api.RuleActionHTTP,
+		HTTP: &stork_api.RuleHTTPAction{
+			URL:    server.URL + "/{{.Namespace}}/{{.PodName}}/{{.PodIP}}",
+			Method: http.MethodPut,
+		},
+	}
+	recorder := newRunRecorder(rule, PreExecRule, "ns")
+	err := executeHTTPAction(context.Background(), pods, rule, owner, action, recorder)
+	require.NoError(t, err, "Error executing http action")
+	require.Equal(t, []string{"PUT /ns/db-0/10.0.0.1", "PUT /ns/db-1/10.0.0.2"}, requests, "Unexpected requests")
+	runs := recorder.getRuns()
+	require.Len(t, runs, 2, "Expected a run for each pod")
+	for i, run := range runs {
+		require.Equal(t, stork_api.RuleRunStatusSuccessful, run.Status, "Run should be successful")
+		require.Equal(t, pods[i].Name, run.Pod, "Unexpected pod for run")
+		require.Equal(t, "ok", run.Stdout, "Unexpected output for run")
+	}
+
+	requests = requests[:0]
+	action.RunInSinglePod = true
+	action.HTTP.Method = ""
+	err = executeHTTPAction(context.Background(), pods, rule, owner, action, nil)
+	require.NoError(t, err, "Error executing http action in a single pod")
+	require.Equal(t, []string{"GET /ns/db-0/10.0.0.1"}, requests, "Request should be sent for a single pod")
+
+	requests = requests[:0]
+	retries := 0
+	action = stork_api.RuleAction{
+		Type:    stork_api.RuleActionHTTP,
+		HTTP:    &stork_api.RuleHTTPAction{URL: server.URL + "/fail/{{.PodName}}"},
+		Retries: &retries,
+	}
+	recorder = newRunRecorder(rule, PreExecRule, "ns")
+	err = executeHTTPAction(context.Background(), pods, rule, owner, action, recorder)
+	require.Error(t, err, "Expected error for failed http action")
+	require.Contains(t, err.Error(), "unexpected status 503", "Unexpected error for failed http action")
+	require.Equal(t, []string{"GET /fail/db-0"}, requests, "Action should stop at the first failed pod")
+	runs = recorder.getRuns()
+	require.Len(t, runs, 1, "Expected a run for the failed pod")
+	require.Equal(t, stork_api.RuleRunStatusFailed, runs[0].Status, "Run should have failed")
+	require.Equal(t, err.Error(), runs[0].Reason, "Unexpected reason for failed run")
+}
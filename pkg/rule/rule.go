@@ -1,6 +1,7 @@
 package rule
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -131,15 +132,32 @@ func Init() error {
 
 // ValidateRule validates a rule
 func ValidateRule(rule *stork_api.Rule, ruleType Type) error {
+	return validateRule(rule, ruleType, nil)
+}
+
+// ValidateRules validates the pre and post exec rules used together for an
+// operation. Either of them can be nil. Scale and fsfreeze actions in the pre
+// exec rule need an action of the same type in the post exec rule, otherwise
+// the workloads would be left scaled down or the filesystems frozen.
+func ValidateRules(preExecRule, postExecRule *stork_api.Rule) error {
+	if preExecRule != nil {
+		if err := validateRule(preExecRule, PreExecRule, getRevertTypes(postExecRule)); err != nil {
+			return err
+		}
+	}
+	if postExecRule != nil {
+		if err := validateRule(postExecRule, PostExecRule, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRule(rule *stork_api.Rule, ruleType Type, revertTypes map[stork_api.RuleActionType]bool) error {
 	for _, item := range rule.Rules {
 		for _, action := range item.Actions {
-			if action.Type == stork_api.RuleActionCommand {
-				if action.Background && ruleType == PostExecRule {
-					return fmt.Errorf("background actions are not supported for post exec rules")
-				}
-			} else {
-				return fmt.Errorf("unsupported action type: %s in rule: [%s] %s",
-					action.Type, rule.GetNamespace(), rule.GetName())
+			if err := validateAction(&action, ruleType, revertTypes); err != nil {
+				return fmt.Errorf("%v in rule: [%s] %s", err, rule.GetNamespace(), rule.GetName())
 			}
 		}
 	}
@@ -150,9 +168,9 @@ func ValidateRule(rule *stork_api.Rule, ruleType Type) error {
 // terminateCommandInPods terminates a previously running background command on given pods for given task ID
 func terminateCommandInPods(owner runtime.Object, pods []v1.Pod, container, taskID string) error {
 	killFile := fmt.Sprintf(cmdexecutor.KillFileFormat, taskID)
-	failedPods, err := runCommandOnPods(pods, container, fmt.Sprintf("touch %s", killFile), execPodStepsHigh, false)
+	failedPods, err := runCommandOnPods(context.Background(), pods, container, fmt.Sprintf("touch %s", killFile), execPodStepsHigh, false, nil, "")
 
-	updateErr := updateRunningCommandPodListInOwner(owner, failedPods, container, taskID)
+	updateErr := updateRunningCommandPodListInOwner(context.Background(), owner, failedPods, container, taskID)
 	if updateErr != nil {
 		log.RuleLog(nil, owner).Warnf("Failed to update list of pods with running command in owner due to: %v", updateErr)
 	}
@@ -161,10 +179,21 @@ func terminateCommandInPods(owner runtime.Object, pods []v1.Pod, container, task
 }
 
 // PerformRuleRecovery terminates potential background commands running pods for
-// the given owner
+// the given owner. Workloads that were scaled down and filesystems that were
+// frozen by the rules are also restored.
 func PerformRuleRecovery(
 	owner runtime.Object,
 ) error {
+	revertTaskList, err := getRevertTasks(owner)
+	if err != nil {
+		return err
+	}
+	if len(revertTaskList) > 0 {
+		if err := revertTasks(owner, func(*revertTask) bool { return true }, nil); err != nil {
+			return err
+		}
+	}
+
 	taskTracker, err := getPodsTrackerForOwner(owner)
 	if err != nil {
 		return err
@@ -227,33 +256,80 @@ func PerformRuleRecovery(
 }
 
 // ExecuteRule executes rules for the given owner. PVCs are used to figure out the pods on which the rule actions will be
-// run on
+// run on. Items are run in the order set in the rule. The result has the
+// records of the actions that were run and the failures of actions that were
+// ignored because of their OnFailure policy. It is returned even if the rule
+// fails.
 func ExecuteRule(
 	rule *stork_api.Rule,
 	rType Type,
 	owner runtime.Object,
 	podNamespace string,
-) (chan bool, error) {
+) (chan bool, *Result, error) {
+	return ExecuteRuleInNamespaces(rule, rType, owner, []string{podNamespace})
+}
+
+// ExecuteRuleInNamespaces executes rules for the given owner on the pods in
+// all the given namespaces as a single task. Each action is run on the pods
+// in all the namespaces before moving to the next one, and background
+// commands in all the namespaces are terminated together.
+func ExecuteRuleInNamespaces(
+	rule *stork_api.Rule,
+	rType Type,
+	owner runtime.Object,
+	podNamespaces []string,
+) (chan bool, *Result, error) {
+	failures := make([]*stork_api.RuleActionFailure, 0)
+	recorder := newRunRecorder(rule, rType, strings.Join(podNamespaces, ","))
+	result := func() *Result {
+		return &Result{
+			Runs:     recorder.getRuns(),
+			Failures: failures,
+		}
+	}
+
 	// Validate the rule. Don't depend on callers to invoke this
 	if err := ValidateRule(rule, rType); err != nil {
-		return nil, err
+		return nil, result(), err
 	}
 
 	log.RuleLog(rule, owner).Infof("Running %v", rType)
 	taskID, err := uuid.New()
 	if err != nil {
 		err = fmt.Errorf("failed to generate uuid for rule tasks due to: %v", err)
-		return nil, err
+		return nil, result(), err
 	}
 
-	pods := make([]v1.Pod, 0)
-	for _, item := range rule.Rules {
-		p, err := core.Instance().GetPods(podNamespace, item.PodSelector)
+	// Scale and fsfreeze actions in post exec rules revert the actions done
+	// by the pre exec rule in the namespace. The pods don't need to match
+	// since scaled down workloads don't have any
+	revertTypes := make(map[stork_api.RuleActionType]bool)
+	if rType == PostExecRule {
+		revertTypes = getRevertTypes(rule)
+	}
+	if len(revertTypes) > 0 {
+		namespaces := make(map[string]bool)
+		for _, ns := range podNamespaces {
+			namespaces[ns] = true
+		}
+		err := revertTasks(owner, func(task *revertTask) bool {
+			return revertTypes[task.Type] && namespaces[task.Namespace]
+		}, recorder)
 		if err != nil {
-			return nil, err
+			return nil, result(), err
 		}
+	}
 
-		pods = append(pods, p.Items...)
+	pods := make([]v1.Pod, 0)
+	for _, item := range rule.Rules {
+		for _, podNamespace := range podNamespaces {
+			p, err := core.Instance().GetPods(podNamespace, item.PodSelector)
+			if err != nil {
+				return nil, result(), err
+			}
+
+			pods = append(pods, p.Items...)
+		}
 	}
 
 	if len(pods) > 0 {
@@ -266,7 +342,8 @@ func ExecuteRule(
 
 		// backgroundActionPresent is used to track if there is atleast one background action
 		backgroundActionPresent := false
-		for _, item := range rule.Rules {
+		for _, itemIndex := range getItemOrder(rule) {
+			item := rule.Rules[itemIndex]
 			container = item.Container
 			filteredPods := make([]v1.Pod, 0)
 			// filter pods and only uses the ones that match this selector
@@ -281,48 +358,87 @@ func ExecuteRule(
 				continue
 			}
 
-			for _, action := range item.Actions {
+			for actionIndex, action := range item.Actions {
 				if action.Background {
 					backgroundActionPresent = true
 				}
 
-				if action.Type == stork_api.RuleActionCommand {
-					err := executeCommandAction(filteredPods, item.Container, rule, owner, action, backgroundPodListChan, rType, taskID)
-					if err != nil {
-						// if any action fails, terminate all background jobs and don't depend on caller
-						// to clean them up
-						if backgroundActionPresent {
-							backgroundCommandTermChan <- true
-							return nil, err
-						}
-
-						backgroundCommandTermChan <- false
-						return nil, err
+				if isRevertAction(&action, rType) {
+					continue
+				}
+				err := runWithTimeout(action, func(ctx context.Context) error {
+					switch action.Type {
+					case stork_api.RuleActionCommand:
+						return executeCommandAction(ctx, filteredPods, item.Container, rule, owner, action, backgroundPodListChan, rType, taskID, recorder)
+					case stork_api.RuleActionHTTP:
+						return executeHTTPAction(ctx, filteredPods, rule, owner, action, recorder)
+					case stork_api.RuleActionScale:
+						return executeScaleAction(ctx, filteredPods, rule, owner, action, taskID.String(), recorder)
+					case stork_api.RuleActionFsfreeze:
+						return executeFsfreezeAction(ctx, filteredPods, item.Container, rule, owner, action, taskID.String(), recorder)
+					}
+					return nil
+				})
+				if err != nil {
+					err = fmt.Errorf("%v action %v in rule item %v failed: %v", action.Type, actionIndex, itemIndex, err)
+					if action.OnFailure == stork_api.RuleActionFailurePolicyContinue {
+						log.RuleLog(rule, owner).Warnf("Continuing since failures are ignored for the action: %v", err)
+						failures = append(failures, &stork_api.RuleActionFailure{
+							Rule:      rule.Name,
+							Namespace: strings.Join(podNamespaces, ","),
+							Item:      itemIndex,
+							Action:    actionIndex,
+							Type:      action.Type,
+							OnFailure: action.OnFailure,
+							Reason:    err.Error(),
+							Timestamp: metav1.Now(),
+						})
+						continue
+					}
+					// if any action fails, revert the workloads that were scaled
+					// down and filesystems that were frozen
+					revertErr := revertTasks(owner, func(task *revertTask) bool {
+						return task.TaskID == taskID.String()
+					}, recorder)
+					if revertErr != nil {
+						log.RuleLog(rule, owner).Warnf("Failed to revert actions for failed rule: %v", revertErr)
+					}
+					// if any action fails, terminate all background jobs and don't depend on caller
+					// to clean them up
+					if backgroundActionPresent {
+						backgroundCommandTermChan <- true
+						return nil, result(), err
 					}
+
+					backgroundCommandTermChan <- false
+					return nil, result(), err
 				}
 			}
 		}
 
 		if backgroundActionPresent {
-			return backgroundCommandTermChan, nil
+			return backgroundCommandTermChan, result(), nil
 		}
 
 		backgroundCommandTermChan <- false
-		return nil, nil
+		return nil, result(), nil
 	}
 
-	return nil, nil
+	return nil, result(), nil
 }
 
-// executeCommandAction executes the command type action on given pods:
+// executeCommandAction executes the command type action on given pods. The
+// commands and waits are stopped when the context is done.
 func executeCommandAction(
+	ctx context.Context,
 	pods []v1.Pod,
 	container string,
 	rule *stork_api.Rule,
 	owner runtime.Object,
 	action stork_api.RuleAction,
 	backgroundPodNotifyChan chan v1.Pod,
-	rType Type, taskID *uuid.UUID) error {
+	rType Type, taskID *uuid.UUID,
+	recorder *runRecorder) error {
 	if len(pods) == 0 {
 		return nil
 	}
@@ -376,7 +492,11 @@ func executeCommandAction(
 
 	if action.Background {
 		for _, podToTerminate := range podsForAction {
-			backgroundPodNotifyChan <- podToTerminate
+			select {
+			case backgroundPodNotifyChan <- podToTerminate:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
 
 		// regardless of the outcome of running the background command, we first update the
@@ -413,17 +533,28 @@ func executeCommandAction(
 			podsForTrackerList = append(podsForTrackerList, pod)
 		}
 
-		updateErr := updateRunningCommandPodListInOwner(owner, podsForTrackerList, container, taskID.String())
+		updateErr := updateRunningCommandPodListInOwner(ctx, owner, podsForTrackerList, container, taskID.String())
 		if updateErr != nil {
 			log.RuleLog(rule, owner).Warnf("Failed to update list of pods with running command in owner due to: %v", updateErr)
 		}
 
-		err = runBackgroundCommandOnPods(podsForAction, container, action.Value, taskID.String(), cmdExecutorImage, cmdExecutorImageSecret)
+		timeout := int64(perPodCommandExecTimeout)
+		if action.TimeoutSeconds > 0 {
+			timeout = action.TimeoutSeconds
+		}
+		runs := make([]*stork_api.RuleRun, 0)
+		for i := range podsForAction {
+			runs = append(runs, recorder.newRun(&podsForAction[i], container, action.Type, action.Value, true))
+		}
+		err = runBackgroundCommandOnPods(ctx, podsForAction, container, action.Value, taskID.String(), cmdExecutorImage, cmdExecutorImageSecret, timeout)
+		for _, run := range runs {
+			recorder.finish(run, err)
+		}
 		if err != nil {
 			return err
 		}
 	} else {
-		_, err := runCommandOnPods(podsForAction, container, action.Value, execPodStepLow, true)
+		_, err := runCommandOnPods(ctx, podsForAction, container, action.Value, getRetrySteps(action, execPodStepLow), true, recorder, action.Type)
 		if err != nil {
 			return err
 		}
@@ -443,8 +574,9 @@ func podsToString(pods []v1.Pod) string {
 
 // updateRunningCommandPodListInOwner updates the owner annotation to track pods which might have a
 // running command. This allows recovery if we crash while running the commands. One can parse these annotations
-// to terminate the running commands
+// to terminate the running commands. Retries are stopped if the context is done.
 func updateRunningCommandPodListInOwner(
+	ctx context.Context,
 	owner runtime.Object,
 	pods []v1.Pod,
 	container string,
@@ -468,7 +600,7 @@ func updateRunningCommandPodListInOwner(
 		return fmt.Errorf("failed to update running command pod list in owner due to: %v", err)
 	}
 
-	err = wait.ExponentialBackoff(ownerAPICallBackoff, func() (bool, error) {
+	err = wait.ExponentialBackoffWithContext(ctx, ownerAPICallBackoff, func() (bool, error) {
 		ownerCopy, err := dynamic.Instance().GetObject(owner)
 		if err != nil {
 			log.RuleLog(nil, owner).Warnf("Failed to get latest owner due to: %v. Will retry.", err)
@@ -502,23 +634,40 @@ func updateRunningCommandPodListInOwner(
 	return err
 }
 
-// runCommandOnPods runs cmd on given pods. If failFast is true, it will return on the first failure. It will
-// return a list of pods that failed.
-func runCommandOnPods(pods []v1.Pod, container string, cmd string, numRetries int, failFast bool) ([]v1.Pod, error) {
+// runCommandOnPods runs cmd on given pods. If failFast is true, the commands on the other pods are stopped on the
+// first failure. It will return a list of pods that failed. The last attempt on each pod is recorded if a recorder
+// is passed in. The commands are stopped when the context is done, and all of them have returned by the time this
+// returns.
+func runCommandOnPods(
+	ctx context.Context,
+	pods []v1.Pod,
+	container string,
+	cmd string,
+	numRetries int,
+	failFast bool,
+	recorder *runRecorder,
+	actionType stork_api.RuleActionType,
+) ([]v1.Pod, error) {
 	var wg sync.WaitGroup
 	backOff := wait.Backoff{
 		Duration: execPodCmdRetryInterval,
 		Factor:   execPodCmdRetryFactor,
 		Steps:    numRetries,
 	}
-	errChannel := make(chan podErrorResponse)
-	finished := make(chan bool, 1)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errChannel := make(chan podErrorResponse, len(pods))
 
 	for _, pod := range pods {
 		wg.Add(1)
 		go func(pod v1.Pod, errRespChan chan podErrorResponse) {
 			defer wg.Done()
-			err := wait.ExponentialBackoff(backOff, func() (bool, error) {
+			run := recorder.newRun(&pod, container, actionType, cmd, false)
+			var lastErr error
+			err := wait.ExponentialBackoffWithContext(ctx, backOff, func() (bool, error) {
+				if run != nil {
+					run.StartTimestamp = metav1.Now()
+				}
 				ns, name := pod.GetNamespace(), pod.GetName()
 				_, err := core.Instance().GetPodByUID(pod.GetUID(), ns)
 				if err != nil {
@@ -554,54 +703,59 @@ func runCommandOnPods(pods []v1.Pod, container string, cmd string, numRetries in
 					return false, nil
 				}
 
-				_, err = core.Instance().RunCommandInPod([]string{"sh", "-c", cmd}, name, container, ns)
+				output, err := execCommandInPod(ctx, []string{"sh", "-c", cmd}, name, container, ns)
+				output.setOutput(run)
 				if err != nil {
 					logrus.Warnf("Failed to run command: %s on pod: [%s] %s due to: %v", cmd, ns, name, err)
+					lastErr = err
 					return false, nil
 				}
+				lastErr = nil
 
 				logrus.Infof("Command: %s succeeded on pod: [%s] %s", cmd, ns, name)
 				return true, nil
 			})
+			if err != nil && lastErr != nil {
+				recorder.finish(run, lastErr)
+			} else {
+				recorder.finish(run, err)
+			}
 			if err != nil {
+				if lastErr != nil {
+					err = lastErr
+				}
 				errChannel <- podErrorResponse{
 					Pod: pod,
 					err: err,
 				}
+				if failFast {
+					cancel()
+				}
 			}
 		}(pod, errChannel)
 	}
 
-	// Put the wait group in a go routine.
-	// By putting the wait group in the go routine we ensure either all pass
-	// and we close the "finished" channel or we wait forever for the wait group
-	// to finish.
-	//
-	// Waiting forever is okay because of the blocking select below.
-	go func() {
-		wg.Wait()
-		close(finished)
-	}()
+	wg.Wait()
+	close(errChannel)
 
 	failed := make([]v1.Pod, 0)
-	select {
-	case <-finished:
-		if len(failed) > 0 {
-			err := fmt.Errorf("command: %s failed on pods: %s", cmd, podsToString(failed))
-			return failed, err
+	var firstErr *podErrorResponse
+	for errResp := range errChannel {
+		failed = append(failed, errResp.Pod)
+		if firstErr == nil {
+			errResp := errResp
+			firstErr = &errResp
 		}
-
+	}
+	if firstErr == nil {
 		logrus.Infof("Command: %s finished successfully on all pods", cmd)
 		return nil, nil
-	case errResp := <-errChannel:
-		failed = append(failed, errResp.Pod) // TODO also accumulate atleast the last error
-		if failFast {
-			return failed, fmt.Errorf("command: %s failed in pod: [%s] %s due to: %s",
-				cmd, errResp.Pod.GetNamespace(), errResp.Pod.GetName(), errResp.err)
-		}
 	}
-
-	return nil, nil
+	if failFast {
+		return failed, fmt.Errorf("command: %s failed in pod: [%s] %s due to: %s",
+			cmd, firstErr.Pod.GetNamespace(), firstErr.Pod.GetName(), firstErr.err)
+	}
+	return failed, fmt.Errorf("command: %s failed on pods: %s", cmd, podsToString(failed))
 }
 
 // ToImagePullSecret converts a secret name to the ImagePullSecret struct.
@@ -618,11 +772,17 @@ func ToImagePullSecret(name string) []v1.LocalObjectReference {
 }
 
 // runBackgroundCommandOnPods will start the given "cmd" on all the given "pods". The taskID is given to
-// the executor pod so it can have unique status files in the target pods where it runs the actual commands
-func runBackgroundCommandOnPods(pods []v1.Pod, container, cmd, taskID, cmdExecutorImage, cmdExecutorImageSecret string) error {
+// the executor pod so it can have unique status files in the target pods where it runs the actual commands, and
+// waits for the executor pod to finish the commands. The wait is stopped when the context is done.
+func runBackgroundCommandOnPods(
+	ctx context.Context,
+	pods []v1.Pod,
+	container, cmd, taskID, cmdExecutorImage, cmdExecutorImageSecret string,
+	timeout int64,
+) error {
 	executorArgs := []string{
 		"/cmdexecutor",
-		"-timeout", strconv.FormatInt(perPodCommandExecTimeout, 10),
+		"-timeout", strconv.FormatInt(timeout, 10),
 		"-cmd", cmd,
 		"-taskid", taskID,
 	}
@@ -700,12 +860,23 @@ func runBackgroundCommandOnPods(pods []v1.Pod, container, cmd, taskID, cmdExecut
 				logrus.Errorf("%v", err)
 				return err
 			}
-			time.Sleep(retrySleep)
+			select {
+			case <-time.After(retrySleep):
+			case <-ctx.Done():
+				return fmt.Errorf("stopped waiting for rule command executor to start: %v", ctx.Err())
+			}
 			continue
 		}
 	}
 
-	err = waitForExecPodCompletion(createdPod)
+	// Wait for the command executor to report the status of the task. The
+	// commands run for at most the timeout after the pod has started.
+	err = cmdexecutor.WaitForTask(ctx, taskID, createdPod, time.Duration(timeout)*time.Second+maxRetry*retrySleep)
+	if err == cmdexecutor.ErrTaskWatchClosed {
+		logrus.Warnf("Failed to watch command executor task, falling back to polling pod: [%s] %s",
+			createdPod.GetNamespace(), createdPod.GetName())
+		err = waitForExecPodCompletion(ctx, createdPod)
+	}
 	if err != nil {
 		// Since the command executor failed, fetch it's status using the pod's name as the key. The fetched status
 		// will have more details on why it failed (for e.g what commands failed to run and why)
@@ -722,10 +893,10 @@ func runBackgroundCommandOnPods(pods []v1.Pod, container, cmd, taskID, cmdExecut
 	return nil
 }
 
-// waitForExecPodCompletion waits until the pod has completed (success or failure)
-func waitForExecPodCompletion(pod *v1.Pod) error {
+// waitForExecPodCompletion waits until the pod has completed (success or failure) or the context is done
+func waitForExecPodCompletion(ctx context.Context, pod *v1.Pod) error {
 	logrus.Infof("Waiting for pod: [%s] %s readiness with backoff: %v", pod.GetNamespace(), pod.GetName(), execCmdBackoff)
-	return wait.ExponentialBackoff(execCmdBackoff, func() (bool, error) {
+	return wait.ExponentialBackoffWithContext(ctx, execCmdBackoff, func() (bool, error) {
 		p, err := core.Instance().GetPodByUID(pod.GetUID(), pod.GetNamespace())
 		if err != nil {
 			return false, nil
@@ -748,7 +919,7 @@ func waitForExecPodCompletion(pod *v1.Pod) error {
 
 // cmdTerminationWatcher accumulates pods supplied to the given podListChan and when
 // the terminationSignalChan is sent a true signal, it terminates commands on the accumulated
-// pods
+// pods and reverts the background scale and fsfreeze actions
 func cmdTerminationWatcher(
 	podListChan chan v1.Pod,
 	container *string,
@@ -771,6 +942,12 @@ func cmdTerminationWatcher(
 				if err := terminateCommandInPods(owner, podList, *container, id); err != nil {
 					log.RuleLog(nil, owner).Warnf("failed to terminate background command in pods due to: %v", err)
 				}
+				err := revertTasks(owner, func(task *revertTask) bool {
+					return task.TaskID == id && task.Background
+				}, nil)
+				if err != nil {
+					log.RuleLog(nil, owner).Warnf("failed to revert background actions due to: %v", err)
+				}
 			}
 			return
 		}
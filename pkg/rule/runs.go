@@ -0,0 +1,189 @@
+package rule
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/k8sutils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/exec"
+)
+
+const (
+	// maxRuleRunOutput is the number of bytes of stdout and stderr of a
+	// command that are recorded
+	maxRuleRunOutput = 512
+	// maxRuleRunReason is the number of bytes of the error that are recorded
+	// as the reason of a failed run
+	maxRuleRunReason = 2048
+	// maxRuleRuns is the number of runs that are kept in the status of an
+	// owner. Older runs are dropped first
+	maxRuleRuns = 100
+)
+
+// Result is the result of executing a rule
+type Result struct {
+	// Runs are the records of the actions run on each pod
+	Runs []*stork_api.RuleRun
+	// Failures are the failures of actions that were ignored because of
+	// their OnFailure policy
+	Failures []*stork_api.RuleActionFailure
+}
+
+// AppendRuleRuns appends the runs to the ones recorded in the status of an
+// owner, keeping at most the last maxRuleRuns runs
+func AppendRuleRuns(existing []*stork_api.RuleRun, result *Result) []*stork_api.RuleRun {
+	if result == nil {
+		return existing
+	}
+	runs := append(existing, result.Runs...)
+	if len(runs) > maxRuleRuns {
+		runs = runs[len(runs)-maxRuleRuns:]
+	}
+	return runs
+}
+
+// runRecorder collects the records of the actions run by a rule. Actions can
+// be run on multiple pods in parallel so the records are protected by a lock.
+type runRecorder struct {
+	sync.Mutex
+	rule      *stork_api.Rule
+	rType     Type
+	namespace string
+	runs      []*stork_api.RuleRun
+}
+
+func newRunRecorder(rule *stork_api.Rule, rType Type, namespace string) *runRecorder {
+	return &runRecorder{
+		rule:      rule,
+		rType:     rType,
+		namespace: namespace,
+		runs:      make([]*stork_api.RuleRun, 0),
+	}
+}
+
+// newRun returns a run for the action that has been started on the pod. The
+// run is recorded once it is finished.
+func (r *runRecorder) newRun(
+	pod *v1.Pod,
+	container string,
+	actionType stork_api.RuleActionType,
+	command string,
+	background bool,
+) *stork_api.RuleRun {
+	if r == nil {
+		return nil
+	}
+	run := &stork_api.RuleRun{
+		Rule:           r.rule.Name,
+		RuleType:       string(r.rType),
+		Namespace:      r.namespace,
+		Container:      container,
+		Action:         actionType,
+		Command:        command,
+		Background:     background,
+		StartTimestamp: metav1.Now(),
+	}
+	if pod != nil {
+		run.Namespace = pod.Namespace
+		run.Pod = pod.Name
+	}
+	return run
+}
+
+// finish records the run with the status based on the error
+func (r *runRecorder) finish(run *stork_api.RuleRun, err error) {
+	if r == nil || run == nil {
+		return
+	}
+	run.FinishTimestamp = metav1.Now()
+	if err != nil {
+		run.Status = stork_api.RuleRunStatusFailed
+		run.Reason = truncateReason(err.Error())
+	} else {
+		run.Status = stork_api.RuleRunStatusSuccessful
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.runs = append(r.runs, run)
+}
+
+// getRuns returns the runs recorded so far
+func (r *runRecorder) getRuns() []*stork_api.RuleRun {
+	r.Lock()
+	defer r.Unlock()
+	runs := make([]*stork_api.RuleRun, len(r.runs))
+	copy(runs, r.runs)
+	return runs
+}
+
+// commandOutput is the output of a command run in a pod
+type commandOutput struct {
+	stdout   string
+	stderr   string
+	exitCode *int
+}
+
+// setOutput sets the truncated output of the command in the run
+func (o *commandOutput) setOutput(run *stork_api.RuleRun) {
+	if run == nil || o == nil {
+		return
+	}
+	run.Stdout = truncateOutput(o.stdout)
+	run.Stderr = truncateOutput(o.stderr)
+	run.ExitCode = o.exitCode
+}
+
+// truncateOutput returns the end of the output since that usually has the
+// reason for a failure
+func truncateOutput(output string) string {
+	if len(output) <= maxRuleRunOutput {
+		return output
+	}
+	return "..." + output[len(output)-maxRuleRunOutput:]
+}
+
+// truncateReason returns the start of the reason since that has the context
+// of the failure
+func truncateReason(reason string) string {
+	if len(reason) <= maxRuleRunReason {
+		return reason
+	}
+	return reason[:maxRuleRunReason] + "..."
+}
+
+// commandError returns the error for a command that failed with the
+// truncated output of the command
+func commandError(err error, output *commandOutput) error {
+	return fmt.Errorf("could not execute: %v: %v %v", err, truncateOutput(output.stderr), truncateOutput(output.stdout))
+}
+
+// execCommandInPod runs the command in the container of the pod. Unlike
+// RunCommandInPod the stdout, stderr and exit code of the command are
+// returned separately so that they can be recorded. The exec is stopped when
+// the context is done.
+func execCommandInPod(ctx context.Context, cmd []string, podName, container, namespace string) (*commandOutput, error) {
+	var stdout, stderr bytes.Buffer
+	err := k8sutils.ExecInPodWithContext(ctx, cmd, podName, container, namespace, &stdout, &stderr)
+	output := &commandOutput{
+		stdout: stdout.String(),
+		stderr: stderr.String(),
+	}
+	var exitErr exec.CodeExitError
+	if errors.As(err, &exitErr) {
+		exitCode := exitErr.Code
+		output.exitCode = &exitCode
+	} else if err == nil {
+		exitCode := 0
+		output.exitCode = &exitCode
+	}
+	if err != nil {
+		return output, commandError(err, output)
+	}
+	return output, nil
+}
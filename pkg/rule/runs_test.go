@@ -0,0 +1,63 @@
+//go:build unittest
+// +build unittest
+
+package rule
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/exec"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	require.Equal(t, "output", truncateOutput("output"), "Short output should not be truncated")
+
+	output := strings.Repeat("a", maxRuleRunOutput) + "end"
+	truncated := truncateOutput(output)
+	require.Len(t, truncated, maxRuleRunOutput+len("..."), "Unexpected length of truncated output")
+	require.True(t, strings.HasPrefix(truncated, "..."), "Truncated output should be marked")
+	require.True(t, strings.HasSuffix(truncated, "end"), "End of the output should be kept")
+}
+
+func TestFailedCommandWithLargeOutput(t *testing.T) {
+	output := &commandOutput{
+		stdout: strings.Repeat("o", 10*maxRuleRunOutput) + "stdout-end",
+		stderr: strings.Repeat("e", 10*maxRuleRunOutput) + "stderr-end",
+	}
+	exitCode := 1
+	output.exitCode = &exitCode
+	err := commandError(exec.CodeExitError{Err: fmt.Errorf("command terminated with exit code 1"), Code: 1}, output)
+	require.Error(t, err, "Expected error for failed command")
+	require.True(t, strings.HasPrefix(err.Error(), "could not execute: command terminated with exit code 1"),
+		"Unexpected error for failed command: %v", err)
+	require.Contains(t, err.Error(), "stdout-end", "End of stdout should be in the error")
+	require.Contains(t, err.Error(), "stderr-end", "End of stderr should be in the error")
+	require.LessOrEqual(t, len(err.Error()), 3*maxRuleRunOutput, "Output in the error should be truncated")
+
+	rule := &stork_api.Rule{ObjectMeta: metav1.ObjectMeta{Name: "rule", Namespace: "ns"}}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "ns"}}
+	recorder := newRunRecorder(rule, PreExecRule, "ns")
+	run := recorder.newRun(pod, "container", stork_api.RuleActionCommand, "cmd", false)
+	output.setOutput(run)
+	recorder.finish(run, err)
+
+	runs := recorder.getRuns()
+	require.Len(t, runs, 1, "Expected one run to be recorded")
+	require.Equal(t, stork_api.RuleRunStatusFailed, runs[0].Status, "Run should have failed")
+	require.Equal(t, err.Error(), runs[0].Reason, "Unexpected reason for failed run")
+	require.Len(t, runs[0].Stdout, maxRuleRunOutput+len("..."), "Stdout should be truncated")
+	require.Len(t, runs[0].Stderr, maxRuleRunOutput+len("..."), "Stderr should be truncated")
+	require.Equal(t, 1, *runs[0].ExitCode, "Unexpected exit code")
+
+	run = recorder.newRun(pod, "container", stork_api.RuleActionCommand, "cmd", false)
+	recorder.finish(run, fmt.Errorf("%v", strings.Repeat("r", 2*maxRuleRunReason)))
+	runs = recorder.getRuns()
+	require.Len(t, runs, 2, "Expected two runs to be recorded")
+	require.Len(t, runs[1].Reason, maxRuleRunReason+len("..."), "Reason should be truncated")
+}
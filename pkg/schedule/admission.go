@@ -0,0 +1,264 @@
+package schedule
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// queueEntryExpiry is how long an entry stays in the trigger queue without
+// the schedule checking in again. Schedules are reconciled every few seconds
+// so this only drops entries for schedules that stopped asking for a trigger.
+const queueEntryExpiry = 5 * time.Minute
+
+// triggerQueue limits the number of scheduled operations that run at the
+// same time across all the schedules in the cluster. Schedules that are due
+// while the limit is reached wait in the queue and are admitted in the order
+// they were queued.
+type triggerQueue struct {
+	sync.Mutex
+	maxConcurrency int
+	jitter         time.Duration
+	// running is the number of operations running for each schedule
+	running map[string]int
+	pending map[string]*queuedTrigger
+}
+
+type queuedTrigger struct {
+	queuedAt time.Time
+	// readyAt is when the jitter delay for the trigger ends
+	readyAt  time.Time
+	lastSeen time.Time
+}
+
+var queue = &triggerQueue{
+	running: make(map[string]int),
+	pending: make(map[string]*queuedTrigger),
+}
+
+// SetTriggerLimits sets the maximum number of scheduled operations that can
+// run at the same time and the maximum random delay added before starting
+// each of them. A maxConcurrency of 0 doesn't limit the number of operations.
+func SetTriggerLimits(maxConcurrency int, jitter time.Duration) {
+	queue.Lock()
+	defer queue.Unlock()
+	queue.maxConcurrency = maxConcurrency
+	queue.jitter = jitter
+}
+
+// GetQueueKey returns the key used to track a schedule in the trigger queue
+func GetQueueKey(kind string, namespace string, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// UpdateRunning records the number of operations triggered by a schedule that
+// are still running
+func UpdateRunning(key string, running int) {
+	queue.Lock()
+	defer queue.Unlock()
+	if running <= 0 {
+		delete(queue.running, key)
+		return
+	}
+	queue.running[key] = running
+}
+
+// RemoveFromQueue removes a schedule from the trigger queue. Should be called
+// when a schedule no longer needs a trigger, or is deleted.
+func RemoveFromQueue(key string) {
+	queue.Lock()
+	defer queue.Unlock()
+	delete(queue.pending, key)
+}
+
+// DeleteFromQueue removes all the state for a schedule that was deleted
+func DeleteFromQueue(key string) {
+	queue.Lock()
+	defer queue.Unlock()
+	delete(queue.pending, key)
+	delete(queue.running, key)
+}
+
+// admit checks if the schedule can start an operation now. If it can, the
+// operation is counted as running until the schedule updates it. Otherwise
+// the schedule stays queued and the reason is returned.
+func (q *triggerQueue) admit(key string, now time.Time) (bool, string) {
+	q.Lock()
+	defer q.Unlock()
+	if q.maxConcurrency <= 0 && q.jitter <= 0 {
+		q.running[key]++
+		return true, ""
+	}
+
+	for k, entry := range q.pending {
+		if now.Sub(entry.lastSeen) > queueEntryExpiry {
+			delete(q.pending, k)
+		}
+	}
+	entry, present := q.pending[key]
+	if !present {
+		entry = &queuedTrigger{
+			queuedAt: now,
+			readyAt:  now,
+		}
+		if q.jitter > 0 {
+			entry.readyAt = now.Add(time.Duration(rand.Int63n(int64(q.jitter))))
+		}
+		q.pending[key] = entry
+	}
+	entry.lastSeen = now
+	if now.Before(entry.readyAt) {
+		return false, fmt.Sprintf("Waiting for jitter delay until %v", entry.readyAt.Format(time.RFC3339))
+	}
+
+	if q.maxConcurrency > 0 {
+		running := 0
+		for _, count := range q.running {
+			running += count
+		}
+		// Entries that are ready and were queued earlier go first
+		ahead := make([]string, 0)
+		for k, e := range q.pending {
+			if k == key || now.Before(e.readyAt) {
+				continue
+			}
+			if e.queuedAt.Before(entry.queuedAt) || (e.queuedAt.Equal(entry.queuedAt) && k < key) {
+				ahead = append(ahead, k)
+			}
+		}
+		sort.Strings(ahead)
+		if running+len(ahead) >= q.maxConcurrency {
+			return false, fmt.Sprintf("Waiting for one of %v running scheduled operations to complete, position %v in queue",
+				running, len(ahead)+1)
+		}
+	}
+	delete(q.pending, key)
+	q.running[key]++
+	return true, ""
+}
+
+// AdmitTrigger checks if a trigger that is due for a schedule can be started
+// now. Triggers are deferred while a blackout window of the policy is
+// active. If queueKey is set the trigger also goes through the queue for
+// scheduled operations. Returns nil if the trigger can be started, otherwise
+// returns the pending status for the schedule. The time since when the
+// trigger has been pending is carried over from the current status.
+func AdmitTrigger(
+	policyName string,
+	namespace string,
+	policyType stork_api.SchedulePolicyType,
+	queueKey string,
+	current *stork_api.SchedulePendingStatus,
+) (*stork_api.SchedulePendingStatus, error) {
+	schedulePolicy, err := getSchedulePolicy(policyName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	location, err := schedulePolicy.Policy.GetLocation()
+	if err != nil {
+		return nil, err
+	}
+	now := GetCurrentTime().In(location)
+
+	pending := &stork_api.SchedulePendingStatus{
+		PolicyType:   policyType,
+		PendingSince: meta.NewTime(now),
+	}
+	if current != nil && current.PolicyType == policyType {
+		pending.PendingSince = current.PendingSince
+	}
+
+	end, err := getBlackoutWindowEnd(schedulePolicy.Policy.BlackoutWindows, now)
+	if err != nil {
+		return nil, err
+	}
+	if !end.IsZero() {
+		if queueKey != "" {
+			RemoveFromQueue(queueKey)
+		}
+		pending.Reason = stork_api.SchedulePendingReasonBlackoutWindow
+		pending.Message = fmt.Sprintf("Deferred until the blackout window ends at %v", end.Format(time.RFC3339))
+		return pending, nil
+	}
+
+	if queueKey != "" {
+		if admitted, message := queue.admit(queueKey, GetCurrentTime()); !admitted {
+			pending.Reason = stork_api.SchedulePendingReasonQueued
+			pending.Message = message
+			return pending, nil
+		}
+	}
+	return nil, nil
+}
+
+// getBlackoutWindowEnd returns the time at which the blackout windows that t
+// falls in end. Windows that overlap or are back to back are treated as one.
+// Returns the zero time if t isn't in any window.
+func getBlackoutWindowEnd(windows []stork_api.BlackoutWindow, t time.Time) (time.Time, error) {
+	var end time.Time
+	current := t
+	// Bound the number of windows that are chained together, a full week
+	// can't need more than one per window and day
+	for i := 0; i <= len(windows)*7; i++ {
+		windowEnd, err := getActiveWindowEnd(windows, current)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if windowEnd.IsZero() {
+			break
+		}
+		end = windowEnd
+		current = windowEnd
+	}
+	return end, nil
+}
+
+// getActiveWindowEnd returns the latest end of the windows that are active at
+// t, or the zero time if none are active
+func getActiveWindowEnd(windows []stork_api.BlackoutWindow, t time.Time) (time.Time, error) {
+	var end time.Time
+	for i := range windows {
+		window := &windows[i]
+		startHour, startMinute, err := window.GetStartHourMinute()
+		if err != nil {
+			return time.Time{}, err
+		}
+		endHour, endMinute, err := window.GetEndHourMinute()
+		if err != nil {
+			return time.Time{}, err
+		}
+		// A window that started the previous day can still be active
+		for _, offset := range []int{-1, 0} {
+			day := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, t.Location())
+			if !windowStartsOn(window, day.Weekday()) {
+				continue
+			}
+			windowStart := time.Date(day.Year(), day.Month(), day.Day(), startHour, startMinute, 0, 0, t.Location())
+			windowEnd := time.Date(day.Year(), day.Month(), day.Day(), endHour, endMinute, 0, 0, t.Location())
+			if !windowEnd.After(windowStart) {
+				windowEnd = time.Date(day.Year(), day.Month(), day.Day()+1, endHour, endMinute, 0, 0, t.Location())
+			}
+			if !t.Before(windowStart) && t.Before(windowEnd) && windowEnd.After(end) {
+				end = windowEnd
+			}
+		}
+	}
+	return end, nil
+}
+
+func windowStartsOn(window *stork_api.BlackoutWindow, weekday time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+	for _, day := range window.Days {
+		if stork_api.Days[day] == weekday {
+			return true
+		}
+	}
+	return false
+}
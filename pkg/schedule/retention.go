@@ -0,0 +1,271 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	snapv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// RetentionTier is a period for which objects are retained with GFS retention
+type RetentionTier string
+
+const (
+	// RetentionTierLatest retains the latest successful object
+	RetentionTierLatest RetentionTier = "Latest"
+	// RetentionTierHourly retains the latest successful object every hour
+	RetentionTierHourly RetentionTier = "Hourly"
+	// RetentionTierDaily retains the latest successful object every day
+	RetentionTierDaily RetentionTier = "Daily"
+	// RetentionTierWeekly retains the latest successful object every week
+	RetentionTierWeekly RetentionTier = "Weekly"
+	// RetentionTierMonthly retains the latest successful object every month
+	RetentionTierMonthly RetentionTier = "Monthly"
+	// RetentionTierYearly retains the latest successful object every year
+	RetentionTierYearly RetentionTier = "Yearly"
+)
+
+// RetentionItem is an object triggered by a schedule that is considered for
+// pruning
+type RetentionItem struct {
+	Name              string
+	PolicyType        stork_api.SchedulePolicyType
+	CreationTimestamp time.Time
+	// Complete is set if the object has either succeeded or failed. Objects
+	// that aren't complete are never pruned
+	Complete   bool
+	Successful bool
+}
+
+// RetentionResult is the result of applying the retention to an item
+type RetentionResult struct {
+	RetentionItem
+	// Tiers are the periods for which the item is retained
+	Tiers []RetentionTier
+	Prune bool
+}
+
+type retentionTier struct {
+	tier   RetentionTier
+	count  int
+	period func(t time.Time) string
+}
+
+// GetGFSRetention returns the GFS retention configured for the policy and the
+// location it should be evaluated in. Returns nil if the policy doesn't use
+// GFS retention.
+func GetGFSRetention(policyName string, namespace string) (*stork_api.GFSRetention, *time.Location, error) {
+	schedulePolicy, err := getSchedulePolicy(policyName, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	if schedulePolicy.Policy.Retention == nil {
+		return nil, nil, nil
+	}
+	if err := schedulePolicy.Policy.Retention.Validate(); err != nil {
+		return nil, nil, err
+	}
+	location, err := schedulePolicy.Policy.GetLocation()
+	if err != nil {
+		return nil, nil, err
+	}
+	return schedulePolicy.Policy.Retention, location, nil
+}
+
+// ApplyGFSRetention decides which of the items should be pruned. Items are
+// considered together irrespective of the policy type that triggered them.
+// For every tier the latest successful item in each of the most recent
+// periods is retained. The latest successful item, failed items after it and
+// items that aren't complete are always retained. Results are returned
+// newest first.
+func ApplyGFSRetention(
+	retention *stork_api.GFSRetention,
+	location *time.Location,
+	items []RetentionItem,
+) []RetentionResult {
+	tiers := []retentionTier{
+		{RetentionTierHourly, retention.Hourly, func(t time.Time) string { return t.Format("2006-01-02T15") }},
+		{RetentionTierDaily, retention.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{RetentionTierWeekly, retention.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%02d", year, week)
+		}},
+		{RetentionTierMonthly, retention.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+		{RetentionTierYearly, retention.Yearly, func(t time.Time) string { return t.Format("2006") }},
+	}
+
+	results := make([]RetentionResult, len(items))
+	for i, item := range items {
+		results[i] = RetentionResult{RetentionItem: item}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].CreationTimestamp.After(results[j].CreationTimestamp)
+	})
+
+	for _, tier := range tiers {
+		if tier.count <= 0 {
+			continue
+		}
+		lastPeriod := ""
+		periods := 0
+		for i := range results {
+			if !results[i].Successful {
+				continue
+			}
+			period := tier.period(results[i].CreationTimestamp.In(location))
+			if period == lastPeriod {
+				continue
+			}
+			lastPeriod = period
+			results[i].Tiers = append(results[i].Tiers, tier.tier)
+			periods++
+			if periods == tier.count {
+				break
+			}
+		}
+	}
+
+	latestFound := false
+	for i := range results {
+		switch {
+		case !results[i].Complete:
+		case results[i].Successful && !latestFound:
+			latestFound = true
+			if len(results[i].Tiers) == 0 {
+				results[i].Tiers = []RetentionTier{RetentionTierLatest}
+			}
+		case results[i].Successful:
+			results[i].Prune = len(results[i].Tiers) == 0
+		default:
+			// Keep failed items until there is a successful one after
+			// them
+			results[i].Prune = latestFound
+		}
+	}
+	return results
+}
+
+// PreviewGFSRetention returns the result of applying the GFS retention of the
+// policy to the items. Returns nil if the policy doesn't use GFS retention.
+func PreviewGFSRetention(
+	policyName string,
+	namespace string,
+	items []RetentionItem,
+) ([]RetentionResult, error) {
+	retention, location, err := GetGFSRetention(policyName, namespace)
+	if err != nil || retention == nil {
+		return nil, err
+	}
+	return ApplyGFSRetention(retention, location, items), nil
+}
+
+// GetApplicationBackupRetentionItems returns the retention items for the
+// backups triggered by an ApplicationBackupSchedule
+func GetApplicationBackupRetentionItems(
+	items map[stork_api.SchedulePolicyType][]*stork_api.ScheduledApplicationBackupStatus,
+) []RetentionItem {
+	retentionItems := make([]RetentionItem, 0)
+	for policyType, backups := range items {
+		for _, backup := range backups {
+			retentionItems = append(retentionItems, RetentionItem{
+				Name:              backup.Name,
+				PolicyType:        policyType,
+				CreationTimestamp: backup.CreationTimestamp.Time,
+				Complete: backup.Status == stork_api.ApplicationBackupStatusSuccessful ||
+					backup.Status == stork_api.ApplicationBackupStatusPartialSuccess ||
+					backup.Status == stork_api.ApplicationBackupStatusFailed,
+				// Partially successful backups can still be restored, so
+				// they are retained like successful ones, same as for
+				// migrations
+				Successful: backup.Status == stork_api.ApplicationBackupStatusSuccessful ||
+					backup.Status == stork_api.ApplicationBackupStatusPartialSuccess,
+			})
+		}
+	}
+	return retentionItems
+}
+
+// GetVolumeSnapshotRetentionItems returns the retention items for the
+// snapshots triggered by a VolumeSnapshotSchedule
+func GetVolumeSnapshotRetentionItems(
+	items map[stork_api.SchedulePolicyType][]*stork_api.ScheduledVolumeSnapshotStatus,
+) []RetentionItem {
+	retentionItems := make([]RetentionItem, 0)
+	for policyType, snapshots := range items {
+		for _, snapshot := range snapshots {
+			retentionItems = append(retentionItems, RetentionItem{
+				Name:              snapshot.Name,
+				PolicyType:        policyType,
+				CreationTimestamp: snapshot.CreationTimestamp.Time,
+				Complete:          snapshot.Status != snapv1.VolumeSnapshotConditionPending,
+				Successful:        snapshot.Status == snapv1.VolumeSnapshotConditionReady,
+			})
+		}
+	}
+	return retentionItems
+}
+
+// GetMigrationRetentionItems returns the retention items for the migrations
+// triggered by a MigrationSchedule
+func GetMigrationRetentionItems(
+	items map[stork_api.SchedulePolicyType][]*stork_api.ScheduledMigrationStatus,
+) []RetentionItem {
+	retentionItems := make([]RetentionItem, 0)
+	for policyType, migrations := range items {
+		for _, migration := range migrations {
+			retentionItems = append(retentionItems, RetentionItem{
+				Name:              migration.Name,
+				PolicyType:        policyType,
+				CreationTimestamp: migration.CreationTimestamp.Time,
+				Complete: migration.Status != stork_api.MigrationStatusPending &&
+					migration.Status != stork_api.MigrationStatusInProgress,
+				Successful: migration.Status == stork_api.MigrationStatusSuccessful ||
+					migration.Status == stork_api.MigrationStatusPartialSuccess,
+			})
+		}
+	}
+	return retentionItems
+}
+
+// PruneGFSRetention applies the GFS retention to the items and deletes the
+// ones that aren't retained with deleteFunc. Items that don't exist anymore
+// are treated as deleted. Returns the names of the deleted items so that
+// they can be removed from the status of the schedule. Items that couldn't
+// be deleted are left out so that they are retried on the next prune, and
+// the errors for them are returned.
+func PruneGFSRetention(
+	retention *stork_api.GFSRetention,
+	location *time.Location,
+	items []RetentionItem,
+	deleteFunc func(name string) error,
+) (map[string]bool, error) {
+	deleted := make(map[string]bool)
+	failures := make([]string, 0)
+	for name := range GetPruneSet(ApplyGFSRetention(retention, location, items)) {
+		if err := deleteFunc(name); err != nil && !errors.IsNotFound(err) {
+			failures = append(failures, fmt.Sprintf("%v: %v", name, err))
+			continue
+		}
+		deleted[name] = true
+	}
+	if len(failures) != 0 {
+		sort.Strings(failures)
+		return deleted, fmt.Errorf("error deleting %v", strings.Join(failures, ", "))
+	}
+	return deleted, nil
+}
+
+// GetPruneSet returns the names of the items that should be pruned
+func GetPruneSet(results []RetentionResult) map[string]bool {
+	prune := make(map[string]bool)
+	for _, result := range results {
+		if result.Prune {
+			prune[result.Name] = true
+		}
+	}
+	return prune
+}
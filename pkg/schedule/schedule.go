@@ -5,6 +5,9 @@ import (
 	"os"
 	"reflect"
 	"time"
+	// Embed the time zone database so that the time zones of policies can
+	// be loaded in containers that don't have one
+	_ "time/tzdata"
 
 	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
@@ -66,7 +69,11 @@ func TriggerRequired(
 		return false, err
 	}
 
-	now := GetCurrentTime()
+	location, err := schedulePolicy.Policy.GetLocation()
+	if err != nil {
+		return false, err
+	}
+	now := GetCurrentTime().In(location)
 	switch policyType {
 	case stork_api.SchedulePolicyTypeInterval:
 		if schedulePolicy.Policy.Interval == nil {
@@ -90,7 +97,7 @@ func TriggerRequired(
 			return false, err
 		}
 
-		nextTrigger := time.Date(now.Year(), now.Month(), now.Day(), policyHour, policyMinute, 0, 0, time.Local)
+		nextTrigger := time.Date(now.Year(), now.Month(), now.Day(), policyHour, policyMinute, 0, 0, location)
 
 		return checkTrigger(lastTrigger.Time, nextTrigger, now)
 
@@ -104,7 +111,7 @@ func TriggerRequired(
 		if err != nil {
 			return false, err
 		}
-		nextTrigger := time.Date(now.Year(), now.Month(), now.Day(), policyHour, policyMinute, 0, 0, time.Local)
+		nextTrigger := time.Date(now.Year(), now.Month(), now.Day(), policyHour, policyMinute, 0, 0, location)
 		// Figure out how many days to add to get to the next
 		// trigger week day
 		if currentDay < scheduledDay {
@@ -122,13 +129,127 @@ func TriggerRequired(
 		if err != nil {
 			return false, err
 		}
-		nextTrigger := time.Date(now.Year(), now.Month(), schedulePolicy.Policy.Monthly.Date, policyHour, policyMinute, 0, 0, time.Local)
+		nextTrigger := time.Date(now.Year(), now.Month(), schedulePolicy.Policy.Monthly.Date, policyHour, policyMinute, 0, 0, location)
 
 		return checkTrigger(lastTrigger.Time, nextTrigger, now)
+	case stork_api.SchedulePolicyTypeCron:
+		if schedulePolicy.Policy.Cron == nil {
+			return false, nil
+		}
+		cron, err := parseCronExpression(schedulePolicy.Policy.Cron.Expression)
+		if err != nil {
+			return false, err
+		}
+		// Same as the other policies, only trigger for a scheduled time
+		// within the last hour that hasn't been triggered for yet
+		from := now.Add(-time.Hour)
+		if lastTrigger.Time.After(from) {
+			from = lastTrigger.Time.In(location)
+		}
+		nextTrigger := cron.next(from)
+		if nextTrigger.IsZero() || nextTrigger.After(now) {
+			return false, nil
+		}
+		return true, nil
 	}
 	return false, nil
 }
 
+// NextTriggerTime returns the next time after the given time at which the
+// policy type is scheduled to be triggered. Interval policies don't have a
+// fixed schedule so the zero time is returned for them, as well as for
+// policy types that aren't configured. If the scheduled time falls in a
+// blackout window the end of the window is returned instead.
+func NextTriggerTime(
+	policy *stork_api.SchedulePolicyItem,
+	policyType stork_api.SchedulePolicyType,
+	after time.Time,
+) (time.Time, error) {
+	location, err := policy.GetLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+	next, err := nextScheduledTime(policy, policyType, after.In(location), location)
+	if err != nil || next.IsZero() {
+		return next, err
+	}
+	// Triggers during a blackout window are deferred until it ends
+	end, err := getBlackoutWindowEnd(policy.BlackoutWindows, next)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !end.IsZero() {
+		return end, nil
+	}
+	return next, nil
+}
+
+func nextScheduledTime(
+	policy *stork_api.SchedulePolicyItem,
+	policyType stork_api.SchedulePolicyType,
+	after time.Time,
+	location *time.Location,
+) (time.Time, error) {
+	switch policyType {
+	case stork_api.SchedulePolicyTypeDaily:
+		if policy.Daily == nil {
+			return time.Time{}, nil
+		}
+		hour, minute, err := policy.Daily.GetHourMinute()
+		if err != nil {
+			return time.Time{}, err
+		}
+		next := time.Date(after.Year(), after.Month(), after.Day(), hour, minute, 0, 0, location)
+		if !next.After(after) {
+			next = time.Date(after.Year(), after.Month(), after.Day()+1, hour, minute, 0, 0, location)
+		}
+		return next, nil
+	case stork_api.SchedulePolicyTypeWeekly:
+		if policy.Weekly == nil {
+			return time.Time{}, nil
+		}
+		if err := policy.Weekly.Validate(); err != nil {
+			return time.Time{}, err
+		}
+		hour, minute, err := policy.Weekly.GetHourMinute()
+		if err != nil {
+			return time.Time{}, err
+		}
+		days := (int(stork_api.Days[policy.Weekly.Day]) - int(after.Weekday()) + 7) % 7
+		next := time.Date(after.Year(), after.Month(), after.Day()+days, hour, minute, 0, 0, location)
+		if !next.After(after) {
+			next = time.Date(after.Year(), after.Month(), after.Day()+days+7, hour, minute, 0, 0, location)
+		}
+		return next, nil
+	case stork_api.SchedulePolicyTypeMonthly:
+		if policy.Monthly == nil {
+			return time.Time{}, nil
+		}
+		if err := policy.Monthly.Validate(); err != nil {
+			return time.Time{}, err
+		}
+		hour, minute, err := policy.Monthly.GetHourMinute()
+		if err != nil {
+			return time.Time{}, err
+		}
+		next := time.Date(after.Year(), after.Month(), policy.Monthly.Date, hour, minute, 0, 0, location)
+		if !next.After(after) {
+			next = time.Date(after.Year(), after.Month()+1, policy.Monthly.Date, hour, minute, 0, 0, location)
+		}
+		return next, nil
+	case stork_api.SchedulePolicyTypeCron:
+		if policy.Cron == nil {
+			return time.Time{}, nil
+		}
+		cron, err := parseCronExpression(policy.Cron.Expression)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return cron.next(after), nil
+	}
+	return time.Time{}, nil
+}
+
 func checkTrigger(
 	lastTrigger time.Time,
 	nextTrigger time.Time,
@@ -174,6 +295,32 @@ func ValidateSchedulePolicy(policy *stork_api.SchedulePolicy) error {
 			return err
 		}
 	}
+	if policy.Policy.Cron != nil {
+		if err := ValidateCronPolicy(policy.Policy.Cron); err != nil {
+			return err
+		}
+	}
+	if policy.Policy.Retention != nil {
+		if err := policy.Policy.Retention.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, window := range policy.Policy.BlackoutWindows {
+		if err := window.Validate(); err != nil {
+			return err
+		}
+	}
+	if _, err := policy.Policy.GetLocation(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateCronPolicy validates the expression in a CronPolicy
+func ValidateCronPolicy(policy *stork_api.CronPolicy) error {
+	if _, err := parseCronExpression(policy.Expression); err != nil {
+		return fmt.Errorf("Invalid expression (%v) in Cron policy: %v", policy.Expression, err)
+	}
 	return nil
 }
 
@@ -213,6 +360,13 @@ func GetRetain(policyName string, namespace string, policyType stork_api.Schedul
 			}
 			return schedulePolicy.Policy.Monthly.Retain, nil
 		}
+	case stork_api.SchedulePolicyTypeCron:
+		if schedulePolicy.Policy.Cron != nil {
+			if schedulePolicy.Policy.Cron.Retain == 0 {
+				return stork_api.DefaultCronPolicyRetain, nil
+			}
+			return schedulePolicy.Policy.Cron.Retain, nil
+		}
 	default:
 		return 0, fmt.Errorf("invalid policy type: %v", policyType)
 	}
@@ -233,7 +387,11 @@ func GetOptions(policyName string, namespace string, policyType stork_api.Schedu
 		options := schedulePolicy.Policy.Daily.Options
 		scheduledDay, ok := stork_api.Days[schedulePolicy.Policy.Daily.ForceFullSnapshotDay]
 		if ok {
-			currentDay := GetCurrentTime().Weekday()
+			location, err := schedulePolicy.Policy.GetLocation()
+			if err != nil {
+				return nil, err
+			}
+			currentDay := GetCurrentTime().In(location).Weekday()
 			// force full backup on specified day
 			if currentDay == scheduledDay {
 				options[utils.PXIncrementalCountAnnotation] = "0"
@@ -245,6 +403,8 @@ func GetOptions(policyName string, namespace string, policyType stork_api.Schedu
 		return schedulePolicy.Policy.Weekly.Options, nil
 	case stork_api.SchedulePolicyTypeMonthly:
 		return schedulePolicy.Policy.Monthly.Options, nil
+	case stork_api.SchedulePolicyTypeCron:
+		return schedulePolicy.Policy.Cron.Options, nil
 	default:
 		return nil, fmt.Errorf("invalid policy type: %v", policyType)
 	}
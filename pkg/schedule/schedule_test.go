@@ -4,6 +4,7 @@
 package schedule
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -12,8 +13,10 @@ import (
 	"github.com/portworx/sched-ops/k8s/core"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	kubernetes "k8s.io/client-go/kubernetes/fake"
 )
 
@@ -34,9 +37,14 @@ func TestSchedule(t *testing.T) {
 	t.Run("triggerDailyRequiredTest", triggerDailyRequiredTest)
 	t.Run("triggerWeeklyRequiredTest", triggerWeeklyRequiredTest)
 	t.Run("triggerMonthlyRequiredTest", triggerMonthlyRequiredTest)
+	t.Run("triggerCronRequiredTest", triggerCronRequiredTest)
+	t.Run("triggerTimeZoneRequiredTest", triggerTimeZoneRequiredTest)
+	t.Run("nextTriggerTimeTest", nextTriggerTimeTest)
+	t.Run("blackoutWindowTest", blackoutWindowTest)
 	t.Run("validateSchedulePolicyTest", validateSchedulePolicyTest)
 	t.Run("policyRetainTest", policyRetainTest)
 	t.Run("policyOptionsTest", policyOptionsTest)
+	t.Run("cronPolicyRetainOptionsTest", cronPolicyRetainOptionsTest)
 }
 
 func createDefaultPoliciesTest(t *testing.T) {
@@ -228,6 +236,226 @@ func triggerMonthlyRequiredTest(t *testing.T) {
 	require.False(t, required, "Trigger should not have been required")
 }
 
+func triggerCronRequiredTest(t *testing.T) {
+	defer func() {
+		err := storkops.Instance().DeleteSchedulePolicy("cronpolicy")
+		require.NoError(t, err, "Error cleaning up schedule policy")
+	}()
+
+	// Every 15 minutes past 10PM on weekdays
+	_, err := storkops.Instance().CreateSchedulePolicy(&stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "cronpolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Cron: &stork_api.CronPolicy{
+				Expression: "*/15 22 * * mon-fri",
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating policy")
+
+	// Thursday
+	mockNow := time.Date(2019, time.February, 7, 22, 16, 0, 0, time.Local)
+	setMockTime(&mockNow)
+	// Last triggered before schedule
+	required, err := TriggerRequired("cronpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Date(2019, time.February, 7, 22, 14, 0, 0, time.Local))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.True(t, required, "Trigger should have been required")
+
+	// Last triggered at schedule
+	required, err = TriggerRequired("cronpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Date(2019, time.February, 7, 22, 15, 0, 0, time.Local))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.False(t, required, "Trigger should not have been required")
+
+	// Never triggered
+	required, err = TriggerRequired("cronpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Time{})
+	require.NoError(t, err, "Error checking if trigger required")
+	require.True(t, required, "Trigger should have been required")
+
+	// Saturday, not in the schedule
+	mockNow = time.Date(2019, time.February, 9, 22, 16, 0, 0, time.Local)
+	setMockTime(&mockNow)
+	required, err = TriggerRequired("cronpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Date(2019, time.February, 8, 22, 45, 0, 0, time.Local))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.False(t, required, "Trigger should not have been required")
+
+	// More than an hour after the last scheduled time on Friday
+	mockNow = time.Date(2019, time.February, 8, 23, 46, 0, 0, time.Local)
+	setMockTime(&mockNow)
+	required, err = TriggerRequired("cronpolicy", "default", stork_api.SchedulePolicyTypeCron, meta.Date(2019, time.February, 7, 22, 45, 0, 0, time.Local))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.False(t, required, "Trigger should not have been required")
+}
+
+func triggerTimeZoneRequiredTest(t *testing.T) {
+	defer func() {
+		err := storkops.Instance().DeleteSchedulePolicy("timezonepolicy")
+		require.NoError(t, err, "Error cleaning up schedule policy")
+	}()
+
+	_, err := storkops.Instance().CreateSchedulePolicy(&stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "timezonepolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Daily: &stork_api.DailyPolicy{
+				Time: "11:15PM",
+			},
+			TimeZone: "Asia/Kolkata",
+		},
+	})
+	require.NoError(t, err, "Error creating policy")
+
+	// 11:16PM in Kolkata
+	mockNow := time.Date(2019, time.February, 7, 17, 46, 0, 0, time.UTC)
+	setMockTime(&mockNow)
+	required, err := TriggerRequired("timezonepolicy", "default", stork_api.SchedulePolicyTypeDaily, meta.NewTime(time.Date(2019, time.February, 6, 17, 45, 0, 0, time.UTC)))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.True(t, required, "Trigger should have been required")
+
+	// 11:16PM in UTC is the next morning in Kolkata
+	mockNow = time.Date(2019, time.February, 7, 23, 16, 0, 0, time.UTC)
+	setMockTime(&mockNow)
+	required, err = TriggerRequired("timezonepolicy", "default", stork_api.SchedulePolicyTypeDaily, meta.NewTime(time.Date(2019, time.February, 6, 17, 45, 0, 0, time.UTC)))
+	require.NoError(t, err, "Error checking if trigger required")
+	require.False(t, required, "Trigger should not have been required")
+}
+
+func nextTriggerTimeTest(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err, "Error loading location")
+	policy := &stork_api.SchedulePolicyItem{
+		Interval: &stork_api.IntervalPolicy{
+			IntervalMinutes: 60,
+		},
+		Daily: &stork_api.DailyPolicy{
+			Time: "01:15am",
+		},
+		Weekly: &stork_api.WeeklyPolicy{
+			Day:  "Thursday",
+			Time: "11:15pm",
+		},
+		Monthly: &stork_api.MonthlyPolicy{
+			Date: 7,
+			Time: "12:15am",
+		},
+		Cron: &stork_api.CronPolicy{
+			Expression: "0 9 1,15 * *",
+		},
+		TimeZone: "America/New_York",
+	}
+	// Thursday 05:00 in New York
+	now := time.Date(2019, time.February, 7, 10, 0, 0, 0, time.UTC)
+
+	next, err := NextTriggerTime(policy, stork_api.SchedulePolicyTypeInterval, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.True(t, next.IsZero(), "Interval policy shouldn't have a next trigger time")
+
+	next, err = NextTriggerTime(policy, stork_api.SchedulePolicyTypeDaily, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.February, 8, 1, 15, 0, 0, location), next)
+
+	next, err = NextTriggerTime(policy, stork_api.SchedulePolicyTypeWeekly, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.February, 7, 23, 15, 0, 0, location), next)
+
+	next, err = NextTriggerTime(policy, stork_api.SchedulePolicyTypeMonthly, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.March, 7, 0, 15, 0, 0, location), next)
+
+	next, err = NextTriggerTime(policy, stork_api.SchedulePolicyTypeCron, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.February, 15, 9, 0, 0, 0, location), next)
+
+	// Both day of month and day of week are restricted, either can match
+	policy.Cron.Expression = "0 0 13 * fri"
+	next, err = NextTriggerTime(policy, stork_api.SchedulePolicyTypeCron, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.February, 8, 0, 0, 0, 0, location), next)
+
+	policy.Cron.Expression = "@monthly"
+	next, err = NextTriggerTime(policy, stork_api.SchedulePolicyTypeCron, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.March, 1, 0, 0, 0, 0, location), next)
+
+	// Never matches
+	policy.Cron.Expression = "0 0 30 2 *"
+	next, err = NextTriggerTime(policy, stork_api.SchedulePolicyTypeCron, now)
+	require.NoError(t, err, "Error getting next trigger time")
+	require.True(t, next.IsZero(), "Expression shouldn't have a next trigger time")
+}
+
+func blackoutWindowTest(t *testing.T) {
+	defer func() {
+		err := storkops.Instance().DeleteSchedulePolicy("blackoutpolicy")
+		require.NoError(t, err, "Error cleaning up schedule policy")
+	}()
+
+	policy, err := storkops.Instance().CreateSchedulePolicy(&stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "blackoutpolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Daily: &stork_api.DailyPolicy{
+				Time: "11:00pm",
+			},
+			TimeZone: "UTC",
+			BlackoutWindows: []stork_api.BlackoutWindow{
+				{
+					Days:      []string{"Thu"},
+					StartTime: "10:00pm",
+					EndTime:   "02:00am",
+				},
+				{
+					StartTime: "01:30am",
+					EndTime:   "03:00am",
+				},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating policy")
+
+	// Thursday 11:00PM, the windows are chained together
+	mockNow := time.Date(2019, time.February, 7, 23, 0, 0, 0, time.UTC)
+	setMockTime(&mockNow)
+	pending, err := AdmitTrigger("blackoutpolicy", "default", stork_api.SchedulePolicyTypeDaily, "", nil)
+	require.NoError(t, err, "Error admitting trigger")
+	require.NotNil(t, pending, "Trigger should be pending")
+	require.Equal(t, stork_api.SchedulePendingReasonBlackoutWindow, pending.Reason)
+	require.Equal(t, "Deferred until the blackout window ends at 2019-02-08T03:00:00Z", pending.Message)
+	require.True(t, pending.PendingSince.Time.Equal(mockNow), "Unexpected pending since time")
+
+	// The time since when the trigger is pending is carried over
+	mockNow = time.Date(2019, time.February, 8, 2, 30, 0, 0, time.UTC)
+	setMockTime(&mockNow)
+	pending, err = AdmitTrigger("blackoutpolicy", "default", stork_api.SchedulePolicyTypeDaily, "", pending)
+	require.NoError(t, err, "Error admitting trigger")
+	require.NotNil(t, pending, "Trigger should be pending")
+	require.True(t, pending.PendingSince.Time.Equal(time.Date(2019, time.February, 7, 23, 0, 0, 0, time.UTC)), "Unexpected pending since time")
+
+	mockNow = time.Date(2019, time.February, 8, 3, 0, 0, 0, time.UTC)
+	setMockTime(&mockNow)
+	pending, err = AdmitTrigger("blackoutpolicy", "default", stork_api.SchedulePolicyTypeDaily, "", pending)
+	require.NoError(t, err, "Error admitting trigger")
+	require.Nil(t, pending, "Trigger shouldn't be pending after the window ends")
+
+	// The first window only starts on Thursdays
+	mockNow = time.Date(2019, time.February, 8, 23, 0, 0, 0, time.UTC)
+	setMockTime(&mockNow)
+	pending, err = AdmitTrigger("blackoutpolicy", "default", stork_api.SchedulePolicyTypeDaily, "", nil)
+	require.NoError(t, err, "Error admitting trigger")
+	require.Nil(t, pending, "Trigger shouldn't be pending outside the windows")
+
+	// The next trigger is moved to the end of the windows
+	next, err := NextTriggerTime(&policy.Policy, stork_api.SchedulePolicyTypeDaily, time.Date(2019, time.February, 7, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.February, 8, 3, 0, 0, 0, time.UTC), next)
+	next, err = NextTriggerTime(&policy.Policy, stork_api.SchedulePolicyTypeDaily, time.Date(2019, time.February, 8, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err, "Error getting next trigger time")
+	require.Equal(t, time.Date(2019, time.February, 8, 23, 0, 0, 0, time.UTC), next)
+}
+
 func validateSchedulePolicyTest(t *testing.T) {
 	policy := &stork_api.SchedulePolicy{
 		ObjectMeta: meta.ObjectMeta{
@@ -329,6 +557,88 @@ func validateSchedulePolicyTest(t *testing.T) {
 	}
 	err = ValidateSchedulePolicy(policy)
 	require.Error(t, err, "Invalid monthly policy should return error")
+
+	for _, expression := range []string{"* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "* * * foo *", "5-1 * * * *", "*/0 * * * *"} {
+		policy = &stork_api.SchedulePolicy{
+			ObjectMeta: meta.ObjectMeta{
+				Name: "invalidCronpolicy",
+			},
+			Policy: stork_api.SchedulePolicyItem{
+				Cron: &stork_api.CronPolicy{
+					Expression: expression,
+				},
+			},
+		}
+		err = ValidateSchedulePolicy(policy)
+		require.Error(t, err, "Invalid cron policy %v should return error", expression)
+	}
+
+	for _, expression := range []string{"*/5 * * * *", "0 0 * * 7", "15,45 1-5 1 jan-jun Sun", "@daily"} {
+		policy = &stork_api.SchedulePolicy{
+			ObjectMeta: meta.ObjectMeta{
+				Name: "validCronpolicy",
+			},
+			Policy: stork_api.SchedulePolicyItem{
+				Cron: &stork_api.CronPolicy{
+					Expression: expression,
+				},
+				TimeZone: "Europe/Berlin",
+			},
+		}
+		err = ValidateSchedulePolicy(policy)
+		require.NoError(t, err, "Valid cron policy %v shouldn't return error", expression)
+	}
+
+	policy = &stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "invalidTimeZonepolicy",
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Daily: &stork_api.DailyPolicy{
+				Time: "11:15pm",
+			},
+			TimeZone: "Europe/Atlantis",
+		},
+	}
+	err = ValidateSchedulePolicy(policy)
+	require.Error(t, err, "Invalid time zone should return error")
+
+	for _, retention := range []*stork_api.GFSRetention{{}, {Daily: 7, Weekly: -1}} {
+		policy = &stork_api.SchedulePolicy{
+			ObjectMeta: meta.ObjectMeta{
+				Name: "invalidRetentionpolicy",
+			},
+			Policy: stork_api.SchedulePolicyItem{
+				Interval: &stork_api.IntervalPolicy{
+					IntervalMinutes: 60,
+				},
+				Retention: retention,
+			},
+		}
+		err = ValidateSchedulePolicy(policy)
+		require.Error(t, err, "Invalid retention %v should return error", retention)
+	}
+
+	for _, window := range []stork_api.BlackoutWindow{
+		{StartTime: "25:00pm", EndTime: "01:00am"},
+		{StartTime: "10:00pm", EndTime: "1am"},
+		{StartTime: "10:00pm", EndTime: "10:00pm"},
+		{StartTime: "10:00pm", EndTime: "01:00am", Days: []string{"Funday"}},
+	} {
+		policy = &stork_api.SchedulePolicy{
+			ObjectMeta: meta.ObjectMeta{
+				Name: "invalidBlackoutpolicy",
+			},
+			Policy: stork_api.SchedulePolicyItem{
+				Interval: &stork_api.IntervalPolicy{
+					IntervalMinutes: 60,
+				},
+				BlackoutWindows: []stork_api.BlackoutWindow{window},
+			},
+		}
+		err = ValidateSchedulePolicy(policy)
+		require.Error(t, err, "Invalid blackout window %v should return error", window)
+	}
 }
 
 func policyRetainTest(t *testing.T) {
@@ -426,6 +736,39 @@ func policyRetainTest(t *testing.T) {
 	require.Equal(t, policy.Policy.Monthly.Retain, retain, "Wrong default retain for monthly policy")
 }
 
+func cronPolicyRetainOptionsTest(t *testing.T) {
+	policyName := "cronoptions"
+	policy, err := storkops.Instance().CreateSchedulePolicy(&stork_api.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: policyName,
+		},
+		Policy: stork_api.SchedulePolicyItem{
+			Cron: &stork_api.CronPolicy{
+				Expression: "0 */6 * * *",
+				Options: map[string]string{
+					"cron-option": "true",
+				},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating schedule policy")
+
+	retain, err := GetRetain(policyName, "default", stork_api.SchedulePolicyTypeCron)
+	require.NoError(t, err, "Error getting retain")
+	require.Equal(t, stork_api.DefaultCronPolicyRetain, retain, "Wrong default retain for cron policy")
+
+	policy.Policy.Cron.Retain = 5
+	_, err = storkops.Instance().UpdateSchedulePolicy(policy)
+	require.NoError(t, err, "Error updating schedule policy")
+	retain, err = GetRetain(policyName, "default", stork_api.SchedulePolicyTypeCron)
+	require.NoError(t, err, "Error getting retain")
+	require.Equal(t, policy.Policy.Cron.Retain, retain, "Wrong retain for cron policy")
+
+	options, err := GetOptions(policyName, "default", stork_api.SchedulePolicyTypeCron)
+	require.NoError(t, err, "Error getting options")
+	require.Equal(t, policy.Policy.Cron.Options, options, "Options mismatch for cron policy")
+}
+
 func policyOptionsTest(t *testing.T) {
 	policyName := "options"
 	policy, err := storkops.Instance().CreateSchedulePolicy(&stork_api.SchedulePolicy{
@@ -476,3 +819,185 @@ func policyOptionsTest(t *testing.T) {
 	require.NoError(t, err, "Error getting options")
 	require.Equal(t, policy.Policy.Monthly.Options, options, "Options mismatch for monthly policy")
 }
+
+func TestGFSRetention(t *testing.T) {
+	retention := &stork_api.GFSRetention{
+		Hourly: 2,
+		Daily:  2,
+		Weekly: 2,
+	}
+	// One object every 12 hours for two weeks starting on a Monday, with
+	// the latest one failed
+	start := time.Date(2019, time.February, 4, 6, 0, 0, 0, time.UTC)
+	items := make([]RetentionItem, 0)
+	for i := 0; i < 28; i++ {
+		items = append(items, RetentionItem{
+			Name:              fmt.Sprintf("backup-%d", i),
+			PolicyType:        stork_api.SchedulePolicyTypeDaily,
+			CreationTimestamp: start.Add(time.Duration(i*12) * time.Hour),
+			Complete:          true,
+			Successful:        true,
+		})
+	}
+	items[27].Successful = false
+	// A failed object before the latest successful one and one in progress
+	items = append(items, RetentionItem{
+		Name:              "failed",
+		PolicyType:        stork_api.SchedulePolicyTypeInterval,
+		CreationTimestamp: start.Add(time.Hour),
+		Complete:          true,
+	})
+	items = append(items, RetentionItem{
+		Name:              "inprogress",
+		PolicyType:        stork_api.SchedulePolicyTypeInterval,
+		CreationTimestamp: start.Add(400 * time.Hour),
+	})
+
+	results := ApplyGFSRetention(retention, time.UTC, items)
+	require.Len(t, results, len(items))
+	retained := make(map[string][]RetentionTier)
+	for _, result := range results {
+		if !result.Prune {
+			retained[result.Name] = result.Tiers
+		}
+	}
+	require.Equal(t, map[string][]RetentionTier{
+		"inprogress": nil,
+		"backup-27":  nil,
+		// Sunday 06:00AM, the latest successful one, is in every tier
+		"backup-26": {RetentionTierHourly, RetentionTierDaily, RetentionTierWeekly},
+		// Saturday 06:00PM
+		"backup-25": {RetentionTierHourly, RetentionTierDaily},
+		// Sunday 06:00PM of the previous week
+		"backup-13": {RetentionTierWeekly},
+	}, retained)
+
+	// The latest successful object is always retained
+	retention = &stork_api.GFSRetention{
+		Yearly: 1,
+	}
+	results = ApplyGFSRetention(retention, time.UTC, items[:2])
+	require.False(t, results[0].Prune, "Latest object should be retained")
+	require.Equal(t, []RetentionTier{RetentionTierYearly}, results[0].Tiers)
+	require.True(t, results[1].Prune, "Older object should be pruned")
+
+	// Periods are evaluated in the given location
+	location, err := time.LoadLocation("Asia/Kolkata")
+	require.NoError(t, err, "Error loading location")
+	retention = &stork_api.GFSRetention{
+		Daily: 2,
+	}
+	results = ApplyGFSRetention(retention, location, []RetentionItem{
+		{Name: "first", CreationTimestamp: time.Date(2019, time.February, 4, 17, 0, 0, 0, time.UTC), Complete: true, Successful: true},
+		{Name: "second", CreationTimestamp: time.Date(2019, time.February, 4, 19, 0, 0, 0, time.UTC), Complete: true, Successful: true},
+	})
+	require.False(t, results[0].Prune, "Object on the second day should be retained")
+	require.False(t, results[1].Prune, "Object on the first day should be retained")
+}
+
+func TestPruneGFSRetention(t *testing.T) {
+	retention := &stork_api.GFSRetention{
+		Daily: 1,
+	}
+	start := time.Date(2019, time.February, 4, 6, 0, 0, 0, time.UTC)
+	items := []RetentionItem{
+		{Name: "latest", CreationTimestamp: start.Add(2 * time.Hour), Complete: true, Successful: true},
+		{Name: "notfound", CreationTimestamp: start.Add(time.Hour), Complete: true, Successful: true},
+		{Name: "error", CreationTimestamp: start, Complete: true, Successful: true},
+		{Name: "deleted", CreationTimestamp: start.Add(-time.Hour), Complete: true, Successful: true},
+	}
+	deleteCalls := make([]string, 0)
+	deleted, err := PruneGFSRetention(retention, time.UTC, items, func(name string) error {
+		deleteCalls = append(deleteCalls, name)
+		switch name {
+		case "notfound":
+			return errors.NewNotFound(schema.GroupResource{}, name)
+		case "error":
+			return fmt.Errorf("delete failed")
+		}
+		return nil
+	})
+	require.Error(t, err, "Expected error for failed delete")
+	require.Equal(t, "error deleting error: delete failed", err.Error())
+	require.ElementsMatch(t, []string{"notfound", "error", "deleted"}, deleteCalls)
+	// Items that failed to be deleted are retried on the next prune
+	require.Equal(t, map[string]bool{"notfound": true, "deleted": true}, deleted)
+
+	deleted, err = PruneGFSRetention(retention, time.UTC, items[:1], func(name string) error {
+		return fmt.Errorf("unexpected delete for %v", name)
+	})
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+}
+
+func TestApplicationBackupRetentionItems(t *testing.T) {
+	now := meta.Now()
+	items := GetApplicationBackupRetentionItems(map[stork_api.SchedulePolicyType][]*stork_api.ScheduledApplicationBackupStatus{
+		stork_api.SchedulePolicyTypeDaily: {
+			{Name: "successful", CreationTimestamp: now, Status: stork_api.ApplicationBackupStatusSuccessful},
+			{Name: "partial", CreationTimestamp: now, Status: stork_api.ApplicationBackupStatusPartialSuccess},
+			{Name: "failed", CreationTimestamp: now, Status: stork_api.ApplicationBackupStatusFailed},
+			{Name: "inprogress", CreationTimestamp: now, Status: stork_api.ApplicationBackupStatusInProgress},
+		},
+	})
+	complete := make(map[string]bool)
+	successful := make(map[string]bool)
+	for _, item := range items {
+		complete[item.Name] = item.Complete
+		successful[item.Name] = item.Successful
+	}
+	require.Equal(t, map[string]bool{"successful": true, "partial": true, "failed": true, "inprogress": false}, complete)
+	// Partially successful backups are retained like successful ones, same
+	// as migrations
+	require.Equal(t, map[string]bool{"successful": true, "partial": true, "failed": false, "inprogress": false}, successful)
+}
+
+func TestTriggerQueue(t *testing.T) {
+	defer func() {
+		queue = &triggerQueue{
+			running: make(map[string]int),
+			pending: make(map[string]*queuedTrigger),
+		}
+	}()
+	SetTriggerLimits(2, 0)
+	now := time.Date(2019, time.February, 7, 23, 0, 0, 0, time.UTC)
+
+	admitted, _ := queue.admit("a", now)
+	require.True(t, admitted, "First trigger should be admitted")
+	admitted, _ = queue.admit("b", now)
+	require.True(t, admitted, "Second trigger should be admitted")
+	admitted, message := queue.admit("c", now)
+	require.False(t, admitted, "Third trigger should be queued")
+	require.Equal(t, "Waiting for one of 2 running scheduled operations to complete, position 1 in queue", message)
+	admitted, message = queue.admit("d", now.Add(time.Second))
+	require.False(t, admitted, "Fourth trigger should be queued")
+	require.Equal(t, "Waiting for one of 2 running scheduled operations to complete, position 2 in queue", message)
+
+	// Triggers are admitted in the order they were queued
+	UpdateRunning("a", 0)
+	admitted, _ = queue.admit("d", now.Add(2*time.Second))
+	require.False(t, admitted, "Fourth trigger should still be queued")
+	admitted, _ = queue.admit("c", now.Add(2*time.Second))
+	require.True(t, admitted, "Third trigger should be admitted")
+
+	// Schedules that don't need a trigger anymore leave the queue
+	RemoveFromQueue("d")
+	DeleteFromQueue("b")
+	admitted, _ = queue.admit("e", now.Add(3*time.Second))
+	require.True(t, admitted, "Trigger should be admitted after schedule was deleted")
+
+	// Stale entries expire
+	admitted, _ = queue.admit("f", now.Add(4*time.Second))
+	require.False(t, admitted, "Trigger should be queued")
+	UpdateRunning("c", 0)
+	admitted, _ = queue.admit("g", now.Add(queueEntryExpiry+5*time.Second))
+	require.True(t, admitted, "Trigger should be admitted after stale entry expired")
+
+	// Triggers wait for the jitter delay
+	SetTriggerLimits(0, time.Minute)
+	admitted, message = queue.admit("h", now)
+	require.False(t, admitted, "Trigger should wait for jitter")
+	require.Contains(t, message, "Waiting for jitter delay until")
+	admitted, _ = queue.admit("h", now.Add(time.Minute))
+	require.True(t, admitted, "Trigger should be admitted after jitter delay")
+}
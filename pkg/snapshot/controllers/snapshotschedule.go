@@ -115,9 +115,11 @@ func (s *SnapshotScheduleController) handle(ctx context.Context, snapshotSchedul
 		return err
 	}
 
+	start := false
+	policyType := stork_api.SchedulePolicyTypeInvalid
 	if snapshotSchedule.Spec.Suspend == nil || !*snapshotSchedule.Spec.Suspend {
 		// Then check if any of the policies require a trigger
-		policyType, start, err := s.shouldStartVolumeSnapshot(snapshotSchedule)
+		policyType, start, err = s.shouldStartVolumeSnapshot(snapshotSchedule)
 		if err != nil {
 			msg := fmt.Sprintf("Error checking if snapshot should be triggered: %v", err)
 			s.recorder.Event(snapshotSchedule,
@@ -127,9 +129,40 @@ func (s *SnapshotScheduleController) handle(ctx context.Context, snapshotSchedul
 			log.VolumeSnapshotScheduleLog(snapshotSchedule).Error(msg)
 			return nil
 		}
-
-		// Start a snapshot for a policy if required
-		if start {
+	}
+	if !start {
+		if snapshotSchedule.Status.Pending != nil {
+			snapshotSchedule.Status.Pending = nil
+			if err := s.client.Update(context.TODO(), snapshotSchedule); err != nil {
+				return err
+			}
+		}
+	} else {
+		// Snapshots aren't limited by the queue for scheduled operations,
+		// only blackout windows can defer the trigger
+		pending, err := schedule.AdmitTrigger(
+			snapshotSchedule.Spec.SchedulePolicyName,
+			snapshotSchedule.Namespace,
+			policyType,
+			"",
+			snapshotSchedule.Status.Pending,
+		)
+		if err != nil {
+			msg := fmt.Sprintf("Error checking if snapshot can be triggered: %v", err)
+			s.recorder.Event(snapshotSchedule,
+				v1.EventTypeWarning,
+				string(snapv1.VolumeSnapshotConditionError),
+				msg)
+			log.VolumeSnapshotScheduleLog(snapshotSchedule).Error(msg)
+			return nil
+		}
+		if pending != nil {
+			if err := s.updatePendingStatus(snapshotSchedule, pending); err != nil {
+				return err
+			}
+		} else {
+			// Start a snapshot for the policy
+			snapshotSchedule.Status.Pending = nil
 			err := s.startVolumeSnapshot(snapshotSchedule, policyType)
 			if err != nil {
 				msg := fmt.Sprintf("Error triggering snapshot for schedule(%v): %v", policyType, err)
@@ -241,6 +274,28 @@ func (s *SnapshotScheduleController) isVolumeSnapshotComplete(status snapv1.Volu
 	return status != snapv1.VolumeSnapshotConditionPending
 }
 
+// updatePendingStatus records why a snapshot that is due hasn't been started.
+// The schedule is only updated if the reason or message changed
+func (s *SnapshotScheduleController) updatePendingStatus(
+	snapshotSchedule *stork_api.VolumeSnapshotSchedule,
+	pending *stork_api.SchedulePendingStatus,
+) error {
+	current := snapshotSchedule.Status.Pending
+	if current != nil && current.Reason == pending.Reason && current.Message == pending.Message {
+		return nil
+	}
+	if current == nil || current.Reason != pending.Reason {
+		msg := fmt.Sprintf("Snapshot for schedule(%v) is pending: %v", pending.PolicyType, pending.Message)
+		s.recorder.Event(snapshotSchedule,
+			v1.EventTypeNormal,
+			string(pending.Reason),
+			msg)
+		log.VolumeSnapshotScheduleLog(snapshotSchedule).Info(msg)
+	}
+	snapshotSchedule.Status.Pending = pending
+	return s.client.Update(context.TODO(), snapshotSchedule)
+}
+
 func (s *SnapshotScheduleController) shouldStartVolumeSnapshot(snapshotSchedule *stork_api.VolumeSnapshotSchedule) (stork_api.SchedulePolicyType, bool, error) {
 	// Don't trigger a new snapshot if one is already in progress
 	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
@@ -254,6 +309,11 @@ func (s *SnapshotScheduleController) shouldStartVolumeSnapshot(snapshotSchedule
 		}
 	}
 
+	// A trigger that was deferred is still due
+	if snapshotSchedule.Status.Pending != nil {
+		return snapshotSchedule.Status.Pending.PolicyType, true, nil
+	}
+
 	for _, policyType := range stork_api.GetValidSchedulePolicyTypes() {
 		var latestVolumeSnapshotTimestamp meta.Time
 		policyVolumeSnapshot, present := snapshotSchedule.Status.Items[policyType]
@@ -370,6 +430,13 @@ func (s *SnapshotScheduleController) startVolumeSnapshot(snapshotSchedule *stork
 }
 
 func (s *SnapshotScheduleController) pruneVolumeSnapshots(snapshotSchedule *stork_api.VolumeSnapshotSchedule) error {
+	retention, location, err := schedule.GetGFSRetention(snapshotSchedule.Spec.SchedulePolicyName, snapshotSchedule.Namespace)
+	if err != nil {
+		return err
+	}
+	if retention != nil {
+		return s.pruneVolumeSnapshotsGFS(snapshotSchedule, retention, location)
+	}
 	for policyType, policyVolumeSnapshot := range snapshotSchedule.Status.Items {
 		numVolumeSnapshots := len(policyVolumeSnapshot)
 		deleteBefore := 0
@@ -413,6 +480,39 @@ func (s *SnapshotScheduleController) pruneVolumeSnapshots(snapshotSchedule *stor
 	return s.client.Update(context.TODO(), snapshotSchedule)
 }
 
+// pruneVolumeSnapshotsGFS deletes the snapshots, across all policy types,
+// that aren't retained by the GFS retention of the policy
+func (s *SnapshotScheduleController) pruneVolumeSnapshotsGFS(
+	snapshotSchedule *stork_api.VolumeSnapshotSchedule,
+	retention *stork_api.GFSRetention,
+	location *time.Location,
+) error {
+	deleted, err := schedule.PruneGFSRetention(
+		retention,
+		location,
+		schedule.GetVolumeSnapshotRetentionItems(snapshotSchedule.Status.Items),
+		func(name string) error {
+			return k8sextops.Instance().DeleteSnapshot(name, snapshotSchedule.Namespace)
+		},
+	)
+	if err != nil {
+		log.VolumeSnapshotScheduleLog(snapshotSchedule).Warnf("Error pruning snapshots: %v", err)
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+	for policyType, policyVolumeSnapshot := range snapshotSchedule.Status.Items {
+		retained := make([]*stork_api.ScheduledVolumeSnapshotStatus, 0, len(policyVolumeSnapshot))
+		for _, snapshot := range policyVolumeSnapshot {
+			if !deleted[snapshot.Name] {
+				retained = append(retained, snapshot)
+			}
+		}
+		snapshotSchedule.Status.Items[policyType] = retained
+	}
+	return s.client.Update(context.TODO(), snapshotSchedule)
+}
+
 func (s *SnapshotScheduleController) createCRD() error {
 	resource := apiextensions.CustomResource{
 		Name:    stork_api.VolumeSnapshotScheduleResourceName,
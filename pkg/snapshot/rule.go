@@ -2,6 +2,8 @@ package snapshot
 
 import (
 	crdv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
+	stork_api "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/log"
 	"github.com/libopenstorage/stork/pkg/rule"
 	k8sextops "github.com/portworx/sched-ops/k8s/externalstorage"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
@@ -18,38 +20,30 @@ const (
 	postSnapRuleAnnotationKeyDeprecated = storkRuleAnnotationPrefixDeprecated + "/post-snapshot"
 )
 
-var ruleAnnotationKeyTypes = map[string]rule.Type{
-	preSnapRuleAnnotationKey:            rule.PreExecRule,
-	postSnapRuleAnnotationKey:           rule.PostExecRule,
-	preSnapRuleAnnotationKeyDeprecated:  rule.PreExecRule,
-	postSnapRuleAnnotationKeyDeprecated: rule.PostExecRule,
-}
-
 // validateSnapRules validates the rules if they are present in the given snapshot's annotations
 func validateSnapRules(snap *crdv1.VolumeSnapshot) error {
-	if snap.Metadata.Annotations != nil {
-		ruleAnnotations := []string{
-			preSnapRuleAnnotationKey,
-			postSnapRuleAnnotationKey,
-			preSnapRuleAnnotationKeyDeprecated,
-			preSnapRuleAnnotationKeyDeprecated,
-		}
-		for _, annotation := range ruleAnnotations {
-			ruleName, present := snap.Metadata.Annotations[annotation]
-			if present && len(ruleName) > 0 {
-				r, err := storkops.Instance().GetRule(ruleName, snap.Metadata.Namespace)
-				if err != nil {
-					return err
-				}
-				err = rule.ValidateRule(r, ruleAnnotationKeyTypes[annotation])
-				if err != nil {
-					return err
-				}
-			}
-		}
+	preSnapRule, err := getSnapRule(snap, preSnapRuleAnnotationKey, preSnapRuleAnnotationKeyDeprecated)
+	if err != nil {
+		return err
 	}
+	postSnapRule, err := getSnapRule(snap, postSnapRuleAnnotationKey, postSnapRuleAnnotationKeyDeprecated)
+	if err != nil {
+		return err
+	}
+	return rule.ValidateRules(preSnapRule, postSnapRule)
+}
 
-	return nil
+// getSnapRule returns the rule in the annotation of the snapshot, or in the
+// deprecated annotation if it isn't set. It returns nil if neither is set.
+func getSnapRule(snap *crdv1.VolumeSnapshot, annotation, deprecatedAnnotation string) (*stork_api.Rule, error) {
+	ruleName := snap.Metadata.Annotations[annotation]
+	if ruleName == "" {
+		ruleName = snap.Metadata.Annotations[deprecatedAnnotation]
+	}
+	if ruleName == "" {
+		return nil, nil
+	}
+	return storkops.Instance().GetRule(ruleName, snap.Metadata.Namespace)
 }
 
 func setKind(snap *crdv1.VolumeSnapshot) {
@@ -79,7 +73,11 @@ func ExecutePreSnapRule(snap *crdv1.VolumeSnapshot, pvcs []v1.PersistentVolumeCl
 		if err != nil {
 			return nil, err
 		}
-		return rule.ExecuteRule(r, rule.PreExecRule, snap, snap.Metadata.Namespace)
+		// The result of the rule is only logged since the snapshot doesn't
+		// have a status to record it in
+		ch, result, err := rule.ExecuteRule(r, rule.PreExecRule, snap, snap.Metadata.Namespace)
+		logRuleFailures(snap, result)
+		return ch, err
 	}
 	return nil, nil
 }
@@ -106,12 +104,24 @@ func ExecutePostSnapRule(pvcs []v1.PersistentVolumeClaim, snap *crdv1.VolumeSnap
 		if err != nil {
 			return err
 		}
-		_, err = rule.ExecuteRule(r, rule.PostExecRule, snap, snap.Metadata.Namespace)
+		_, result, err := rule.ExecuteRule(r, rule.PostExecRule, snap, snap.Metadata.Namespace)
+		logRuleFailures(snap, result)
 		return err
 	}
 	return nil
 }
 
+// logRuleFailures logs the actions of the rule that failed but were ignored
+// since their OnFailure policy is Continue
+func logRuleFailures(snap *crdv1.VolumeSnapshot, result *rule.Result) {
+	if result == nil {
+		return
+	}
+	for _, failure := range result.Failures {
+		log.SnapshotLog(snap).Warnf("Ignored failure in rule %v: %v", failure.Rule, failure.Reason)
+	}
+}
+
 // performRuleRecovery terminates potential background commands running pods for
 // the given snapshot
 func performRuleRecovery() error {
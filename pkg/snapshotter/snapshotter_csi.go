@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -15,7 +14,6 @@ import (
 	kSnapshotClient "github.com/kubernetes-csi/external-snapshotter/client/v4/clientset/versioned"
 	"github.com/libopenstorage/stork/drivers"
 	storkapi "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
-	"github.com/libopenstorage/stork/pkg/crypto"
 	"github.com/libopenstorage/stork/pkg/k8sutils"
 	"github.com/libopenstorage/stork/pkg/objectstore"
 	"github.com/libopenstorage/stork/pkg/version"
@@ -1031,31 +1029,18 @@ func (c *csiDriver) UploadSnapshotObjects(
 	if err != nil {
 		return err
 	}
-	if backupLocation.Location.EncryptionKey != "" {
-		return fmt.Errorf("EncryptionKey is deprecated, use EncryptionKeyV2 instead")
-	}
-	if backupLocation.Location.EncryptionV2Key != "" {
-		if data, err = crypto.Encrypt(data, backupLocation.Location.EncryptionV2Key); err != nil {
-			return err
-		}
-	}
-
-	writer, err := bucket.NewWriter(context.TODO(), filepath.Join(objectPath, objectName), nil)
+	// The backup isn't available here, so the snapshot objects get their
+	// own data key if the backup location uses envelope encryption
+	dataKey, err := objectstore.GetDataKey(backupLocation, nil)
 	if err != nil {
 		return err
 	}
-
-	_, err = writer.Write(data)
-	if err != nil {
-		closeErr := writer.Close()
-		if closeErr != nil {
-			logrus.Errorf("error closing writer for objectstore: %v", closeErr)
-		}
+	if data, err = objectstore.Encrypt(backupLocation, dataKey, data); err != nil {
 		return err
 	}
-	err = writer.Close()
-	if err != nil {
-		logrus.Errorf("error closing writer for objectstore: %v", err)
+
+	if err := objectstore.UploadObject(bucket, backupLocation, objectPath, objectName, data); err != nil {
+		logrus.Errorf("error uploading %v to objectstore: %v", objectName, err)
 		return err
 	}
 	return nil
@@ -1079,11 +1064,8 @@ func (c *csiDriver) DownloadSnapshotObjects(
 	if err != nil {
 		return snapshotInfoList, err
 	}
-	if backupLocation.Location.EncryptionV2Key != "" {
-		if decryptData, err := crypto.Decrypt(data, backupLocation.Location.EncryptionV2Key); err != nil {
-		} else {
-			data = decryptData
-		}
+	if decryptData, err := objectstore.Decrypt(backupLocation, data); err == nil {
+		data = decryptData
 	}
 
 	cboCommon := &csiBackupObject{}
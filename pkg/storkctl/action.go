@@ -0,0 +1,231 @@
+package storkctl
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"time"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkclientset "github.com/libopenstorage/stork/pkg/client/clientset/versioned"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/portworx/sched-ops/task"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	actionTimeout          = 6 * time.Hour
+	actionNameTimeSuffix   = "2006-01-02-150405"
+	failoverSubcommand     = "failover"
+	failbackSubcommand     = "failback"
+	actionWaitInitialDelay = 5 * time.Second
+)
+
+var (
+	actionRetryTimeout = 30 * time.Second
+	// storkClient is used for the stork resources that aren't supported by
+	// sched-ops. It is created from the config of the command factory unless
+	// it is already set
+	storkClient storkclientset.Interface
+)
+
+func getStorkClient(cmdFactory Factory) (storkclientset.Interface, error) {
+	if storkClient != nil {
+		return storkClient, nil
+	}
+	config, err := cmdFactory.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := storkclientset.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	storkClient = client
+	return storkClient, nil
+}
+
+func newPerformFailoverCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return newPerformActionCommand(cmdFactory, ioStreams, storkv1.ActionTypeFailover,
+		"Fail over the applications migrated to this cluster by a migrationschedule")
+}
+
+func newPerformFailbackCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return newPerformActionCommand(cmdFactory, ioStreams, storkv1.ActionTypeFailback,
+		"Fail back the applications that were failed over from this cluster")
+}
+
+func newPerformActionCommand(
+	cmdFactory Factory,
+	ioStreams genericclioptions.IOStreams,
+	actionType storkv1.ActionType,
+	short string,
+) *cobra.Command {
+	var migrationSchedule string
+	var clusterPair string
+	var reverseMigrationSchedule string
+	var remoteKubeFile string
+	var namespaceList []string
+	var skipFinalMigration bool
+	var waitForCompletion bool
+
+	performActionCommand := &cobra.Command{
+		Use:   string(actionType),
+		Short: short,
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) > 1 {
+				util.CheckErr(fmt.Errorf("at most one name can be provided for the %v", actionType))
+				return
+			}
+			if len(migrationSchedule) == 0 {
+				util.CheckErr(fmt.Errorf("MigrationSchedule name needs to be provided for %v", actionType))
+				return
+			}
+			if len(clusterPair) == 0 {
+				util.CheckErr(fmt.Errorf("ClusterPair name needs to be provided for %v", actionType))
+				return
+			}
+			namespace := cmdFactory.GetNamespace()
+			actionName := fmt.Sprintf("%v-%v-%v", migrationSchedule, actionType, time.Now().Format(actionNameTimeSuffix))
+			if len(args) == 1 {
+				actionName = args[0]
+			}
+
+			if remoteKubeFile != "" {
+				if err := createReverseClusterPair(clusterPair, namespace, remoteKubeFile, ioStreams); err != nil {
+					util.CheckErr(err)
+					return
+				}
+			}
+
+			client, err := getStorkClient(cmdFactory)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			action := &storkv1.Action{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      actionName,
+					Namespace: namespace,
+				},
+				Spec: storkv1.ActionSpec{
+					ActionType:               actionType,
+					MigrationSchedule:        migrationSchedule,
+					ClusterPair:              clusterPair,
+					ReverseMigrationSchedule: reverseMigrationSchedule,
+					Namespaces:               namespaceList,
+					SkipFinalMigration:       skipFinalMigration,
+				},
+			}
+			if _, err := client.StorkV1alpha1().Actions(namespace).Create(context.TODO(), action, metav1.CreateOptions{}); err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			if waitForCompletion {
+				msg, err := waitForAction(client, actionName, namespace, ioStreams)
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+				printMsg(msg, ioStreams.Out)
+				return
+			}
+			printMsg(fmt.Sprintf("Started %v %v", actionType, actionName), ioStreams.Out)
+		},
+	}
+	performActionCommand.Flags().StringVarP(&migrationSchedule, "migration-schedule", "m", "",
+		"Name of the migrationschedule on the remote cluster that migrates the applications to this cluster")
+	performActionCommand.Flags().StringVarP(&clusterPair, "cluster-pair", "c", "", "Name of the clusterpair used to reach the remote cluster")
+	performActionCommand.Flags().StringVarP(&remoteKubeFile, "remote-kube-file", "", "",
+		"kube-config of the remote cluster used to create the clusterpair if it doesn't exist")
+	performActionCommand.Flags().StringVarP(&reverseMigrationSchedule, "reverse-migration-schedule", "", "",
+		"Name of the migrationschedule that migrates the applications back to the remote cluster")
+	performActionCommand.Flags().StringSliceVarP(&namespaceList, "namespaces", "", nil,
+		"Comma separated list of namespaces to activate. Defaults to the namespaces of the migrationschedule")
+	performActionCommand.Flags().BoolVarP(&skipFinalMigration, "skip-final-migration", "", false,
+		"Skip the migration from the remote cluster before deactivating the applications there")
+	performActionCommand.Flags().BoolVarP(&waitForCompletion, "wait", "", false, fmt.Sprintf("Wait for %v to complete", actionType))
+
+	return performActionCommand
+}
+
+// createReverseClusterPair creates the clusterpair to reach the remote
+// cluster if it doesn't exist. Storage isn't paired since the clusterpair is
+// only used to reach the scheduler of the remote cluster
+func createReverseClusterPair(name, namespace, remoteKubeFile string, ioStreams genericclioptions.IOStreams) error {
+	_, err := storkops.Instance().GetClusterPair(name, namespace)
+	if err == nil {
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	clusterPair, err := generateClusterPair(name, namespace, "", "", "", remoteKubeFile, "", true)
+	if err != nil {
+		return err
+	}
+	clusterPair.Spec.Options = nil
+	if _, err := storkops.Instance().CreateClusterPair(clusterPair); err != nil {
+		return err
+	}
+	printMsg(fmt.Sprintf("ClusterPair %v created successfully", name), ioStreams.Out)
+	return nil
+}
+
+func waitForAction(client storkclientset.Interface, name, namespace string, ioStreams genericclioptions.IOStreams) (string, error) {
+	var msg string
+	var err error
+
+	log.SetFlags(0)
+	log.SetOutput(ioutil.Discard)
+	heading := fmt.Sprintf("%s\t\t%-20s", stage, status)
+	printMsg(heading, ioStreams.Out)
+	t := func() (interface{}, bool, error) {
+		action, err := client.StorkV1alpha1().Actions(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			util.CheckErr(err)
+			return "", false, err
+		}
+		stat := fmt.Sprintf("%s\t\t%-20s", action.Status.Stage, action.Status.Status)
+		printMsg(stat, ioStreams.Out)
+		switch action.Status.Status {
+		case storkv1.ActionStatusSuccessful, storkv1.ActionStatusPartialSuccess, storkv1.ActionStatusFailed:
+			msg = getActionSummary(action)
+			return "", false, nil
+		}
+		return "", true, fmt.Errorf("%v", action.Status.Status)
+	}
+	// sleep just so that instead of blank initial stage/status,
+	// we have something at start
+	time.Sleep(actionWaitInitialDelay)
+	if _, err = task.DoRetryWithTimeout(t, actionTimeout, actionRetryTimeout); err != nil {
+		msg = "Timed out performing task"
+	}
+
+	return msg, err
+}
+
+func getActionSummary(action *storkv1.Action) string {
+	var summary []string
+	switch action.Status.Status {
+	case storkv1.ActionStatusFailed:
+		summary = append(summary, fmt.Sprintf("%v %v failed: %v", strings.Title(string(action.Spec.ActionType)), action.Name, action.Status.Reason))
+	case storkv1.ActionStatusPartialSuccess:
+		summary = append(summary, fmt.Sprintf("%v %v partially succeeded: %v", strings.Title(string(action.Spec.ActionType)), action.Name, action.Status.Reason))
+	default:
+		summary = append(summary, fmt.Sprintf("%v %v completed successfully", strings.Title(string(action.Spec.ActionType)), action.Name))
+	}
+	for _, ns := range action.Status.Namespaces {
+		summary = append(summary, fmt.Sprintf("Namespace %v: %v: %v", ns.Namespace, ns.Status, ns.Reason))
+	}
+	if action.Status.ReverseMigrationSchedule != "" {
+		summary = append(summary, fmt.Sprintf("Reverse MigrationSchedule: %v", action.Status.ReverseMigrationSchedule))
+	}
+	return strings.Join(summary, "\n")
+}
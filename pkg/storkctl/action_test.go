@@ -0,0 +1,166 @@
+//go:build unittest
+// +build unittest
+
+package storkctl
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testRemoteKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://remote:6443
+  name: remote
+contexts:
+- context:
+    cluster: remote
+    user: remote
+  name: remote
+current-context: remote
+users:
+- name: remote
+  user:
+    token: remote-token
+`
+
+func getAction(t *testing.T, name, namespace string) *storkv1.Action {
+	action, err := storkClient.StorkV1alpha1().Actions(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	require.NoError(t, err, "Error getting action")
+	return action
+}
+
+func TestPerformFailoverNoMigrationSchedule(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"perform", "failover", "-c", "pair1", "failover1"}
+
+	expected := "error: MigrationSchedule name needs to be provided for failover"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestPerformFailoverNoClusterPair(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"perform", "failover", "-m", "schedule1", "failover1"}
+
+	expected := "error: ClusterPair name needs to be provided for failover"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestPerformFailoverMultipleNames(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"perform", "failover", "-m", "schedule1", "-c", "pair1", "failover1", "failover2"}
+
+	expected := "error: at most one name can be provided for the failover"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestPerformFailover(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"perform", "failover", "-n", "test", "-m", "schedule1", "-c", "pair1",
+		"--namespaces", "ns1,ns2", "--skip-final-migration", "failover1"}
+
+	expected := "Started failover failover1\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	action := getAction(t, "failover1", "test")
+	require.Equal(t, storkv1.ActionTypeFailover, action.Spec.ActionType)
+	require.Equal(t, "schedule1", action.Spec.MigrationSchedule)
+	require.Equal(t, "pair1", action.Spec.ClusterPair)
+	require.Equal(t, []string{"ns1", "ns2"}, action.Spec.Namespaces)
+	require.True(t, action.Spec.SkipFinalMigration)
+
+	// Creating it again should fail
+	expected = "Error from server (AlreadyExists): actions.stork.libopenstorage.org \"failover1\" already exists"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestPerformFailback(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"perform", "failback", "-n", "test", "-m", "schedule1-reverse", "-c", "pair1",
+		"--reverse-migration-schedule", "schedule1", "failback1"}
+
+	expected := "Started failback failback1\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	action := getAction(t, "failback1", "test")
+	require.Equal(t, storkv1.ActionTypeFailback, action.Spec.ActionType)
+	require.Equal(t, "schedule1-reverse", action.Spec.MigrationSchedule)
+	require.Equal(t, "schedule1", action.Spec.ReverseMigrationSchedule)
+	require.False(t, action.Spec.SkipFinalMigration)
+}
+
+func TestPerformFailoverCreateClusterPair(t *testing.T) {
+	defer resetTest()
+	kubeFile, err := ioutil.TempFile("", "remote-kubeconfig")
+	require.NoError(t, err, "Error creating kubeconfig")
+	defer os.Remove(kubeFile.Name())
+	_, err = kubeFile.WriteString(testRemoteKubeConfig)
+	require.NoError(t, err, "Error writing kubeconfig")
+	require.NoError(t, kubeFile.Close(), "Error writing kubeconfig")
+
+	cmdArgs := []string{"perform", "failover", "-n", "test", "-m", "schedule1", "-c", "pair1",
+		"--remote-kube-file", kubeFile.Name(), "failover1"}
+	expected := "ClusterPair pair1 created successfully\nStarted failover failover1\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	clusterPair, err := storkops.Instance().GetClusterPair("pair1", "test")
+	require.NoError(t, err, "Error getting clusterpair")
+	require.Nil(t, clusterPair.Spec.Options)
+	require.Equal(t, "remote", clusterPair.Spec.Config.CurrentContext)
+
+	// The existing clusterpair should be used
+	cmdArgs = []string{"perform", "failover", "-n", "test", "-m", "schedule1", "-c", "pair1",
+		"--remote-kube-file", kubeFile.Name(), "failover2"}
+	expected = "Started failover failover2\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestPerformFailoverWait(t *testing.T) {
+	actionRetryTimeout = 10 * time.Second
+	defer resetTest()
+	cmdArgs := []string{"perform", "failover", "-n", "test", "-m", "schedule1", "-c", "pair1", "failover1", "--wait"}
+
+	expected := "STAGE\t\tSTATUS              \n" +
+		"Final\t\tPartialSuccess      \n" +
+		"Failover failover1 partially succeeded: Applications in some namespaces couldn't be activated\n" +
+		"Namespace ns1: Successful: Applications activated\n" +
+		"Namespace ns2: Failed: Error activating applications: error listing deployments\n" +
+		"Reverse MigrationSchedule: schedule1-reverse\n"
+	go setActionStatus(t, "failover1", "test")
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func setActionStatus(t *testing.T, name, namespace string) {
+	time.Sleep(2 * time.Second)
+	action := getAction(t, name, namespace)
+	action.Status.Stage = storkv1.ActionStageFinal
+	action.Status.Status = storkv1.ActionStatusPartialSuccess
+	action.Status.Reason = "Applications in some namespaces couldn't be activated"
+	action.Status.ReverseMigrationSchedule = "schedule1-reverse"
+	action.Status.Namespaces = []*storkv1.ActionNamespaceStatus{
+		{
+			Namespace: "ns1",
+			Stage:     storkv1.ActionStageActivate,
+			Status:    storkv1.ActionStatusSuccessful,
+			Reason:    "Applications activated",
+		},
+		{
+			Namespace: "ns2",
+			Stage:     storkv1.ActionStageActivate,
+			Status:    storkv1.ActionStatusFailed,
+			Reason:    "Error activating applications: error listing deployments",
+		},
+	}
+	_, err := storkClient.StorkV1alpha1().Actions(namespace).Update(context.TODO(), action, metav1.UpdateOptions{})
+	require.NoError(t, err, "Error updating action")
+}
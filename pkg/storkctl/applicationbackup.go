@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"text/tabwriter"
 	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/objectstore"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/portworx/sched-ops/task"
 	"github.com/spf13/cobra"
@@ -185,6 +187,65 @@ func deleteApplicationBackups(applicationBackups []string, namespace string, ioS
 	}
 }
 
+func newVerifyApplicationBackupCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var checkDecrypt bool
+	verifyApplicationBackupCommand := &cobra.Command{
+		Use:     applicationBackupSubcommand,
+		Aliases: applicationBackupAliases,
+		Short:   "Verify the objects uploaded for an applicationbackup against its manifest",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.CheckErr(fmt.Errorf("exactly one name needs to be provided for applicationbackup name"))
+				return
+			}
+			namespace := cmdFactory.GetNamespace()
+			applicationBackup, err := storkops.Instance().GetApplicationBackup(args[0], namespace)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			if applicationBackup.Status.Status != storkv1.ApplicationBackupStatusSuccessful ||
+				applicationBackup.Status.BackupPath == "" {
+				util.CheckErr(fmt.Errorf("applicationbackup %v hasn't completed successfully", applicationBackup.Name))
+				return
+			}
+			backupLocation, err := storkops.Instance().GetBackupLocation(applicationBackup.Spec.BackupLocation, namespace)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			results, err := objectstore.VerifyManifest(backupLocation, applicationBackup.Status.BackupPath, checkDecrypt)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+
+			failed := 0
+			w := tabwriter.NewWriter(ioStreams.Out, 0, 8, 3, ' ', 0)
+			fmt.Fprintln(w, "OBJECT\tSTATUS\tREASON") // nolint: errcheck
+			for _, result := range results {
+				if result.Status != objectstore.VerifyStatusOK {
+					failed++
+				}
+				fmt.Fprintf(w, "%v\t%v\t%v\n", result.Name, result.Status, result.Reason) // nolint: errcheck
+			}
+			if err := w.Flush(); err != nil {
+				util.CheckErr(err)
+				return
+			}
+			if failed != 0 {
+				util.CheckErr(fmt.Errorf("%v of %v objects failed verification for applicationbackup %v", failed, len(results), applicationBackup.Name))
+				return
+			}
+			msg := fmt.Sprintf("ApplicationBackup %v verified successfully", applicationBackup.Name)
+			printMsg(msg, ioStreams.Out)
+		},
+	}
+	verifyApplicationBackupCommand.Flags().BoolVarP(&checkDecrypt, "checkDecrypt", "", false, "Check that the objects can be decrypted with the keys of the backup location")
+
+	return verifyApplicationBackupCommand
+}
+
 func applicationBackupPrinter(
 	applicationBackupList *storkv1.ApplicationBackupList,
 	options printers.GenerateOptions,
@@ -4,11 +4,19 @@
 package storkctl
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/objectstore"
 	"github.com/portworx/sched-ops/k8s/core"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/stretchr/testify/require"
@@ -233,3 +241,148 @@ func setApplicationBackupStatus(name, namespace string, isFail bool, t *testing.
 	_, err = storkops.Instance().UpdateApplicationBackup(backup)
 	require.NoError(t, err, "Error updating ApplicationBackups")
 }
+
+func TestVerifyApplicationBackup(t *testing.T) {
+	defer resetTest()
+	mountPath, err := ioutil.TempDir("", "verify")
+	require.NoError(t, err, "Error creating mount path")
+	defer os.RemoveAll(mountPath) // nolint: errcheck
+
+	backupLocation := &storkv1.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nfslocation",
+			Namespace: "default",
+		},
+		Location: storkv1.BackupLocationItem{
+			Type: storkv1.BackupLocationNFS,
+			Path: "bucket",
+			NFSConfig: &storkv1.NFSConfig{
+				MountPath: mountPath,
+			},
+		},
+	}
+	_, err = storkops.Instance().CreateBackupLocation(backupLocation)
+	require.NoError(t, err, "Error creating backuplocation")
+
+	bucket, err := objectstore.GetBucket(backupLocation)
+	require.NoError(t, err, "Error getting bucket")
+	for _, name := range []string{"resources.json", "namespaces.json"} {
+		err = objectstore.UploadObject(bucket, backupLocation, "backup", name, []byte(name))
+		require.NoError(t, err, "Error uploading object")
+	}
+
+	backup := &storkv1.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "verifybackup",
+			Namespace: "default",
+		},
+		Spec: storkv1.ApplicationBackupSpec{
+			BackupLocation: "nfslocation",
+		},
+		Status: storkv1.ApplicationBackupStatus{
+			Status:     storkv1.ApplicationBackupStatusSuccessful,
+			BackupPath: "backup",
+		},
+	}
+	_, err = storkops.Instance().CreateApplicationBackup(backup)
+	require.NoError(t, err, "Error creating applicationbackup")
+
+	cmdArgs := []string{"verify", "applicationbackup", "verifybackup", "--checkDecrypt"}
+	expected := "OBJECT            STATUS   REASON\n" +
+		"namespaces.json   OK       \n" +
+		"resources.json    OK       \n" +
+		"ApplicationBackup verifybackup verified successfully\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	err = bucket.WriteAll(context.TODO(), filepath.Join("backup", "resources.json"), []byte("tampered"), nil)
+	require.NoError(t, err, "Error writing object")
+	expected = "error: 1 of 2 objects failed verification for applicationbackup verifybackup"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	err = bucket.Delete(context.TODO(), filepath.Join("backup", "namespaces.json"))
+	require.NoError(t, err, "Error deleting object")
+	expected = "error: 2 of 2 objects failed verification for applicationbackup verifybackup"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestVerifyApplicationBackupSignedManifest(t *testing.T) {
+	defer resetTest()
+	mountPath, err := ioutil.TempDir("", "verify")
+	require.NoError(t, err, "Error creating mount path")
+	defer os.RemoveAll(mountPath) // nolint: errcheck
+
+	backupLocation := &storkv1.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "signedlocation",
+			Namespace: "default",
+		},
+		Location: storkv1.BackupLocationItem{
+			Type:            storkv1.BackupLocationNFS,
+			Path:            "bucket",
+			EncryptionV2Key: "testkey",
+			NFSConfig: &storkv1.NFSConfig{
+				MountPath: mountPath,
+			},
+		},
+	}
+	_, err = storkops.Instance().CreateBackupLocation(backupLocation)
+	require.NoError(t, err, "Error creating backuplocation")
+
+	bucket, err := objectstore.GetBucket(backupLocation)
+	require.NoError(t, err, "Error getting bucket")
+	data, err := objectstore.Encrypt(backupLocation, nil, []byte("resources"))
+	require.NoError(t, err, "Error encrypting object")
+	err = objectstore.UploadObject(bucket, backupLocation, "backup", "resources.json", data)
+	require.NoError(t, err, "Error uploading object")
+
+	manifest, err := objectstore.GetManifest(bucket, backupLocation, "backup")
+	require.NoError(t, err, "Error getting manifest")
+	require.NotEmpty(t, manifest.MAC, "Manifest should be signed")
+	require.Len(t, manifest.Objects, 1)
+	require.Equal(t, int64(len(data)), manifest.Objects[0].Size)
+
+	backup := &storkv1.ApplicationBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "signedbackup",
+			Namespace: "default",
+		},
+		Spec: storkv1.ApplicationBackupSpec{
+			BackupLocation: "signedlocation",
+		},
+		Status: storkv1.ApplicationBackupStatus{
+			Status:     storkv1.ApplicationBackupStatusSuccessful,
+			BackupPath: "backup",
+		},
+	}
+	_, err = storkops.Instance().CreateApplicationBackup(backup)
+	require.NoError(t, err, "Error creating applicationbackup")
+
+	cmdArgs := []string{"verify", "applicationbackup", "signedbackup", "--checkDecrypt"}
+	expected := "OBJECT           STATUS   REASON\n" +
+		"resources.json   OK       \n" +
+		"ApplicationBackup signedbackup verified successfully\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	// Rewriting the object along with its checksum in the manifest should be
+	// caught by the signature
+	tampered := []byte("tampered")
+	manifest.Objects[0].Size = int64(len(tampered))
+	manifest.Objects[0].SHA256 = fmt.Sprintf("%x", sha256.Sum256(tampered))
+	manifestData, err := json.Marshal(manifest)
+	require.NoError(t, err, "Error encoding manifest")
+	err = bucket.WriteAll(context.TODO(), filepath.Join("backup", "resources.json"), tampered, nil)
+	require.NoError(t, err, "Error writing object")
+	err = bucket.WriteAll(context.TODO(), filepath.Join("backup", objectstore.ManifestObjectName), manifestData, nil)
+	require.NoError(t, err, "Error writing manifest")
+	expected = "error: error getting manifest for backup: manifest signature doesn't match, it may have been tampered with"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestVerifyApplicationBackupNotComplete(t *testing.T) {
+	defer resetTest()
+	createApplicationBackupAndVerify(t, "incompletebackup", "default", []string{"namespace1"}, "backuplocation", "", "")
+
+	cmdArgs := []string{"verify", "applicationbackup", "incompletebackup"}
+	expected := "error: applicationbackup incompletebackup hasn't completed successfully"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
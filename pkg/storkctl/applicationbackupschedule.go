@@ -5,6 +5,7 @@ import (
 	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/schedule"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -93,6 +94,7 @@ func newCreateApplicationBackupScheduleCommand(cmdFactory Factory, ioStreams gen
 
 func newGetApplicationBackupScheduleCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var backupLocation string
+	var retentionPreview bool
 	getApplicationBackupScheduleCommand := &cobra.Command{
 		Use:     applicationBackupScheduleSubcommand,
 		Aliases: applicationBackupScheduleAliases,
@@ -147,6 +149,15 @@ func newGetApplicationBackupScheduleCommand(cmdFactory Factory, ioStreams generi
 				handleEmptyList(ioStreams.Out)
 				return
 			}
+			if retentionPreview {
+				for _, sched := range applicationBackupSchedules.Items {
+					if err := printRetentionPreview(ioStreams.Out, "ApplicationBackupSchedule", sched.Namespace, sched.Name, sched.Spec.SchedulePolicyName, schedule.GetApplicationBackupRetentionItems(sched.Status.Items)); err != nil {
+						util.CheckErr(err)
+						return
+					}
+				}
+				return
+			}
 			if cmdFactory.IsWatchSet() {
 				if err := printObjectsWithWatch(c, applicationBackupSchedules, cmdFactory, applicationBackupScheduleColumns, applicationBackupSchedulePrinter, ioStreams.Out); err != nil {
 					util.CheckErr(err)
@@ -161,6 +172,7 @@ func newGetApplicationBackupScheduleCommand(cmdFactory Factory, ioStreams generi
 		},
 	}
 	getApplicationBackupScheduleCommand.Flags().StringVarP(&backupLocation, "backupLocation", "b", "", "Name of the BackupLocation for which to list applicationBackup schedules")
+	getApplicationBackupScheduleCommand.Flags().BoolVarP(&retentionPreview, "retentionPreview", "", false, "Show which applicationbackups would be retained or pruned by the GFS retention of the schedule policy")
 	cmdFactory.BindGetFlags(getApplicationBackupScheduleCommand.Flags())
 
 	return getApplicationBackupScheduleCommand
@@ -209,6 +209,61 @@ func TestGetApplicationBackupSchedulesWithStatus(t *testing.T) {
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
+func TestGetApplicationBackupSchedulesRetentionPreview(t *testing.T) {
+	defer resetTest()
+	createApplicationBackupScheduleAndVerify(t, "retentionpreviewtest", "testpolicy", "default", "backuplocation1", []string{"namespace1"}, "", "", true)
+
+	expected := "ApplicationBackupSchedule default/retentionpreviewtest: SchedulePolicy testpolicy doesn't use GFS retention\n"
+	cmdArgs := []string{"get", "applicationbackupschedules", "retentionpreviewtest", "--retentionPreview"}
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	_, err := storkops.Instance().CreateSchedulePolicy(&storkv1.SchedulePolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "retentionpolicy",
+		},
+		Policy: storkv1.SchedulePolicyItem{
+			Daily: &storkv1.DailyPolicy{
+				Time: "10:00am",
+			},
+			Retention: &storkv1.GFSRetention{
+				Daily: 2,
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating schedulepolicy")
+	applicationBackupSchedule, err := storkops.Instance().GetApplicationBackupSchedule("retentionpreviewtest", "default")
+	require.NoError(t, err, "Error getting applicationbackup schedule")
+	applicationBackupSchedule.Spec.SchedulePolicyName = "retentionpolicy"
+
+	start := time.Date(2019, time.February, 4, 10, 0, 0, 0, time.Local)
+	timestamps := make([]metav1.Time, 5)
+	for i := range timestamps {
+		timestamps[i] = metav1.NewTime(start.Add(time.Duration(i*12) * time.Hour))
+	}
+	applicationBackupSchedule.Status.Items = map[storkv1.SchedulePolicyType][]*storkv1.ScheduledApplicationBackupStatus{
+		storkv1.SchedulePolicyTypeDaily: {
+			{Name: "dailybackup1", CreationTimestamp: timestamps[0], Status: storkv1.ApplicationBackupStatusSuccessful},
+			{Name: "dailybackup2", CreationTimestamp: timestamps[1], Status: storkv1.ApplicationBackupStatusSuccessful},
+			{Name: "dailybackup3", CreationTimestamp: timestamps[2], Status: storkv1.ApplicationBackupStatusSuccessful},
+		},
+		storkv1.SchedulePolicyTypeInterval: {
+			{Name: "failedbackup", CreationTimestamp: timestamps[3], Status: storkv1.ApplicationBackupStatusFailed},
+			{Name: "inprogressbackup", CreationTimestamp: timestamps[4], Status: storkv1.ApplicationBackupStatusInProgress},
+		},
+	}
+	_, err = storkops.Instance().UpdateApplicationBackupSchedule(applicationBackupSchedule)
+	require.NoError(t, err, "Error updating applicationbackup schedule")
+
+	expected = "ApplicationBackupSchedule default/retentionpreviewtest:\n" +
+		"NAME               POLICY-TYPE   CREATED               RETAINED-BY   ACTION\n" +
+		"inprogressbackup   Interval      " + toTimeString(timestamps[4].Time) + "   InProgress    Retain\n" +
+		"failedbackup       Interval      " + toTimeString(timestamps[3].Time) + "   -             Retain\n" +
+		"dailybackup3       Daily         " + toTimeString(timestamps[2].Time) + "   Daily         Retain\n" +
+		"dailybackup2       Daily         " + toTimeString(timestamps[1].Time) + "   Daily         Retain\n" +
+		"dailybackup1       Daily         " + toTimeString(timestamps[0].Time) + "   -             Prune\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
 func TestCreateApplicationBackupSchedulesNoNamespace(t *testing.T) {
 	cmdArgs := []string{"create", "applicationbackupschedules", "-b", "backuplocation1", "applicationbackup1"}
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"text/tabwriter"
 	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
@@ -32,6 +33,9 @@ func newCreateApplicationRestoreCommand(cmdFactory Factory, ioStreams genericcli
 	var waitForCompletion bool
 	var backupName string
 	var replacePolicy string
+	var dryRun bool
+	var backupSchedule string
+	var pointInTime string
 
 	createApplicationRestoreCommand := &cobra.Command{
 		Use:     applicationRestoreSubcommand,
@@ -42,14 +46,24 @@ func newCreateApplicationRestoreCommand(cmdFactory Factory, ioStreams genericcli
 				util.CheckErr(fmt.Errorf("exactly one name needs to be provided for applicationrestore name"))
 				return
 			}
-			if backupLocation == "" {
+			// The backup location defaults to the one of the backup picked
+			// from the schedule
+			if backupLocation == "" && backupSchedule == "" {
 				util.CheckErr(fmt.Errorf("need to provide BackupLocation to use for restore"))
 				return
 			}
-			if backupName == "" {
+			if backupName == "" && backupSchedule == "" {
 				util.CheckErr(fmt.Errorf("need to provide BackupName to restore"))
 				return
 			}
+			if backupName != "" && backupSchedule != "" {
+				util.CheckErr(fmt.Errorf("only one of BackupName and schedule can be provided"))
+				return
+			}
+			if pointInTime != "" && backupSchedule == "" {
+				util.CheckErr(fmt.Errorf("need to provide the schedule to restore from at a point in time"))
+				return
+			}
 
 			applicationRestoreName = args[0]
 			applicationRestore := &storkv1.ApplicationRestore{
@@ -57,10 +71,26 @@ func newCreateApplicationRestoreCommand(cmdFactory Factory, ioStreams genericcli
 					BackupLocation: backupLocation,
 					BackupName:     backupName,
 					ReplacePolicy:  storkv1.ApplicationRestoreReplacePolicyType(replacePolicy),
+					DryRun:         dryRun,
+					BackupSchedule: backupSchedule,
 				},
 			}
 			applicationRestore.Name = applicationRestoreName
 			applicationRestore.Namespace = cmdFactory.GetNamespace()
+			if pointInTime != "" {
+				at, err := time.Parse(time.RFC3339, pointInTime)
+				if err != nil {
+					util.CheckErr(fmt.Errorf("invalid time %v, should be in RFC3339 format: %v", pointInTime, err))
+					return
+				}
+				applicationRestore.Spec.PointInTime = &metav1.Time{Time: at}
+			}
+			if backupSchedule != "" {
+				if _, err := storkops.Instance().GetApplicationBackupSchedule(backupSchedule, applicationRestore.Namespace); err != nil {
+					util.CheckErr(err)
+					return
+				}
+			}
 			_, err := storkops.Instance().CreateApplicationRestore(applicationRestore)
 			if err != nil {
 				util.CheckErr(err)
@@ -77,13 +107,22 @@ func newCreateApplicationRestoreCommand(cmdFactory Factory, ioStreams genericcli
 					return
 				}
 				printMsg(msg, ioStreams.Out)
+				if dryRun {
+					if err := printApplicationRestoreDryRun(applicationRestore.Name, applicationRestore.Namespace, ioStreams); err != nil {
+						util.CheckErr(err)
+						return
+					}
+				}
 			}
 		},
 	}
 	createApplicationRestoreCommand.Flags().BoolVarP(&waitForCompletion, "wait", "", false, "Wait for applicationrestore to complete")
+	createApplicationRestoreCommand.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Only report what the restore would do, use with --wait to print the result")
 	createApplicationRestoreCommand.Flags().StringVarP(&backupLocation, "backupLocation", "l", "", "BackupLocation to use for the restore")
 	createApplicationRestoreCommand.Flags().StringVarP(&backupName, "backupName", "b", "", "Backup to restore from")
 	createApplicationRestoreCommand.Flags().StringVarP(&replacePolicy, "replacePolicy", "r", "Retain", "Policy to use if resources being restored already exist (Retain or Delete).")
+	createApplicationRestoreCommand.Flags().StringVarP(&backupSchedule, "schedule", "", "", "ApplicationBackupSchedule to pick the backup to restore from")
+	createApplicationRestoreCommand.Flags().StringVarP(&pointInTime, "at", "", "", "Restore the latest successful backup of the schedule that finished at or before this time, in RFC3339 format (e.g. 2026-10-01T03:00:00Z)")
 
 	return createApplicationRestoreCommand
 }
@@ -182,6 +221,30 @@ func deleteApplicationRestores(applicationRestores []string, namespace string, i
 	}
 }
 
+// printApplicationRestoreDryRun prints the resources and volumes in the
+// status of a dry run restore
+func printApplicationRestoreDryRun(name, namespace string, ioStreams genericclioptions.IOStreams) error {
+	restore, err := storkops.Instance().GetApplicationRestore(name, namespace)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(ioStreams.Out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "\nResources:\n----------")              // nolint: errcheck
+	fmt.Fprintln(w, "KIND\tNAMESPACE\tNAME\tACTION\tREASON") // nolint: errcheck
+	for _, resource := range restore.Status.DryRunResources {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", // nolint: errcheck
+			resource.Kind, resource.Namespace, resource.Name, resource.Action, resource.Reason)
+	}
+	fmt.Fprintln(w, "\nVolumes:\n--------")                                          // nolint: errcheck
+	fmt.Fprintln(w, "PVC\tNAMESPACE\tSOURCE-NAMESPACE\tSTORAGE-CLASS\tSIZE\tDRIVER") // nolint: errcheck
+	for _, volume := range restore.Status.DryRunVolumes {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", // nolint: errcheck
+			volume.PersistentVolumeClaim, volume.Namespace, volume.SourceNamespace,
+			volume.StorageClass, volume.Size, volume.DriverName)
+	}
+	return w.Flush()
+}
+
 func applicationRestorePrinter(
 	applicationRestoreList *storkv1.ApplicationRestoreList,
 	options printers.GenerateOptions,
@@ -226,3 +226,105 @@ func setApplicationRestoreStatus(name, namespace string, isFail bool, t *testing
 	_, err = storkops.Instance().UpdateApplicationRestore(restore)
 	require.NoError(t, err, "Error updating ApplicationRestores")
 }
+
+func TestCreateApplicationRestoreDryRun(t *testing.T) {
+	restoreStatusRetryInterval = 10 * time.Second
+	defer resetTest()
+
+	namespace := "dummy-namespace"
+	name := "dryrun-restore"
+	cmdArgs := []string{"create", "apprestores", "-n", namespace, name, "--backupLocation", "backuplocation", "--backupName", "backupname", "--dry-run", "--wait"}
+
+	expected := "ApplicationRestore dryrun-restore started successfully\n" +
+		"STAGE\t\tSTATUS              \n" +
+		"\t\t                    \n" +
+		"Final\t\tSuccessful          \n" +
+		"ApplicationRestore dryrun-restore completed successfully\n" +
+		"\n" +
+		"Resources:\n" +
+		"----------\n" +
+		"KIND                    NAMESPACE   NAME     ACTION   REASON\n" +
+		"PersistentVolumeClaim   ns1         data     Create   Resource does not exist\n" +
+		"ConfigMap               ns1         config   Skip     Resource already exists and ReplacePolicy is set to Retain\n" +
+		"\n" +
+		"Volumes:\n" +
+		"--------\n" +
+		"PVC    NAMESPACE   SOURCE-NAMESPACE   STORAGE-CLASS   SIZE   DRIVER\n" +
+		"data   ns1         ns0                fast            1Gi    pxd\n"
+	go func() {
+		time.Sleep(10 * time.Second)
+		restore, err := storkops.Instance().GetApplicationRestore(name, namespace)
+		require.NoError(t, err, "Error getting ApplicationRestore details")
+		require.True(t, restore.Spec.DryRun, "DryRun should be set in the spec")
+		restore.Status.Status = storkv1.ApplicationRestoreStatusSuccessful
+		restore.Status.Stage = storkv1.ApplicationRestoreStageFinal
+		restore.Status.DryRunResources = []*storkv1.ApplicationRestoreDryRunResourceInfo{
+			{
+				ObjectInfo: storkv1.ObjectInfo{
+					Name:             "data",
+					Namespace:        "ns1",
+					GroupVersionKind: metav1.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"},
+				},
+				Action: storkv1.ApplicationRestoreDryRunActionCreate,
+				Reason: "Resource does not exist",
+			},
+			{
+				ObjectInfo: storkv1.ObjectInfo{
+					Name:             "config",
+					Namespace:        "ns1",
+					GroupVersionKind: metav1.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+				},
+				Action: storkv1.ApplicationRestoreDryRunActionSkip,
+				Reason: "Resource already exists and ReplacePolicy is set to Retain",
+			},
+		}
+		restore.Status.DryRunVolumes = []*storkv1.ApplicationRestoreDryRunVolumeInfo{
+			{
+				PersistentVolumeClaim: "data",
+				Namespace:             "ns1",
+				SourceNamespace:       "ns0",
+				StorageClass:          "fast",
+				Size:                  "1Gi",
+				DriverName:            "pxd",
+			},
+		}
+		_, err = storkops.Instance().UpdateApplicationRestore(restore)
+		require.NoError(t, err, "Error updating ApplicationRestores")
+	}()
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestCreateApplicationRestoreFromSchedule(t *testing.T) {
+	defer resetTest()
+	createApplicationBackupScheduleAndVerify(t, "restoreschedule", "restorepolicy", "default", "backuplocation", []string{"namespace1"}, "", "", false)
+
+	cmdArgs := []string{"create", "apprestores", "schedulerestore", "--schedule", "restoreschedule", "--at", "2026-10-01T03:00:00Z"}
+	expected := "ApplicationRestore schedulerestore started successfully\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	restore, err := storkops.Instance().GetApplicationRestore("schedulerestore", "default")
+	require.NoError(t, err, "Error getting restore")
+	require.Equal(t, "restoreschedule", restore.Spec.BackupSchedule, "ApplicationRestore schedule mismatch")
+	require.Equal(t, "", restore.Spec.BackupName, "ApplicationRestore backupName mismatch")
+	require.NotNil(t, restore.Spec.PointInTime, "ApplicationRestore point in time not set")
+	require.True(t, restore.Spec.PointInTime.Equal(&metav1.Time{Time: time.Date(2026, time.October, 1, 3, 0, 0, 0, time.UTC)}), "ApplicationRestore point in time mismatch")
+}
+
+func TestCreateApplicationRestoreFromScheduleInvalidArgs(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"create", "apprestores", "schedulerestore", "--backupName", "backupname", "--schedule", "restoreschedule"}
+	expected := "error: only one of BackupName and schedule can be provided"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"create", "apprestores", "schedulerestore", "--backupLocation", "backuplocation", "--backupName", "backupname", "--at", "2026-10-01T03:00:00Z"}
+	expected = "error: need to provide the schedule to restore from at a point in time"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"create", "apprestores", "schedulerestore", "--schedule", "restoreschedule", "--at", "yesterday"}
+	expected = "error: invalid time yesterday, should be in RFC3339 format: parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\""
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"create", "apprestores", "schedulerestore", "--schedule", "missing"}
+	expected = "Error from server (NotFound): applicationbackupschedules.stork.libopenstorage.org \"missing\" not found"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
@@ -21,6 +21,82 @@ const (
 var s3BackupLocationColumns = []string{"NAME", "PATH", "ACCESS-KEY-ID", "SECRET-ACCESS-KEY", "REGION", "ENDPOINT", "SSL-DISABLED"}
 var azureBackupLocationColumns = []string{"NAME", "PATH", "STORAGE-ACCOUNT-NAME", "STORAGE-ACCOUNT-KEY"}
 var googleBackupLocationColumns = []string{"NAME", "PATH", "PROJECT-ID"}
+var nfsBackupLocationColumns = []string{"NAME", "PATH", "SUB-PATH", "MOUNT-PATH"}
+
+func newCreateBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var locationType string
+	var path string
+	var secretConfig string
+	var encryptionKey string
+	var s3Config storkv1.S3Config
+	var azureConfig storkv1.AzureConfig
+	var googleConfig storkv1.GoogleConfig
+	var nfsConfig storkv1.NFSConfig
+
+	createBackupLocationCommand := &cobra.Command{
+		Use:     backupLocationSubcommand,
+		Aliases: []string{"bl"},
+		Short:   "Create a BackupLocation",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.CheckErr(fmt.Errorf("exactly one name needs to be provided for backuplocation name"))
+				return
+			}
+			if len(path) == 0 {
+				util.CheckErr(fmt.Errorf("path needs to be provided for backuplocation"))
+				return
+			}
+
+			backupLocation := &storkv1.BackupLocation{
+				Location: storkv1.BackupLocationItem{
+					Type:            storkv1.BackupLocationType(locationType),
+					Path:            path,
+					SecretConfig:    secretConfig,
+					EncryptionV2Key: encryptionKey,
+				},
+			}
+			switch backupLocation.Location.Type {
+			case storkv1.BackupLocationS3:
+				backupLocation.Location.S3Config = &s3Config
+			case storkv1.BackupLocationAzure:
+				backupLocation.Location.AzureConfig = &azureConfig
+			case storkv1.BackupLocationGoogle:
+				backupLocation.Location.GoogleConfig = &googleConfig
+			case storkv1.BackupLocationNFS:
+				backupLocation.Location.NFSConfig = &nfsConfig
+			default:
+				util.CheckErr(fmt.Errorf("invalid type %v for backuplocation, should be one of s3, azure, google or nfs", locationType))
+				return
+			}
+			backupLocation.Name = args[0]
+			backupLocation.Namespace = cmdFactory.GetNamespace()
+			_, err := storkops.Instance().CreateBackupLocation(backupLocation)
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			msg := fmt.Sprintf("BackupLocation %v created successfully", backupLocation.Name)
+			printMsg(msg, ioStreams.Out)
+		},
+	}
+	createBackupLocationCommand.Flags().StringVarP(&locationType, "type", "t", string(storkv1.BackupLocationS3), "Type of the backuplocation (s3, azure, google or nfs)")
+	createBackupLocationCommand.Flags().StringVarP(&path, "path", "p", "", "Bucket or directory to use for the backuplocation")
+	createBackupLocationCommand.Flags().StringVarP(&secretConfig, "secretConfig", "", "", "Name of the secret with the config for the backuplocation")
+	createBackupLocationCommand.Flags().StringVarP(&encryptionKey, "encryptionKey", "", "", "Key used to encrypt the backups")
+	createBackupLocationCommand.Flags().StringVarP(&s3Config.Endpoint, "endpoint", "", "s3.amazonaws.com", "Endpoint for the S3 objectstore")
+	createBackupLocationCommand.Flags().StringVarP(&s3Config.AccessKeyID, "accessKeyID", "", "", "Access key ID for the S3 objectstore")
+	createBackupLocationCommand.Flags().StringVarP(&s3Config.SecretAccessKey, "secretAccessKey", "", "", "Secret access key for the S3 objectstore")
+	createBackupLocationCommand.Flags().StringVarP(&s3Config.Region, "region", "", "us-east-1", "Region for the S3 objectstore")
+	createBackupLocationCommand.Flags().BoolVarP(&s3Config.DisableSSL, "disableSSL", "", false, "Disable SSL for the S3 objectstore")
+	createBackupLocationCommand.Flags().StringVarP(&azureConfig.StorageAccountName, "storageAccountName", "", "", "Storage account name for Azure Blob Storage")
+	createBackupLocationCommand.Flags().StringVarP(&azureConfig.StorageAccountKey, "storageAccountKey", "", "", "Storage account key for Azure Blob Storage")
+	createBackupLocationCommand.Flags().StringVarP(&googleConfig.ProjectID, "projectID", "", "", "Project ID for Google Cloud Storage")
+	createBackupLocationCommand.Flags().StringVarP(&googleConfig.AccountKey, "accountKey", "", "", "Service account key for Google Cloud Storage")
+	createBackupLocationCommand.Flags().StringVarP(&nfsConfig.SubPath, "subPath", "", "", "Directory on the NFS export to use for the backuplocation")
+	createBackupLocationCommand.Flags().StringVarP(&nfsConfig.MountPath, "mountPath", "", storkv1.DefaultNFSMountPath, "Path where the NFS export is mounted in the stork pods")
+
+	return createBackupLocationCommand
+}
 
 func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var showSecrets bool
@@ -74,6 +150,7 @@ func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions
 			s3BackupLocations := &storkv1.BackupLocationList{}
 			azureBackupLocations := &storkv1.BackupLocationList{}
 			googleBackupLocations := &storkv1.BackupLocationList{}
+			nfsBackupLocations := &storkv1.BackupLocationList{}
 			unknownBackupLocations := &storkv1.BackupLocationList{}
 			for _, bl := range backupLocations.Items {
 				switch bl.Location.Type {
@@ -92,6 +169,8 @@ func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions
 						bl.Location.GoogleConfig.AccountKey = hiddenString
 					}
 					googleBackupLocations.Items = append(googleBackupLocations.Items, bl)
+				case storkv1.BackupLocationNFS:
+					nfsBackupLocations.Items = append(nfsBackupLocations.Items, bl)
 				default:
 					unknownBackupLocations.Items = append(unknownBackupLocations.Items, bl)
 				}
@@ -102,7 +181,7 @@ func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions
 				return
 			}
 
-			if outputFormat == outputFormatTable {
+			if outputFormat == outputFormatTable || outputFormat == outputFormatWide {
 				if len(s3BackupLocations.Items) != 0 {
 					if _, err := fmt.Fprintf(ioStreams.Out, "\nS3:\n---\n"); err != nil {
 						util.CheckErr(err)
@@ -133,6 +212,16 @@ func newGetBackupLocationCommand(cmdFactory Factory, ioStreams genericclioptions
 						return
 					}
 				}
+				if len(nfsBackupLocations.Items) != 0 {
+					if _, err := fmt.Fprintf(ioStreams.Out, "\nNFS:\n----\n"); err != nil {
+						util.CheckErr(err)
+						return
+					}
+					if err := printObjects(c, nfsBackupLocations, cmdFactory, nfsBackupLocationColumns, nfsBackupLocationPrinter, ioStreams.Out); err != nil {
+						util.CheckErr(err)
+						return
+					}
+				}
 			} else {
 				if err := printObjects(c, backupLocations, cmdFactory, nil, nil, ioStreams.Out); err != nil {
 					util.CheckErr(err)
@@ -212,3 +301,24 @@ func googleBackupLocationPrinter(
 	}
 	return rows, nil
 }
+
+func nfsBackupLocationPrinter(
+	backupLocationList *storkv1.BackupLocationList,
+	options printers.GenerateOptions,
+) ([]metav1beta1.TableRow, error) {
+	if backupLocationList == nil {
+		return nil, nil
+	}
+
+	rows := make([]metav1beta1.TableRow, 0)
+	for _, backupLocation := range backupLocationList.Items {
+		row := getRow(&backupLocation,
+			[]interface{}{backupLocation.Name,
+				backupLocation.Location.Path,
+				backupLocation.Location.NFSConfig.SubPath,
+				backupLocation.Location.NFSConfig.MountPath},
+		)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
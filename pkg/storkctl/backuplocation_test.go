@@ -261,3 +261,66 @@ func TestAllBackupLocation(t *testing.T) {
 	cmdArgs = []string{"get", "backuplocation", "--all-namespaces"}
 	testCommon(t, cmdArgs, nil, expected, false)
 }
+
+func TestNFSBackupLocation(t *testing.T) {
+	defer resetTest()
+
+	backupLocation := &storkv1.BackupLocation{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "nfslocation",
+			Namespace: "default",
+		},
+		Location: storkv1.BackupLocationItem{
+			Type: storkv1.BackupLocationNFS,
+		},
+	}
+	_, err := storkops.Instance().CreateBackupLocation(backupLocation)
+	require.NoError(t, err, "Error creating backuplocation")
+
+	expected := "\nNFS:\n----\n" +
+		"NAME          PATH   SUB-PATH   MOUNT-PATH\n" +
+		"nfslocation                     /var/lib/stork/nfs\n"
+	cmdArgs := []string{"get", "backuplocation", "nfslocation"}
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	backupLocation.Location.Path = "testpath"
+	backupLocation.Location.NFSConfig = &storkv1.NFSConfig{
+		SubPath:   "exports/backups",
+		MountPath: "/mnt/nfs",
+	}
+	_, err = storkops.Instance().UpdateBackupLocation(backupLocation)
+	require.NoError(t, err, "Error updating backuplocation")
+
+	expected = "\nNFS:\n----\n" +
+		"NAME          PATH       SUB-PATH          MOUNT-PATH\n" +
+		"nfslocation   testpath   exports/backups   /mnt/nfs\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestCreateBackupLocation(t *testing.T) {
+	defer resetTest()
+
+	cmdArgs := []string{"create", "backuplocation"}
+	expected := "error: exactly one name needs to be provided for backuplocation name"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"create", "backuplocation", "createlocation"}
+	expected = "error: path needs to be provided for backuplocation"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"create", "backuplocation", "createlocation", "--path", "testpath", "--type", "invalid"}
+	expected = "error: invalid type invalid for backuplocation, should be one of s3, azure, google or nfs"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"create", "backuplocation", "createlocation", "--path", "testpath", "--type", "nfs",
+		"--subPath", "exports"}
+	expected = "BackupLocation createlocation created successfully\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	backupLocation, err := storkops.Instance().GetBackupLocation("createlocation", "default")
+	require.NoError(t, err, "Error getting backuplocation")
+	require.Equal(t, storkv1.BackupLocationNFS, backupLocation.Location.Type)
+	require.Equal(t, "testpath", backupLocation.Location.Path)
+	require.Equal(t, "exports", backupLocation.Location.NFSConfig.SubPath)
+	require.Equal(t, storkv1.DefaultNFSMountPath, backupLocation.Location.NFSConfig.MountPath)
+}
@@ -2,24 +2,35 @@ package storkctl
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	clusterclient "github.com/libopenstorage/openstorage/api/client/cluster"
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkclientset "github.com/libopenstorage/stork/pkg/client/clientset/versioned"
+	migration "github.com/libopenstorage/stork/pkg/migration/controllers"
 	"github.com/libopenstorage/stork/pkg/utils"
 	"github.com/portworx/sched-ops/k8s/core"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/portworx/sched-ops/task"
 	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/validation"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/kubectl/pkg/cmd/util"
@@ -31,10 +42,142 @@ const (
 	cmdPathKey            = "cmd-path"
 	gcloudPath            = "./google-cloud-sdk/bin/gcloud"
 	gcloudBinaryName      = "gcloud"
+
+	clusterPairServiceAccountPrefix  = "stork-clusterpair-"
+	clusterPairReadyTimeout          = 5 * time.Minute
+	clusterPairReadyRetryInterval    = 10 * time.Second
+	serviceAccountTokenTimeout       = 1 * time.Minute
+	serviceAccountTokenRetryInterval = 2 * time.Second
 )
 
 var clusterPairColumns = []string{"NAME", "STORAGE-STATUS", "SCHEDULER-STATUS", "CREATED"}
 
+var clusterPairRoleVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+
+// Permissions given to the service accounts used by bidirectional
+// clusterpairs on cluster scoped resources. The service accounts can't bind or
+// escalate roles, so the roles and bindings created by migrations can only
+// grant permissions that the service account already has
+var clusterPairClusterRoleRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"namespaces"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"persistentvolumes"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+	{
+		APIGroups: []string{"storage.k8s.io"},
+		Resources: []string{"storageclasses"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+	{
+		APIGroups: []string{"apiextensions.k8s.io"},
+		Resources: []string{"customresourcedefinitions"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch"},
+	},
+	{
+		APIGroups: []string{"rbac.authorization.k8s.io"},
+		Resources: []string{"clusterroles", "clusterrolebindings"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch"},
+	},
+	{
+		APIGroups: []string{storkv1.SchemeGroupVersion.Group},
+		Resources: []string{"*"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+	{
+		APIGroups: []string{"authorization.k8s.io"},
+		Resources: []string{"selfsubjectaccessreviews"},
+		Verbs:     []string{"create"},
+	},
+}
+
+// Permissions given to the service accounts used by bidirectional
+// clusterpairs in the namespace of the clusterpair and the namespaces being
+// migrated. Rules for the custom resources registered with
+// ApplicationRegistrations are added when the roles are created
+var clusterPairRoleRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"persistentvolumeclaims", "secrets", "configmaps", "services", "serviceaccounts",
+			"endpoints", "resourcequotas", "limitranges", "replicationcontrollers"},
+		Verbs: clusterPairRoleVerbs,
+	},
+	{
+		// Pods aren't migrated, but the pods of suspended custom resources
+		// are deleted when deactivating applications
+		APIGroups: []string{""},
+		Resources: []string{"pods"},
+		Verbs:     []string{"get", "list", "watch", "delete"},
+	},
+	{
+		APIGroups: []string{"apps"},
+		Resources: []string{"deployments", "statefulsets", "daemonsets", "replicasets"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+	{
+		APIGroups: []string{"batch"},
+		Resources: []string{"jobs", "cronjobs"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+	{
+		APIGroups: []string{"networking.k8s.io"},
+		Resources: []string{"ingresses", "networkpolicies"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+	{
+		APIGroups: []string{"policy"},
+		Resources: []string{"poddisruptionbudgets"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+	{
+		APIGroups: []string{"rbac.authorization.k8s.io"},
+		Resources: []string{"roles", "rolebindings"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+	{
+		// Allows the rolebindings of the migrated applications to refer to
+		// cluster roles, only in the namespaces being migrated
+		APIGroups: []string{"rbac.authorization.k8s.io"},
+		Resources: []string{"roles", "clusterroles"},
+		Verbs:     []string{"bind"},
+	},
+	{
+		APIGroups: []string{"apps.openshift.io"},
+		Resources: []string{"deploymentconfigs"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+	{
+		APIGroups: []string{"image.openshift.io"},
+		Resources: []string{"imagestreams"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+	{
+		APIGroups: []string{"route.openshift.io"},
+		Resources: []string{"routes"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+	{
+		APIGroups: []string{"template.openshift.io"},
+		Resources: []string{"templates"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+	{
+		APIGroups: []string{"ibp.com"},
+		Resources: []string{"ibppeers", "ibpcas", "ibporderers", "ibpconsoles"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+	{
+		APIGroups: []string{storkv1.SchemeGroupVersion.Group},
+		Resources: []string{"*"},
+		Verbs:     clusterPairRoleVerbs,
+	},
+}
+
 func newGetClusterPairCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	getClusterPairCommand := &cobra.Command{
 		Use:     clusterPairSubcommand,
@@ -231,7 +374,9 @@ func newGenerateClusterPairCommand(cmdFactory Factory, ioStreams genericclioptio
 
 func newCreateClusterPairCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var sIP, dIP, sPort, dPort, srcToken, destToken, projectMappingsStr string
-	var sFile, dFile string
+	var sFile, dFile, backupLocation string
+	var bidirectional bool
+	var migrationNamespaces []string
 	createClusterPairCommand := &cobra.Command{
 		Use:   clusterPairSubcommand,
 		Short: "Create ClusterPair on source and destination cluster",
@@ -252,6 +397,26 @@ func newCreateClusterPairCommand(cmdFactory Factory, ioStreams genericclioptions
 				util.CheckErr(err)
 				return
 			}
+			if bidirectional {
+				if sFile == "" || dFile == "" {
+					util.CheckErr(fmt.Errorf("both src-kube-file and dest-kube-file need to be provided for a bidirectional clusterpair"))
+					return
+				}
+				src := &clusterPairEndpoint{kubeFile: sFile, ip: sIP, port: sPort, token: srcToken}
+				dest := &clusterPairEndpoint{kubeFile: dFile, ip: dIP, port: dPort, token: destToken}
+				if err := createBidirectionalClusterPair(clusterPairName, cmdFactory.GetNamespace(), migrationNamespaces, src, dest, backupLocation, projectMappingsStr, ioStreams); err != nil {
+					util.CheckErr(err)
+				}
+				return
+			}
+			if backupLocation != "" {
+				util.CheckErr(fmt.Errorf("backup-location can only be used for a bidirectional clusterpair"))
+				return
+			}
+			if len(migrationNamespaces) != 0 {
+				util.CheckErr(fmt.Errorf("namespaces can only be used for a bidirectional clusterpair"))
+				return
+			}
 			printMsg("Using PX-Service Endpoint of DR cluster to create clusterpair...\n", ioStreams.Out)
 			ip, port, token, err := getClusterPairParams(dFile, dIP)
 			if err != nil {
@@ -335,17 +500,18 @@ func newCreateClusterPairCommand(cmdFactory Factory, ioStreams genericclioptions
 	createClusterPairCommand.Flags().StringVarP(&destToken, "dest-token", "", "", "(optional)destination cluster token for cluster pairing")
 	createClusterPairCommand.Flags().StringVarP(&projectMappingsStr, "project-mappings", "", "",
 		"project mappings between source and destination clusters, use comma-separated <source-project-id>=<dest-project-id> pairs (Currently supported only for Rancher)")
+	createClusterPairCommand.Flags().BoolVarP(&bidirectional, "bidirectional", "", false,
+		"Create the clusterpairs in both directions using service accounts with only the permissions needed for migrations, and wait for them to be ready")
+	createClusterPairCommand.Flags().StringVarP(&backupLocation, "backup-location", "", "",
+		"(optional)name of the backuplocation on the source cluster shared by both clusterpairs, only used with --bidirectional")
+	createClusterPairCommand.Flags().StringSliceVarP(&migrationNamespaces, "namespaces", "", nil,
+		"(optional)comma-separated list of namespaces that will be migrated using the clusterpairs, in addition to the namespace of the clusterpair. Only used with --bidirectional. "+
+			"Defaults to the namespaces of the migrationschedules using the clusterpair")
 
 	return createClusterPairCommand
 }
 
 func generateClusterPair(name, ns, ip, port, token, configFile, projectIDMappings string, reverse bool) (*storkv1.ClusterPair, error) {
-	opts := make(map[string]string)
-	opts["ip"] = ip
-	opts["port"] = port
-	// extract token from px-endpoint command
-	opts["token"] = token
-
 	config, err := getConfig(configFile).RawConfig()
 	if err != nil {
 		return nil, err
@@ -354,6 +520,17 @@ func generateClusterPair(name, ns, ip, port, token, configFile, projectIDMapping
 	if err != nil {
 		return nil, err
 	}
+	return generateClusterPairFromConfig(name, ns, ip, port, token, currConfig, projectIDMappings, reverse)
+}
+
+func generateClusterPairFromConfig(name, ns, ip, port, token string, currConfig clientcmdapi.Config, projectIDMappings string, reverse bool) (*storkv1.ClusterPair, error) {
+	var err error
+	opts := make(map[string]string)
+	opts["ip"] = ip
+	opts["port"] = port
+	// extract token from px-endpoint command
+	opts["token"] = token
+
 	var projectIDMap map[string]string
 	if len(projectIDMappings) != 0 {
 		projectIDMap, err = utils.ParseKeyValueList(strings.Split(projectIDMappings, ","))
@@ -515,3 +692,464 @@ func getClusterPairParams(config, endpoint string) (string, string, string, erro
 	token = resp.GetToken()
 	return ip, port, token, nil
 }
+
+// clusterPairEndpoint is the kubeconfig and the storage endpoint of one of
+// the clusters of a bidirectional clusterpair
+type clusterPairEndpoint struct {
+	kubeFile string
+	ip       string
+	port     string
+	token    string
+}
+
+// createBidirectionalClusterPair creates a clusterpair on each cluster that
+// points to the other one. The pairs use the token of a service account with
+// only the permissions needed to migrate applications in the given namespaces
+// instead of the credentials from the kubeconfig files
+func createBidirectionalClusterPair(
+	name, namespace string,
+	namespaces []string,
+	src, dest *clusterPairEndpoint,
+	backupLocation, projectMappings string,
+	ioStreams genericclioptions.IOStreams,
+) error {
+	srcConf, err := getConfig(src.kubeFile).ClientConfig()
+	if err != nil {
+		return err
+	}
+	destConf, err := getConfig(dest.kubeFile).ClientConfig()
+	if err != nil {
+		return err
+	}
+	srcStork, err := storkops.NewForConfig(srcConf)
+	if err != nil {
+		return err
+	}
+	destStork, err := storkops.NewForConfig(destConf)
+	if err != nil {
+		return err
+	}
+	if len(namespaces) == 0 {
+		namespaces, err = getBidirectionalClusterPairNamespaces(name, namespace, srcStork, destStork)
+		if err != nil {
+			return err
+		}
+		printMsg("Using namespaces "+strings.Join(namespaces, ",")+" from the migrationschedules using the clusterpair", ioStreams.Out)
+	}
+
+	printMsg("Using PX-Service Endpoint of DR cluster to create clusterpair...\n", ioStreams.Out)
+	if err := updateClusterPairEndpoint(dest); err != nil {
+		return fmt.Errorf("unable to create clusterpair from source to DR cluster. Err: %v", err)
+	}
+	printMsg("Using PX-Service endpoints of source cluster to create clusterpair...\n", ioStreams.Out)
+	if err := updateClusterPairEndpoint(src); err != nil {
+		return fmt.Errorf("unable to create clusterpair from DR to source cluster. Err: %v", err)
+	}
+
+	srcAccess, err := createClusterPairAccess(name, namespace, namespaces, src.kubeFile, srcConf, srcStork)
+	if err != nil {
+		return fmt.Errorf("error creating service account on source cluster: %v", err)
+	}
+	printMsg("Service account "+getClusterPairServiceAccountName(name)+" created successfully on source cluster", ioStreams.Out)
+	destAccess, err := createClusterPairAccess(name, namespace, namespaces, dest.kubeFile, destConf, destStork)
+	if err != nil {
+		return fmt.Errorf("error creating service account on destination cluster: %v", err)
+	}
+	printMsg("Service account "+getClusterPairServiceAccountName(name)+" created successfully on destination cluster", ioStreams.Out)
+
+	if backupLocation != "" {
+		srcClient, err := kubernetes.NewForConfig(srcConf)
+		if err != nil {
+			return err
+		}
+		destClient, err := kubernetes.NewForConfig(destConf)
+		if err != nil {
+			return err
+		}
+		srcStorkClient, err := storkclientset.NewForConfig(srcConf)
+		if err != nil {
+			return err
+		}
+		destStorkClient, err := storkclientset.NewForConfig(destConf)
+		if err != nil {
+			return err
+		}
+		if err := shareBackupLocation(backupLocation, namespace, srcClient, destClient, srcStorkClient, destStorkClient); err != nil {
+			return err
+		}
+		printMsg("BackupLocation "+backupLocation+" shared with destination cluster", ioStreams.Out)
+	}
+
+	srcClusterPair, err := generateClusterPairFromConfig(name, namespace, dest.ip, dest.port, dest.token, destAccess, projectMappings, false)
+	if err != nil {
+		return err
+	}
+	destClusterPair, err := generateClusterPairFromConfig(name, namespace, src.ip, src.port, src.token, srcAccess, projectMappings, true)
+	if err != nil {
+		return err
+	}
+	if backupLocation != "" {
+		srcClusterPair.Spec.Options[storkv1.BackupLocationResourceName] = backupLocation
+		destClusterPair.Spec.Options[storkv1.BackupLocationResourceName] = backupLocation
+	}
+	if _, err := srcStork.CreateClusterPair(srcClusterPair); err != nil {
+		return err
+	}
+	printMsg("ClusterPair "+name+" created successfully on source cluster", ioStreams.Out)
+	if _, err := destStork.CreateClusterPair(destClusterPair); err != nil {
+		return err
+	}
+	printMsg("ClusterPair "+name+" created successfully on destination cluster", ioStreams.Out)
+
+	if err := waitForClusterPairReady(srcStork, name, namespace); err != nil {
+		return fmt.Errorf("clusterpair %v on source cluster is not ready: %v", name, err)
+	}
+	printMsg("ClusterPair "+name+" is ready on source cluster", ioStreams.Out)
+	if err := waitForClusterPairReady(destStork, name, namespace); err != nil {
+		return fmt.Errorf("clusterpair %v on destination cluster is not ready: %v", name, err)
+	}
+	printMsg("ClusterPair "+name+" is ready on destination cluster", ioStreams.Out)
+	return nil
+}
+
+// getBidirectionalClusterPairNamespaces returns the namespaces migrated by the
+// migrationschedules that use the clusterpair on either cluster. The service
+// accounts of the pairs are only given permissions in these namespaces so they
+// need to be known when the pairs are created
+func getBidirectionalClusterPairNamespaces(name, namespace string, src, dest storkops.Ops) ([]string, error) {
+	namespaces := make([]string, 0)
+	found := make(map[string]bool)
+	for _, client := range []storkops.Ops{src, dest} {
+		migrationNamespaces, err := migration.GetClusterPairMigrationNamespaces(client, name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range migrationNamespaces {
+			if !found[ns] {
+				found[ns] = true
+				namespaces = append(namespaces, ns)
+			}
+		}
+	}
+	if len(namespaces) == 0 {
+		return nil, fmt.Errorf("namespaces need to be provided for a bidirectional clusterpair since no migrationschedules use clusterpair %v", name)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// updateClusterPairEndpoint fills in the storage endpoint and pairing token
+// that weren't provided
+func updateClusterPairEndpoint(endpoint *clusterPairEndpoint) error {
+	ip, port, token, err := getClusterPairParams(endpoint.kubeFile, endpoint.ip)
+	if err != nil {
+		return err
+	}
+	endpoint.ip = ip
+	if endpoint.port == "" {
+		endpoint.port = port
+	}
+	if endpoint.token == "" {
+		endpoint.token = token
+	}
+	return nil
+}
+
+func getClusterPairServiceAccountName(clusterPairName string) string {
+	return clusterPairServiceAccountPrefix + clusterPairName
+}
+
+// createClusterPairAccess creates a service account bound to roles with the
+// permissions needed to migrate applications to the cluster, and returns a
+// kubeconfig that uses its token
+func createClusterPairAccess(
+	name, namespace string,
+	namespaces []string,
+	configFile string,
+	conf *restclient.Config,
+	storkClient storkops.Ops,
+) (clientcmdapi.Config, error) {
+	client, err := kubernetes.NewForConfig(conf)
+	if err != nil {
+		return clientcmdapi.Config{}, err
+	}
+	saName := getClusterPairServiceAccountName(name)
+	if err := createClusterPairRoles(client, storkClient, name, namespace, namespaces); err != nil {
+		return clientcmdapi.Config{}, err
+	}
+	// Tokens aren't created automatically for service accounts from k8s 1.24
+	// so create the secret and wait for the token to be filled in
+	tokenSecret := saName + "-token"
+	if _, err := client.CoreV1().Secrets(namespace).Create(context.TODO(), &v1.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Name:        tokenSecret,
+			Namespace:   namespace,
+			Annotations: map[string]string{v1.ServiceAccountNameKey: saName},
+		},
+		Type: v1.SecretTypeServiceAccountToken,
+	}, meta.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return clientcmdapi.Config{}, err
+	}
+	t := func() (interface{}, bool, error) {
+		secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), tokenSecret, meta.GetOptions{})
+		if err != nil {
+			return "", true, err
+		}
+		token := secret.Data[v1.ServiceAccountTokenKey]
+		if len(token) == 0 {
+			return "", true, fmt.Errorf("token not populated in secret %v", tokenSecret)
+		}
+		return string(token), false, nil
+	}
+	token, err := task.DoRetryWithTimeout(t, serviceAccountTokenTimeout, serviceAccountTokenRetryInterval)
+	if err != nil {
+		return clientcmdapi.Config{}, err
+	}
+
+	config, err := getConfig(configFile).RawConfig()
+	if err != nil {
+		return clientcmdapi.Config{}, err
+	}
+	currConfig, err := pruneConfigContexts(config)
+	if err != nil {
+		return clientcmdapi.Config{}, err
+	}
+	return getServiceAccountConfig(currConfig, saName, token.(string))
+}
+
+// createClusterPairRoles creates the service account of the clusterpair and
+// binds it to a cluster role with the permissions needed on cluster scoped
+// resources. The permissions on namespaced resources, including secrets, are
+// only given in the namespace of the clusterpair and the namespaces being
+// migrated
+func createClusterPairRoles(
+	client kubernetes.Interface,
+	storkClient storkops.Ops,
+	name, namespace string,
+	namespaces []string,
+) error {
+	saName := getClusterPairServiceAccountName(name)
+	// The cluster role is cluster scoped so include the namespace of the pair
+	// in the name to not conflict with pairs with the same name in other
+	// namespaces. The roles use the same name since pairs from different
+	// namespaces can migrate the same namespace
+	roleName := clusterPairServiceAccountPrefix + namespace + "-" + name
+	subjects := []rbacv1.Subject{
+		{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      saName,
+			Namespace: namespace,
+		},
+	}
+
+	if _, err := client.CoreV1().Namespaces().Create(context.TODO(), &v1.Namespace{
+		ObjectMeta: meta.ObjectMeta{Name: namespace},
+	}, meta.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	if _, err := client.CoreV1().ServiceAccounts(namespace).Create(context.TODO(), &v1.ServiceAccount{
+		ObjectMeta: meta.ObjectMeta{Name: saName, Namespace: namespace},
+	}, meta.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	clusterRole := &rbacv1.ClusterRole{
+		ObjectMeta: meta.ObjectMeta{Name: roleName},
+		Rules:      clusterPairClusterRoleRules,
+	}
+	if _, err := client.RbacV1().ClusterRoles().Create(context.TODO(), clusterRole, meta.CreateOptions{}); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return err
+		}
+		existing, err := client.RbacV1().ClusterRoles().Get(context.TODO(), roleName, meta.GetOptions{})
+		if err != nil {
+			return err
+		}
+		existing.Rules = clusterPairClusterRoleRules
+		if _, err := client.RbacV1().ClusterRoles().Update(context.TODO(), existing, meta.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	if _, err := client.RbacV1().ClusterRoleBindings().Create(context.TODO(), &rbacv1.ClusterRoleBinding{
+		ObjectMeta: meta.ObjectMeta{Name: roleName},
+		Subjects:   subjects,
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     roleName,
+		},
+	}, meta.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	rules, err := getClusterPairRoleRules(storkClient)
+	if err != nil {
+		return err
+	}
+	roleNamespaces := map[string]bool{namespace: true}
+	for _, ns := range namespaces {
+		if roleNamespaces[ns] {
+			continue
+		}
+		roleNamespaces[ns] = true
+		if _, err := client.CoreV1().Namespaces().Create(context.TODO(), &v1.Namespace{
+			ObjectMeta: meta.ObjectMeta{Name: ns},
+		}, meta.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	for ns := range roleNamespaces {
+		role := &rbacv1.Role{
+			ObjectMeta: meta.ObjectMeta{Name: roleName, Namespace: ns},
+			Rules:      rules,
+		}
+		if _, err := client.RbacV1().Roles(ns).Create(context.TODO(), role, meta.CreateOptions{}); err != nil {
+			if !errors.IsAlreadyExists(err) {
+				return err
+			}
+			existing, err := client.RbacV1().Roles(ns).Get(context.TODO(), roleName, meta.GetOptions{})
+			if err != nil {
+				return err
+			}
+			existing.Rules = rules
+			if _, err := client.RbacV1().Roles(ns).Update(context.TODO(), existing, meta.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+		if _, err := client.RbacV1().RoleBindings(ns).Create(context.TODO(), &rbacv1.RoleBinding{
+			ObjectMeta: meta.ObjectMeta{Name: roleName, Namespace: ns},
+			Subjects:   subjects,
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     roleName,
+			},
+		}, meta.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getClusterPairRoleRules returns the rules for the namespaced roles of the
+// clusterpair service account, including the custom resources registered
+// with ApplicationRegistrations on the cluster
+func getClusterPairRoleRules(storkClient storkops.Ops) ([]rbacv1.PolicyRule, error) {
+	registrations, err := storkClient.ListApplicationRegistrations()
+	if err != nil {
+		return nil, fmt.Errorf("error listing applicationregistrations: %v", err)
+	}
+	rules := append([]rbacv1.PolicyRule{}, clusterPairRoleRules...)
+	groupResources := make(map[string][]string)
+	groups := make([]string, 0)
+	for _, registration := range registrations.Items {
+		for _, resource := range registration.Resources {
+			if resource.Group == storkv1.SchemeGroupVersion.Group {
+				continue
+			}
+			if _, ok := groupResources[resource.Group]; !ok {
+				groups = append(groups, resource.Group)
+			}
+			plural := migration.GetCRResourceName(resource.Kind)
+			found := false
+			for _, r := range groupResources[resource.Group] {
+				if r == plural {
+					found = true
+					break
+				}
+			}
+			if !found {
+				groupResources[resource.Group] = append(groupResources[resource.Group], plural)
+			}
+		}
+	}
+	for _, group := range groups {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: groupResources[group],
+			Verbs:     clusterPairRoleVerbs,
+		})
+	}
+	return rules, nil
+}
+
+// getServiceAccountConfig replaces the credentials of the current context with
+// the token of the service account
+func getServiceAccountConfig(config clientcmdapi.Config, saName, token string) (clientcmdapi.Config, error) {
+	currContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return config, fmt.Errorf("current context %v not found in kubeconfig", config.CurrentContext)
+	}
+	currContext.AuthInfo = saName
+	config.AuthInfos = map[string]*clientcmdapi.AuthInfo{
+		saName: {
+			Token: token,
+		},
+	}
+	return config, nil
+}
+
+// shareBackupLocation creates the backuplocation from the source cluster on
+// the destination cluster if it doesn't exist, along with the secret it refers
+// to for its credentials. The backuplocation is read without merging the
+// secret so that the credentials aren't copied into it
+func shareBackupLocation(
+	name, namespace string,
+	srcClient, destClient kubernetes.Interface,
+	srcStork, destStork storkclientset.Interface,
+) error {
+	backupLocation, err := srcStork.StorkV1alpha1().BackupLocations(namespace).Get(context.TODO(), name, meta.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting backuplocation %v on source cluster: %v", name, err)
+	}
+	if _, err := destStork.StorkV1alpha1().BackupLocations(namespace).Get(context.TODO(), name, meta.GetOptions{}); err == nil {
+		return nil
+	} else if !errors.IsNotFound(err) {
+		return fmt.Errorf("error getting backuplocation %v on destination cluster: %v", name, err)
+	}
+	if backupLocation.Location.SecretConfig != "" {
+		secretName := backupLocation.Location.SecretConfig
+		secret, err := srcClient.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, meta.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Errorf("secret %v used by backuplocation %v not found on source cluster", secretName, name)
+			}
+			return fmt.Errorf("error getting secret %v used by backuplocation %v on source cluster: %v", secretName, name, err)
+		}
+		if _, err := destClient.CoreV1().Secrets(namespace).Create(context.TODO(), &v1.Secret{
+			ObjectMeta: meta.ObjectMeta{
+				Name:        secret.Name,
+				Namespace:   namespace,
+				Labels:      secret.Labels,
+				Annotations: secret.Annotations,
+			},
+			Type: secret.Type,
+			Data: secret.Data,
+		}, meta.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("error creating secret %v used by backuplocation %v on destination cluster: %v", secretName, name, err)
+		}
+	}
+	backupLocation.ResourceVersion = ""
+	backupLocation.UID = ""
+	if _, err := destStork.StorkV1alpha1().BackupLocations(namespace).Create(context.TODO(), backupLocation, meta.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("error creating backuplocation %v on destination cluster: %v", name, err)
+	}
+	return nil
+}
+
+func waitForClusterPairReady(client *storkops.Client, name, namespace string) error {
+	t := func() (interface{}, bool, error) {
+		clusterPair, err := client.GetClusterPair(name, namespace)
+		if err != nil {
+			return "", true, err
+		}
+		storageReady := clusterPair.Status.StorageStatus == storkv1.ClusterPairStatusReady ||
+			clusterPair.Status.StorageStatus == storkv1.ClusterPairStatusNotProvided
+		if clusterPair.Status.SchedulerStatus == storkv1.ClusterPairStatusReady && storageReady {
+			return "", false, nil
+		}
+		return "", true, fmt.Errorf("scheduler status: %v, storage status: %v",
+			clusterPair.Status.SchedulerStatus, clusterPair.Status.StorageStatus)
+	}
+	_, err := task.DoRetryWithTimeout(t, clusterPairReadyTimeout, clusterPairReadyRetryInterval)
+	return err
+}
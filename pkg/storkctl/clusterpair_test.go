@@ -4,14 +4,23 @@
 package storkctl
 
 import (
+	"context"
 	"testing"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	fakeclient "github.com/libopenstorage/stork/pkg/client/clientset/versioned/fake"
+	migration "github.com/libopenstorage/stork/pkg/migration/controllers"
 	"github.com/portworx/sched-ops/k8s/core"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubernetes "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 func createClusterPairAndVerify(t *testing.T, name string, namespace string) {
@@ -126,3 +135,286 @@ func TestGenerateClusterPairInvalidNamespace(t *testing.T) {
 	expected := "error: the Namespace \"test_namespace\" is not valid: [a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')]"
 	testCommon(t, cmdArgs, nil, expected, true)
 }
+
+func TestCreateBidirectionalClusterPairNoKubeFile(t *testing.T) {
+	cmdArgs := []string{"create", "clusterpair", "pair1", "-n", "test", "--bidirectional", "--src-kube-file", "src-kubeconfig"}
+
+	expected := "error: both src-kube-file and dest-kube-file need to be provided for a bidirectional clusterpair"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestCreateClusterPairBackupLocationNotBidirectional(t *testing.T) {
+	cmdArgs := []string{"create", "clusterpair", "pair1", "-n", "test", "--backup-location", "bl1"}
+
+	expected := "error: backup-location can only be used for a bidirectional clusterpair"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestCreateClusterPairNamespacesNotBidirectional(t *testing.T) {
+	cmdArgs := []string{"create", "clusterpair", "pair1", "-n", "test", "--namespaces", "ns1,ns2"}
+
+	expected := "error: namespaces can only be used for a bidirectional clusterpair"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestCreateClusterPairRoles(t *testing.T) {
+	kubeClient := kubernetes.NewSimpleClientset()
+	storkClient := storkops.New(kubeClient, fakeclient.NewSimpleClientset(), nil)
+	_, err := storkClient.CreateApplicationRegistration(&storkv1.ApplicationRegistration{
+		ObjectMeta: metav1.ObjectMeta{Name: "cassandra"},
+		Resources: []storkv1.ApplicationResource{
+			{
+				GroupVersionKind: metav1.GroupVersionKind{Group: "cassandra.datastax.com", Version: "v1beta1", Kind: "CassandraDatacenter"},
+			},
+		},
+	})
+	require.NoError(t, err, "Error creating applicationregistration")
+
+	err = createClusterPairRoles(kubeClient, storkClient, "pair1", "admin", []string{"ns1", "ns2", "admin"})
+	require.NoError(t, err, "Error creating clusterpair roles")
+	roleName := "stork-clusterpair-admin-pair1"
+
+	clusterRole, err := kubeClient.RbacV1().ClusterRoles().Get(context.TODO(), roleName, metav1.GetOptions{})
+	require.NoError(t, err, "Error getting cluster role")
+	for _, rule := range clusterRole.Rules {
+		require.NotContains(t, rule.Verbs, "bind", "Cluster role shouldn't allow binding roles")
+		require.NotContains(t, rule.Verbs, "escalate", "Cluster role shouldn't allow escalating roles")
+		require.NotContains(t, rule.Resources, "secrets", "Cluster role shouldn't give access to secrets")
+	}
+	clusterRoleBinding, err := kubeClient.RbacV1().ClusterRoleBindings().Get(context.TODO(), roleName, metav1.GetOptions{})
+	require.NoError(t, err, "Error getting cluster role binding")
+	require.Equal(t, "stork-clusterpair-pair1", clusterRoleBinding.Subjects[0].Name, "Cluster role binding subject mismatch")
+	require.Equal(t, "admin", clusterRoleBinding.Subjects[0].Namespace, "Cluster role binding subject namespace mismatch")
+
+	roles, err := kubeClient.RbacV1().Roles("").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err, "Error listing roles")
+	require.Len(t, roles.Items, 3, "Roles should be created once in each namespace")
+	for _, ns := range []string{"admin", "ns1", "ns2"} {
+		_, err := kubeClient.CoreV1().Namespaces().Get(context.TODO(), ns, metav1.GetOptions{})
+		require.NoError(t, err, "Error getting namespace %v", ns)
+		role, err := kubeClient.RbacV1().Roles(ns).Get(context.TODO(), roleName, metav1.GetOptions{})
+		require.NoError(t, err, "Error getting role in namespace %v", ns)
+		require.Contains(t, role.Rules, rbacv1.PolicyRule{
+			APIGroups: []string{"cassandra.datastax.com"},
+			Resources: []string{"cassandradatacenters"},
+			Verbs:     clusterPairRoleVerbs,
+		}, "Role should give access to registered custom resources")
+		secretsAllowed := false
+		for _, rule := range role.Rules {
+			for _, resource := range rule.Resources {
+				if resource == "secrets" {
+					secretsAllowed = true
+				}
+			}
+		}
+		require.True(t, secretsAllowed, "Role should give access to secrets in namespace %v", ns)
+		roleBinding, err := kubeClient.RbacV1().RoleBindings(ns).Get(context.TODO(), roleName, metav1.GetOptions{})
+		require.NoError(t, err, "Error getting role binding in namespace %v", ns)
+		require.Equal(t, "Role", roleBinding.RoleRef.Kind, "Role binding should refer to the role")
+		require.Equal(t, "admin", roleBinding.Subjects[0].Namespace, "Role binding subject namespace mismatch")
+	}
+
+	// Creating the roles again should update them
+	err = createClusterPairRoles(kubeClient, storkClient, "pair1", "admin", []string{"ns1"})
+	require.NoError(t, err, "Error updating clusterpair roles")
+}
+
+func TestGetServiceAccountConfig(t *testing.T) {
+	config := clientcmdapi.Config{
+		CurrentContext: "remote",
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"remote": {Server: "https://remote:6443"},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			"remote": {Cluster: "remote", AuthInfo: "admin"},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"admin": {ClientCertificateData: []byte("cert"), ClientKeyData: []byte("key")},
+		},
+	}
+	saConfig, err := getServiceAccountConfig(config, "stork-clusterpair-pair1", "sa-token")
+	require.NoError(t, err, "Error getting service account config")
+	require.Equal(t, "stork-clusterpair-pair1", saConfig.Contexts["remote"].AuthInfo)
+	require.Len(t, saConfig.AuthInfos, 1)
+	require.Equal(t, "sa-token", saConfig.AuthInfos["stork-clusterpair-pair1"].Token)
+	require.Equal(t, "https://remote:6443", saConfig.Clusters["remote"].Server)
+
+	config.CurrentContext = "missing"
+	_, err = getServiceAccountConfig(config, "stork-clusterpair-pair1", "sa-token")
+	require.Error(t, err, "Expected error for missing context")
+}
+
+// rbacAccessReviewReactor evaluates SelfSubjectAccessReviews for the service
+// account against the roles and cluster roles bound to it in rbacClient
+func rbacAccessReviewReactor(rbacClient *kubernetes.Clientset, saName, saNamespace string) k8stesting.ReactionFunc {
+	ruleAllows := func(rules []rbacv1.PolicyRule, attributes *authorizationv1.ResourceAttributes) bool {
+		matches := func(values []string, value string) bool {
+			for _, v := range values {
+				if v == "*" || v == value {
+					return true
+				}
+			}
+			return false
+		}
+		for _, rule := range rules {
+			if matches(rule.Verbs, attributes.Verb) &&
+				matches(rule.APIGroups, attributes.Group) &&
+				matches(rule.Resources, attributes.Resource) {
+				return true
+			}
+		}
+		return false
+	}
+	boundToSA := func(subjects []rbacv1.Subject) bool {
+		for _, subject := range subjects {
+			if subject.Kind == rbacv1.ServiceAccountKind && subject.Name == saName && subject.Namespace == saNamespace {
+				return true
+			}
+		}
+		return false
+	}
+	getRules := func(ref rbacv1.RoleRef, namespace string) []rbacv1.PolicyRule {
+		if ref.Kind == "ClusterRole" {
+			role, err := rbacClient.RbacV1().ClusterRoles().Get(context.TODO(), ref.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil
+			}
+			return role.Rules
+		}
+		role, err := rbacClient.RbacV1().Roles(namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil
+		}
+		return role.Rules
+	}
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		allowed := false
+		clusterRoleBindings, err := rbacClient.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return true, nil, err
+		}
+		for _, binding := range clusterRoleBindings.Items {
+			if boundToSA(binding.Subjects) && ruleAllows(getRules(binding.RoleRef, ""), attributes) {
+				allowed = true
+			}
+		}
+		if attributes.Namespace != "" {
+			roleBindings, err := rbacClient.RbacV1().RoleBindings(attributes.Namespace).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				return true, nil, err
+			}
+			for _, binding := range roleBindings.Items {
+				if boundToSA(binding.Subjects) && ruleAllows(getRules(binding.RoleRef, attributes.Namespace), attributes) {
+					allowed = true
+				}
+			}
+		}
+		review.Status.Allowed = allowed
+		return true, review, nil
+	}
+}
+
+func TestClusterPairProbeWithGeneratedRoles(t *testing.T) {
+	rbacClient := kubernetes.NewSimpleClientset()
+	storkClient := storkops.New(rbacClient, fakeclient.NewSimpleClientset(), nil)
+	err := createClusterPairRoles(rbacClient, storkClient, "pair1", "admin", []string{"ns1", "ns2"})
+	require.NoError(t, err, "Error creating clusterpair roles")
+
+	probeClient := kubernetes.NewSimpleClientset()
+	probeClient.PrependReactor("create", "selfsubjectaccessreviews",
+		rbacAccessReviewReactor(rbacClient, "stork-clusterpair-pair1", "admin"))
+
+	condition := migration.CheckClusterPairPermissions(probeClient, []string{"admin", "ns1", "ns2"})
+	require.Equal(t, metav1.ConditionTrue, condition.Status,
+		"Generated roles should have the permissions checked by the probe: %v", condition.Message)
+	require.Equal(t, "Allowed", condition.Reason, "Unexpected reason for permissions condition")
+
+	condition = migration.CheckClusterPairPermissions(probeClient, []string{"admin", "ns1", "ns3"})
+	require.Equal(t, metav1.ConditionFalse, condition.Status, "Permissions should be missing in namespace without roles")
+	require.Equal(t, "PermissionDenied", condition.Reason, "Unexpected reason for permissions condition")
+	require.Contains(t, condition.Message, "create secrets in ns3", "Missing permissions should include the namespace")
+	require.NotContains(t, condition.Message, "in ns1", "Permissions in namespaces with roles should be allowed")
+	require.NotContains(t, condition.Message, "create namespaces", "Cluster scoped permissions should be allowed")
+}
+
+func TestGetBidirectionalClusterPairNamespaces(t *testing.T) {
+	src := storkops.New(kubernetes.NewSimpleClientset(), fakeclient.NewSimpleClientset(), nil)
+	dest := storkops.New(kubernetes.NewSimpleClientset(), fakeclient.NewSimpleClientset(), nil)
+
+	_, err := getBidirectionalClusterPairNamespaces("pair1", "admin", src, dest)
+	require.Error(t, err, "Expected error when no migrationschedules use the clusterpair")
+	require.Contains(t, err.Error(), "namespaces need to be provided", "Unexpected error without migrationschedules")
+
+	newSchedule := func(name, clusterPair string, namespaces ...string) *storkv1.MigrationSchedule {
+		return &storkv1.MigrationSchedule{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "admin"},
+			Spec: storkv1.MigrationScheduleSpec{
+				Template: storkv1.MigrationTemplateSpec{
+					Spec: storkv1.MigrationSpec{
+						ClusterPair: clusterPair,
+						Namespaces:  namespaces,
+					},
+				},
+			},
+		}
+	}
+	_, err = src.CreateMigrationSchedule(newSchedule("schedule1", "pair1", "ns2", "ns1"))
+	require.NoError(t, err, "Error creating migrationschedule")
+	_, err = src.CreateMigrationSchedule(newSchedule("schedule2", "pair2", "ns4"))
+	require.NoError(t, err, "Error creating migrationschedule")
+	_, err = dest.CreateMigrationSchedule(newSchedule("schedule1", "pair1", "ns1", "ns3"))
+	require.NoError(t, err, "Error creating migrationschedule")
+
+	namespaces, err := getBidirectionalClusterPairNamespaces("pair1", "admin", src, dest)
+	require.NoError(t, err, "Error getting namespaces for clusterpair")
+	require.Equal(t, []string{"ns1", "ns2", "ns3"}, namespaces, "Unexpected namespaces for clusterpair")
+}
+
+func TestShareBackupLocation(t *testing.T) {
+	srcClient := kubernetes.NewSimpleClientset()
+	destClient := kubernetes.NewSimpleClientset()
+	srcStork := fakeclient.NewSimpleClientset()
+	destStork := fakeclient.NewSimpleClientset()
+
+	_, err := srcStork.StorkV1alpha1().BackupLocations("admin").Create(context.TODO(), &storkv1.BackupLocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "admin"},
+		Location: storkv1.BackupLocationItem{
+			Type:         storkv1.BackupLocationS3,
+			Path:         "bucket",
+			SecretConfig: "s3-secret",
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err, "Error creating backuplocation")
+
+	err = shareBackupLocation("s3", "admin", srcClient, destClient, srcStork, destStork)
+	require.Error(t, err, "Expected error when the secret is missing")
+	require.Contains(t, err.Error(), "secret s3-secret used by backuplocation s3 not found on source cluster",
+		"Unexpected error for missing secret")
+	_, err = destStork.StorkV1alpha1().BackupLocations("admin").Get(context.TODO(), "s3", metav1.GetOptions{})
+	require.Error(t, err, "Backuplocation shouldn't be created without its secret")
+
+	_, err = srcClient.CoreV1().Secrets("admin").Create(context.TODO(), &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-secret", Namespace: "admin"},
+		Data: map[string][]byte{
+			"accessKeyID":     []byte("access"),
+			"secretAccessKey": []byte("secret"),
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err, "Error creating secret")
+
+	err = shareBackupLocation("s3", "admin", srcClient, destClient, srcStork, destStork)
+	require.NoError(t, err, "Error sharing backuplocation")
+	secret, err := destClient.CoreV1().Secrets("admin").Get(context.TODO(), "s3-secret", metav1.GetOptions{})
+	require.NoError(t, err, "Secret should be copied to destination cluster")
+	require.Equal(t, []byte("secret"), secret.Data["secretAccessKey"], "Unexpected secret data")
+	backupLocation, err := destStork.StorkV1alpha1().BackupLocations("admin").Get(context.TODO(), "s3", metav1.GetOptions{})
+	require.NoError(t, err, "Backuplocation should be created on destination cluster")
+	require.Equal(t, "s3-secret", backupLocation.Location.SecretConfig, "Backuplocation should refer to the secret")
+	require.Nil(t, backupLocation.Location.S3Config, "Credentials shouldn't be copied into the backuplocation")
+
+	// Sharing again is a no-op
+	err = shareBackupLocation("s3", "admin", srcClient, destClient, srcStork, destStork)
+	require.NoError(t, err, "Error sharing backuplocation again")
+}
@@ -78,6 +78,7 @@ func resetTest() {
 
 	core.SetInstance(core.New(fakeKubeClient))
 	storkops.SetInstance(storkops.New(fakeKubeClient, fakeStorkClient, fakeRestClient))
+	storkClient = fakeStorkClient
 	externalstorage.SetInstance(externalstorage.New(fakeRestClient))
 	openshift.SetInstance(openshift.New(fakeKubeClient, fakeOCPClient, fakeOCPSecurityClient, fakeOCPConfigClient))
 	apps.SetInstance(apps.New(fakeKubeClient.AppsV1(), fakeKubeClient.CoreV1()))
@@ -0,0 +1,19 @@
+package storkctl
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newDescribeCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	describeCommands := &cobra.Command{
+		Use:   "describe",
+		Short: "Show details of stork resources",
+	}
+
+	describeCommands.AddCommand(
+		newDescribeMigrationCommand(cmdFactory, ioStreams),
+	)
+
+	return describeCommands
+}
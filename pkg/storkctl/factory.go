@@ -18,6 +18,7 @@ import (
 
 const (
 	outputFormatTable = "table"
+	outputFormatWide  = "wide"
 	outputFormatYaml  = "yaml"
 	outputFormatJSON  = "json"
 )
@@ -75,7 +76,7 @@ func (f *factory) BindFlags(flags *pflag.FlagSet) {
 	flags.StringVarP(&f.namespace, "namespace", "n", "default", "If present, the namespace scope for this CLI request")
 	flags.StringVar(&f.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file to use for CLI requests")
 	flags.StringVar(&f.context, "context", "", "The name of the kubeconfig context to use")
-	flags.StringVarP(&f.outputFormat, "output", "o", outputFormatTable, "Output format. One of: table|json|yaml")
+	flags.StringVarP(&f.outputFormat, "output", "o", outputFormatTable, "Output format. One of: table|wide|json|yaml")
 	flags.BoolVarP(&f.watch, "watch", "w", false, "watch stork resourrces")
 	flags.IntVarP(&f.qps, "qps", "", 100, "Restrict number of k8s api requests from stork")
 	flags.IntVarP(&f.burst, "burst", "", 100, "Restrict number of k8s api requests from stork")
@@ -164,7 +165,7 @@ func (f *factory) RawConfig() (clientcmdapi.Config, error) {
 
 func (f *factory) GetOutputFormat() (string, error) {
 	switch f.outputFormat {
-	case outputFormatTable, outputFormatYaml, outputFormatJSON:
+	case outputFormatTable, outputFormatWide, outputFormatYaml, outputFormatJSON:
 		return f.outputFormat, nil
 	default:
 		return "", fmt.Errorf("unsupported output type %v", f.outputFormat)
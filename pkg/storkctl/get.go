@@ -35,6 +35,7 @@ func newGetCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *c
 		newGetApplicationCloneCommand(cmdFactory, ioStreams),
 		newGetBackupLocationCommand(cmdFactory, ioStreams),
 		newGetapplicationRegistrationCommand(cmdFactory, ioStreams),
+		newGetRuleRunCommand(cmdFactory, ioStreams),
 	)
 
 	return getCommands
@@ -102,7 +103,7 @@ func printObjects(cmd *cobra.Command, object runtime.Object, cmdFactory Factory,
 	if err != nil {
 		return err
 	}
-	if outputFormat == outputFormatTable {
+	if outputFormat == outputFormatTable || outputFormat == outputFormatWide {
 		return printTable(cmd, object, columns, cmdFactory.AllNamespaces(), printerFunc, out)
 	}
 	return printEncoded(cmd, object, outputFormat, out)
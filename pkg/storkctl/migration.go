@@ -3,7 +3,6 @@ package storkctl
 import (
 	"bufio"
 	"bytes"
-	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -12,14 +11,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/go-openapi/inflect"
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
 	migration "github.com/libopenstorage/stork/pkg/migration/controllers"
-	"github.com/portworx/sched-ops/k8s/apps"
-	"github.com/portworx/sched-ops/k8s/batch"
 	"github.com/portworx/sched-ops/k8s/core"
 	"github.com/portworx/sched-ops/k8s/dynamic"
-	"github.com/portworx/sched-ops/k8s/openshift"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/portworx/sched-ops/task"
 	"github.com/spf13/cobra"
@@ -27,10 +22,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
-	k8sdynamic "k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubernetes/pkg/printers"
@@ -48,6 +41,8 @@ var (
 )
 
 var migrationColumns = []string{"NAME", "CLUSTERPAIR", "STAGE", "STATUS", "VOLUMES", "RESOURCES", "CREATED", "ELAPSED", "TOTAL BYTES TRANSFERRED"}
+var migrationWideColumns = []string{"NAME", "CLUSTERPAIR", "STAGE", "STATUS", "VOLUMES", "RESOURCES", "CREATED", "ELAPSED", "TOTAL BYTES TRANSFERRED",
+	"CREATED RESOURCES", "UPDATED RESOURCES", "UNCHANGED RESOURCES", "PURGED RESOURCES"}
 var migrationSubcommand = "migrations"
 var migrationAliases = []string{"migration"}
 
@@ -190,18 +185,7 @@ func newActivateMigrationsCommand(cmdFactory Factory, ioStreams genericclioption
 					printMsg(fmt.Sprintf("Updated migrationschedule status for %v/%v to activated", migr.Namespace, migr.Name), ioStreams.Out)
 				}
 			}
-			for _, ns := range activationNamespaces {
-				updateStatefulSets(ns, true, ioStreams)
-				updateDeployments(ns, true, ioStreams)
-				updateDeploymentConfigs(ns, true, ioStreams)
-				updateIBPObjects("IBPPeer", ns, true, ioStreams)
-				updateIBPObjects("IBPCA", ns, true, ioStreams)
-				updateIBPObjects("IBPOrderer", ns, true, ioStreams)
-				updateIBPObjects("IBPConsole", ns, true, ioStreams)
-				updateVMObjects("VirtualMachine", ns, true, ioStreams)
-				updateCRDObjects(ns, true, ioStreams, config)
-				updateCronJobObjects(ns, true, ioStreams)
-			}
+			updateMigratedApplications(config, activationNamespaces, true, ioStreams)
 
 		},
 	}
@@ -238,18 +222,7 @@ func newDeactivateMigrationsCommand(cmdFactory Factory, ioStreams genericcliopti
 				deactivationNamespaces = append(deactivationNamespaces, cmdFactory.GetNamespace())
 			}
 
-			for _, ns := range deactivationNamespaces {
-				updateStatefulSets(ns, false, ioStreams)
-				updateDeployments(ns, false, ioStreams)
-				updateDeploymentConfigs(ns, false, ioStreams)
-				updateIBPObjects("IBPPeer", ns, false, ioStreams)
-				updateIBPObjects("IBPCA", ns, false, ioStreams)
-				updateIBPObjects("IBPOrderer", ns, false, ioStreams)
-				updateIBPObjects("IBPConsole", ns, false, ioStreams)
-				updateVMObjects("VirtualMachine", ns, true, ioStreams)
-				updateCRDObjects(ns, false, ioStreams, config)
-				updateCronJobObjects(ns, false, ioStreams)
-			}
+			updateMigratedApplications(config, deactivationNamespaces, false, ioStreams)
 
 		},
 	}
@@ -258,206 +231,24 @@ func newDeactivateMigrationsCommand(cmdFactory Factory, ioStreams genericcliopti
 	return deactivateMigrationCommand
 }
 
-func updateStatefulSets(namespace string, activate bool, ioStreams genericclioptions.IOStreams) {
-	statefulSets, err := apps.Instance().ListStatefulSets(namespace, metav1.ListOptions{})
-	if err != nil {
-		util.CheckErr(err)
-		return
-	}
-	for _, statefulSet := range statefulSets.Items {
-		if replicas, update := getUpdatedReplicaCount(statefulSet.Annotations, activate, ioStreams); update {
-			statefulSet.Spec.Replicas = &replicas
-			_, err := apps.Instance().UpdateStatefulSet(&statefulSet)
-			if err != nil {
-				printMsg(fmt.Sprintf("Error updating replicas for statefulset %v/%v : %v", statefulSet.Namespace, statefulSet.Name, err), ioStreams.ErrOut)
-				continue
-			}
-			printMsg(fmt.Sprintf("Updated replicas for statefulset %v/%v to %v", statefulSet.Namespace, statefulSet.Name, replicas), ioStreams.Out)
-		}
-
-	}
-}
-
-func updateDeployments(namespace string, activate bool, ioStreams genericclioptions.IOStreams) {
-	deployments, err := apps.Instance().ListDeployments(namespace, metav1.ListOptions{})
+// updateMigratedApplications activates or deactivates the applications
+// created by migrations in the namespaces
+func updateMigratedApplications(config *rest.Config, namespaces []string, activate bool, ioStreams genericclioptions.IOStreams) {
+	activator, err := migration.NewApplicationActivator(config, func(msg string) {
+		printMsg(msg, ioStreams.Out)
+	})
 	if err != nil {
 		util.CheckErr(err)
 		return
 	}
-	for _, deployment := range deployments.Items {
-		if replicas, update := getUpdatedReplicaCount(deployment.Annotations, activate, ioStreams); update {
-			deployment.Spec.Replicas = &replicas
-			_, err := apps.Instance().UpdateDeployment(&deployment)
-			if err != nil {
-				printMsg(fmt.Sprintf("Error updating replicas for deployment %v/%v : %v", deployment.Namespace, deployment.Name, err), ioStreams.ErrOut)
-				continue
-			}
-			printMsg(fmt.Sprintf("Updated replicas for deployment %v/%v to %v", deployment.Namespace, deployment.Name, replicas), ioStreams.Out)
+	for _, ns := range namespaces {
+		if err := activator.UpdateNamespace(ns, activate); err != nil {
+			printMsg(fmt.Sprintf("Error updating applications in namespace %v: %v", ns, err), ioStreams.ErrOut)
 		}
+		updateVMObjects("VirtualMachine", ns, activate, ioStreams)
 	}
 }
 
-func updateDeploymentConfigs(namespace string, activate bool, ioStreams genericclioptions.IOStreams) {
-	deployments, err := openshift.Instance().ListDeploymentConfigs(namespace)
-	if err != nil {
-		if !errors.IsNotFound(err) {
-			util.CheckErr(err)
-		}
-		return
-	}
-	for _, deployment := range deployments.Items {
-		if replicas, update := getUpdatedReplicaCount(deployment.Annotations, activate, ioStreams); update {
-			deployment.Spec.Replicas = replicas
-			_, err := openshift.Instance().UpdateDeploymentConfig(&deployment)
-			if err != nil {
-				printMsg(fmt.Sprintf("Error updating replicas for deploymentconfig %v/%v : %v", deployment.Namespace, deployment.Name, err), ioStreams.ErrOut)
-				continue
-			}
-			printMsg(fmt.Sprintf("Updated replicas for deploymentconfig %v/%v to %v", deployment.Namespace, deployment.Name, replicas), ioStreams.Out)
-		}
-	}
-}
-
-func updateCRDObjects(ns string, activate bool, ioStreams genericclioptions.IOStreams, config *rest.Config) {
-	crdList, err := storkops.Instance().ListApplicationRegistrations()
-	if err != nil {
-		util.CheckErr(err)
-		return
-	}
-	configClient, err := k8sdynamic.NewForConfig(config)
-	if err != nil {
-		util.CheckErr(err)
-		return
-	}
-	ruleset := inflect.NewDefaultRuleset()
-	ruleset.AddPlural("quota", "quotas")
-	ruleset.AddPlural("prometheus", "prometheuses")
-	ruleset.AddPlural("mongodbcommunity", "mongodbcommunity")
-	for _, res := range crdList.Items {
-		for _, crd := range res.Resources {
-			var client k8sdynamic.ResourceInterface
-			opts := &metav1.ListOptions{
-				TypeMeta: metav1.TypeMeta{
-					Kind:       crd.Kind,
-					APIVersion: crd.Group + "/" + crd.Version},
-			}
-			gvk := schema.FromAPIVersionAndKind(opts.APIVersion, opts.Kind)
-			resourceInterface := configClient.Resource(gvk.GroupVersion().WithResource(ruleset.Pluralize(strings.ToLower(gvk.Kind))))
-			client = resourceInterface.Namespace(ns)
-			objects, err := client.List(context.TODO(), *opts)
-			if err != nil {
-				if errors.IsNotFound(err) {
-					continue
-				}
-				util.CheckErr(err)
-				return
-			}
-			for _, o := range objects.Items {
-				annotations := o.GetAnnotations()
-				if annotations == nil {
-					printMsg(fmt.Sprintf("Warn: Skipping CR update %s-%s/%s, annotations not found", strings.ToLower(crd.Kind), o.GetNamespace(), o.GetName()), ioStreams.ErrOut)
-					continue
-				}
-				if crd.SuspendOptions.Path != "" {
-					crd.NestedSuspendOptions = append(crd.NestedSuspendOptions, crd.SuspendOptions)
-				}
-				if len(crd.NestedSuspendOptions) == 0 {
-					continue
-				}
-				for _, suspend := range crd.NestedSuspendOptions {
-					specPath := strings.Split(suspend.Path, ".")
-					if len(specPath) > 1 {
-						var disableVersion interface{}
-						if suspend.Type == "bool" {
-							if val, err := strconv.ParseBool(suspend.Value); err != nil {
-								disableVersion = !activate
-							} else {
-								disableVersion = val
-								if activate {
-									disableVersion = !val
-								}
-							}
-						} else if suspend.Type == "int" {
-							replicas, _ := getSuspendIntOpts(o.GetAnnotations(), activate, suspend.Path, ioStreams)
-							disableVersion = replicas
-						} else if suspend.Type == "string" {
-							suspend, err := getSuspendStringOpts(o.GetAnnotations(), activate, suspend.Path, ioStreams)
-							if err != nil {
-								util.CheckErr(err)
-								return
-							}
-							disableVersion = suspend
-						} else {
-							util.CheckErr(fmt.Errorf("invalid type %v to suspend cr", crd.SuspendOptions.Type))
-							return
-						}
-						err := unstructured.SetNestedField(o.Object, disableVersion, specPath...)
-						if err != nil {
-							printMsg(fmt.Sprintf("Error updating \"%v\" for %v %v/%v to %v : %v", suspend.Path, strings.ToLower(crd.Kind), o.GetNamespace(), o.GetName(), disableVersion, err), ioStreams.ErrOut)
-							continue
-						}
-					}
-				}
-				_, err = client.Update(context.TODO(), &o, metav1.UpdateOptions{}, "")
-				if err != nil {
-					printMsg(fmt.Sprintf("Error updating CR %v %v/%v: %v", strings.ToLower(crd.Kind), o.GetNamespace(), o.GetName(), err), ioStreams.ErrOut)
-					continue
-				}
-				printMsg(fmt.Sprintf("Updated CR for %v %v/%v", strings.ToLower(crd.Kind), o.GetNamespace(), o.GetName()), ioStreams.Out)
-				if !activate {
-					if crd.PodsPath == "" {
-						continue
-					}
-					podpath := strings.Split(crd.PodsPath, ".")
-					pods, found, err := unstructured.NestedStringSlice(o.Object, podpath...)
-					if err != nil {
-						printMsg(fmt.Sprintf("Error getting pods for %v %v/%v : %v", strings.ToLower(crd.Kind), o.GetNamespace(), o.GetName(), err), ioStreams.ErrOut)
-						continue
-					}
-					if !found {
-						continue
-					}
-					for _, pod := range pods {
-						err = core.Instance().DeletePod(o.GetNamespace(), pod, true)
-						printMsg(fmt.Sprintf("Error deleting pod %v for %v %v/%v : %v", pod, strings.ToLower(crd.Kind), o.GetNamespace(), o.GetName(), err), ioStreams.ErrOut)
-						continue
-					}
-				}
-			}
-
-		}
-	}
-}
-func updateIBPObjects(kind string, namespace string, activate bool, ioStreams genericclioptions.IOStreams) {
-	objects, err := dynamic.Instance().ListObjects(
-		&metav1.ListOptions{
-			TypeMeta: metav1.TypeMeta{
-				Kind:       kind,
-				APIVersion: "ibp.com/v1alpha1"},
-		},
-		namespace)
-	if err != nil {
-		if !errors.IsNotFound(err) {
-			util.CheckErr(err)
-		}
-		return
-	}
-	for _, o := range objects.Items {
-		if replicas, update := getUpdatedReplicaCount(o.GetAnnotations(), activate, ioStreams); update {
-			err := unstructured.SetNestedField(o.Object, int64(replicas), "spec", "replicas")
-			if err != nil {
-				printMsg(fmt.Sprintf("Error updating replicas for %v %v/%v : %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), err), ioStreams.ErrOut)
-				continue
-			}
-			_, err = dynamic.Instance().UpdateObject(&o)
-			if err != nil {
-				printMsg(fmt.Sprintf("Error updating replicas for %v %v/%v : %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), err), ioStreams.ErrOut)
-				continue
-			}
-			printMsg(fmt.Sprintf("Updated replicas for %v %v/%v to %v", strings.ToLower(kind), o.GetNamespace(), o.GetName(), replicas), ioStreams.Out)
-		}
-	}
-}
 func updateVMObjects(kind string, namespace string, activate bool, ioStreams genericclioptions.IOStreams) {
 	objects, err := dynamic.Instance().ListObjects(
 		&metav1.ListOptions{
@@ -479,90 +270,6 @@ func updateVMObjects(kind string, namespace string, activate bool, ioStreams gen
 	}
 }
 
-func updateCronJobObjects(namespace string, activate bool, ioStreams genericclioptions.IOStreams) {
-	cronJobs, err := batch.Instance().ListCronJobs(namespace, metav1.ListOptions{})
-	if err != nil {
-		util.CheckErr(err)
-		return
-	}
-
-	for _, cronJob := range cronJobs.Items {
-		*cronJob.Spec.Suspend = !activate
-		_, err = batch.Instance().UpdateCronJob(&cronJob)
-		if err != nil {
-			printMsg(fmt.Sprintf("Error updating suspend option for cronJob %v/%v : %v", cronJob.Namespace, cronJob.Name, err), ioStreams.ErrOut)
-			continue
-		}
-		printMsg(fmt.Sprintf("Updated suspend option for cronjob %v/%v to %v", cronJob.Namespace, cronJob.Name, !activate), ioStreams.Out)
-	}
-
-}
-func getSuspendStringOpts(annotations map[string]string, activate bool, path string, ioStreams genericclioptions.IOStreams) (string, error) {
-	if val, present := annotations[migration.StorkAnnotationPrefix+path]; present {
-		suspend := strings.Split(val, ",")
-		if len(suspend) != 2 {
-			return "", fmt.Errorf("migrated annotation does not have proper values %s/%s", migration.StorkAnnotationPrefix+path, val)
-		}
-		if activate {
-			return suspend[0], nil
-		}
-		return suspend[1], nil
-	}
-	// for backward compatibility of old migrated cr's
-	crdOpts := migration.StorkMigrationCRDActivateAnnotation
-	if !activate {
-		crdOpts = migration.StorkMigrationCRDDeactivateAnnotation
-	}
-	suspend, present := annotations[crdOpts]
-	if !present {
-		return "", fmt.Errorf("required migration annotation not found %s", crdOpts)
-	}
-	return suspend, nil
-}
-
-func getSuspendIntOpts(annotations map[string]string, activate bool, path string, ioStreams genericclioptions.IOStreams) (int64, bool) {
-	intOpts := ""
-	if val, present := annotations[migration.StorkAnnotationPrefix+path]; present {
-		intOpts = strings.Split(val, ",")[0]
-	} else if val, present := annotations[migration.StorkMigrationCRDActivateAnnotation]; present {
-		// for old migrated cr compatibility
-		intOpts = val
-	} else {
-		return 0, false
-	}
-	var replicas int64
-	if activate {
-		parsedReplicas, err := strconv.Atoi(intOpts)
-		if err != nil {
-			printMsg(fmt.Sprintf("Error parsing replicas for app : %v", err), ioStreams.ErrOut)
-			return 0, false
-		}
-		replicas = int64(parsedReplicas)
-	} else {
-		replicas = 0
-	}
-	return replicas, true
-}
-
-func getUpdatedReplicaCount(annotations map[string]string, activate bool, ioStreams genericclioptions.IOStreams) (int32, bool) {
-	if replicas, present := annotations[migration.StorkMigrationReplicasAnnotation]; present {
-		var updatedReplicas int32
-		if activate {
-			parsedReplicas, err := strconv.Atoi(replicas)
-			if err != nil {
-				printMsg(fmt.Sprintf("Error parsing replicas for app : %v", err), ioStreams.ErrOut)
-				return 0, false
-			}
-			updatedReplicas = int32(parsedReplicas)
-		} else {
-			updatedReplicas = 0
-		}
-		return updatedReplicas, true
-	}
-
-	return 0, false
-}
-
 func newGetMigrationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var clusterPair string
 	getMigrationCommand := &cobra.Command{
@@ -618,14 +325,20 @@ func newGetMigrationCommand(cmdFactory Factory, ioStreams genericclioptions.IOSt
 				handleEmptyList(ioStreams.Out)
 				return
 			}
+			columns := migrationColumns
+			var printer interface{} = migrationPrinter
+			if outputFormat, err := cmdFactory.GetOutputFormat(); err == nil && outputFormat == outputFormatWide {
+				columns = migrationWideColumns
+				printer = migrationWidePrinter
+			}
 			if cmdFactory.IsWatchSet() {
-				if err := printObjectsWithWatch(c, migrations, cmdFactory, migrationColumns, migrationPrinter, ioStreams.Out); err != nil {
+				if err := printObjectsWithWatch(c, migrations, cmdFactory, columns, printer, ioStreams.Out); err != nil {
 					util.CheckErr(err)
 					return
 				}
 				return
 			}
-			if err := printObjects(c, migrations, cmdFactory, migrationColumns, migrationPrinter, ioStreams.Out); err != nil {
+			if err := printObjects(c, migrations, cmdFactory, columns, printer, ioStreams.Out); err != nil {
 				util.CheckErr(err)
 				return
 			}
@@ -637,6 +350,73 @@ func newGetMigrationCommand(cmdFactory Factory, ioStreams genericclioptions.IOSt
 	return getMigrationCommand
 }
 
+func newDescribeMigrationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	describeMigrationCommand := &cobra.Command{
+		Use:     migrationSubcommand,
+		Aliases: migrationAliases,
+		Short:   "Describe migrations and the changes made to the resources on the destination cluster",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) == 0 {
+				util.CheckErr(fmt.Errorf("at least one argument needs to be provided for migration name"))
+				return
+			}
+			namespace := cmdFactory.GetNamespace()
+			for i, migrationName := range args {
+				migration, err := storkops.Instance().GetMigration(migrationName, namespace)
+				if err != nil {
+					util.CheckErr(err)
+					return
+				}
+				if i > 0 {
+					printMsg("", ioStreams.Out)
+				}
+				printMsg(describeMigration(migration), ioStreams.Out)
+			}
+		},
+	}
+
+	return describeMigrationCommand
+}
+
+func describeMigration(migration *storkv1.Migration) string {
+	lines := []string{
+		fmt.Sprintf("Name:        %v", migration.Name),
+		fmt.Sprintf("Namespace:   %v", migration.Namespace),
+		fmt.Sprintf("ClusterPair: %v", migration.Spec.ClusterPair),
+		fmt.Sprintf("Stage:       %v", migration.Status.Stage),
+		fmt.Sprintf("Status:      %v", migration.Status.Status),
+	}
+	if summary := migration.Status.Summary; summary != nil {
+		lines = append(lines,
+			"Summary:",
+			fmt.Sprintf("  Volumes:   %v/%v", summary.NumberOfMigratedVolumes, summary.TotalNumberOfVolumes),
+			fmt.Sprintf("  Resources: %v/%v", summary.NumberOfMigratedResources, summary.TotalNumberOfResources),
+			fmt.Sprintf("  Created:   %v", summary.NumberOfCreatedResources),
+			fmt.Sprintf("  Updated:   %v", summary.NumberOfUpdatedResources),
+			fmt.Sprintf("  Unchanged: %v", summary.NumberOfUnchangedResources),
+			fmt.Sprintf("  Purged:    %v", summary.NumberOfPurgedResources),
+		)
+	}
+	if len(migration.Status.Resources) != 0 {
+		lines = append(lines, "Resources:")
+		for _, resource := range migration.Status.Resources {
+			name := resource.Name
+			if resource.Namespace != "" {
+				name = resource.Namespace + "/" + resource.Name
+			}
+			line := fmt.Sprintf("  %v %v: %v", resource.Kind, name, resource.Status)
+			if resource.Action != "" {
+				line = fmt.Sprintf("%v, %v", line, resource.Action)
+			}
+			if resource.Diff != "" {
+				line = fmt.Sprintf("%v (%v)", line, resource.Diff)
+			}
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func newDeleteMigrationCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var clusterPair string
 	deleteMigrationCommand := &cobra.Command{
@@ -777,6 +557,31 @@ func migrationPrinter(
 	return rows, nil
 }
 
+// migrationWidePrinter adds the number of resources created, updated,
+// unchanged and purged on the destination cluster to the default columns
+func migrationWidePrinter(
+	migrationList *storkv1.MigrationList,
+	options printers.GenerateOptions,
+) ([]metav1beta1.TableRow, error) {
+	rows, err := migrationPrinter(migrationList, options)
+	if err != nil || migrationList == nil {
+		return rows, err
+	}
+	for i, migration := range migrationList.Items {
+		if migration.Status.Summary == nil {
+			rows[i].Cells = append(rows[i].Cells, "N/A", "N/A", "N/A", "N/A")
+			continue
+		}
+		rows[i].Cells = append(rows[i].Cells,
+			strconv.FormatUint(migration.Status.Summary.NumberOfCreatedResources, 10),
+			strconv.FormatUint(migration.Status.Summary.NumberOfUpdatedResources, 10),
+			strconv.FormatUint(migration.Status.Summary.NumberOfUnchangedResources, 10),
+			strconv.FormatUint(migration.Status.Summary.NumberOfPurgedResources, 10),
+		)
+	}
+	return rows, nil
+}
+
 func waitForMigration(name, namespace string, ioStreams genericclioptions.IOStreams) (string, error) {
 	var msg string
 	var err error
@@ -168,6 +168,108 @@ func TestGetMigrationsWithSummary(t *testing.T) {
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
+func createMigrationWithResourceChanges(t *testing.T) *storkv1.Migration {
+	createMigrationAndVerify(t, "getmigrationwidetest", "default", "clusterpair1", []string{"namespace1"}, "", "")
+	migration, err := storkops.Instance().GetMigration("getmigrationwidetest", "default")
+	require.NoError(t, err, "Error getting migration")
+
+	migration.Status.FinishTimestamp = metav1.Now()
+	migration.CreationTimestamp = metav1.NewTime(migration.Status.FinishTimestamp.Add(-5 * time.Minute))
+	migration.Status.Stage = storkv1.MigrationStageFinal
+	migration.Status.Status = storkv1.MigrationStatusSuccessful
+	migration.Status.Resources = []*storkv1.MigrationResourceInfo{
+		{
+			Name:      "app",
+			Namespace: "namespace1",
+			GroupVersionKind: metav1.GroupVersionKind{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			},
+			Status: storkv1.MigrationStatusSuccessful,
+			Action: storkv1.MigrationResourceActionUpdated,
+			Diff:   "spec.replicas: 1 -> 2",
+		},
+		{
+			Name:      "config",
+			Namespace: "namespace1",
+			GroupVersionKind: metav1.GroupVersionKind{
+				Version: "v1",
+				Kind:    "ConfigMap",
+			},
+			Status: storkv1.MigrationStatusSuccessful,
+			Action: storkv1.MigrationResourceActionCreated,
+		},
+		{
+			Name:      "old",
+			Namespace: "namespace1",
+			GroupVersionKind: metav1.GroupVersionKind{
+				Version: "v1",
+				Kind:    "Service",
+			},
+			Status: storkv1.MigrationStatusPurged,
+			Action: storkv1.MigrationResourceActionPurged,
+		},
+	}
+	migration.Status.Summary = &storkv1.MigrationSummary{
+		TotalBytesMigrated:              uint64(12345),
+		TotalNumberOfResources:          uint64(2),
+		NumberOfMigratedResources:       uint64(2),
+		NumberOfCreatedResources:        uint64(1),
+		NumberOfUpdatedResources:        uint64(1),
+		NumberOfPurgedResources:         uint64(1),
+		ElapsedTimeForVolumeMigration:   "5m0s",
+		ElapsedTimeForResourceMigration: "1m0s",
+	}
+	migration, err = storkops.Instance().UpdateMigration(migration)
+	require.NoError(t, err, "Error updating migration")
+	return migration
+}
+
+func TestGetMigrationsWide(t *testing.T) {
+	defer resetTest()
+	migration := createMigrationWithResourceChanges(t)
+
+	expected := "NAME                   CLUSTERPAIR    STAGE   STATUS       VOLUMES   RESOURCES   CREATED               ELAPSED" +
+		"                           TOTAL BYTES TRANSFERRED   CREATED RESOURCES   UPDATED RESOURCES   UNCHANGED RESOURCES   PURGED RESOURCES\n" +
+		"getmigrationwidetest   clusterpair1   Final   Successful   0/0       2/2         " + toTimeString(migration.CreationTimestamp.Time) +
+		"   Volumes (5m0s) Resources (1m0s)   12345                     1                   1                   0                     1\n"
+	cmdArgs := []string{"get", "migrations", "getmigrationwidetest", "-o", "wide"}
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestDescribeMigrationNoName(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"describe", "migrations"}
+
+	expected := "error: at least one argument needs to be provided for migration name"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
+
+func TestDescribeMigration(t *testing.T) {
+	defer resetTest()
+	createMigrationWithResourceChanges(t)
+
+	expected := "Name:        getmigrationwidetest\n" +
+		"Namespace:   default\n" +
+		"ClusterPair: clusterpair1\n" +
+		"Stage:       Final\n" +
+		"Status:      Successful\n" +
+		"Summary:\n" +
+		"  Volumes:   0/0\n" +
+		"  Resources: 2/2\n" +
+		"  Created:   1\n" +
+		"  Updated:   1\n" +
+		"  Unchanged: 0\n" +
+		"  Purged:    1\n" +
+		"Resources:\n" +
+		"  Deployment namespace1/app: Successful, Updated (spec.replicas: 1 -> 2)\n" +
+		"  ConfigMap namespace1/config: Successful, Created\n" +
+		"  Service namespace1/old: Purged, Purged\n"
+	cmdArgs := []string{"describe", "migrations", "getmigrationwidetest"}
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
 func TestCreateMigrationsNoNamespace(t *testing.T) {
 	cmdArgs := []string{"create", "migrations", "-c", "clusterPair1", "migration1"}
 
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/schedule"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/spf13/cobra"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
@@ -101,6 +102,7 @@ func newCreateMigrationScheduleCommand(cmdFactory Factory, ioStreams genericclio
 
 func newGetMigrationScheduleCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var clusterPair string
+	var retentionPreview bool
 	getMigrationScheduleCommand := &cobra.Command{
 		Use:     migrationScheduleSubcommand,
 		Aliases: migrationScheduleAliases,
@@ -155,6 +157,15 @@ func newGetMigrationScheduleCommand(cmdFactory Factory, ioStreams genericcliopti
 				handleEmptyList(ioStreams.Out)
 				return
 			}
+			if retentionPreview {
+				for _, sched := range migrationSchedules.Items {
+					if err := printRetentionPreview(ioStreams.Out, "MigrationSchedule", sched.Namespace, sched.Name, sched.Spec.SchedulePolicyName, schedule.GetMigrationRetentionItems(sched.Status.Items)); err != nil {
+						util.CheckErr(err)
+						return
+					}
+				}
+				return
+			}
 			if cmdFactory.IsWatchSet() {
 				if err := printObjectsWithWatch(c, migrationSchedules, cmdFactory, migrationScheduleColumns, migrationSchedulePrinter, ioStreams.Out); err != nil {
 					util.CheckErr(err)
@@ -169,6 +180,7 @@ func newGetMigrationScheduleCommand(cmdFactory Factory, ioStreams genericcliopti
 		},
 	}
 	getMigrationScheduleCommand.Flags().StringVarP(&clusterPair, "clusterpair", "c", "", "Name of the cluster pair for which to list migration schedules")
+	getMigrationScheduleCommand.Flags().BoolVarP(&retentionPreview, "retentionPreview", "", false, "Show which migrations would be retained or pruned by the GFS retention of the schedule policy")
 	cmdFactory.BindGetFlags(getMigrationScheduleCommand.Flags())
 
 	return getMigrationScheduleCommand
@@ -0,0 +1,132 @@
+package storkctl
+
+import (
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/cmd/util"
+)
+
+var ruleRunSubcommand = "ruleruns"
+var ruleRunAliases = []string{"rulerun"}
+
+func newGetRuleRunCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	var showOutput bool
+	getRuleRunCommand := &cobra.Command{
+		Use:     ruleRunSubcommand,
+		Aliases: ruleRunAliases,
+		Short:   "Get the actions run by the rules for an applicationbackup, migration, groupsnapshot or applicationclone",
+		Run: func(c *cobra.Command, args []string) {
+			if len(args) != 2 {
+				util.CheckErr(fmt.Errorf("the type and name of the object that ran the rules need to be provided"))
+				return
+			}
+			ruleRuns, err := getRuleRuns(args[0], args[1], cmdFactory.GetNamespace())
+			if err != nil {
+				util.CheckErr(err)
+				return
+			}
+			if len(ruleRuns) == 0 {
+				handleEmptyList(ioStreams.Out)
+				return
+			}
+
+			w := tabwriter.NewWriter(ioStreams.Out, 0, 8, 3, ' ', 0)
+			header := "RULE\tTYPE\tPOD\tCONTAINER\tACTION\tCOMMAND\tSTATUS\tEXIT CODE\tSTARTED\tELAPSED"
+			if showOutput {
+				header += "\tSTDOUT\tSTDERR\tREASON"
+			}
+			fmt.Fprintln(w, header) // nolint: errcheck
+			for _, run := range ruleRuns {
+				fmt.Fprint(w, ruleRunRow(run, showOutput)) // nolint: errcheck
+			}
+			if err := w.Flush(); err != nil {
+				util.CheckErr(err)
+				return
+			}
+		},
+	}
+	getRuleRunCommand.Flags().BoolVarP(&showOutput, "showOutput", "", false, "Show the output of the commands and the reason for failures")
+
+	return getRuleRunCommand
+}
+
+// getRuleRuns returns the runs recorded in the status of the object of the
+// given type
+func getRuleRuns(objectType string, name string, namespace string) ([]*storkv1.RuleRun, error) {
+	switch {
+	case isSubcommand(objectType, applicationBackupSubcommand, applicationBackupAliases):
+		backup, err := storkops.Instance().GetApplicationBackup(name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return backup.Status.RuleRuns, nil
+	case isSubcommand(objectType, migrationSubcommand, migrationAliases):
+		migration, err := storkops.Instance().GetMigration(name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return migration.Status.RuleRuns, nil
+	case isSubcommand(objectType, groupSnapshotSubcommand, groupSnapshotAliases):
+		groupSnapshot, err := storkops.Instance().GetGroupSnapshot(name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return groupSnapshot.Status.RuleRuns, nil
+	case isSubcommand(objectType, applicationCloneSubcommand, applicationCloneAliases):
+		clone, err := storkops.Instance().GetApplicationClone(name, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return clone.Status.RuleRuns, nil
+	}
+	return nil, fmt.Errorf("rule runs are not recorded for type %v, should be one of %v, %v, %v or %v",
+		objectType, applicationBackupSubcommand, migrationSubcommand, groupSnapshotSubcommand, applicationCloneSubcommand)
+}
+
+func isSubcommand(name string, subcommand string, aliases []string) bool {
+	if name == subcommand {
+		return true
+	}
+	for _, alias := range aliases {
+		if name == alias {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleRunRow(run *storkv1.RuleRun, showOutput bool) string {
+	pod := ""
+	if run.Pod != "" {
+		pod = run.Namespace + "/" + run.Pod
+	}
+	exitCode := ""
+	if run.ExitCode != nil {
+		exitCode = strconv.Itoa(*run.ExitCode)
+	}
+	elapsed := ""
+	if !run.FinishTimestamp.IsZero() {
+		elapsed = run.FinishTimestamp.Sub(run.StartTimestamp.Time).String()
+	}
+	row := fmt.Sprintf("%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v",
+		run.Rule,
+		run.RuleType,
+		pod,
+		run.Container,
+		run.Action,
+		strconv.Quote(run.Command),
+		run.Status,
+		exitCode,
+		toTimeString(run.StartTimestamp.Time),
+		elapsed)
+	if showOutput {
+		row += fmt.Sprintf("\t%v\t%v\t%v", strconv.Quote(run.Stdout), strconv.Quote(run.Stderr), run.Reason)
+	}
+	return row + "\n"
+}
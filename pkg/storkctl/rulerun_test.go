@@ -0,0 +1,98 @@
+//go:build unittest
+// +build unittest
+
+package storkctl
+
+import (
+	"testing"
+	"time"
+
+	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	storkops "github.com/portworx/sched-ops/k8s/stork"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func createRuleRunsBackup(t *testing.T, name string, namespace string) {
+	createApplicationBackupAndVerify(t, name, namespace, []string{"namespace1"}, "backuplocation", "prerule", "")
+	backup, err := storkops.Instance().GetApplicationBackup(name, namespace)
+	require.NoError(t, err, "Error getting backup")
+
+	start := time.Date(2021, time.January, 2, 3, 4, 5, 0, time.UTC)
+	exitCode := 0
+	failedExitCode := 1
+	backup.Status.RuleRuns = []*storkv1.RuleRun{
+		{
+			Rule:            "prerule",
+			RuleType:        "preExecRule",
+			Namespace:       "namespace1",
+			Pod:             "mysql-0",
+			Container:       "mysql",
+			Action:          storkv1.RuleActionCommand,
+			Command:         "mysql -e 'flush tables'",
+			Status:          storkv1.RuleRunStatusSuccessful,
+			StartTimestamp:  metav1.NewTime(start),
+			FinishTimestamp: metav1.NewTime(start.Add(2 * time.Second)),
+			ExitCode:        &exitCode,
+			Stdout:          "flushed",
+		},
+		{
+			Rule:            "prerule",
+			RuleType:        "preExecRule",
+			Namespace:       "namespace1",
+			Pod:             "mysql-1",
+			Container:       "mysql",
+			Action:          storkv1.RuleActionCommand,
+			Command:         "mysql -e 'flush tables'",
+			Status:          storkv1.RuleRunStatusFailed,
+			StartTimestamp:  metav1.NewTime(start),
+			FinishTimestamp: metav1.NewTime(start.Add(time.Minute)),
+			ExitCode:        &failedExitCode,
+			Stderr:          "access denied\n",
+			Reason:          "command terminated with exit code 1",
+		},
+	}
+	_, err = storkops.Instance().UpdateApplicationBackup(backup)
+	require.NoError(t, err, "Error updating backup")
+}
+
+func TestGetRuleRunsNoRuns(t *testing.T) {
+	defer resetTest()
+	createApplicationBackupAndVerify(t, "rulerunnoruns", "default", []string{"namespace1"}, "backuplocation", "", "")
+
+	cmdArgs := []string{"get", "ruleruns", "applicationbackup", "rulerunnoruns"}
+	expected := "No resources found.\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestGetRuleRunsApplicationBackup(t *testing.T) {
+	defer resetTest()
+	createRuleRunsBackup(t, "rulerunbackup", "default")
+
+	cmdArgs := []string{"get", "ruleruns", "backup", "rulerunbackup"}
+	expected := "RULE      TYPE          POD                  CONTAINER   ACTION    COMMAND                     STATUS       EXIT CODE   STARTED               ELAPSED\n" +
+		"prerule   preExecRule   namespace1/mysql-0   mysql       command   \"mysql -e 'flush tables'\"   Successful   0           02 Jan 21 03:04 UTC   2s\n" +
+		"prerule   preExecRule   namespace1/mysql-1   mysql       command   \"mysql -e 'flush tables'\"   Failed       1           02 Jan 21 03:04 UTC   1m0s\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	cmdArgs = []string{"get", "rulerun", "backup", "rulerunbackup", "--showOutput"}
+	expected = "RULE      TYPE          POD                  CONTAINER   ACTION    COMMAND                     STATUS       EXIT CODE   STARTED               ELAPSED   STDOUT      STDERR              REASON\n" +
+		"prerule   preExecRule   namespace1/mysql-0   mysql       command   \"mysql -e 'flush tables'\"   Successful   0           02 Jan 21 03:04 UTC   2s        \"flushed\"   \"\"                  \n" +
+		"prerule   preExecRule   namespace1/mysql-1   mysql       command   \"mysql -e 'flush tables'\"   Failed       1           02 Jan 21 03:04 UTC   1m0s      \"\"          \"access denied\\n\"   command terminated with exit code 1\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestGetRuleRunsInvalidArgs(t *testing.T) {
+	defer resetTest()
+	cmdArgs := []string{"get", "ruleruns", "backup"}
+	expected := "error: the type and name of the object that ran the rules need to be provided"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"get", "ruleruns", "pvc", "name"}
+	expected = "error: rule runs are not recorded for type pvc, should be one of applicationbackups, migrations, groupsnapshots or applicationclones"
+	testCommon(t, cmdArgs, nil, expected, true)
+
+	cmdArgs = []string{"get", "ruleruns", "migration", "missing"}
+	expected = "Error from server (NotFound): migrations.stork.libopenstorage.org \"missing\" not found"
+	testCommon(t, cmdArgs, nil, expected, true)
+}
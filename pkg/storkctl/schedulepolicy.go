@@ -2,8 +2,13 @@ package storkctl
 
 import (
 	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/schedule"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/spf13/cobra"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
@@ -16,7 +21,13 @@ const (
 	schedulePolicySubcommand = "schedulepolicy"
 )
 
-var schedulePolicyColumns = []string{"NAME", "INTERVAL-MINUTES", "DAILY", "WEEKLY", "MONTHLY"}
+const nextTriggerTimeFormat = "2006-01-02 15:04"
+
+var schedulePolicyColumns = []string{"NAME", "INTERVAL-MINUTES", "DAILY", "WEEKLY", "MONTHLY", "CRON", "TIMEZONE", "NEXT-TRIGGER"}
+
+// getCurrentTime is used to compute the next trigger time of the policies,
+// it is replaced in tests
+var getCurrentTime = schedule.GetCurrentTime
 
 func newGetSchedulePolicyCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var err error
@@ -108,14 +119,100 @@ func schedulePolicyPrinter(
 			}
 		}
 
+		cron := notConfiguredString
+		if schedulePolicy.Policy.Cron != nil {
+			if schedule.ValidateCronPolicy(schedulePolicy.Policy.Cron) == nil {
+				cron = schedulePolicy.Policy.Cron.Expression
+			} else {
+				cron = invalidString
+			}
+		}
+
+		timeZone := "Local"
+		if schedulePolicy.Policy.TimeZone != "" {
+			timeZone = schedulePolicy.Policy.TimeZone
+		}
+		nextTrigger := notConfiguredString
+		if _, err := schedulePolicy.Policy.GetLocation(); err != nil {
+			timeZone = invalidString
+		} else if next := getNextTriggerTime(&schedulePolicy.Policy); !next.IsZero() {
+			nextTrigger = next.Format(nextTriggerTimeFormat)
+		}
+
 		row := getRow(&schedulePolicy,
 			[]interface{}{schedulePolicy.Name,
 				interval,
 				daily,
 				weekly,
-				monthly},
+				monthly,
+				cron,
+				timeZone,
+				nextTrigger},
 		)
 		rows = append(rows, row)
 	}
 	return rows, nil
 }
+
+// getNextTriggerTime returns the earliest next trigger time across all the
+// valid policy types, in the time zone of the policy
+func getNextTriggerTime(policy *storkv1.SchedulePolicyItem) time.Time {
+	now := getCurrentTime()
+	var nextTrigger time.Time
+	for _, policyType := range storkv1.GetValidSchedulePolicyTypes() {
+		next, err := schedule.NextTriggerTime(policy, policyType, now)
+		if err != nil || next.IsZero() {
+			continue
+		}
+		if nextTrigger.IsZero() || next.Before(nextTrigger) {
+			nextTrigger = next
+		}
+	}
+	return nextTrigger
+}
+
+// printRetentionPreview prints which of the objects triggered by a schedule
+// would be retained or pruned by the GFS retention of its policy
+func printRetentionPreview(
+	out io.Writer,
+	kind string,
+	namespace string,
+	name string,
+	policyName string,
+	items []schedule.RetentionItem,
+) error {
+	results, err := schedule.PreviewGFSRetention(policyName, namespace, items)
+	if err != nil {
+		return err
+	}
+	if results == nil {
+		printMsg(fmt.Sprintf("%v %v/%v: SchedulePolicy %v doesn't use GFS retention", kind, namespace, name, policyName), out)
+		return nil
+	}
+	printMsg(fmt.Sprintf("%v %v/%v:", kind, namespace, name), out)
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPOLICY-TYPE\tCREATED\tRETAINED-BY\tACTION")
+	for _, result := range results {
+		retainedBy := "-"
+		if !result.Complete {
+			retainedBy = "InProgress"
+		} else if len(result.Tiers) > 0 {
+			tiers := make([]string, 0, len(result.Tiers))
+			for _, tier := range result.Tiers {
+				tiers = append(tiers, string(tier))
+			}
+			retainedBy = strings.Join(tiers, ",")
+		}
+		action := "Retain"
+		if result.Prune {
+			action = "Prune"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n",
+			result.Name,
+			result.PolicyType,
+			toTimeString(result.CreationTimestamp),
+			retainedBy,
+			action)
+	}
+	return w.Flush()
+}
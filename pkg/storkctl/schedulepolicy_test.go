@@ -5,13 +5,26 @@ package storkctl
 
 import (
 	"testing"
+	"time"
 
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/schedule"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/stretchr/testify/require"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// mockSchedulePolicyTime sets the time used to compute the next trigger
+// times and returns a function to reset it
+func mockSchedulePolicyTime(now time.Time) func() {
+	getCurrentTime = func() time.Time {
+		return now
+	}
+	return func() {
+		getCurrentTime = schedule.GetCurrentTime
+	}
+}
+
 func TestNoSchedulePolicy(t *testing.T) {
 	cmdArgs := []string{"get", "schedulepolicy"}
 
@@ -36,8 +49,8 @@ func TestSchedulePolicyNotFound(t *testing.T) {
 	expected = `Error from server (NotFound): schedulepolicies.stork.libopenstorage.org "testpolicy" not found`
 	testCommon(t, cmdArgs, nil, expected, true)
 
-	expected = "NAME          INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY\n" +
-		"testpolicy1   N/A                N/A     N/A      N/A\n"
+	expected = "NAME          INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"testpolicy1   N/A                N/A     N/A      N/A       N/A    Local      N/A\n"
 	cmdArgs = []string{"get", "schedulepolicy", "testpolicy1"}
 	testCommon(t, cmdArgs, nil, expected, false)
 }
@@ -59,8 +72,8 @@ func TestIntervalSchedulePolicy(t *testing.T) {
 	_, err := storkops.Instance().CreateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected := "NAME             INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY\n" +
-		"intervalpolicy   Invalid            N/A     N/A      N/A\n"
+	expected := "NAME             INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"intervalpolicy   Invalid            N/A     N/A      N/A       N/A    Local      N/A\n"
 	cmdArgs := []string{"get", "schedulepolicy", "intervalpolicy"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -69,13 +82,14 @@ func TestIntervalSchedulePolicy(t *testing.T) {
 	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected = "NAME             INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY\n" +
-		"intervalpolicy   60                 N/A     N/A      N/A\n"
+	expected = "NAME             INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"intervalpolicy   60                 N/A     N/A      N/A       N/A    Local      N/A\n"
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
 func TestDailySchedulePolicy(t *testing.T) {
 	defer resetTest()
+	defer mockSchedulePolicyTime(time.Date(2019, time.February, 7, 10, 0, 0, 0, time.Local))()
 
 	schedulePolicy := &storkv1.SchedulePolicy{
 		ObjectMeta: meta.ObjectMeta{
@@ -91,8 +105,8 @@ func TestDailySchedulePolicy(t *testing.T) {
 	_, err := storkops.Instance().CreateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected := "NAME          INTERVAL-MINUTES   DAILY     WEEKLY   MONTHLY\n" +
-		"dailypolicy   N/A                Invalid   N/A      N/A\n"
+	expected := "NAME          INTERVAL-MINUTES   DAILY     WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"dailypolicy   N/A                Invalid   N/A      N/A       N/A    Local      N/A\n"
 	cmdArgs := []string{"get", "schedulepolicy", "dailypolicy"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -105,13 +119,14 @@ func TestDailySchedulePolicy(t *testing.T) {
 	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected = "NAME          INTERVAL-MINUTES   DAILY     WEEKLY   MONTHLY\n" +
-		"dailypolicy   N/A                12:15pm   N/A      N/A\n"
+	expected = "NAME          INTERVAL-MINUTES   DAILY     WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"dailypolicy   N/A                12:15pm   N/A      N/A       N/A    Local      2019-02-07 12:15\n"
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
 func TestWeeklySchedulePolicy(t *testing.T) {
 	defer resetTest()
+	defer mockSchedulePolicyTime(time.Date(2019, time.February, 7, 10, 0, 0, 0, time.Local))()
 
 	schedulePolicy := &storkv1.SchedulePolicy{
 		ObjectMeta: meta.ObjectMeta{
@@ -128,8 +143,8 @@ func TestWeeklySchedulePolicy(t *testing.T) {
 	_, err := storkops.Instance().CreateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected := "NAME           INTERVAL-MINUTES   DAILY   WEEKLY    MONTHLY\n" +
-		"weeklypolicy   N/A                N/A     Invalid   N/A\n"
+	expected := "NAME           INTERVAL-MINUTES   DAILY   WEEKLY    MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"weeklypolicy   N/A                N/A     Invalid   N/A       N/A    Local      N/A\n"
 	cmdArgs := []string{"get", "schedulepolicy", "weeklypolicy"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -144,13 +159,14 @@ func TestWeeklySchedulePolicy(t *testing.T) {
 	schedulePolicy.Policy.Weekly.Time = "12:15pm"
 	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
-	expected = "NAME           INTERVAL-MINUTES   DAILY   WEEKLY        MONTHLY\n" +
-		"weeklypolicy   N/A                N/A     Sun@12:15pm   N/A\n"
+	expected = "NAME           INTERVAL-MINUTES   DAILY   WEEKLY        MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"weeklypolicy   N/A                N/A     Sun@12:15pm   N/A       N/A    Local      2019-02-10 12:15\n"
 	testCommon(t, cmdArgs, nil, expected, false)
 }
 
 func TestMonthlySchedulePolicy(t *testing.T) {
 	defer resetTest()
+	defer mockSchedulePolicyTime(time.Date(2019, time.February, 7, 10, 0, 0, 0, time.Local))()
 
 	schedulePolicy := &storkv1.SchedulePolicy{
 		ObjectMeta: meta.ObjectMeta{
@@ -167,8 +183,8 @@ func TestMonthlySchedulePolicy(t *testing.T) {
 	_, err := storkops.Instance().CreateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
 
-	expected := "NAME            INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY\n" +
-		"monthlypolicy   N/A                N/A     N/A      Invalid\n"
+	expected := "NAME            INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"monthlypolicy   N/A                N/A     N/A      Invalid   N/A    Local      N/A\n"
 	cmdArgs := []string{"get", "schedulepolicy", "monthlypolicy"}
 	testCommon(t, cmdArgs, nil, expected, false)
 
@@ -183,7 +199,50 @@ func TestMonthlySchedulePolicy(t *testing.T) {
 	schedulePolicy.Policy.Monthly.Time = "12:15pm"
 	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
 	require.NoError(t, err, "Error creating schedulepolicy")
-	expected = "NAME            INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY\n" +
-		"monthlypolicy   N/A                N/A     N/A      15@12:15pm\n"
+	expected = "NAME            INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY      CRON   TIMEZONE   NEXT-TRIGGER\n" +
+		"monthlypolicy   N/A                N/A     N/A      15@12:15pm   N/A    Local      2019-02-15 12:15\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+}
+
+func TestCronSchedulePolicy(t *testing.T) {
+	defer resetTest()
+	defer mockSchedulePolicyTime(time.Date(2019, time.February, 7, 10, 0, 0, 0, time.UTC))()
+
+	schedulePolicy := &storkv1.SchedulePolicy{
+		ObjectMeta: meta.ObjectMeta{
+			Name: "cronpolicy",
+		},
+		Policy: storkv1.SchedulePolicyItem{
+			Cron: &storkv1.CronPolicy{
+				//Invalid minute
+				Expression: "61 2 * * 1-5",
+			},
+			TimeZone: "America/New_York",
+		},
+	}
+	_, err := storkops.Instance().CreateSchedulePolicy(schedulePolicy)
+	require.NoError(t, err, "Error creating schedulepolicy")
+
+	expected := "NAME         INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON      TIMEZONE           NEXT-TRIGGER\n" +
+		"cronpolicy   N/A                N/A     N/A      N/A       Invalid   America/New_York   N/A\n"
+	cmdArgs := []string{"get", "schedulepolicy", "cronpolicy"}
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	// Update with valid expression but invalid time zone
+	schedulePolicy.Policy.Cron.Expression = "30 2 * * 1-5"
+	schedulePolicy.Policy.TimeZone = "Mars/Olympus_Mons"
+	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
+	require.NoError(t, err, "Error updating schedulepolicy")
+	expected = "NAME         INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON           TIMEZONE   NEXT-TRIGGER\n" +
+		"cronpolicy   N/A                N/A     N/A      N/A       30 2 * * 1-5   Invalid    N/A\n"
+	testCommon(t, cmdArgs, nil, expected, false)
+
+	// Update with valid time zone, 10:00 UTC on Thursday is 05:00 in New
+	// York so the next trigger is on Friday
+	schedulePolicy.Policy.TimeZone = "America/New_York"
+	_, err = storkops.Instance().UpdateSchedulePolicy(schedulePolicy)
+	require.NoError(t, err, "Error updating schedulepolicy")
+	expected = "NAME         INTERVAL-MINUTES   DAILY   WEEKLY   MONTHLY   CRON           TIMEZONE           NEXT-TRIGGER\n" +
+		"cronpolicy   N/A                N/A     N/A      N/A       30 2 * * 1-5   America/New_York   2019-02-08 02:30\n"
 	testCommon(t, cmdArgs, nil, expected, false)
 }
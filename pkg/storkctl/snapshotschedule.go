@@ -6,6 +6,7 @@ import (
 
 	snapv1 "github.com/kubernetes-incubator/external-storage/snapshot/pkg/apis/crd/v1"
 	storkv1 "github.com/libopenstorage/stork/pkg/apis/stork/v1alpha1"
+	"github.com/libopenstorage/stork/pkg/schedule"
 	storkops "github.com/portworx/sched-ops/k8s/stork"
 	"github.com/spf13/cobra"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
@@ -79,6 +80,7 @@ func newCreateSnapshotScheduleCommand(cmdFactory Factory, ioStreams genericcliop
 
 func newGetSnapshotScheduleCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
 	var pvc string
+	var retentionPreview bool
 	getSnapshotScheduleCommand := &cobra.Command{
 		Use:     snapshotScheduleSubcommand,
 		Aliases: snapshotScheduleAliases,
@@ -134,6 +136,16 @@ func newGetSnapshotScheduleCommand(cmdFactory Factory, ioStreams genericclioptio
 				return
 			}
 
+			if retentionPreview {
+				for _, sched := range snapshotSchedules.Items {
+					if err := printRetentionPreview(ioStreams.Out, "VolumeSnapshotSchedule", sched.Namespace, sched.Name, sched.Spec.SchedulePolicyName, schedule.GetVolumeSnapshotRetentionItems(sched.Status.Items)); err != nil {
+						util.CheckErr(err)
+						return
+					}
+				}
+				return
+			}
+
 			if err := printObjects(c, snapshotSchedules, cmdFactory, snapshotScheduleColumns, snapshotSchedulePrinter, ioStreams.Out); err != nil {
 				util.CheckErr(err)
 				return
@@ -141,6 +153,7 @@ func newGetSnapshotScheduleCommand(cmdFactory Factory, ioStreams genericclioptio
 		},
 	}
 	getSnapshotScheduleCommand.Flags().StringVarP(&pvc, "pvc", "p", "", "Name of the PVC for which to list snapshot schedules")
+	getSnapshotScheduleCommand.Flags().BoolVarP(&retentionPreview, "retentionPreview", "", false, "Show which snapshots would be retained or pruned by the GFS retention of the schedule policy")
 	cmdFactory.BindGetFlags(getSnapshotScheduleCommand.Flags())
 
 	return getSnapshotScheduleCommand
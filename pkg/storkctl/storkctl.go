@@ -31,11 +31,14 @@ func NewCommand(cmdFactory Factory, in io.Reader, out io.Writer, errOut io.Write
 		newCreateCommand(cmdFactory, ioStreams),
 		newDeleteCommand(cmdFactory, ioStreams),
 		newGetCommand(cmdFactory, ioStreams),
+		newDescribeCommand(cmdFactory, ioStreams),
 		newActivateCommand(cmdFactory, ioStreams),
 		newDeactivateCommand(cmdFactory, ioStreams),
 		newGenerateCommand(cmdFactory, ioStreams),
 		newSuspendCommand(cmdFactory, ioStreams),
 		newResumeCommand(cmdFactory, ioStreams),
+		newPerformCommand(cmdFactory, ioStreams),
+		newVerifyCommand(cmdFactory, ioStreams),
 		newVersionCommand(cmdFactory, ioStreams),
 	)
 
@@ -0,0 +1,19 @@
+package storkctl
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newVerifyCommand(cmdFactory Factory, ioStreams genericclioptions.IOStreams) *cobra.Command {
+	verifyCommands := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify stork resources",
+	}
+
+	verifyCommands.AddCommand(
+		newVerifyApplicationBackupCommand(cmdFactory, ioStreams),
+	)
+
+	return verifyCommands
+}